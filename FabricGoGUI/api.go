@@ -0,0 +1,412 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APISettings configure the local automation API, which lets scripts and
+// launchers such as Raycast or Alfred run presets and read history
+type APISettings struct {
+	Enabled bool   `json:"enabled"`
+	Port    int    `json:"port"`  // on 127.0.0.1, defaults to defaultAPIPort
+	Token   string `json:"token"` // required as "Authorization: Bearer <token>", generated when empty
+}
+
+// APIStatus describes the local automation API
+type APIStatus struct {
+	Running bool   `json:"running"`
+	URL     string `json:"url,omitempty"`
+	Error   string `json:"error,omitempty"` // why the API could not start
+}
+
+// APIRunRequest is the body of POST /v1/run. A preset is run on the input,
+// otherwise the pattern with the model.
+type APIRunRequest struct {
+	Preset    string            `json:"preset"`
+	Pattern   string            `json:"pattern"`
+	Vendor    string            `json:"vendor"`
+	Model     string            `json:"model"`
+	Input     string            `json:"input"`
+	Variables map[string]string `json:"variables"`
+	Async     bool              `json:"async"` // return the job at once instead of waiting for the output
+}
+
+// APIRunResult is the response to a finished run
+type APIRunResult struct {
+	HistoryID string `json:"historyId"`
+	Output    string `json:"output"`
+}
+
+// apiHistoryItem is an entry in GET /v1/history, without the texts
+type apiHistoryItem struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Pattern string `json:"pattern"`
+	Model   string `json:"model"`
+	Time    int64  `json:"time"`
+	Error   string `json:"error,omitempty"`
+}
+
+const (
+	defaultAPIPort = 8765
+	// defaultAPIHistoryLimit is how many entries GET /v1/history lists
+	// without a limit
+	defaultAPIHistoryLimit = 20
+	// maxAPIRequestSize bounds request bodies, inputs included
+	maxAPIRequestSize = 16 << 20
+)
+
+// GetAPISettings returns the local API settings
+func (a *App) GetAPISettings() APISettings {
+	if prefs, err := a.loadPreferences(); err == nil {
+		return prefs.API
+	}
+	return APISettings{}
+}
+
+// SaveAPISettings stores the local API settings, generating a token when
+// there is none, and starts or stops the API to match. Returns the settings
+// as saved.
+func (a *App) SaveAPISettings(settings APISettings) (APISettings, error) {
+	if settings.Port < 0 || settings.Port > 65535 {
+		return settings, fmt.Errorf("port must be between 1 and 65535")
+	}
+	settings.Token = strings.TrimSpace(settings.Token)
+	if settings.Token == "" {
+		settings.Token = newAPIToken()
+	}
+
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return settings, err
+	}
+	prefs.API = settings
+	if err := a.SavePreferences(*prefs); err != nil {
+		return settings, err
+	}
+
+	// The token is read on every request, only a new port needs a restart
+	a.stopAPI()
+	if settings.Enabled {
+		return settings, a.startAPI(settings)
+	}
+	return settings, nil
+}
+
+// RegenerateAPIToken replaces the API token, locking out every client that
+// used the old one, and returns the new token
+func (a *App) RegenerateAPIToken() (string, error) {
+	settings := a.GetAPISettings()
+	settings.Token = ""
+	saved, err := a.SaveAPISettings(settings)
+	return saved.Token, err
+}
+
+// GetAPIStatus reports whether the local API is serving
+func (a *App) GetAPIStatus() APIStatus {
+	a.apiMutex.Lock()
+	defer a.apiMutex.Unlock()
+	status := APIStatus{Running: a.api != nil, Error: a.apiError}
+	if a.api != nil {
+		status.URL = "http://" + a.api.Addr
+	}
+	return status
+}
+
+// startAPI serves the API on localhost if it is not serving yet
+func (a *App) startAPI(settings APISettings) error {
+	a.apiMutex.Lock()
+	defer a.apiMutex.Unlock()
+	if a.api != nil {
+		return nil
+	}
+	port := settings.Port
+	if port == 0 {
+		port = defaultAPIPort
+	}
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		a.apiError = fmt.Sprintf("failed to listen on %s: %v", addr, err)
+		a.log.Warn("failed to start API", "error", err)
+		return fmt.Errorf("%s", a.apiError)
+	}
+
+	server := &http.Server{Addr: addr, Handler: a.apiHandler(port), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			a.log.Error("API stopped", "error", err)
+		}
+	}()
+	a.api = server
+	a.apiError = ""
+	a.log.Info("started API", "address", addr)
+	return nil
+}
+
+// stopAPI stops serving the API, letting requests in flight finish briefly
+func (a *App) stopAPI() {
+	a.apiMutex.Lock()
+	server := a.api
+	a.api = nil
+	a.apiMutex.Unlock()
+	if server == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		server.Close()
+	}
+}
+
+// apiHandler routes the API's endpoints behind the token and host checks
+func (a *App) apiHandler(port int) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/status", a.apiStatus)
+	mux.HandleFunc("GET /v1/patterns", a.apiPatterns)
+	mux.HandleFunc("GET /v1/presets", a.apiPresets)
+	mux.HandleFunc("POST /v1/run", a.apiRun)
+	mux.HandleFunc("POST /v1/extension/run", a.apiExtensionRun)
+	mux.HandleFunc("GET /v1/jobs/{id}", a.apiJob)
+	mux.HandleFunc("GET /v1/history", a.apiHistory)
+	mux.HandleFunc("GET /v1/history/{id}", a.apiHistoryEntry)
+	mux.HandleFunc("GET /v1/history/{id}/output", a.apiHistoryOutput)
+
+	hosts := []string{"127.0.0.1:" + strconv.Itoa(port), "localhost:" + strconv.Itoa(port)}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Web pages can reach localhost too, a foreign Host means DNS rebinding
+		if !slices.ContainsFunc(hosts, func(host string) bool { return strings.EqualFold(host, r.Host) }) {
+			apiError(w, http.StatusForbidden, "unexpected host")
+			return
+		}
+		// The browser extension's requests are cross-origin and preflighted
+		if origin := r.Header.Get("Origin"); isExtensionOrigin(origin) {
+			allowExtensionOrigin(w, origin)
+			if r.Method == http.MethodOptions {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		token := a.GetAPISettings().Token
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			apiError(w, http.StatusUnauthorized, "missing or wrong token")
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxAPIRequestSize)
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// apiStatus handles GET /v1/status
+func (a *App) apiStatus(w http.ResponseWriter, r *http.Request) {
+	apiJSON(w, http.StatusOK, map[string]any{
+		"version":      appVersion,
+		"serverOnline": a.CheckHealth(),
+	})
+}
+
+// apiPatterns handles GET /v1/patterns
+func (a *App) apiPatterns(w http.ResponseWriter, r *http.Request) {
+	patterns, err := a.GetPatterns()
+	if err != nil {
+		apiError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	apiJSON(w, http.StatusOK, patterns)
+}
+
+// apiPresets handles GET /v1/presets
+func (a *App) apiPresets(w http.ResponseWriter, r *http.Request) {
+	presets, err := a.ListPresets()
+	if err != nil {
+		apiError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	apiJSON(w, http.StatusOK, presets)
+}
+
+// apiRun handles POST /v1/run, waiting for the output unless async is set
+func (a *App) apiRun(w http.ResponseWriter, r *http.Request) {
+	var req APIRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiError(w, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", err))
+		return
+	}
+	prompt, err := a.apiPrompt(req)
+	if err != nil {
+		apiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	a.serveRun(w, r, prompt, req.Preset, req.Async)
+}
+
+// serveRun runs prompt as a job and responds with its output, or at once
+// with the job when async is set. label names the job, the pattern when empty.
+func (a *App) serveRun(w http.ResponseWriter, r *http.Request, prompt PromptRequest, label string, async bool) {
+	if label == "" {
+		label = prompt.PatternName
+	}
+	j, ctx := a.startJob("chat", "API: "+label)
+	if async {
+		snapshot, _ := a.jobSnapshot(j.ID)
+		go a.runAPIJob(j, ctx, prompt)
+		apiJSON(w, http.StatusAccepted, snapshot)
+		return
+	}
+
+	// A client that goes away cancels its run
+	stop := context.AfterFunc(r.Context(), j.cancel)
+	defer stop()
+	result, err := a.runAPIJob(j, ctx, prompt)
+	if err != nil {
+		apiError(w, http.StatusBadGateway, toChatError(err).Message)
+		return
+	}
+	apiJSON(w, http.StatusOK, result)
+}
+
+// apiPrompt builds the prompt an API run asks for
+func (a *App) apiPrompt(req APIRunRequest) (PromptRequest, error) {
+	if strings.TrimSpace(req.Input) == "" {
+		return PromptRequest{}, fmt.Errorf("input is required")
+	}
+	if req.Preset != "" {
+		return a.presetPrompt(req.Preset, req.Input)
+	}
+	if req.Model == "" {
+		return PromptRequest{}, fmt.Errorf("a preset or a model is required")
+	}
+	vendor := req.Vendor
+	if vendor == "" {
+		var err error
+		if vendor, err = a.vendorForModel(req.Model); err != nil {
+			return PromptRequest{}, err
+		}
+	}
+	prompt := a.newPrompt(req.Pattern, vendor, req.Model, req.Input)
+	prompt.Variables = req.Variables
+	return prompt, nil
+}
+
+// runAPIJob runs an API prompt as job j and records it in history
+func (a *App) runAPIJob(j *job, ctx context.Context, prompt PromptRequest) (*APIRunResult, error) {
+	start := time.Now()
+	var stats runStats
+	output, err := a.streamChatWithRetry(ctx, prompt, func(string) {}, stats.addUsage, func(ChatRetry) {})
+	stats.duration = time.Since(start)
+	stats.err = err
+
+	var historyID string
+	if err == nil || output != "" {
+		historyID = a.recordHistory(chatRun{Prompt: prompt}, output, stats)
+		a.setJobResult(j, historyID)
+	}
+	a.finishJob(j, err)
+	if err != nil {
+		return nil, err
+	}
+	return &APIRunResult{HistoryID: historyID, Output: output}, nil
+}
+
+// apiJob handles GET /v1/jobs/{id}. A finished run's result is its history
+// entry ID.
+func (a *App) apiJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := a.jobSnapshot(r.PathValue("id"))
+	if !ok {
+		apiError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	apiJSON(w, http.StatusOK, job)
+}
+
+// apiHistory handles GET /v1/history, newest first. ?limit= and ?pattern=
+// narrow the list.
+func (a *App) apiHistory(w http.ResponseWriter, r *http.Request) {
+	limit := defaultAPIHistoryLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			apiError(w, http.StatusBadRequest, "limit must be a positive number")
+			return
+		}
+		limit = n
+	}
+	pattern := r.URL.Query().Get("pattern")
+
+	entries := a.history.All()
+	items := []apiHistoryItem{}
+	for i := len(entries) - 1; i >= 0 && len(items) < limit; i-- {
+		e := entries[i]
+		if pattern != "" && e.Pattern != pattern {
+			continue
+		}
+		items = append(items, apiHistoryItem{ID: e.ID, Title: defaultNoteTitle(e), Pattern: e.Pattern, Model: e.Model, Time: e.Time, Error: e.Error})
+	}
+	apiJSON(w, http.StatusOK, items)
+}
+
+// apiHistoryEntry handles GET /v1/history/{id}, where "latest" is the
+// newest entry
+func (a *App) apiHistoryEntry(w http.ResponseWriter, r *http.Request) {
+	entry, ok := a.apiEntry(r)
+	if !ok {
+		apiError(w, http.StatusNotFound, "history entry not found")
+		return
+	}
+	apiJSON(w, http.StatusOK, entry)
+}
+
+// apiHistoryOutput handles GET /v1/history/{id}/output, the output alone
+// as Markdown for shell scripts
+func (a *App) apiHistoryOutput(w http.ResponseWriter, r *http.Request) {
+	entry, ok := a.apiEntry(r)
+	if !ok {
+		apiError(w, http.StatusNotFound, "history entry not found")
+		return
+	}
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Write([]byte(entry.Output))
+}
+
+// apiEntry finds the history entry a request names
+func (a *App) apiEntry(r *http.Request) (HistoryEntry, bool) {
+	id := r.PathValue("id")
+	if id == "latest" {
+		id = ""
+	}
+	return a.historyEntryOrLast(id)
+}
+
+// apiJSON writes v as a JSON response
+func apiJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// apiError writes a JSON error response
+func apiError(w http.ResponseWriter, status int, message string) {
+	apiJSON(w, status, map[string]string{"error": message})
+}
+
+// newAPIToken returns a random API token
+func newAPIToken() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
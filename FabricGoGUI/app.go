@@ -4,14 +4,17 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
@@ -19,31 +22,166 @@ import (
 
 // App struct holds the application context and configuration
 type App struct {
-	ctx           context.Context
-	baseURL       string
-	client        *http.Client
-	history       []HistoryEntry
-	serverProcess *exec.Cmd
-	serverMutex   sync.Mutex
+	ctx               context.Context
+	headless          bool // run from the command line, without a window, see runCLI
+	baseURL           string
+	client            *http.Client
+	streamIdleTimeout time.Duration
+	external          *http.Client // client for third-party services, see newExternalClient
+	connMutex         sync.RWMutex // guards baseURL, client, external and streamIdleTimeout, which profile switches replace
+	fabric            FabricClient // replaces the HTTP client to the Fabric server when set
+	log               *slog.Logger
+	logLevel          slog.LevelVar
+	logFile           *rotatingFile
+	history           *historyStore
+	patternStats      *patternStatsStore
+	catalog           *catalogCache
+	secrets           *secretStore // vendor API keys, nil without a config directory
+	redact            *redactor    // scrubs secrets from logs and diagnostics
+	session           *sessionSaver
+	serverProcess     *exec.Cmd
+	serverMutex       sync.Mutex
+	watcher           *clipboardWatcher
+	watcherMutex      sync.Mutex
+	recording         *recording
+	recordingMutex    sync.Mutex
+	speech            *speech
+	speechMutex       sync.Mutex
+	codeBlocks        []CodeBlock // blocks of the last output ExtractCodeBlocks was given
+	codeBlocksMutex   sync.Mutex
+	tray              *statusNotifier // tray icon, nil when not shown
+	trayError         string          // why the tray icon could not be shown
+	trayMutex         sync.Mutex
+	quitting          atomic.Bool      // set by quit so closing is not turned into hiding to the tray
+	hotkey            *globalShortcuts // global hotkeys, nil when not bound
+	hotkeyError       string           // why the hotkey could not be bound
+	hotkeyMutex       sync.Mutex
+	hotkeyBusy        atomic.Bool      // a hotkey run is in progress
+	notifier          *desktopNotifier // Linux notification service, connected on first use
+	notifierMutex     sync.Mutex
+	mini              *windowGeometry // full window geometry while the mini window is shown
+	miniMutex         sync.Mutex
+	api               *http.Server // local automation API, nil when not serving
+	apiError          string       // why the API could not start
+	apiMutex          sync.Mutex
+	scheduler         *scheduler // runs schedules, nil in headless runs
+	schedulerMutex    sync.Mutex
+	folderWatch       *folderWatcher // watches the enabled folder watches, nil when there are none
+	folderWatchMutex  sync.Mutex
+	feedsMutex        sync.Mutex // held while a feed digest runs
+	attachments       []ImageAttachment
+	attachmentsMutex  sync.Mutex
+	jobs              map[string]*job
+	jobSeq            int
+	jobsMutex         sync.Mutex
+	streams           map[string]*job
+	interrupted       map[string]*interruptedStream
+	streamsMutex      sync.Mutex
+	patternIndex      patternIndex
+	tasks             sync.WaitGroup // background work started by runs, which headless runs wait for
+	frontendReady     bool           // the frontend's event handlers are registered, see FrontendReady
+	pendingEvents     []pendingEvent // emitted by emitWhenReady before the frontend was ready
+	readyMutex        sync.Mutex
+}
+
+// pendingEvent is an event held back until the frontend is ready
+type pendingEvent struct {
+	name string
+	data interface{}
 }
 
 // HistoryEntry represents a single history item
 type HistoryEntry struct {
-	Pattern string `json:"pattern"`
-	Model   string `json:"model"`
-	Input   string `json:"input"`
-	Output  string `json:"output"`
-	Time    int64  `json:"time"`
+	ID           string            `json:"id"`
+	Title        string            `json:"title,omitempty"`
+	Pattern      string            `json:"pattern"`
+	Model        string            `json:"model"`
+	Input        string            `json:"input"`
+	Output       string            `json:"output"`
+	Time         int64             `json:"time"`
+	Note         string            `json:"note,omitempty"`
+	Pinned       bool              `json:"pinned,omitempty"`
+	Tags         []string          `json:"tags,omitempty"`
+	RerunOf      string            `json:"rerunOf,omitempty"`      // ID of the entry this run replayed
+	Variables    map[string]string `json:"variables,omitempty"`    // pattern variables the run used
+	Context      string            `json:"context,omitempty"`      // Fabric context the run included
+	Strategy     string            `json:"strategy,omitempty"`     // prompting strategy the run used
+	Session      string            `json:"session,omitempty"`      // server-side session the run belonged to
+	SystemPrompt string            `json:"systemPrompt,omitempty"` // ad-hoc system prompt the run used
+	SystemMode   string            `json:"systemMode,omitempty"`   // how SystemPrompt combined with the pattern
+	ThreadID     string            `json:"threadId,omitempty"`     // ID of the first entry in the conversation, unset for the first turn
+	ChunkOf      string            `json:"chunkOf,omitempty"`      // shared by the parts and combined result of a chunked run
+	Part         int               `json:"part,omitempty"`         // chunk of a chunked run this processed, 0 for the combined result
+
+	Vendor           string `json:"vendor,omitempty"`
+	DurationMs       int64  `json:"durationMs,omitempty"`
+	PromptTokens     int    `json:"promptTokens,omitempty"`
+	CompletionTokens int    `json:"completionTokens,omitempty"`
+	Error            string `json:"error,omitempty"` // set when the run failed, Output then holds any partial text
 }
 
 // Preferences holds user preferences
 type Preferences struct {
-	BaseURL         string `json:"baseUrl"`
-	Theme           string `json:"theme"`
-	AutoStartServer bool   `json:"autoStartServer"`
-	LastPattern     string `json:"lastPattern"`
-	LastModel       string `json:"lastModel"`
-	LastVendor      string `json:"lastVendor"`
+	BaseURL           string                  `json:"baseUrl"`
+	Theme             string                  `json:"theme"`
+	AutoStartServer   bool                    `json:"autoStartServer"`
+	LastPattern       string                  `json:"lastPattern"`
+	LastModel         string                  `json:"lastModel"`
+	LastVendor        string                  `json:"lastVendor"`
+	QuickPattern      string                  `json:"quickPattern"`
+	QuickModel        string                  `json:"quickModel"`
+	QuickVendor       string                  `json:"quickVendor"`
+	WhisperProvider   string                  `json:"whisperProvider"`
+	WhisperURL        string                  `json:"whisperUrl"`
+	WhisperAPIKey     string                  `json:"whisperApiKey"`
+	WhisperModel      string                  `json:"whisperModel"`
+	WhisperLanguage   string                  `json:"whisperLanguage"`
+	RecordingDevice   string                  `json:"recordingDevice"`
+	OCRDroppedImages  bool                    `json:"ocrDroppedImages"`
+	OCRLanguage       string                  `json:"ocrLanguage"`
+	OCREndpoint       string                  `json:"ocrEndpoint"`
+	BatchWorkers      int                     `json:"batchWorkers"`
+	BatchTemplate     string                  `json:"batchTemplate"`
+	DisableChatRetry  bool                    `json:"disableChatRetry"`
+	ChatMaxRetries    int                     `json:"chatMaxRetries"`
+	ChatRetryDelayMs  int                     `json:"chatRetryDelayMs"`
+	HistoryMaxEntries int                     `json:"historyMaxEntries"`
+	HistoryMaxSizeMB  int                     `json:"historyMaxSizeMb"`
+	HistoryMaxAgeDays int                     `json:"historyMaxAgeDays"`
+	HistoryTitles     string                  `json:"historyTitles"`     // heuristic (default), model or off
+	HistoryEncryption string                  `json:"historyEncryption"` // off (default), keychain or passphrase
+	TitleVendor       string                  `json:"titleVendor"`
+	TitleModel        string                  `json:"titleModel"`
+	ProxyMode         string                  `json:"proxyMode"`         // system (default, from HTTP_PROXY/HTTPS_PROXY), manual or none
+	ProxyURL          string                  `json:"proxyUrl"`          // http://, https:// or socks5:// proxy for manual mode
+	NoProxy           string                  `json:"noProxy"`           // comma-separated hosts that bypass the manual proxy
+	LogLevel          string                  `json:"logLevel"`          // debug, info (default), warn or error
+	CustomPatternDirs []string                `json:"customPatternDirs"` // searched in order, new patterns are saved to the first
+	PatternModels     map[string]PatternModel `json:"patternModels"`     // default model per pattern
+	OllamaURL         string                  `json:"ollamaUrl"`         // Ollama server, defaults to Fabric's OLLAMA_API_URL
+	OpenAIEndpoints   []OpenAIEndpoint        `json:"openaiEndpoints"`   // extra OpenAI-compatible servers, written to Fabric's .env
+	RefuseOversized   bool                    `json:"refuseOversized"`   // refuse chats larger than the model's context window instead of warning
+	ModelVisibility   ModelVisibility         `json:"modelVisibility"`   // vendors and models left out of GetModels
+	FavoriteModels    []FavoriteModel         `json:"favoriteModels"`    // pinned above the model list
+	Obsidian          ObsidianSettings        `json:"obsidian"`          // vault that SaveToObsidian writes notes to
+	DailyNote         *DailyNoteSettings      `json:"dailyNote"`         // nil until saved, so the default heading applies
+	AutoSave          AutoSaveSettings        `json:"autoSave"`          // save every completed run to a folder
+	TTS               TTSSettings             `json:"tts"`               // engine SpeakOutput reads with
+	Webhooks          []Webhook               `json:"webhooks"`          // URLs run results are POSTed to
+	SlackWebhookURL   string                  `json:"slackWebhookUrl"`   // incoming webhook SendToSlack posts to
+	DiscordWebhookURL string                  `json:"discordWebhookUrl"` // channel webhook SendToDiscord posts to
+	Notion            NotionSettings          `json:"notion"`            // integration ExportToNotion creates pages with
+	SMTP              SMTPSettings            `json:"smtp"`              // mail server SendOutputByEmail sends through
+	Hooks             []Hook                  `json:"hooks"`             // commands run on outputs after a run
+	Tray              TraySettings            `json:"tray"`              // system tray icon and its quick actions
+	Hotkey            HotkeySettings          `json:"hotkey"`            // global hotkey that runs a preset on the clipboard
+	Notifications     *NotificationSettings   `json:"notifications"`     // nil until saved, so the defaults apply
+	API               APISettings             `json:"api"`               // local automation API for scripts and launchers
+	Schedules         []Schedule              `json:"schedules"`         // pattern runs started on a cron schedule
+	FolderWatches     []FolderWatch           `json:"folderWatches"`     // folders whose new files are run through a pattern
+	Feeds             []FeedSubscription      `json:"feeds"`             // feeds whose new items RunFeedDigest runs
+	GitHubToken       string                  `json:"githubToken"`       // kept in secret storage, see SetGitHubToken
+	GitHubHosts       []string                `json:"githubHosts"`       // GitHub Enterprise servers the GitHub token is sent to
 }
 
 // ModelsResponse represents the API response for models
@@ -55,21 +193,70 @@ type ModelsResponse struct {
 // ChatRequest represents a chat API request
 type ChatRequest struct {
 	Prompts []PromptRequest `json:"prompts"`
+	ChatOptions
+}
+
+// ChatOptions are the model parameters sent with a chat request. Zero values
+// leave the server's defaults in place.
+type ChatOptions struct {
+	Temperature      float64 `json:"temperature,omitempty"`
+	TopP             float64 `json:"topP,omitempty"`
+	PresencePenalty  float64 `json:"presencePenalty,omitempty"`
+	FrequencyPenalty float64 `json:"frequencyPenalty,omitempty"`
+	Seed             int     `json:"seed,omitempty"`
 }
 
 // PromptRequest represents a single prompt in a chat request
 type PromptRequest struct {
-	UserInput   string `json:"userInput"`
-	Vendor      string `json:"vendor"`
-	Model       string `json:"model"`
-	PatternName string `json:"patternName"`
+	UserInput    string            `json:"userInput"`
+	Vendor       string            `json:"vendor"`
+	Model        string            `json:"model"`
+	PatternName  string            `json:"patternName"`
+	Variables    map[string]string `json:"variables,omitempty"` // values for {{name}} placeholders in the pattern
+	ContextName  string            `json:"contextName,omitempty"`
+	StrategyName string            `json:"strategyName,omitempty"`
+	SessionName  string            `json:"sessionName,omitempty"`
+	SystemPrompt string            `json:"-"` // inlined by streamChat, see resolveSystemPrompt
+	SystemMode   string            `json:"-"` // how SystemPrompt combines with the pattern
+	Attachments  []string          `json:"attachments,omitempty"`
+	Options      ChatOptions       `json:"-"` // sent at the request level
+}
+
+// ChatExtras are the optional parts of a chat request
+type ChatExtras struct {
+	Variables    map[string]string `json:"variables,omitempty"`    // values for the pattern's {{variables}}
+	Context      string            `json:"context,omitempty"`      // Fabric context to include, see ListContexts
+	Strategy     string            `json:"strategy,omitempty"`     // prompting strategy, see ListStrategies
+	Session      string            `json:"session,omitempty"`      // server-side session that remembers the conversation
+	SystemPrompt string            `json:"systemPrompt,omitempty"` // ad-hoc system prompt, or an override of the pattern's
+	SystemMode   string            `json:"systemMode,omitempty"`   // SystemPromptReplace (default) or SystemPromptPrepend
+	Chunk        bool              `json:"chunk,omitempty"`        // split input too large for the model's context window, see runChunked
+	OutputFile   string            `json:"outputFile,omitempty"`   // written to as the output streams in, see outputTee
+}
+
+// apply copies the extras into a prompt
+func (e ChatExtras) apply(prompt *PromptRequest) {
+	prompt.Variables = e.Variables
+	prompt.ContextName = e.Context
+	prompt.StrategyName = e.Strategy
+	prompt.SessionName = e.Session
+	prompt.SystemPrompt = e.SystemPrompt
+	prompt.SystemMode = e.SystemMode
 }
 
 // StreamEvent represents a streamed response event
 type StreamEvent struct {
-	Type    string `json:"type"`
-	Content string `json:"content"`
-	Format  string `json:"format,omitempty"`
+	Type    string       `json:"type"`
+	Content string       `json:"content"`
+	Format  string       `json:"format,omitempty"`
+	Usage   *streamUsage `json:"usage,omitempty"`
+}
+
+// streamUsage is the token count reported by a "usage" stream event
+type streamUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+	TotalTokens  int `json:"total_tokens"`
 }
 
 // NewApp creates a new App application struct
@@ -79,19 +266,138 @@ func NewApp() *App {
 		client: &http.Client{
 			Timeout: 0, // No timeout for streaming
 		},
-		history: []HistoryEntry{},
+		external:          &http.Client{},
+		streamIdleTimeout: defaultStreamIdleTimeout,
+		log:               discardLogger,
+		history:           newHistoryStore(defaultHistoryMaxEntries),
+		patternStats:      newPatternStatsStore(),
+		catalog:           newCatalogCache(),
+		redact:            newRedactor(),
+		session:           newSessionSaver(),
 	}
 }
 
 // startup is called when the app starts
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
-	a.loadPreferences()
+	prefs := a.loadState()
+	if dir := a.getConfigDir(); dir != "" {
+		a.startSessionRecovery(dir)
+	}
+	go a.refreshCatalog()
+	go a.checkForUpdatesOnStartup()
+	if prefs.Tray.Enabled {
+		a.startTray()
+	}
+	if prefs.Hotkey.Enabled {
+		// The desktop may ask the user to confirm the shortcut first
+		go a.startHotkey(prefs.Hotkey)
+	}
+	if prefs.API.Enabled {
+		a.startAPI(prefs.API)
+	}
+	a.startScheduler()
+	a.restartFolderWatcher()
+	go a.registerURLScheme()
+	wd, _ := os.Getwd()
+	a.handleLaunchArgs(os.Args[1:], wd)
+
+	runtime.OnFileDrop(ctx, a.handleFileDrop)
+}
+
+// loadState restores preferences, the active profile, history and caches
+// from the config directory
+func (a *App) loadState() *Preferences {
+	prefs, _ := a.loadPreferences()
+	a.initLogging(prefs)
+	if prefs.BaseURL != "" {
+		a.SetBaseURL(prefs.BaseURL)
+	}
+	a.restoreProfile(prefs)
+
+	// Restore persisted history and drop whatever the retention settings no longer allow
+	a.applyHistoryRetention(prefs)
+	if dir := a.getConfigDir(); dir != "" {
+		a.secrets = openSecretStore(dir)
+		a.moveSecretsFromPreferences(prefs)
+		a.refreshRedactions()
+		if err := a.history.Load(filepath.Join(dir, "history.json")); errors.Is(err, errHistoryLocked) {
+			a.unlockHistoryOnStartup(prefs)
+		} else if err != nil {
+			a.log.Error("failed to load history", "error", err)
+			a.emit("debug:log", err.Error())
+		}
+		a.history.Prune()
+
+		if err := a.patternStats.Load(filepath.Join(dir, "pattern_stats.json"), a.history.All()); err != nil {
+			a.log.Error("failed to load pattern stats", "error", err)
+		}
+		if err := a.catalog.Load(filepath.Join(dir, "catalog.json")); err != nil {
+			a.log.Error("failed to load cached patterns and models", "error", err)
+		}
+	}
+	return prefs
+}
+
+// emit sends an event to the frontend. Headless runs have no frontend and
+// drop their events.
+func (a *App) emit(name string, data ...interface{}) {
+	if a.headless {
+		return
+	}
+	runtime.EventsEmit(a.ctx, name, data...)
+}
+
+// emitWhenReady emits an event once the frontend listens for events, so
+// events raised while the app starts are not lost
+func (a *App) emitWhenReady(name string, data interface{}) {
+	a.readyMutex.Lock()
+	if !a.frontendReady {
+		a.pendingEvents = append(a.pendingEvents, pendingEvent{name: name, data: data})
+		a.readyMutex.Unlock()
+		return
+	}
+	a.readyMutex.Unlock()
+	a.emit(name, data)
+}
+
+// FrontendReady is called by the frontend once its event handlers are
+// registered, and emits the events held back until then
+func (a *App) FrontendReady() {
+	a.readyMutex.Lock()
+	a.frontendReady = true
+	pending := a.pendingEvents
+	a.pendingEvents = nil
+	a.readyMutex.Unlock()
+	for _, event := range pending {
+		a.emit(event.name, event.data)
+	}
+}
+
+// goTask runs fn in the background, tracked in tasks
+func (a *App) goTask(fn func()) {
+	a.tasks.Add(1)
+	go func() {
+		defer a.tasks.Done()
+		fn()
+	}()
 }
 
 // shutdown is called when the app is closing - clean up server process
 func (a *App) shutdown(ctx context.Context) {
+	a.cancelAllJobs()
+	a.stopTray()
+	a.stopHotkey()
+	a.stopAPI()
+	a.stopScheduler()
+	a.stopFolderWatcher()
+	a.closeNotifier()
+	a.session.close()
+	a.StopClipboardWatcher()
+	a.discardRecording()
+	a.StopSpeech()
 	a.StopServer()
+	a.closeLogging()
 }
 
 // getConfigDir returns the config directory path
@@ -107,14 +413,34 @@ func (a *App) getConfigDir() string {
 
 // SetBaseURL updates the Fabric server base URL
 func (a *App) SetBaseURL(url string) {
+	a.connMutex.Lock()
 	a.baseURL = strings.TrimSuffix(url, "/")
+	a.connMutex.Unlock()
 }
 
 // GetBaseURL returns the current base URL
 func (a *App) GetBaseURL() string {
+	a.connMutex.RLock()
+	defer a.connMutex.RUnlock()
 	return a.baseURL
 }
 
+// httpClient returns the client for the active profile's server, with its
+// TLS and proxy settings
+func (a *App) httpClient() *http.Client {
+	a.connMutex.RLock()
+	defer a.connMutex.RUnlock()
+	return a.client
+}
+
+// externalClient returns the client for third-party services, which always
+// verifies certificates against the system roots
+func (a *App) externalClient() *http.Client {
+	a.connMutex.RLock()
+	defer a.connMutex.RUnlock()
+	return a.external
+}
+
 // ============================================
 // Server Management
 // ============================================
@@ -133,23 +459,31 @@ func (a *App) StartServer() error {
 	}
 
 	// Find fabric executable
-	fabricPath, err := exec.LookPath("fabric")
+	fabricPath, err := a.fabricPath()
 	if err != nil {
-		return fmt.Errorf("fabric not found in PATH: %v", err)
+		return err
 	}
 
-	// Start the server
+	// Start the server, with the API keys kept in secret storage. Keys added
+	// outside the app since startup are picked up for redaction here.
+	a.refreshRedactions()
 	cmd := exec.Command(fabricPath, "--serve")
+	cmd.Env = os.Environ()
+	for key, value := range a.secretEnv() {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
 
 	// Capture output for logging
 	stdout, _ := cmd.StdoutPipe()
 	stderr, _ := cmd.StderrPipe()
 
 	if err := cmd.Start(); err != nil {
+		a.log.Error("failed to start fabric server", "error", err)
 		return fmt.Errorf("failed to start server: %v", err)
 	}
 
 	a.serverProcess = cmd
+	a.log.Info("started fabric server", "path", fabricPath, "pid", cmd.Process.Pid)
 
 	// Read output in background
 	go func() {
@@ -160,7 +494,9 @@ func (a *App) StartServer() error {
 				break
 			}
 			// Emit server log event
-			runtime.EventsEmit(a.ctx, "server:log", strings.TrimSpace(line))
+			line = a.redact.String(strings.TrimSpace(line))
+			a.log.Info("fabric server", "output", line)
+			a.emit("server:log", line)
 		}
 	}()
 
@@ -173,7 +509,9 @@ func (a *App) StartServer() error {
 		time.Sleep(3 * time.Second)
 	}
 
-	runtime.EventsEmit(a.ctx, "server:started", "")
+	a.emit("server:started", "")
+	a.refreshTray()
+	go a.refreshCatalog()
 	return nil
 }
 
@@ -193,8 +531,10 @@ func (a *App) StopServer() error {
 
 	a.serverProcess.Wait()
 	a.serverProcess = nil
+	a.log.Info("stopped fabric server")
 
-	runtime.EventsEmit(a.ctx, "server:stopped", "")
+	a.emit("server:stopped", "")
+	a.refreshTray()
 	return nil
 }
 
@@ -218,14 +558,30 @@ func (a *App) SavePreferences(prefs Preferences) error {
 		return fmt.Errorf("could not determine config directory")
 	}
 
-	a.baseURL = prefs.BaseURL
+	if prefs.BaseURL != "" {
+		a.SetBaseURL(prefs.BaseURL)
+	}
+	if err := a.syncActiveProfile(&prefs); err != nil {
+		return err
+	}
+	a.applyHistoryRetention(&prefs)
+	a.logLevel.Set(parseLogLevel(prefs.LogLevel))
+	a.patternIndex.invalidate() // the custom pattern directories may have changed
+	if err := a.storePreferenceSecrets(&prefs); err != nil {
+		return err
+	}
 
 	data, err := json.MarshalIndent(prefs, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(filepath.Join(dir, "preferences.json"), data, 0644)
+	// Private, since credentials stay here when there is no secret storage
+	if err := writeFileAtomic(filepath.Join(dir, "preferences.json"), data, 0600); err != nil {
+		return err
+	}
+	a.refreshRedactions()
+	return nil
 }
 
 // LoadPreferences loads user preferences from disk
@@ -248,108 +604,92 @@ func (a *App) loadPreferences() (*Preferences, error) {
 	if err := json.Unmarshal(data, &prefs); err != nil {
 		return &Preferences{BaseURL: "http://localhost:8080", Theme: "dark", AutoStartServer: true}, nil
 	}
-
-	// Apply loaded preferences
-	if prefs.BaseURL != "" {
-		a.baseURL = prefs.BaseURL
-	}
+	a.loadPreferenceSecrets(&prefs)
 
 	return &prefs, nil
 }
 
 // CheckHealth checks if the Fabric server is reachable
 func (a *App) CheckHealth() bool {
-	client := &http.Client{Timeout: 3 * time.Second}
-	resp, err := client.Get(a.baseURL + "/patterns/names")
-	if err != nil {
-		return false
-	}
-	defer resp.Body.Close()
-	return resp.StatusCode == 200
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	return a.fabricClient().Health(ctx) == nil
 }
 
-// GetPatterns fetches the list of available patterns from Fabric
-func (a *App) GetPatterns() ([]string, error) {
-	resp, err := a.client.Get(a.baseURL + "/patterns/names")
+// GetPatterns returns the list of available patterns from Fabric, merged with
+// those in the custom pattern directories, with their descriptions and tags.
+// The server's list is cached, see patternNames.
+func (a *App) GetPatterns() ([]PatternInfo, error) {
+	names, err := a.patternNames()
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch patterns: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("server returned status %d", resp.StatusCode)
+		return nil, err
 	}
-
-	var patterns []string
-	if err := json.NewDecoder(resp.Body).Decode(&patterns); err != nil {
-		return nil, fmt.Errorf("failed to parse patterns: %v", err)
-	}
-
+	patterns := mergePatterns(names, a.customPatterns())
+	a.describePatterns(patterns)
+	a.patternIndex.set(patterns)
 	return patterns, nil
 }
 
-// GetModels fetches the list of available models grouped by vendor
+// GetModels returns the list of available models grouped by vendor, cached
+// like the patterns, without the vendors and models hidden in preferences
 func (a *App) GetModels() (*ModelsResponse, error) {
-	resp, err := a.client.Get(a.baseURL + "/models/names")
+	models, err := a.models()
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch models: %v", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("server returned status %d", resp.StatusCode)
-	}
-
-	var models ModelsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&models); err != nil {
-		return nil, fmt.Errorf("failed to parse models: %v", err)
-	}
-
-	return &models, nil
+	return a.GetModelVisibility().filter(models), nil
 }
 
 // AddHistoryEntry adds an entry to history
 func (a *App) AddHistoryEntry(pattern, model, input, output string) {
-	entry := HistoryEntry{
+	a.history.Add(HistoryEntry{
 		Pattern: pattern,
 		Model:   model,
 		Input:   input,
 		Output:  output,
 		Time:    time.Now().Unix(),
-	}
-
-	a.history = append(a.history, entry)
+	})
+}
 
-	// Keep only last 50 entries
-	if len(a.history) > 50 {
-		a.history = a.history[len(a.history)-50:]
+// GetHistory returns the history entries, only those carrying tag if one is given
+func (a *App) GetHistory(tag string) []HistoryEntry {
+	entries := a.history.All()
+	if tag == "" {
+		return entries
 	}
-}
 
-// GetHistory returns the history entries
-func (a *App) GetHistory() []HistoryEntry {
-	return a.history
+	filtered := []HistoryEntry{}
+	for _, e := range entries {
+		if e.hasTag(tag) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
 }
 
 // GetHistoryCount returns the number of history entries
 func (a *App) GetHistoryCount() int {
-	return len(a.history)
+	return a.history.Len()
 }
 
 // GetHistoryEntry returns a specific history entry by index
 func (a *App) GetHistoryEntry(index int) *HistoryEntry {
-	if index < 0 || index >= len(a.history) {
+	entry, ok := a.history.Get(index)
+	if !ok {
 		return nil
 	}
-	return &a.history[index]
+	return &entry
 }
 
 // OpenFileDialog opens a file dialog and returns the selected file content
 func (a *App) OpenFileDialog() (string, error) {
 	selection, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "Import Text File",
+		Title: "Import File",
 		Filters: []runtime.FileFilter{
+			{DisplayName: "Supported Files", Pattern: "*.txt;*.md;*.pdf;*.docx;*.odt"},
 			{DisplayName: "Text Files", Pattern: "*.txt;*.md"},
+			{DisplayName: "PDF Documents", Pattern: "*.pdf"},
+			{DisplayName: "Office Documents", Pattern: "*.docx;*.odt"},
 			{DisplayName: "All Files", Pattern: "*.*"},
 		},
 	})
@@ -360,12 +700,7 @@ func (a *App) OpenFileDialog() (string, error) {
 		return "", nil // User cancelled
 	}
 
-	content, err := os.ReadFile(selection)
-	if err != nil {
-		return "", fmt.Errorf("failed to read file: %v", err)
-	}
-
-	return string(content), nil
+	return readImportFile(selection)
 }
 
 // SaveFileDialog opens a save dialog and saves the content
@@ -394,98 +729,66 @@ func (a *App) SaveFileDialog(content string) (string, error) {
 	return selection, nil
 }
 
-// SendChat sends a chat request and streams the response
-func (a *App) SendChat(pattern, vendor, model, input string) error {
-	// Build request
-	reqBody := ChatRequest{
-		Prompts: []PromptRequest{
-			{
-				UserInput:   input,
-				Vendor:      vendor,
-				Model:       model,
-				PatternName: pattern,
-			},
-		},
+// SendChat sends a chat request and streams the response, returning once the stream ends
+func (a *App) SendChat(pattern, vendor, model, input string, extras ChatExtras) error {
+	prompt := a.newPrompt(pattern, vendor, model, input)
+	extras.apply(&prompt)
+	if err := prepareOutputFile(extras.OutputFile); err != nil {
+		return err
 	}
-
-	jsonBody, err := json.Marshal(reqBody)
-	if err != nil {
-		return fmt.Errorf("failed to marshal request: %v", err)
+	if chunks := a.inputChunks(prompt, extras.Chunk); len(chunks) > 1 {
+		id, job, ctx := a.openStream(chatTitle(pattern) + " (chunked)")
+		return a.runChunked(id, job, ctx, prompt, chunks, extras.OutputFile)
 	}
-
-	req, err := http.NewRequest("POST", a.baseURL+"/chat", strings.NewReader(string(jsonBody)))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
+	if err := a.checkContextWindow(prompt); err != nil {
+		return err
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "text/event-stream")
+	id, job, ctx := a.openStream(chatTitle(pattern))
+	return a.runStream(id, job, ctx, chatRun{Prompt: prompt, OutputFile: extras.OutputFile})
+}
 
-	resp, err := a.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to send request: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("server error %d: %s", resp.StatusCode, string(body))
-	}
-
-	// Read streaming response (SSE format: "data: {...json...}")
-	// Use Scanner for robust line reading
-	scanner := bufio.NewScanner(resp.Body)
-	// Increase buffer size just in case (max 1MB lines)
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024)
-
-	var fullOutput string
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		if len(line) > 0 {
-			line = strings.TrimSpace(line)
-			if line != "" {
-				// Strip "data: " prefix
-				if strings.HasPrefix(line, "data: ") {
-					line = strings.TrimPrefix(line, "data: ")
-				} else if strings.HasPrefix(line, "data:") {
-					line = strings.TrimPrefix(line, "data:")
-				}
-
-				if line != "" {
-					var event StreamEvent
-					if err := json.Unmarshal([]byte(line), &event); err == nil {
-						switch event.Type {
-						case "content":
-							runtime.EventsEmit(a.ctx, "chat:chunk", event.Content)
-							fullOutput += event.Content
-						case "complete":
-							// Some servers/models might send the final chunk in the complete event
-							if event.Content != "" {
-								runtime.EventsEmit(a.ctx, "debug:log", fmt.Sprintf("Complete event had content: %q", event.Content))
-								runtime.EventsEmit(a.ctx, "chat:chunk", event.Content)
-								fullOutput += event.Content
-							}
-							runtime.EventsEmit(a.ctx, "debug:log", "Backend received complete event")
-							runtime.EventsEmit(a.ctx, "chat:complete", "")
-							a.AddHistoryEntry(pattern, model, input, fullOutput)
-							return nil
-						case "usage":
-							// ignore usage events
-						}
-					}
-				}
-			}
-		}
+// StartChat starts a chat request in the background and returns its stream ID.
+// All chat events for the request carry this ID so several streams can run at once.
+func (a *App) StartChat(pattern, vendor, model, input string, extras ChatExtras) (string, error) {
+	prompt := a.newPrompt(pattern, vendor, model, input)
+	extras.apply(&prompt)
+	if err := prepareOutputFile(extras.OutputFile); err != nil {
+		return "", err
+	}
+	if chunks := a.inputChunks(prompt, extras.Chunk); len(chunks) > 1 {
+		id, job, ctx := a.openStream(chatTitle(pattern) + " (chunked)")
+		go a.runChunked(id, job, ctx, prompt, chunks, extras.OutputFile)
+		return id, nil
+	}
+	if err := a.checkContextWindow(prompt); err != nil {
+		return "", err
 	}
+	id, job, ctx := a.openStream(chatTitle(pattern))
+	go a.runStream(id, job, ctx, chatRun{Prompt: prompt, OutputFile: extras.OutputFile})
+	return id, nil
+}
 
-	if err := scanner.Err(); err != nil {
-		runtime.EventsEmit(a.ctx, "debug:log", fmt.Sprintf("Stream scanner error: %v", err))
-		return fmt.Errorf("error reading stream: %v", err)
+// newPrompt builds a prompt request, consuming any queued image attachments.
+// Without a model, the pattern's default model is used.
+func (a *App) newPrompt(pattern, vendor, model, input string) PromptRequest {
+	vendor, model = a.patternDefaultModel(pattern, vendor, model)
+	return PromptRequest{
+		UserInput:   input,
+		Vendor:      vendor,
+		Model:       model,
+		PatternName: pattern,
+		Attachments: a.takeAttachments(),
 	}
+}
 
-	a.AddHistoryEntry(pattern, model, input, fullOutput)
-	runtime.EventsEmit(a.ctx, "chat:complete", "")
-	return nil
+// streamChat posts a prompt to the Fabric server, calling onChunk for every
+// content chunk and onUsage when token counts are reported, and returns the
+// full output once the stream ends. See FabricClient.Chat. Custom patterns
+// and ad-hoc system prompts are expanded here, the server does not know them.
+func (a *App) streamChat(ctx context.Context, prompt PromptRequest, onChunk func(string), onUsage func(streamUsage)) (string, error) {
+	prompt, err := a.resolveSystemPrompt(prompt)
+	if err != nil {
+		return "", err
+	}
+	return a.fabricClient().Chat(ctx, a.resolveCustomPattern(prompt), onChunk, onUsage)
 }
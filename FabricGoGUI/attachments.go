@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// maxImageSize is the largest image accepted as an attachment
+const maxImageSize = 20 * 1024 * 1024
+
+// supportedImageTypes lists the image formats vision models accept
+var supportedImageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// ImageAttachment is an image queued to be sent with the next chat request
+type ImageAttachment struct {
+	Name     string `json:"name"`
+	MimeType string `json:"mimeType"`
+	Size     int64  `json:"size"`
+	DataURL  string `json:"dataUrl"`
+}
+
+// AttachImage validates an image file and queues it for the next chat request
+func (a *App) AttachImage(path string) (*ImageAttachment, error) {
+	attachment, err := loadImageAttachment(path)
+	if err != nil {
+		return nil, err
+	}
+
+	a.attachmentsMutex.Lock()
+	a.attachments = append(a.attachments, *attachment)
+	a.attachmentsMutex.Unlock()
+
+	return attachment, nil
+}
+
+// GetAttachments returns the images queued for the next chat request
+func (a *App) GetAttachments() []ImageAttachment {
+	a.attachmentsMutex.Lock()
+	defer a.attachmentsMutex.Unlock()
+
+	attachments := make([]ImageAttachment, len(a.attachments))
+	copy(attachments, a.attachments)
+	return attachments
+}
+
+// RemoveAttachment removes a queued image by index
+func (a *App) RemoveAttachment(index int) error {
+	a.attachmentsMutex.Lock()
+	defer a.attachmentsMutex.Unlock()
+
+	if index < 0 || index >= len(a.attachments) {
+		return fmt.Errorf("attachment %d not found", index)
+	}
+	a.attachments = append(a.attachments[:index], a.attachments[index+1:]...)
+	return nil
+}
+
+// ClearAttachments removes all queued images
+func (a *App) ClearAttachments() {
+	a.attachmentsMutex.Lock()
+	a.attachments = nil
+	a.attachmentsMutex.Unlock()
+}
+
+// takeAttachments returns the queued images as data URLs and clears the queue
+func (a *App) takeAttachments() []string {
+	a.attachmentsMutex.Lock()
+	defer a.attachmentsMutex.Unlock()
+
+	if len(a.attachments) == 0 {
+		return nil
+	}
+
+	urls := make([]string, len(a.attachments))
+	for i, attachment := range a.attachments {
+		urls[i] = attachment.DataURL
+	}
+	a.attachments = nil
+	return urls
+}
+
+// loadImageAttachment reads an image, checks its size and format and encodes it as a data URL
+func loadImageAttachment(path string) (*ImageAttachment, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %v", err)
+	}
+	if info.Size() > maxImageSize {
+		return nil, fmt.Errorf("image is %d MB, the limit is %d MB", info.Size()/(1024*1024), maxImageSize/(1024*1024))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %v", err)
+	}
+
+	// Sniff the content rather than trusting the file extension
+	mimeType := http.DetectContentType(data)
+	if !supportedImageTypes[mimeType] {
+		return nil, fmt.Errorf("unsupported image format %q (use PNG, JPEG, GIF or WebP)", mimeType)
+	}
+
+	return &ImageAttachment{
+		Name:     filepath.Base(path),
+		MimeType: mimeType,
+		Size:     info.Size(),
+		DataURL:  "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data),
+	}, nil
+}
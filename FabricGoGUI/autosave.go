@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// AutoSaveSettings configure saving every completed run to a folder
+type AutoSaveSettings struct {
+	Enabled  bool   `json:"enabled"`
+	Dir      string `json:"dir"`
+	Template string `json:"template"` // file name, see expandAutoSaveTemplate
+}
+
+const (
+	// defaultAutoSaveTemplate names files by date, pattern and title
+	defaultAutoSaveTemplate = "{date}-{pattern}-{title}.md"
+	// maxFileNamePart shortens long titles in file names
+	maxFileNamePart = 60
+)
+
+// GetAutoSaveSettings returns the auto-save settings, with defaults filled in
+func (a *App) GetAutoSaveSettings() AutoSaveSettings {
+	settings := AutoSaveSettings{}
+	if prefs, err := a.loadPreferences(); err == nil {
+		settings = prefs.AutoSave
+	}
+	if settings.Template == "" {
+		settings.Template = defaultAutoSaveTemplate
+	}
+	return settings
+}
+
+// SaveAutoSaveSettings stores the auto-save settings. Enabling it needs a
+// folder, which is created if missing.
+func (a *App) SaveAutoSaveSettings(settings AutoSaveSettings) error {
+	if settings.Enabled {
+		if settings.Dir == "" {
+			return fmt.Errorf("choose a folder to save outputs to")
+		}
+		if err := os.MkdirAll(settings.Dir, 0755); err != nil {
+			return fmt.Errorf("failed to create folder: %v", err)
+		}
+	}
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	prefs.AutoSave = settings
+	return a.SavePreferences(*prefs)
+}
+
+// SelectAutoSaveDir picks the auto-save folder with a dialog and enables
+// auto-save. Returns the folder, or "" if the dialog was cancelled.
+func (a *App) SelectAutoSaveDir() (string, error) {
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select Folder for Saved Outputs",
+	})
+	if err != nil || dir == "" {
+		return "", err
+	}
+	settings := a.GetAutoSaveSettings()
+	settings.Dir = dir
+	settings.Enabled = true
+	if err := a.SaveAutoSaveSettings(settings); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// OpenAutoSaveFolder shows the auto-save folder in the system file manager
+func (a *App) OpenAutoSaveFolder() error {
+	dir := a.GetAutoSaveSettings().Dir
+	if dir == "" {
+		return fmt.Errorf("no auto-save folder configured")
+	}
+	return openPath(dir)
+}
+
+// autoSaveRun writes a finished run to the auto-save folder when enabled
+func (a *App) autoSaveRun(entry HistoryEntry) {
+	settings := a.GetAutoSaveSettings()
+	if !settings.Enabled || settings.Dir == "" {
+		return
+	}
+
+	name := expandAutoSaveTemplate(settings.Template, entry)
+	path, err := vaultPath(settings.Dir, name)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+	}
+	if err == nil {
+		ext := filepath.Ext(path)
+		path, err = writeNewFile(filepath.Dir(path), strings.TrimSuffix(filepath.Base(path), ext), ext, []byte(entry.Output))
+	}
+	if err != nil {
+		a.log.Warn("failed to auto-save output", "dir", settings.Dir, "error", err)
+		return
+	}
+	a.log.Info("auto-saved output", "path", path)
+	a.emit("autosave:saved", path)
+}
+
+// repeatedSeparators collapses the runs left by empty placeholders
+var repeatedSeparators = regexp.MustCompile(`([-_ ])[-_ ]+`)
+
+// expandAutoSaveTemplate fills in the file name placeholders: {date},
+// {time}, {pattern}, {model}, {vendor} and {title}. An entry without a title
+// uses defaultNoteTitle's. "/" in the template makes subfolders, but not in
+// the values.
+func expandAutoSaveTemplate(template string, entry HistoryEntry) string {
+	created := time.Unix(entry.Time, 0)
+	pattern := entry.Pattern
+	if pattern == "" {
+		pattern = rawChatTitle
+	}
+	title := entry.Title
+	if title == "" {
+		title = defaultNoteTitle(entry)
+	}
+	name := strings.NewReplacer(
+		"{date}", created.Format("2006-01-02"),
+		"{time}", created.Format("150405"),
+		"{pattern}", fileNamePart(pattern),
+		"{model}", fileNamePart(entry.Model),
+		"{vendor}", fileNamePart(entry.Vendor),
+		"{title}", fileNamePart(title),
+	).Replace(template)
+
+	ext := filepath.Ext(name)
+	base := repeatedSeparators.ReplaceAllString(strings.TrimSuffix(name, ext), "$1")
+	base = strings.TrimRight(base, "-_ ")
+	if base == "" || strings.HasSuffix(base, "/") {
+		base += "output"
+	}
+	return base + ext
+}
+
+// fileNamePart makes a value safe inside a file name: lower case, words
+// joined by "-", without path separators or reserved characters
+func fileNamePart(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`*"\/<>:|?#^[]`, r) || r < 32 {
+			return ' '
+		}
+		return r
+	}, strings.ToLower(value))
+	value = strings.Join(strings.Fields(value), "-")
+	for utf8.RuneCountInString(value) > maxFileNamePart {
+		_, size := utf8.DecodeLastRuneInString(value)
+		value = value[:len(value)-size]
+	}
+	return strings.Trim(value, "-.")
+}
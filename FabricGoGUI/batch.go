@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	// defaultBatchWorkers is how many files are processed at once
+	defaultBatchWorkers = 3
+	// defaultBatchTemplate names output files after the input file and pattern
+	defaultBatchTemplate = "{name}_{pattern}.md"
+)
+
+// BatchFileEvent reports the state of one file in a batch run
+type BatchFileEvent struct {
+	File   string `json:"file"`
+	Output string `json:"output,omitempty"`
+	Index  int    `json:"index"`
+	Total  int    `json:"total"`
+	Status string `json:"status"` // started, complete, error
+	Error  string `json:"error,omitempty"`
+}
+
+// BatchSummary is returned and emitted when a batch run finishes
+type BatchSummary struct {
+	Total      int      `json:"total"`
+	Succeeded  int      `json:"succeeded"`
+	Failed     int      `json:"failed"`
+	OutputDir  string   `json:"outputDir"`
+	Errors     []string `json:"errors"`
+	DurationMs int64    `json:"durationMs"`
+}
+
+// RunBatch runs every file in dir matching glob through a pattern and writes
+// the outputs to a directory chosen by the user
+func (a *App) RunBatch(dir, glob, pattern, model string) (*BatchSummary, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("a pattern is required")
+	}
+	if glob == "" {
+		glob = "*"
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, glob))
+	if err != nil {
+		return nil, fmt.Errorf("invalid file pattern: %v", err)
+	}
+
+	var files []string
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && !info.IsDir() {
+			files = append(files, match)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files in %s match %s", dir, glob)
+	}
+	sort.Strings(files)
+
+	outputDir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:                "Choose Output Folder",
+		DefaultDirectory:     dir,
+		CanCreateDirectories: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if outputDir == "" {
+		return nil, nil // User cancelled
+	}
+
+	vendor, err := a.vendorForModel(model)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs, _ := a.loadPreferences()
+	workers := prefs.BatchWorkers
+	if workers <= 0 {
+		workers = defaultBatchWorkers
+	}
+	template := prefs.BatchTemplate
+	if template == "" {
+		template = defaultBatchTemplate
+	}
+
+	job, ctx := a.startJob("batch", fmt.Sprintf("%s on %d files", pattern, len(files)))
+
+	start := time.Now()
+	summary := &BatchSummary{Total: len(files), OutputDir: outputDir, Errors: []string{}}
+	var summaryMutex sync.Mutex
+
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, len(files)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				outPath, err := a.runBatchFile(ctx, files[i], i, len(files), outputDir, template, pattern, vendor, model)
+
+				summaryMutex.Lock()
+				if err != nil {
+					summary.Failed++
+					summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %v", filepath.Base(files[i]), err))
+				} else {
+					summary.Succeeded++
+				}
+				done := summary.Succeeded + summary.Failed
+				summaryMutex.Unlock()
+
+				a.updateJob(job, float64(done)/float64(len(files))*100, fmt.Sprintf("%d of %d files", done, len(files)))
+
+				event := BatchFileEvent{File: files[i], Output: outPath, Index: i, Total: len(files), Status: "complete"}
+				if err != nil {
+					event.Status = "error"
+					event.Error = err.Error()
+				}
+				a.emit("batch:file", event)
+			}
+		}()
+	}
+
+	// Stop handing out files once the job is cancelled
+	for i := range files {
+		if ctx.Err() != nil {
+			break
+		}
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	a.finishJob(job, ctx.Err())
+
+	summary.DurationMs = time.Since(start).Milliseconds()
+	a.emit("batch:complete", summary)
+	go a.notifyBatch(summary)
+	return summary, nil
+}
+
+// runBatchFile processes a single batch input and writes its output file
+func (a *App) runBatchFile(ctx context.Context, path string, index, total int, outputDir, template, pattern, vendor, model string) (string, error) {
+	a.emit("batch:file", BatchFileEvent{File: path, Index: index, Total: total, Status: "started"})
+
+	input, err := readImportFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := a.streamChatWithRetry(ctx, PromptRequest{
+		UserInput:   input,
+		Vendor:      vendor,
+		Model:       model,
+		PatternName: pattern,
+	}, func(string) {}, func(streamUsage) {}, func(ChatRetry) {})
+	if err != nil {
+		return "", err
+	}
+
+	// Inputs such as notes.txt and notes.md share a name, and each keeps its output
+	name := expandBatchTemplate(template, path, pattern, model, index)
+	ext := filepath.Ext(name)
+	outPath, err := writeNewFile(outputDir, strings.TrimSuffix(name, ext), ext, []byte(output))
+	if err != nil {
+		return "", fmt.Errorf("failed to save output: %v", err)
+	}
+	return outPath, nil
+}
+
+// expandBatchTemplate fills in the output filename placeholders:
+// {name}, {ext}, {pattern}, {model}, {index}, {date} and {time}
+func expandBatchTemplate(template, path, pattern, model string, index int) string {
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	return expandOutputTemplate(template, strings.TrimSuffix(base, ext), strings.TrimPrefix(ext, "."), pattern, model, index)
+}
+
+// expandOutputTemplate is expandBatchTemplate for outputs not named after a
+// file, such as ones named after a title: name and ext are used as they are
+func expandOutputTemplate(template, name, ext, pattern, model string, index int) string {
+	name = strings.NewReplacer(
+		"{name}", name,
+		"{ext}", ext,
+		"{pattern}", pattern,
+		"{model}", model,
+		"{index}", strconv.Itoa(index+1),
+		"{date}", time.Now().Format("2006-01-02"),
+		"{time}", time.Now().Format("150405"),
+	).Replace(template)
+
+	// Model names such as "org/model" must not create subdirectories
+	return strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(name)
+}
+
+// vendorForModel looks up which vendor serves a model
+func (a *App) vendorForModel(model string) (string, error) {
+	models, err := a.models() // hidden models can still be run by name
+	if err != nil {
+		return "", err
+	}
+
+	for vendor, names := range models.Vendors {
+		for _, name := range names {
+			if name == model {
+				return vendor, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("model %q not found", model)
+}
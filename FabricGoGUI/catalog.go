@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+)
+
+// catalogTTL is how long the cached pattern and model lists are served
+// before being refreshed in the background
+const catalogTTL = 5 * time.Minute
+
+// catalogTimeout bounds a background refresh of the lists
+const catalogTimeout = 30 * time.Second
+
+// CatalogUpdate is emitted as "catalog:updated" when a refresh finds the
+// server's pattern or model list changed
+type CatalogUpdate struct {
+	Patterns bool `json:"patterns"`
+	Models   bool `json:"models"`
+}
+
+// catalogData is what catalog.json holds
+type catalogData struct {
+	BaseURL    string          `json:"baseUrl"` // server the lists came from
+	Patterns   []string        `json:"patterns"`
+	Models     *ModelsResponse `json:"models"`
+	PatternsAt int64           `json:"patternsAt"` // Unix seconds
+	ModelsAt   int64           `json:"modelsAt"`
+}
+
+// catalogCache keeps the server's pattern names and models, persisted so
+// they are shown instantly at the next start
+type catalogCache struct {
+	mu         sync.Mutex
+	path       string
+	data       catalogData
+	refreshing bool
+}
+
+func newCatalogCache() *catalogCache {
+	return &catalogCache{}
+}
+
+// Load reads the lists saved by an earlier run and keeps saving to path
+func (c *catalogCache) Load(path string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.path = path
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &c.data)
+}
+
+// patterns returns the cached pattern names for baseURL and whether they
+// are still fresh
+func (c *catalogCache) patterns(baseURL string) ([]string, bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.data.BaseURL != baseURL || c.data.Patterns == nil {
+		return nil, false, false
+	}
+	return c.data.Patterns, time.Since(time.Unix(c.data.PatternsAt, 0)) < catalogTTL, true
+}
+
+// models returns the cached models for baseURL and whether they are still
+// fresh
+func (c *catalogCache) models(baseURL string) (*ModelsResponse, bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.data.BaseURL != baseURL || c.data.Models == nil {
+		return nil, false, false
+	}
+	return c.data.Models, time.Since(time.Unix(c.data.ModelsAt, 0)) < catalogTTL, true
+}
+
+// setPatterns stores the pattern names and reports whether they changed
+func (c *catalogCache) setPatterns(baseURL string, names []string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.switchServer(baseURL)
+	changed := !slices.Equal(c.data.Patterns, names)
+	c.data.Patterns = names
+	c.data.PatternsAt = time.Now().Unix()
+	c.saveLocked()
+	return changed
+}
+
+// setModels stores the models and reports whether they changed
+func (c *catalogCache) setModels(baseURL string, models *ModelsResponse) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.switchServer(baseURL)
+	changed := !reflect.DeepEqual(c.data.Models, models)
+	c.data.Models = models
+	c.data.ModelsAt = time.Now().Unix()
+	c.saveLocked()
+	return changed
+}
+
+// invalidate marks both lists stale so the next read refreshes them
+func (c *catalogCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data.PatternsAt = 0
+	c.data.ModelsAt = 0
+}
+
+// switchServer forgets lists that came from another server
+func (c *catalogCache) switchServer(baseURL string) {
+	if c.data.BaseURL != baseURL {
+		c.data = catalogData{BaseURL: baseURL}
+	}
+}
+
+func (c *catalogCache) saveLocked() {
+	if c.path == "" {
+		return
+	}
+	data, err := json.Marshal(c.data)
+	if err != nil {
+		return
+	}
+	writeFileAtomic(c.path, data, 0644)
+}
+
+// patternNames returns the server's patterns, from the cache when there is
+// one. A stale cache is served as is and refreshed in the background.
+func (a *App) patternNames() ([]string, error) {
+	if names, fresh, ok := a.catalog.patterns(a.GetBaseURL()); ok {
+		if !fresh {
+			go a.refreshCatalog()
+		}
+		return names, nil
+	}
+	names, err := a.fabricClient().Patterns(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	a.catalog.setPatterns(a.GetBaseURL(), names)
+	return names, nil
+}
+
+// models returns the server's models, from the cache when there is one. A
+// stale cache is served as is and refreshed in the background.
+func (a *App) models() (*ModelsResponse, error) {
+	if models, fresh, ok := a.catalog.models(a.GetBaseURL()); ok {
+		if !fresh {
+			go a.refreshCatalog()
+		}
+		return models, nil
+	}
+	models, err := a.fabricClient().Models(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	a.catalog.setModels(a.GetBaseURL(), models)
+	return models, nil
+}
+
+// RefreshCatalog fetches the pattern and model lists from the server now,
+// emitting "catalog:updated" if either changed
+func (a *App) RefreshCatalog() CatalogUpdate {
+	return a.refreshCatalog()
+}
+
+// refreshCatalog refetches both lists unless a refresh is already running.
+// Failures keep the cached lists; the server may simply be offline.
+func (a *App) refreshCatalog() CatalogUpdate {
+	var update CatalogUpdate
+
+	a.catalog.mu.Lock()
+	if a.catalog.refreshing {
+		a.catalog.mu.Unlock()
+		return update
+	}
+	a.catalog.refreshing = true
+	a.catalog.mu.Unlock()
+	defer func() {
+		a.catalog.mu.Lock()
+		a.catalog.refreshing = false
+		a.catalog.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), catalogTimeout)
+	defer cancel()
+	baseURL := a.GetBaseURL()
+	client := a.fabricClient()
+
+	if names, err := client.Patterns(ctx); err != nil {
+		a.log.Debug("failed to refresh patterns", "error", err)
+	} else {
+		update.Patterns = a.catalog.setPatterns(baseURL, names)
+	}
+	if models, err := client.Models(ctx); err != nil {
+		a.log.Debug("failed to refresh models", "error", err)
+	} else {
+		update.Models = a.catalog.setModels(baseURL, models)
+	}
+
+	if update.Patterns {
+		a.patternIndex.invalidate()
+	}
+	if update.Patterns || update.Models {
+		a.log.Info("catalog changed", "patterns", update.Patterns, "models", update.Models)
+		a.emit("catalog:updated", update)
+	}
+	return update
+}
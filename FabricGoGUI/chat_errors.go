@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// Chat error codes reported in "chat:error" events
+const (
+	ChatErrNetwork     = "network"
+	ChatErrAuth        = "auth"
+	ChatErrRateLimit   = "rate-limit"
+	ChatErrServer      = "server"
+	ChatErrParse       = "parse"
+	ChatErrCancelled   = "cancelled"
+	ChatErrInterrupted = "interrupted"
+)
+
+// chatError is a classified chat failure
+type chatError struct {
+	Code       string
+	Status     int // HTTP status, 0 when not applicable
+	Message    string
+	Retryable  bool
+	RetryAfter time.Duration // Server-provided wait before retrying, if any
+}
+
+func (e *chatError) Error() string {
+	return e.Message
+}
+
+// newHTTPChatError classifies a non-200 response from the server
+func newHTTPChatError(status int, body string) *chatError {
+	err := classifyVendorError(body)
+	err.Status = status
+	err.Message = fmt.Sprintf("server error %d: %s", status, body)
+
+	switch {
+	case status == 401 || status == 403:
+		err.Code, err.Retryable = ChatErrAuth, false
+	case status == 429:
+		err.Code, err.Retryable = ChatErrRateLimit, true
+	case status >= 500:
+		// A vendor failure wrapped in a 500 keeps its more specific code
+		if err.Code == ChatErrServer {
+			err.Retryable = status == 502 || status == 503 || status == 504
+		}
+	}
+	return err
+}
+
+// newNetworkChatError wraps a transport failure
+func newNetworkChatError(err error) *chatError {
+	if errors.Is(err, context.Canceled) {
+		return &chatError{Code: ChatErrCancelled, Message: "request cancelled"}
+	}
+	return &chatError{Code: ChatErrNetwork, Message: fmt.Sprintf("failed to send request: %v", err), Retryable: true}
+}
+
+// classifyVendorError inspects an error message from the server or the
+// vendor behind it and guesses the kind of failure
+func classifyVendorError(message string) *chatError {
+	lower := strings.ToLower(message)
+
+	switch {
+	case containsAny(lower, "401", "403", "unauthorized", "invalid api key", "invalid_api_key", "incorrect api key", "authentication", "permission denied"):
+		return &chatError{Code: ChatErrAuth, Message: message}
+	case containsAny(lower, "429", "rate limit", "rate_limit", "too many requests", "quota", "overloaded"):
+		return &chatError{Code: ChatErrRateLimit, Message: message, Retryable: true}
+	case containsAny(lower, "timeout", "timed out", "connection reset", "connection refused", "eof"):
+		return &chatError{Code: ChatErrNetwork, Message: message, Retryable: true}
+	default:
+		return &chatError{Code: ChatErrServer, Message: message}
+	}
+}
+
+// toChatError converts any error returned by streamChat into a chatError
+func toChatError(err error) *chatError {
+	var ce *chatError
+	if errors.As(err, &ce) {
+		return ce
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return newNetworkChatError(err)
+	}
+	if errors.Is(err, context.Canceled) {
+		return newNetworkChatError(err)
+	}
+	return classifyVendorError(err.Error())
+}
+
+func containsAny(s string, substrs ...string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxOutputReserve caps the part of the context window kept free for the
+// model's answer when splitting input
+const maxOutputReserve = 4096
+
+// chunkSeparators are tried in order when splitting input, so chunks break
+// between paragraphs where possible, then lines, sentences and words
+var chunkSeparators = []string{"\n\n", "\n", ". ", " "}
+
+// ChunkProgress is emitted as "chunk:progress" while a chunked run works
+// through its parts
+type ChunkProgress struct {
+	StreamID  string `json:"streamId"`
+	Part      int    `json:"part"` // 1-based, 0 for the combining pass
+	Total     int    `json:"total"`
+	Status    string `json:"status"` // started, complete, combining
+	HistoryID string `json:"historyId,omitempty"`
+}
+
+// inputChunks splits a prompt's input to fit the model's context window,
+// leaving room for the pattern and the answer. It returns nil when chunking
+// is off, the model's window is unknown or the input already fits.
+func (a *App) inputChunks(prompt PromptRequest, enabled bool) []string {
+	if !enabled {
+		return nil
+	}
+	info := a.GetModelInfo(prompt.Model)
+	if info == nil || info.ContextWindow == 0 {
+		return nil
+	}
+
+	budget := info.ContextWindow - a.promptOverhead(prompt) - min(info.ContextWindow/4, maxOutputReserve)
+	if budget <= 0 || estimateTokens(prompt.UserInput) <= budget {
+		return nil
+	}
+	return splitText(prompt.UserInput, budget, chunkSeparators...)
+}
+
+// runChunked runs the prompt on each chunk in turn, then streams a combining
+// pass over their results under stream id. Every part is recorded in history
+// with ChunkOf set to id, as is the combined result, which is also streamed
+// to outputFile when set.
+func (a *App) runChunked(id string, j *job, ctx context.Context, prompt PromptRequest, chunks []string, outputFile string) error {
+	a.log.Info("chunking input", "pattern", prompt.PatternName, "model", prompt.Model, "chunks", len(chunks))
+
+	// The parts are independent; only the combined result joins a session
+	part := prompt
+	part.SessionName = ""
+
+	results := make([]string, 0, len(chunks))
+	for i, chunk := range chunks {
+		a.emit("chunk:progress", ChunkProgress{StreamID: id, Part: i + 1, Total: len(chunks), Status: "started"})
+		a.updateJob(j, float64(i)/float64(len(chunks)+1)*100, fmt.Sprintf("Part %d of %d", i+1, len(chunks)))
+
+		part.UserInput = chunk
+		start := time.Now()
+		var stats runStats
+		output, err := a.streamChatWithRetry(ctx, part, func(string) {}, stats.addUsage, func(ChatRetry) {})
+		stats.duration = time.Since(start)
+		stats.err = err
+
+		run := chatRun{Prompt: part, ChunkOf: id, Part: i + 1}
+		if err != nil {
+			return a.failChunked(id, j, run, output, stats)
+		}
+		historyID := a.recordHistory(run, output, stats)
+		results = append(results, output)
+		a.emit("chunk:progress", ChunkProgress{StreamID: id, Part: i + 1, Total: len(chunks), Status: "complete", HistoryID: historyID})
+	}
+
+	a.emit("chunk:progress", ChunkProgress{StreamID: id, Total: len(chunks), Status: "combining"})
+	a.updateJob(j, float64(len(chunks))/float64(len(chunks)+1)*100, "Combining results")
+
+	prompt.UserInput = combineChunkResults(results)
+	return a.runStream(id, j, ctx, chatRun{Prompt: prompt, ChunkOf: id, OutputFile: outputFile})
+}
+
+// failChunked ends a chunked run whose part failed, the same way a failed
+// stream ends
+func (a *App) failChunked(id string, j *job, run chatRun, output string, stats runStats) error {
+	a.streamsMutex.Lock()
+	delete(a.streams, id)
+	a.streamsMutex.Unlock()
+
+	a.finishJob(j, stats.err)
+	ce := toChatError(stats.err)
+	historyID := ""
+	if ce.Code != ChatErrCancelled {
+		historyID = a.recordHistory(run, output, stats)
+	}
+	a.emit("chat:error", ChatError{
+		StreamID:  id,
+		Error:     fmt.Sprintf("part %d: %s", run.Part, ce.Message),
+		Code:      ce.Code,
+		Status:    ce.Status,
+		Retryable: ce.Retryable,
+	})
+	go a.notifyRun(run, historyID, output, stats.duration, stats.err)
+	return stats.err
+}
+
+// combineChunkResults builds the input of the combining pass
+func combineChunkResults(results []string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "The input was too long to process at once, so it was split into %d consecutive parts "+
+		"and each part was processed separately. The results for each part follow in order. "+
+		"Combine them into a single result for the whole input, merging duplicates.\n\n", len(results))
+	for i, result := range results {
+		fmt.Fprintf(&sb, "## Part %d\n\n%s\n\n", i+1, strings.TrimSpace(result))
+	}
+	return sb.String()
+}
+
+// splitText cuts text into pieces of at most maxTokens, breaking at the
+// first separator that gives small enough pieces and packing neighbouring
+// pieces back together while they fit
+func splitText(text string, maxTokens int, separators ...string) []string {
+	if estimateTokens(text) <= maxTokens {
+		return []string{text}
+	}
+	if len(separators) == 0 {
+		// No separator left, cut at a conservative three characters per token
+		runes := []rune(text)
+		size := max(maxTokens*3, 1)
+		var chunks []string
+		for start := 0; start < len(runes); start += size {
+			chunks = append(chunks, string(runes[start:min(start+size, len(runes))]))
+		}
+		return chunks
+	}
+
+	sep := separators[0]
+	sepTokens := estimateTokens(sep)
+	var chunks []string
+	var current strings.Builder
+	currentTokens := 0
+	flush := func() {
+		if current.Len() > 0 {
+			chunks = append(chunks, current.String())
+			current.Reset()
+			currentTokens = 0
+		}
+	}
+
+	for _, piece := range strings.Split(text, sep) {
+		tokens := estimateTokens(piece)
+		if tokens > maxTokens {
+			flush()
+			chunks = append(chunks, splitText(piece, maxTokens, separators[1:]...)...)
+			continue
+		}
+		if current.Len() > 0 && currentTokens+sepTokens+tokens > maxTokens {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteString(sep)
+			currentTokens += sepTokens
+		}
+		current.WriteString(piece)
+		currentTokens += tokens
+	}
+	flush()
+	return chunks
+}
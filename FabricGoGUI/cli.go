@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+)
+
+// cliUsage introduces the flag list printed by --help
+const cliUsage = `Usage: FabricGoGUI [flags] [text]
+
+Runs a pattern or preset without opening a window, with the presets,
+history and preferences of the GUI. The input is --input, the text
+arguments or standard input, in that order. Started without flags, the
+app opens its window.
+
+Examples:
+  FabricGoGUI --pattern summarize --input notes.md --model gpt-4o
+  pbpaste | FabricGoGUI --preset "Tweet thread"
+  FabricGoGUI --list patterns
+
+Flags:
+`
+
+// cliVariables collects repeated --var name=value flags
+type cliVariables map[string]string
+
+func (v cliVariables) String() string {
+	return fmt.Sprint(map[string]string(v))
+}
+
+func (v cliVariables) Set(value string) error {
+	name, val, ok := strings.Cut(value, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("expected name=value")
+	}
+	v[name] = val
+	return nil
+}
+
+// isCLI reports whether the command line asks for a headless run. macOS
+// passes -psn_ process serial numbers to apps opened from the Finder.
+func isCLI(args []string) bool {
+	return len(args) > 0 && strings.HasPrefix(args[0], "-") && !strings.HasPrefix(args[0], "-psn_")
+}
+
+// runCLI runs the app headless with the given command line and returns the
+// exit code. The output goes to stdout or --output, errors to stderr.
+func runCLI(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("FabricGoGUI", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, cliUsage)
+		flags.PrintDefaults()
+	}
+	pattern := flags.String("pattern", "", "pattern to run")
+	preset := flags.String("preset", "", "preset to run instead of a pattern")
+	model := flags.String("model", "", "model, defaults to the pattern's or the last one used in the GUI")
+	vendor := flags.String("vendor", "", "vendor of the model, looked up when not given")
+	input := flags.String("input", "", "file to read the input from, - for standard input")
+	output := flags.String("output", "", "file to write the output to instead of standard output")
+	contextName := flags.String("context", "", "Fabric context to include")
+	strategy := flags.String("strategy", "", "prompting strategy")
+	server := flags.String("server", "", "Fabric server URL, defaults to the GUI's")
+	noHistory := flags.Bool("no-history", false, "do not save the run to history")
+	list := flags.String("list", "", "list patterns, models, presets or history instead of running")
+	variables := cliVariables{}
+	flags.Var(variables, "var", "pattern variable as name=value, may be repeated")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	a := NewApp()
+	a.headless = true
+	a.ctx = context.Background()
+	prefs := a.loadState()
+	defer a.closeLogging()
+	if *server != "" {
+		a.SetBaseURL(*server)
+	}
+
+	fail := func(err error) int {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+
+	if *list != "" {
+		if err := a.cliList(*list, stdout); err != nil {
+			return fail(err)
+		}
+		return 0
+	}
+
+	text, err := cliInput(*input, flags.Args(), stdin)
+	if err != nil {
+		return fail(err)
+	}
+
+	var prompt PromptRequest
+	if *preset != "" {
+		if prompt, err = a.presetPrompt(*preset, text); err != nil {
+			return fail(err)
+		}
+		if *model != "" {
+			prompt.Model, prompt.Vendor = *model, *vendor
+		}
+	} else {
+		name, vendorName := *model, *vendor
+		if name == "" && *pattern != "" {
+			vendorName, name = a.patternDefaultModel(*pattern, vendorName, "")
+		}
+		if name == "" {
+			name, vendorName = prefs.LastModel, prefs.LastVendor
+		}
+		if name == "" {
+			return fail(fmt.Errorf("no model given, use --model"))
+		}
+		prompt = a.newPrompt(*pattern, vendorName, name, text)
+	}
+	if prompt.Vendor == "" {
+		if prompt.Vendor, err = a.vendorForModel(prompt.Model); err != nil {
+			return fail(err)
+		}
+	}
+	ChatExtras{Variables: variables, Context: *contextName, Strategy: *strategy}.apply(&prompt)
+
+	// The output streams to stdout as it arrives, a file is written at the end
+	onChunk := func(chunk string) { io.WriteString(stdout, chunk) }
+	if *output != "" {
+		onChunk = func(string) {}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	start := time.Now()
+	var stats runStats
+	result, err := a.streamChatWithRetry(ctx, prompt, onChunk, stats.addUsage, func(retry ChatRetry) {
+		fmt.Fprintf(stderr, "retrying (%d/%d): %s\n", retry.Attempt, retry.MaxAttempts, retry.Error)
+	})
+	stats.duration = time.Since(start)
+	stats.err = err
+
+	if !*noHistory && (err == nil || result != "") {
+		if a.history.Locked() {
+			fmt.Fprintln(stderr, "warning: history is locked with a passphrase, the run was not saved")
+		}
+		a.recordHistory(chatRun{Prompt: prompt}, result, stats)
+	}
+	if err == nil && *output != "" {
+		err = writeFileAtomic(*output, []byte(result), 0644)
+	} else if err == nil && !strings.HasSuffix(result, "\n") {
+		fmt.Fprintln(stdout)
+	}
+	// Webhooks, autosave and the like finish before the process exits
+	a.tasks.Wait()
+	if err != nil {
+		return fail(fmt.Errorf("%s", toChatError(err).Message))
+	}
+	return 0
+}
+
+// cliInput reads the input from a file, the text arguments or stdin
+func cliInput(path string, args []string, stdin io.Reader) (string, error) {
+	switch {
+	case path == "-":
+		data, err := io.ReadAll(stdin)
+		return string(data), err
+	case path != "":
+		return readImportFile(path)
+	case len(args) > 0:
+		return strings.Join(args, " "), nil
+	}
+	if f, ok := stdin.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			return "", fmt.Errorf("no input given, use --input, text arguments or standard input")
+		}
+	}
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read standard input: %v", err)
+	}
+	return string(data), nil
+}
+
+// cliList prints patterns, models, presets or recent history, one per line
+func (a *App) cliList(what string, w io.Writer) error {
+	switch what {
+	case "patterns":
+		patterns, err := a.GetPatterns()
+		if err != nil {
+			return err
+		}
+		for _, p := range patterns {
+			fmt.Fprintln(w, p.Name)
+		}
+	case "models":
+		models, err := a.models()
+		if err != nil {
+			return err
+		}
+		for _, vendor := range slices.Sorted(maps.Keys(models.Vendors)) {
+			for _, name := range models.Vendors[vendor] {
+				fmt.Fprintf(w, "%s\t%s\n", vendor, name)
+			}
+		}
+	case "presets":
+		presets, err := a.ListPresets()
+		if err != nil {
+			return err
+		}
+		for _, p := range presets {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", p.Name, p.Pattern, p.Model)
+		}
+	case "history":
+		entries := a.history.All()
+		for i := len(entries) - 1; i >= 0; i-- {
+			e := entries[i]
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.ID, time.Unix(e.Time, 0).Format("2006-01-02 15:04"), e.Pattern, defaultNoteTitle(e))
+		}
+	default:
+		return fmt.Errorf("cannot list %q, use patterns, models, presets or history", what)
+	}
+	return nil
+}
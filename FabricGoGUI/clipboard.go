@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	goruntime "runtime"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// clipboardTool describes an external clipboard helper used as a fallback on Linux
+type clipboardTool struct {
+	name      string
+	read      []string
+	write     []string
+	selection []string // reads the primary selection, the text last selected
+}
+
+// linuxClipboardTools lists helpers in order of preference. Wayland tools come
+// first so they are used when both a Wayland and an X11 tool are installed.
+var linuxClipboardTools = []clipboardTool{
+	{name: "wl-paste", read: []string{"wl-paste", "--no-newline"}, write: []string{"wl-copy"}, selection: []string{"wl-paste", "--primary", "--no-newline"}},
+	{name: "xclip", read: []string{"xclip", "-selection", "clipboard", "-o"}, write: []string{"xclip", "-selection", "clipboard", "-i"}, selection: []string{"xclip", "-selection", "primary", "-o"}},
+	{name: "xsel", read: []string{"xsel", "--clipboard", "--output"}, write: []string{"xsel", "--clipboard", "--input"}, selection: []string{"xsel", "--primary", "--output"}},
+}
+
+// ReadClipboard returns the current text content of the system clipboard
+func (a *App) ReadClipboard() (string, error) {
+	text, err := runtime.ClipboardGetText(a.ctx)
+	if err == nil && (text != "" || goruntime.GOOS != "linux") {
+		return text, nil
+	}
+
+	// The webview clipboard is unreliable on some Linux desktops, try native tools
+	if goruntime.GOOS == "linux" {
+		if fallback, ferr := readClipboardLinux(); ferr == nil {
+			return fallback, nil
+		}
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to read clipboard: %v", err)
+	}
+	return text, nil
+}
+
+// WriteClipboard places the given text on the system clipboard
+func (a *App) WriteClipboard(text string) error {
+	a.noteClipboardWrite(text)
+	err := runtime.ClipboardSetText(a.ctx, text)
+
+	// The webview clipboard is unreliable on some Linux desktops, also set it natively
+	if goruntime.GOOS == "linux" {
+		if ferr := writeClipboardLinux(text); ferr == nil {
+			return nil
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to write clipboard: %v", err)
+	}
+	return nil
+}
+
+// Formats accepted by CopyOutput. There is no HTML format: the clipboard
+// only takes plain text, so the markup would be pasted as it is. ExportToHTML
+// writes rendered output instead.
+const (
+	CopyFormatMarkdown = "markdown" // the output as the model wrote it
+	CopyFormatText     = "text"     // rendered, without markdown markup
+)
+
+// CopyOutput copies a history entry's output to the clipboard as markdown
+// or plain text. An empty id copies the most recent output. Rendering
+// happens here rather than in the webview, which struggles with very large
+// outputs.
+func (a *App) CopyOutput(id, format string) error {
+	entry, ok := a.history.Last()
+	if id != "" {
+		entry, ok = a.history.Find(id)
+	}
+	if !ok {
+		return fmt.Errorf("no output to copy")
+	}
+
+	output, err := formatOutput(entry.Output, format)
+	if err != nil {
+		return err
+	}
+	if err := a.WriteClipboard(output); err != nil {
+		return err
+	}
+
+	a.emit("clipboard:copied", len(output))
+	return nil
+}
+
+// formatOutput converts markdown output to one of the copy formats
+func formatOutput(output, format string) (string, error) {
+	switch format {
+	case "", CopyFormatMarkdown:
+		return output, nil
+	case CopyFormatText:
+		return markdownToText(output), nil
+	default:
+		return "", fmt.Errorf("unknown copy format: %s", format)
+	}
+}
+
+// availableClipboardTools returns the Linux clipboard helpers usable in this session
+func availableClipboardTools() []clipboardTool {
+	wayland := os.Getenv("WAYLAND_DISPLAY") != ""
+
+	var tools []clipboardTool
+	for _, tool := range linuxClipboardTools {
+		if tool.name == "wl-paste" && !wayland {
+			continue
+		}
+		if _, err := exec.LookPath(tool.read[0]); err != nil {
+			continue
+		}
+		if _, err := exec.LookPath(tool.write[0]); err != nil {
+			continue
+		}
+		tools = append(tools, tool)
+	}
+	return tools
+}
+
+func readClipboardLinux() (string, error) {
+	for _, tool := range availableClipboardTools() {
+		out, err := exec.Command(tool.read[0], tool.read[1:]...).Output()
+		if err == nil {
+			return string(out), nil
+		}
+	}
+	return "", fmt.Errorf("no working clipboard tool found (install wl-clipboard, xclip or xsel)")
+}
+
+// readSelectionLinux returns the primary selection, which X11 and most
+// Wayland desktops fill with whatever text was last selected
+func readSelectionLinux() (string, error) {
+	for _, tool := range availableClipboardTools() {
+		out, err := exec.Command(tool.selection[0], tool.selection[1:]...).Output()
+		if err == nil {
+			return string(out), nil
+		}
+	}
+	return "", fmt.Errorf("no working clipboard tool found (install wl-clipboard, xclip or xsel)")
+}
+
+func writeClipboardLinux(text string) error {
+	for _, tool := range availableClipboardTools() {
+		cmd := exec.Command(tool.write[0], tool.write[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("no working clipboard tool found (install wl-clipboard, xclip or xsel)")
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// clipboardPollInterval is how often the watcher checks the clipboard
+const clipboardPollInterval = time.Second
+
+// clipboardWatcher polls the clipboard and runs new text through the quick pattern
+type clipboardWatcher struct {
+	stop    chan struct{}
+	mu      sync.Mutex
+	armed   bool
+	busy    bool
+	pattern string
+	vendor  string
+	model   string
+	last    string
+}
+
+// QuickModeStatus describes the state of the clipboard watcher
+type QuickModeStatus struct {
+	Watching bool   `json:"watching"`
+	Armed    bool   `json:"armed"`
+	Pattern  string `json:"pattern"`
+	Vendor   string `json:"vendor"`
+	Model    string `json:"model"`
+}
+
+// QuickResult is emitted when clipboard text has been processed in quick mode
+type QuickResult struct {
+	Pattern string `json:"pattern"`
+	Model   string `json:"model"`
+	Input   string `json:"input"`
+	Output  string `json:"output"`
+}
+
+// StartClipboardWatcher begins monitoring the clipboard for new text
+func (a *App) StartClipboardWatcher() error {
+	a.watcherMutex.Lock()
+	defer a.watcherMutex.Unlock()
+
+	if a.watcher != nil {
+		return fmt.Errorf("clipboard watcher already running")
+	}
+
+	w := &clipboardWatcher{stop: make(chan struct{})}
+
+	// Restore the quick pattern from preferences so the watcher can be armed right away
+	if prefs, err := a.loadPreferences(); err == nil {
+		w.pattern = prefs.QuickPattern
+		w.vendor = prefs.QuickVendor
+		w.model = prefs.QuickModel
+	}
+
+	// Whatever is already on the clipboard is not "new"
+	if text, err := a.ReadClipboard(); err == nil {
+		w.last = text
+	}
+
+	a.watcher = w
+	go a.watchClipboard(w)
+
+	a.emit("quick:watching", true)
+	return nil
+}
+
+// StopClipboardWatcher stops monitoring the clipboard
+func (a *App) StopClipboardWatcher() {
+	a.watcherMutex.Lock()
+	defer a.watcherMutex.Unlock()
+
+	if a.watcher == nil {
+		return
+	}
+
+	close(a.watcher.stop)
+	a.watcher = nil
+
+	a.emit("quick:watching", false)
+}
+
+// SetQuickMode arms or disarms quick mode and sets the pattern used to process clipboard text
+func (a *App) SetQuickMode(armed bool, pattern, vendor, model string) error {
+	a.watcherMutex.Lock()
+	defer a.watcherMutex.Unlock()
+
+	if a.watcher == nil {
+		return fmt.Errorf("clipboard watcher is not running")
+	}
+	if armed && pattern == "" {
+		return fmt.Errorf("a pattern is required to arm quick mode")
+	}
+
+	a.watcher.mu.Lock()
+	a.watcher.armed = armed
+	a.watcher.pattern = pattern
+	a.watcher.vendor = vendor
+	a.watcher.model = model
+	a.watcher.mu.Unlock()
+
+	a.emit("quick:armed", armed)
+	return nil
+}
+
+// GetQuickModeStatus returns the current clipboard watcher state
+func (a *App) GetQuickModeStatus() QuickModeStatus {
+	a.watcherMutex.Lock()
+	defer a.watcherMutex.Unlock()
+
+	if a.watcher == nil {
+		return QuickModeStatus{}
+	}
+
+	a.watcher.mu.Lock()
+	defer a.watcher.mu.Unlock()
+
+	return QuickModeStatus{
+		Watching: true,
+		Armed:    a.watcher.armed,
+		Pattern:  a.watcher.pattern,
+		Vendor:   a.watcher.vendor,
+		Model:    a.watcher.model,
+	}
+}
+
+// noteClipboardWrite records text the app placed on the clipboard itself so
+// the watcher does not process our own output
+func (a *App) noteClipboardWrite(text string) {
+	a.watcherMutex.Lock()
+	w := a.watcher
+	a.watcherMutex.Unlock()
+
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	w.last = text
+	w.mu.Unlock()
+}
+
+// watchClipboard polls the clipboard until the watcher is stopped
+func (a *App) watchClipboard(w *clipboardWatcher) {
+	ticker := time.NewTicker(clipboardPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		}
+
+		text, err := a.ReadClipboard()
+		if err != nil {
+			continue
+		}
+
+		w.mu.Lock()
+		if text == w.last {
+			w.mu.Unlock()
+			continue
+		}
+		w.last = text
+
+		// Only one quick run at a time, and only when armed
+		if !w.armed || w.busy || strings.TrimSpace(text) == "" {
+			w.mu.Unlock()
+			continue
+		}
+		w.busy = true
+		prompt := PromptRequest{
+			UserInput:   text,
+			Vendor:      w.vendor,
+			Model:       w.model,
+			PatternName: w.pattern,
+		}
+		w.mu.Unlock()
+
+		go a.runQuickPattern(w, prompt)
+	}
+}
+
+// runQuickPattern processes clipboard text and emits the result
+func (a *App) runQuickPattern(w *clipboardWatcher, prompt PromptRequest) {
+	defer func() {
+		w.mu.Lock()
+		w.busy = false
+		w.mu.Unlock()
+	}()
+
+	a.runQuick(prompt, prompt.PatternName, nil)
+}
+
+// runQuick runs a prompt without streaming it to the main window, emitting
+// quick:started and then quick:result or quick:error. onChunk, when set,
+// receives the output as it streams.
+func (a *App) runQuick(prompt PromptRequest, label string, onChunk func(string)) (string, error) {
+	if onChunk == nil {
+		onChunk = func(string) {}
+	}
+	a.emit("quick:started", prompt.PatternName)
+
+	job, ctx := a.startJob("chat", "Quick: "+label)
+	start := time.Now()
+	var stats runStats
+	output, err := a.streamChatWithRetry(ctx, prompt, onChunk, stats.addUsage, func(ChatRetry) {})
+	a.finishJob(job, err)
+	stats.duration = time.Since(start)
+	if err != nil {
+		a.emit("quick:error", err.Error())
+		return "", err
+	}
+
+	a.recordHistory(chatRun{Prompt: prompt}, output, stats)
+	a.emit("quick:result", QuickResult{
+		Pattern: prompt.PatternName,
+		Model:   prompt.Model,
+		Input:   prompt.UserInput,
+		Output:  output,
+	})
+	return output, nil
+}
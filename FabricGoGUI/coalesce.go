@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// chunkCoalesceInterval is how long streamed chunks are buffered before being
+// emitted together, fast models can otherwise produce hundreds of events per second
+const chunkCoalesceInterval = 30 * time.Millisecond
+
+// chunkCoalescer batches small chunks and hands them to emit at most once per interval
+type chunkCoalescer struct {
+	mu       sync.Mutex
+	buf      strings.Builder
+	timer    *time.Timer
+	interval time.Duration
+	emit     func(string)
+}
+
+func newChunkCoalescer(interval time.Duration, emit func(string)) *chunkCoalescer {
+	return &chunkCoalescer{interval: interval, emit: emit}
+}
+
+// Add buffers a chunk, scheduling a flush if none is pending
+func (c *chunkCoalescer) Add(chunk string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.buf.WriteString(chunk)
+	if c.timer == nil {
+		c.timer = time.AfterFunc(c.interval, c.Flush)
+	}
+}
+
+// Flush emits any buffered text immediately
+func (c *chunkCoalescer) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	if c.buf.Len() == 0 {
+		return
+	}
+
+	// Emit while holding the lock so chunks can never be delivered out of order
+	c.emit(c.buf.String())
+	c.buf.Reset()
+}
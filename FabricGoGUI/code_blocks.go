@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// CodeBlock is a code block found in an output
+type CodeBlock struct {
+	Index    int    `json:"index"`    // position among the output's code blocks, from 0
+	Language string `json:"language"` // from the fence's info string, may be empty
+	Code     string `json:"code"`
+	Line     int    `json:"line"` // first line of code in the output, from 1
+}
+
+// codeFileNames suggests a file name for saving a block, by language
+var codeFileNames = map[string]string{
+	"go": "main.go", "golang": "main.go",
+	"python": "script.py", "py": "script.py",
+	"javascript": "script.js", "js": "script.js", "jsx": "component.jsx",
+	"typescript": "script.ts", "ts": "script.ts", "tsx": "component.tsx",
+	"bash": "script.sh", "sh": "script.sh", "shell": "script.sh", "zsh": "script.sh",
+	"powershell": "script.ps1", "ps1": "script.ps1",
+	"rust": "main.rs", "rs": "main.rs", "c": "main.c", "cpp": "main.cpp", "c++": "main.cpp",
+	"cs": "Program.cs", "csharp": "Program.cs", "java": "Main.java", "kotlin": "Main.kt", "kt": "Main.kt",
+	"swift": "main.swift", "ruby": "script.rb", "rb": "script.rb", "php": "index.php",
+	"html": "index.html", "css": "style.css", "scss": "style.scss",
+	"json": "data.json", "yaml": "config.yaml", "yml": "config.yaml", "toml": "config.toml", "xml": "data.xml",
+	"sql": "query.sql", "markdown": "README.md", "md": "README.md",
+	"dockerfile": "Dockerfile", "docker": "Dockerfile", "makefile": "Makefile", "make": "Makefile",
+	"diff": "changes.diff", "patch": "changes.patch",
+}
+
+// ExtractCodeBlocks returns the fenced and indented code blocks in output,
+// in order. SaveCodeBlock and CopyCodeBlock work on the blocks of the last
+// output passed here.
+func (a *App) ExtractCodeBlocks(output string) []CodeBlock {
+	blocks := extractCodeBlocks(output)
+	a.codeBlocksMutex.Lock()
+	a.codeBlocks = blocks
+	a.codeBlocksMutex.Unlock()
+	return blocks
+}
+
+// SaveCodeBlock writes a code block to path, asking for one with a save
+// dialog when empty. Returns the path written, or "" if the dialog was
+// cancelled.
+func (a *App) SaveCodeBlock(index int, path string) (string, error) {
+	block, err := a.codeBlock(index)
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		path, err = runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+			Title:           "Save Code Block",
+			DefaultFilename: codeFileName(block.Language),
+		})
+		if err != nil || path == "" {
+			return "", err
+		}
+	}
+
+	code := block.Code
+	if !strings.HasSuffix(code, "\n") {
+		code += "\n"
+	}
+	if err := os.WriteFile(path, []byte(code), 0644); err != nil {
+		return "", fmt.Errorf("failed to save file: %v", err)
+	}
+	a.log.Info("saved code block", "index", index, "path", path)
+	return path, nil
+}
+
+// CopyCodeBlock copies a code block to the clipboard
+func (a *App) CopyCodeBlock(index int) error {
+	block, err := a.codeBlock(index)
+	if err != nil {
+		return err
+	}
+	if err := a.WriteClipboard(block.Code); err != nil {
+		return err
+	}
+	a.emit("clipboard:copied", len(block.Code))
+	return nil
+}
+
+// codeBlock returns a block of the last extracted output, or of the most
+// recent history entry when nothing has been extracted yet
+func (a *App) codeBlock(index int) (CodeBlock, error) {
+	a.codeBlocksMutex.Lock()
+	blocks := a.codeBlocks
+	a.codeBlocksMutex.Unlock()
+	if blocks == nil {
+		if entry, ok := a.history.Last(); ok {
+			blocks = a.ExtractCodeBlocks(entry.Output)
+		}
+	}
+	if len(blocks) == 0 {
+		return CodeBlock{}, fmt.Errorf("the output has no code blocks")
+	}
+	if index < 0 || index >= len(blocks) {
+		return CodeBlock{}, fmt.Errorf("code block %d not found, the output has %d", index+1, len(blocks))
+	}
+	return blocks[index], nil
+}
+
+func extractCodeBlocks(output string) []CodeBlock {
+	src := []byte(output)
+	doc := markdown.Parser().Parse(text.NewReader(src))
+	blocks := []CodeBlock{}
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		var language string
+		switch node := n.(type) {
+		case *ast.FencedCodeBlock:
+			language = string(node.Language(src))
+		case *ast.CodeBlock:
+		default:
+			return ast.WalkContinue, nil
+		}
+		lines := n.Lines()
+		if lines.Len() == 0 {
+			return ast.WalkSkipChildren, nil
+		}
+		blocks = append(blocks, CodeBlock{
+			Index:    len(blocks),
+			Language: language,
+			Code:     blockLines(n, src),
+			Line:     bytes.Count(src[:lines.At(0).Start], []byte("\n")) + 1,
+		})
+		return ast.WalkSkipChildren, nil
+	})
+	return blocks
+}
+
+// codeFileName suggests a file name for code in language
+func codeFileName(language string) string {
+	if name, ok := codeFileNames[strings.ToLower(language)]; ok {
+		return name
+	}
+	return "snippet.txt"
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// FabricContext is a Fabric context: text included with a chat request to
+// give the model background, like the CLI's --context
+type FabricContext struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// storageTimeout bounds calls to the server's storage endpoints
+const storageTimeout = 10 * time.Second
+
+// ListContexts returns the names of the contexts the server knows
+func (a *App) ListContexts() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+	return a.fabricClient().StorageNames(ctx, storageContexts)
+}
+
+// GetContext returns a context with its content
+func (a *App) GetContext(name string) (*FabricContext, error) {
+	if err := validatePatternName(name); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+
+	data, err := a.fabricClient().StorageGet(ctx, storageContexts, name)
+	if err != nil {
+		return nil, err
+	}
+	var item FabricContext
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("invalid context: %v", err)
+	}
+	item.Name = name
+	return &item, nil
+}
+
+// SaveContext creates a context or replaces its content
+func (a *App) SaveContext(name, content string) error {
+	if err := validatePatternName(name); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+
+	if err := a.fabricClient().StorageSave(ctx, storageContexts, name, []byte(content)); err != nil {
+		return fmt.Errorf("failed to save context: %v", err)
+	}
+	a.log.Info("saved context", "context", name)
+	return nil
+}
+
+// DeleteContext removes a context
+func (a *App) DeleteContext(name string) error {
+	if err := validatePatternName(name); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+
+	if err := a.fabricClient().StorageDelete(ctx, storageContexts, name); err != nil {
+		return fmt.Errorf("failed to delete context: %v", err)
+	}
+	a.log.Info("deleted context", "context", name)
+	return nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed five-field cron expression: minute, hour, day of
+// month, month and day of week. Each field is a bit set of allowed values.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// Cron matches either day field when both are restricted, both otherwise
+	domAny, dowAny bool
+}
+
+// cronField describes the range and names of a field
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // names for values from min, such as jan or sun
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// cronMacros are the shorthands cron accepts for common schedules
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// parseCron parses a cron expression such as "*/15 9-17 * * mon-fri" or a
+// macro such as "@daily"
+func parseCron(expr string) (*cronSchedule, error) {
+	expr = strings.ToLower(strings.TrimSpace(expr))
+	if macro, ok := cronMacros[expr]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression needs 5 fields (minute hour day month weekday), got %d", len(fields))
+	}
+
+	var sets [5]uint64
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = set
+	}
+	// Sunday is 0 or 7
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+	return &cronSchedule{
+		minute: sets[0],
+		hour:   sets[1],
+		dom:    sets[2],
+		month:  sets[3],
+		dow:    sets[4],
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}, nil
+}
+
+// parseCronField parses a comma-separated list of values, ranges and steps
+func parseCronField(field string, f cronField) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q in %s", stepPart, f.name)
+			}
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+		if rangePart != "*" {
+			first, last, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = f.value(first); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = f.value(last); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// "5/15" means from 5 to the end in steps of 15
+				hi = f.max
+			}
+			if hi < lo {
+				return 0, fmt.Errorf("invalid range %q in %s", rangePart, f.name)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// value parses a number or name in the field's range
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if s == name {
+			return f.min + i, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("invalid %s %q, must be %d-%d", f.name, s, f.min, f.max)
+	}
+	return n, nil
+}
+
+// matchesDay reports whether the schedule runs on t's day
+func (c *cronSchedule) matchesDay(t time.Time) bool {
+	dom := c.dom&(1<<t.Day()) != 0
+	dow := c.dow&(1<<int(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Next returns the first time after t the schedule runs at, or the zero
+// time when it never does (such as on February 30th)
+func (c *cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// Five years covers every combination of day of month and weekday
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
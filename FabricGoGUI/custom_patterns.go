@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Pattern sources
+const (
+	PatternSourceFabric = "fabric"
+	PatternSourceCustom = "custom"
+)
+
+// patternSystemFile is the prompt file inside a pattern directory
+const patternSystemFile = "system.md"
+
+// PatternInfo is one entry in the pattern list
+type PatternInfo struct {
+	Name        string   `json:"name"`
+	Source      string   `json:"source"`        // fabric or custom
+	Dir         string   `json:"dir,omitempty"` // the custom directory holding it
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Category    string   `json:"category,omitempty"`
+}
+
+// Pattern is a pattern's prompt, as shown in the pattern editor
+type Pattern struct {
+	PatternInfo
+	System string `json:"system"`
+}
+
+// customPatternDirs returns the configured custom pattern directories
+func (a *App) customPatternDirs() []string {
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return nil
+	}
+
+	var dirs []string
+	for _, dir := range prefs.CustomPatternDirs {
+		if dir = strings.TrimSpace(dir); dir == "" {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(dir, "~"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				dir = filepath.Join(home, rest)
+			}
+		}
+		dirs = append(dirs, filepath.Clean(dir))
+	}
+	return dirs
+}
+
+// customPatterns lists the patterns in the custom directories. A name found
+// in more than one directory comes from the first.
+func (a *App) customPatterns() []PatternInfo {
+	seen := map[string]bool{}
+	var patterns []PatternInfo
+	for _, dir := range a.customPatternDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			a.log.Warn("failed to read custom pattern directory", "dir", dir, "error", err)
+			continue
+		}
+		for _, e := range entries {
+			if !e.IsDir() || seen[e.Name()] {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(dir, e.Name(), patternSystemFile)); err != nil {
+				continue
+			}
+			seen[e.Name()] = true
+			patterns = append(patterns, PatternInfo{Name: e.Name(), Source: PatternSourceCustom, Dir: dir})
+		}
+	}
+	return patterns
+}
+
+// findCustomPattern looks a pattern up in the custom directories
+func (a *App) findCustomPattern(name string) (PatternInfo, bool) {
+	for _, p := range a.customPatterns() {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return PatternInfo{}, false
+}
+
+// mergePatterns combines the server's patterns with custom ones, sorted by
+// name. A custom pattern replaces a Fabric one of the same name.
+func mergePatterns(names []string, custom []PatternInfo) []PatternInfo {
+	merged := make([]PatternInfo, 0, len(names)+len(custom))
+	overridden := map[string]bool{}
+	for _, p := range custom {
+		overridden[p.Name] = true
+		merged = append(merged, p)
+	}
+	for _, name := range names {
+		if !overridden[name] {
+			merged = append(merged, PatternInfo{Name: name, Source: PatternSourceFabric})
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Name < merged[j].Name })
+	return merged
+}
+
+// resolveCustomPattern expands a custom pattern into the prompt itself
+func (a *App) resolveCustomPattern(prompt PromptRequest) PromptRequest {
+	if prompt.PatternName == "" {
+		return prompt
+	}
+	info, ok := a.findCustomPattern(prompt.PatternName)
+	if !ok {
+		return prompt
+	}
+	data, err := os.ReadFile(filepath.Join(info.Dir, info.Name, patternSystemFile))
+	if err != nil {
+		a.log.Warn("failed to read custom pattern", "pattern", info.Name, "error", err)
+		return prompt
+	}
+
+	return inlineSystemPrompt(prompt, string(data))
+}
+
+// inlineSystemPrompt puts a system prompt into the user message, in place of
+// a pattern the server would apply, as fabric does with its own patterns:
+// variables are filled in, {{input}} is replaced by the input, otherwise the
+// input follows the prompt
+func inlineSystemPrompt(prompt PromptRequest, system string) PromptRequest {
+	system = substituteVariables(system, prompt.Variables)
+	if strings.Contains(system, "{{input}}") {
+		prompt.UserInput = strings.ReplaceAll(system, "{{input}}", prompt.UserInput)
+	} else {
+		prompt.UserInput = strings.TrimRight(system, "\n") + "\n\n" + prompt.UserInput
+	}
+	prompt.PatternName = ""
+	prompt.Variables = nil
+	return prompt
+}
+
+// GetPattern returns a pattern's prompt for editing
+func (a *App) GetPattern(name string) (*Pattern, error) {
+	if err := validatePatternName(name); err != nil {
+		return nil, err
+	}
+
+	info, ok := a.findCustomPattern(name)
+	dir := info.Dir
+	if !ok {
+		var err error
+		if dir, err = fabricPatternsDir(); err != nil {
+			return nil, err
+		}
+		info = PatternInfo{Name: name, Source: PatternSourceFabric}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, name, patternSystemFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pattern: %v", err)
+	}
+	return &Pattern{PatternInfo: info, System: string(data)}, nil
+}
+
+// SavePattern writes a pattern's prompt. Custom patterns are saved where they
+// are; new patterns, and edits to Fabric's own, go to the first custom
+// directory, since UpdatePatterns overwrites Fabric's patterns directory.
+func (a *App) SavePattern(name, system string) (*PatternInfo, error) {
+	if err := validatePatternName(name); err != nil {
+		return nil, err
+	}
+
+	info, ok := a.findCustomPattern(name)
+	if !ok {
+		dirs := a.customPatternDirs()
+		if len(dirs) == 0 {
+			return nil, fmt.Errorf("add a custom pattern directory in preferences to save patterns")
+		}
+		info = PatternInfo{Name: name, Source: PatternSourceCustom, Dir: dirs[0]}
+	}
+
+	dir := filepath.Join(info.Dir, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create pattern directory: %v", err)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, patternSystemFile), []byte(system), 0644); err != nil {
+		return nil, fmt.Errorf("failed to save pattern: %v", err)
+	}
+	a.patternIndex.invalidate()
+	a.log.Info("saved pattern", "pattern", name, "dir", info.Dir)
+	return &info, nil
+}
+
+// validatePatternName rejects names that would escape the pattern directory.
+// Contexts and sessions are stored as files by name the same way.
+func validatePatternName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid pattern name %q", name)
+	}
+	return nil
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DailyNoteSettings configure AppendToDailyNote
+type DailyNoteSettings struct {
+	Folder   string   `json:"folder"`   // notes root, the Obsidian vault when empty
+	Path     string   `json:"path"`     // note path in the folder, with {date} tokens such as {YYYY-MM-DD}
+	Heading  string   `json:"heading"`  // heading to append under, at the end of the note when empty
+	Patterns []string `json:"patterns"` // patterns whose results are appended automatically
+}
+
+const (
+	// defaultDailyNotePath matches Obsidian's default daily note name.
+	// Logseq keeps journals as journals/{YYYY_MM_DD}.md.
+	defaultDailyNotePath = "{YYYY-MM-DD}.md"
+	// defaultDailyNoteHeading is the section Fabric output is collected in
+	defaultDailyNoteHeading = "## Fabric"
+)
+
+// dailyNoteMu serialises edits, as runs can finish at the same time
+var dailyNoteMu sync.Mutex
+
+// GetDailyNoteSettings returns the daily note settings, with defaults filled in
+func (a *App) GetDailyNoteSettings() DailyNoteSettings {
+	settings := DailyNoteSettings{Path: defaultDailyNotePath, Heading: defaultDailyNoteHeading}
+	if prefs, err := a.loadPreferences(); err == nil && prefs.DailyNote != nil {
+		settings = *prefs.DailyNote
+	}
+	if settings.Path == "" {
+		settings.Path = defaultDailyNotePath
+	}
+	if settings.Patterns == nil {
+		settings.Patterns = []string{}
+	}
+	return settings
+}
+
+// SaveDailyNoteSettings stores the daily note settings
+func (a *App) SaveDailyNoteSettings(settings DailyNoteSettings) error {
+	if settings.Folder != "" {
+		if info, err := os.Stat(settings.Folder); err != nil || !info.IsDir() {
+			return fmt.Errorf("daily note folder not found: %s", settings.Folder)
+		}
+	}
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	prefs.DailyNote = &settings
+	return a.SavePreferences(*prefs)
+}
+
+// AppendToDailyNote adds content to today's daily note under the configured
+// heading, creating the note and the heading when needed. Returns the note's path.
+func (a *App) AppendToDailyNote(content string) (string, error) {
+	if strings.TrimSpace(content) == "" {
+		return "", fmt.Errorf("no content to append")
+	}
+	settings := a.GetDailyNoteSettings()
+	folder := settings.Folder
+	if folder == "" {
+		folder = a.GetObsidianSettings().Vault
+	}
+	if folder == "" {
+		return "", fmt.Errorf("no daily note folder configured")
+	}
+
+	path, err := vaultPath(folder, expandDatePath(settings.Path, time.Now()))
+	if err != nil {
+		return "", err
+	}
+
+	dailyNoteMu.Lock()
+	defer dailyNoteMu.Unlock()
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read daily note: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create folder: %v", err)
+	}
+	// Headings in the content would end the section early, so they are
+	// moved below the section's level
+	if level := headingLevel(strings.TrimSpace(settings.Heading)); level > 0 {
+		content = demoteHeadings(content, level+1)
+	}
+	note := insertUnderHeading(string(existing), settings.Heading, strings.TrimSpace(content))
+	if err := writeFileAtomic(path, []byte(note), 0644); err != nil {
+		return "", fmt.Errorf("failed to save daily note: %v", err)
+	}
+	a.log.Info("appended to daily note", "path", path)
+	return path, nil
+}
+
+// appendRunToDailyNote adds a finished run to the daily note when its
+// pattern is one of the automatic ones
+func (a *App) appendRunToDailyNote(entry HistoryEntry) {
+	settings := a.GetDailyNoteSettings()
+	if entry.Pattern == "" || !containsFold(settings.Patterns, entry.Pattern) {
+		return
+	}
+	// Each run gets a heading one level below the section, with the output's
+	// own headings below that
+	level := max(headingLevel(strings.TrimSpace(settings.Heading))+1, 3)
+	title := fmt.Sprintf("%s %s", time.Unix(entry.Time, 0).Format("15:04"), entry.Pattern)
+	if level > 6 {
+		title = "**" + title + "**"
+	} else {
+		title = strings.Repeat("#", level) + " " + title
+	}
+	block := title + "\n\n" + demoteHeadings(entry.Output, level+1)
+	if _, err := a.AppendToDailyNote(block); err != nil {
+		a.log.Warn("failed to append run to daily note", "pattern", entry.Pattern, "error", err)
+	}
+}
+
+// dateToken matches the {...} date formats in a daily note path
+var dateToken = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// expandDatePath replaces each {format} in path with t in that format.
+// Formats use the Moment.js tokens Obsidian and Logseq settings use:
+// YYYY, YY, MMMM, MMM, MM, M, DD, D, dddd, ddd, HH and mm.
+func expandDatePath(path string, t time.Time) string {
+	return dateToken.ReplaceAllStringFunc(path, func(token string) string {
+		return formatMomentDate(token[1:len(token)-1], t)
+	})
+}
+
+// momentTokens maps Moment.js tokens to Go layouts, longest first so MMMM
+// is not read as MM twice
+var momentTokens = []struct{ token, layout string }{
+	{"YYYY", "2006"}, {"YY", "06"},
+	{"MMMM", "January"}, {"MMM", "Jan"}, {"MM", "01"}, {"M", "1"},
+	{"dddd", "Monday"}, {"ddd", "Mon"},
+	{"DD", "02"}, {"D", "2"},
+	{"HH", "15"}, {"mm", "04"},
+}
+
+func formatMomentDate(format string, t time.Time) string {
+	var sb strings.Builder
+	for len(format) > 0 {
+		matched := false
+		for _, m := range momentTokens {
+			if strings.HasPrefix(format, m.token) {
+				sb.WriteString(t.Format(m.layout))
+				format = format[len(m.token):]
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			sb.WriteByte(format[0])
+			format = format[1:]
+		}
+	}
+	return sb.String()
+}
+
+// insertUnderHeading adds block at the end of heading's section, before the
+// next heading of the same or a higher level. A missing heading is added at
+// the end of the note, and with no heading the block is simply appended.
+func insertUnderHeading(note, heading, block string) string {
+	note = strings.TrimRight(note, "\n")
+	heading = strings.TrimSpace(heading)
+
+	lines := strings.Split(note, "\n")
+	start := -1
+	if heading != "" {
+		inFence := false
+		for i, line := range lines {
+			if strings.HasPrefix(strings.TrimSpace(line), "```") {
+				inFence = !inFence
+			}
+			if !inFence && strings.TrimSpace(line) == heading {
+				start = i
+				break
+			}
+		}
+	}
+
+	if start < 0 {
+		parts := []string{}
+		if note != "" {
+			parts = append(parts, note)
+		}
+		if heading != "" {
+			parts = append(parts, heading)
+		}
+		return strings.Join(append(parts, block), "\n\n") + "\n"
+	}
+
+	level := headingLevel(heading)
+	end := len(lines)
+	inFence := false
+	for i := start + 1; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), "```") {
+			inFence = !inFence
+		}
+		if l := headingLevel(lines[i]); !inFence && l > 0 && l <= level {
+			end = i
+			break
+		}
+	}
+
+	// The block follows the section's last line, one blank line apart
+	last := end
+	for last > start+1 && strings.TrimSpace(lines[last-1]) == "" {
+		last--
+	}
+	out := append([]string{}, lines[:last]...)
+	out = append(out, "", block)
+	if end < len(lines) {
+		out = append(out, "")
+		out = append(out, lines[end:]...)
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+// headingLevel returns the level of an ATX heading line, 0 for other lines
+func headingLevel(line string) int {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(line) && line[level] != ' ') {
+		return 0
+	}
+	return level
+}
+
+// demoteHeadings moves the ATX headings in text down so the highest is at
+// level, keeping their relative levels. Headings pushed past level 6 become
+// bold lines. Fenced code is left alone.
+func demoteHeadings(text string, level int) string {
+	lines := strings.Split(text, "\n")
+	top := 0
+	inFence := false
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if l := headingLevel(line); !inFence && l > 0 && (top == 0 || l < top) {
+			top = l
+		}
+	}
+	if top == 0 || top >= level {
+		return text
+	}
+
+	shift := level - top
+	inFence = false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		l := headingLevel(line)
+		if inFence || l == 0 {
+			continue
+		}
+		title := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(line[l:]), "#"))
+		if l+shift > 6 {
+			lines[i] = "**" + title + "**"
+		} else {
+			lines[i] = strings.Repeat("#", l+shift) + " " + title
+		}
+	}
+	return strings.Join(lines, "\n")
+}
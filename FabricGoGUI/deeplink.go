@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"time"
+)
+
+// urlScheme is the scheme of the app's deep links, see resolveDeepLink
+const urlScheme = "fabricgui"
+
+// DeepLink is what a fabricgui:// link asks for, emitted as "deeplink:open"
+// for the frontend to load
+type DeepLink struct {
+	Pattern string `json:"pattern"`
+	Vendor  string `json:"vendor"`
+	Model   string `json:"model"`
+	Input   string `json:"input"`
+	Run     bool   `json:"run"` // offer to start the run once loaded
+}
+
+// handleLaunchArgs acts on the arguments the app was started with, or that
+// a second instance forwarded. dir is where they were given.
+func (a *App) handleLaunchArgs(args []string, dir string) {
+	for _, arg := range args {
+		switch {
+		case isDeepLink(arg):
+			go a.openDeepLink(arg)
+		case strings.HasPrefix(arg, "-"):
+			// -psn_ and other arguments the OS adds
+		default:
+			if !filepath.IsAbs(arg) {
+				arg = filepath.Join(dir, arg)
+			}
+			go a.openFile(arg)
+		}
+	}
+}
+
+// isDeepLink reports whether s is one of the app's links
+func isDeepLink(s string) bool {
+	return strings.HasPrefix(strings.ToLower(s), urlScheme+":")
+}
+
+// openDeepLink loads what a link asks for into the window, fetching the
+// page it names first. Failures are emitted as "deeplink:error".
+func (a *App) openDeepLink(raw string) {
+	if a.isFrontendReady() {
+		a.showWindow()
+	}
+	link, err := a.resolveDeepLink(raw)
+	if err != nil {
+		a.log.Warn("failed to open link", "link", raw, "error", err)
+		a.emitWhenReady("deeplink:error", err.Error())
+		return
+	}
+	a.log.Info("opened link", "pattern", link.Pattern, "run", link.Run)
+	a.emitWhenReady("deeplink:open", link)
+}
+
+// resolveDeepLink parses a link such as
+//
+//	fabricgui://run?pattern=extract_wisdom&url=https://example.com/post
+//
+// run starts the run once the user confirms it, open only loads it. pattern, model and vendor choose
+// what runs, or preset chooses all three. The input is text, the page at
+// url or both; only public pages are fetched.
+func (a *App) resolveDeepLink(raw string) (*DeepLink, error) {
+	u, err := url.Parse(raw)
+	if err != nil || !strings.EqualFold(u.Scheme, urlScheme) {
+		return nil, fmt.Errorf("not a %s:// link: %s", urlScheme, raw)
+	}
+	// fabricgui://run has the action as its host, fabricgui:run as opaque
+	action := u.Host
+	if action == "" {
+		action = u.Opaque
+	}
+	link := &DeepLink{}
+	switch strings.ToLower(strings.Trim(action, "/")) {
+	case "run":
+		link.Run = true
+	case "open":
+	default:
+		return nil, fmt.Errorf("unknown link action %q, use run or open", action)
+	}
+
+	query := u.Query()
+	input := query.Get("text")
+	if page := query.Get("url"); page != "" {
+		a.emitWhenReady("deeplink:fetching", page)
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		text, err := a.fetchPublicPageText(ctx, page)
+		if err != nil {
+			return nil, err
+		}
+		if input != "" {
+			input += "\n\n"
+		}
+		input += text
+	}
+
+	link.Pattern = query.Get("pattern")
+	link.Vendor = query.Get("vendor")
+	link.Model = query.Get("model")
+	link.Input = input
+	if err := a.completeDeepLink(link, query.Get("preset")); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// completeDeepLink fills in the pattern and model of a preset, when one is
+// named, or the vendor of the link's model
+func (a *App) completeDeepLink(link *DeepLink, preset string) error {
+	if preset != "" {
+		prompt, err := a.presetPrompt(preset, link.Input)
+		if err != nil {
+			return err
+		}
+		link.Pattern, link.Vendor, link.Model, link.Input = prompt.PatternName, prompt.Vendor, prompt.Model, prompt.UserInput
+		return nil
+	}
+	if link.Model != "" && link.Vendor == "" {
+		vendor, err := a.vendorForModel(link.Model)
+		if err != nil {
+			return err
+		}
+		link.Vendor = vendor
+	}
+	return nil
+}
+
+// isFrontendReady reports whether the frontend handles events yet
+func (a *App) isFrontendReady() bool {
+	a.readyMutex.Lock()
+	defer a.readyMutex.Unlock()
+	return a.frontendReady
+}
+
+// registerURLScheme makes the app the handler of fabricgui:// links for
+// the current user, and on Linux offers it in "Open With" for text and
+// Markdown files. macOS reads both from Info.plist and the Windows
+// installer registers them, this covers Linux and portable Windows builds.
+func (a *App) registerURLScheme() {
+	// The AppImage or executable, what a launcher starts
+	exe, err := updateTarget()
+	if err != nil {
+		return
+	}
+
+	switch goruntime.GOOS {
+	case "linux":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return
+		}
+		dir := filepath.Join(home, ".local", "share", "applications")
+		path := filepath.Join(dir, "fabric-gui-url.desktop")
+		entry := fmt.Sprintf("[Desktop Entry]\nType=Application\nName=Fabric GUI\nExec=\"%s\" %%U\nTerminal=false\nMimeType=x-scheme-handler/%s;text/markdown;text/plain;\n", exe, urlScheme)
+		if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, []byte(entry)) {
+			return
+		}
+		err = os.MkdirAll(dir, 0755)
+		if err == nil {
+			err = os.WriteFile(path, []byte(entry), 0644)
+		}
+		if err == nil {
+			err = exec.Command("xdg-mime", "default", filepath.Base(path), "x-scheme-handler/"+urlScheme).Run()
+		}
+		if err != nil {
+			a.log.Warn("failed to register link handler", "error", err)
+			return
+		}
+	case "windows":
+		key := `HKCU\Software\Classes\` + urlScheme
+		commands := [][]string{
+			{"add", key, "/ve", "/d", "URL:Fabric GUI", "/f"},
+			{"add", key, "/v", "URL Protocol", "/d", "", "/f"},
+			{"add", key + `\shell\open\command`, "/ve", "/d", fmt.Sprintf(`"%s" "%%1"`, exe), "/f"},
+		}
+		for _, args := range commands {
+			if err := exec.Command("reg", args...).Run(); err != nil {
+				a.log.Warn("failed to register link handler", "error", err)
+				return
+			}
+		}
+	default:
+		return
+	}
+	a.log.Debug("registered link handler", "scheme", urlScheme, "path", exe)
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// appVersion is the GUI version, set at build time with
+// -ldflags "-X main.appVersion=1.2.3"
+var appVersion = "dev"
+
+// diagnosticLogLines is how much of the log goes into a diagnostics report
+const diagnosticLogLines = 2000
+
+// DiagnosticsReport is the summary written to report.json in the bundle
+type DiagnosticsReport struct {
+	GeneratedAt   string             `json:"generatedAt"`
+	AppVersion    string             `json:"appVersion"`
+	GoVersion     string             `json:"goVersion"`
+	OS            string             `json:"os"`
+	Arch          string             `json:"arch"`
+	FabricPath    string             `json:"fabricPath"`
+	FabricVersion string             `json:"fabricVersion"`
+	ServerManaged bool               `json:"serverManaged"` // started by the GUI
+	BaseURL       string             `json:"baseUrl"`
+	Checks        []DiagnosticsCheck `json:"checks"`
+}
+
+// DiagnosticsCheck is the outcome of one connectivity check
+type DiagnosticsCheck struct {
+	Name       string `json:"name"`
+	OK         bool   `json:"ok"`
+	Detail     string `json:"detail,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// GenerateDiagnostics bundles version information, connectivity checks,
+// recent logs and settings, with secrets masked, into a zip for bug reports.
+// Returns the path written, or "" if the save dialog was cancelled.
+func (a *App) GenerateDiagnostics() (string, error) {
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Save Diagnostics Report",
+		DefaultFilename: "fabric-gui-diagnostics-" + time.Now().Format("20060102-150405") + ".zip",
+		Filters:         []runtime.FileFilter{{DisplayName: "Zip Archives", Pattern: "*.zip"}},
+	})
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return "", nil // User cancelled
+	}
+
+	files := map[string][]byte{}
+
+	report, err := json.MarshalIndent(a.diagnosticsReport(), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to build report: %v", err)
+	}
+	files["report.json"] = report
+
+	if logs, err := a.GetAppLogs(diagnosticLogLines); err == nil {
+		files["app.log"] = []byte(a.redact.String(logs))
+	}
+	if prefs, err := a.loadPreferences(); err == nil {
+		if data, err := maskedJSON(prefs); err == nil {
+			files["preferences.json"] = data
+		}
+	}
+	if profiles, err := a.ListProfiles(); err == nil {
+		if data, err := maskedJSON(profiles); err == nil {
+			files["profiles.json"] = data
+		}
+	}
+
+	if err := writeZip(path, files); err != nil {
+		return "", fmt.Errorf("failed to write diagnostics: %v", err)
+	}
+	a.log.Info("wrote diagnostics report", "path", path)
+	return path, nil
+}
+
+// diagnosticsReport collects versions and runs the connectivity checks
+func (a *App) diagnosticsReport() DiagnosticsReport {
+	report := DiagnosticsReport{
+		GeneratedAt:   time.Now().Format(time.RFC3339),
+		AppVersion:    appVersion,
+		GoVersion:     goruntime.Version(),
+		OS:            goruntime.GOOS,
+		Arch:          goruntime.GOARCH,
+		ServerManaged: a.IsServerRunning(),
+		BaseURL:       a.GetBaseURL(),
+	}
+
+	if path, err := a.fabricPath(); err == nil {
+		report.FabricPath = path
+		report.FabricVersion = fabricBinaryVersion(path)
+	} else {
+		report.FabricVersion = err.Error()
+	}
+
+	client := a.fabricClient()
+	report.Checks = append(report.Checks,
+		a.runCheck("server health", func(ctx context.Context) (string, error) {
+			return "", client.Health(ctx)
+		}),
+		a.runCheck("patterns", func(ctx context.Context) (string, error) {
+			patterns, err := client.Patterns(ctx)
+			return fmt.Sprintf("%d patterns", len(patterns)), err
+		}),
+		a.runCheck("models", func(ctx context.Context) (string, error) {
+			models, err := client.Models(ctx)
+			if err != nil {
+				return "", err
+			}
+			count := 0
+			for _, names := range models.Vendors {
+				count += len(names)
+			}
+			return fmt.Sprintf("%d models from %d vendors", count, len(models.Vendors)), nil
+		}),
+	)
+	return report
+}
+
+// runCheck times a connectivity check, giving it ten seconds
+func (a *App) runCheck(name string, check func(context.Context) (string, error)) DiagnosticsCheck {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	detail, err := check(ctx)
+	result := DiagnosticsCheck{Name: name, OK: err == nil, Detail: detail, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Detail = a.redact.String(err.Error())
+	}
+	return result
+}
+
+// fabricBinaryVersion runs fabric --version
+func fabricBinaryVersion(path string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, path, "--version").CombinedOutput()
+	if err != nil {
+		return fmt.Sprintf("unknown (%v)", err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// writeZip writes the named files into a new zip archive
+func writeZip(path string, files map[string][]byte) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	zw := zip.NewWriter(out)
+	for name, data := range files {
+		w, err := zw.Create(filepath.ToSlash(name))
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Diff operations
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// maxDiffEdits bounds the work done by myersDiff. Inputs that differ by more
+// than this are reported as a full replacement.
+const maxDiffEdits = 4000
+
+// DiffSpan is a run of words within a changed line
+type DiffSpan struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// DiffLine is one line of a diff. Changed lines that pair up with a line on
+// the other side carry a word-level breakdown in Words.
+type DiffLine struct {
+	Op    string     `json:"op"`
+	Text  string     `json:"text"`
+	LineA int        `json:"lineA,omitempty"` // 1-based, 0 for inserted lines
+	LineB int        `json:"lineB,omitempty"` // 1-based, 0 for deleted lines
+	Words []DiffSpan `json:"words,omitempty"`
+}
+
+// OutputDiff is the structured diff between two history entries' outputs
+type OutputDiff struct {
+	A       HistoryEntry `json:"a"`
+	B       HistoryEntry `json:"b"`
+	Lines   []DiffLine   `json:"lines"`
+	Added   int          `json:"added"`
+	Removed int          `json:"removed"`
+	Same    bool         `json:"same"`
+}
+
+// DiffOutputs compares the outputs of two history entries line by line, with
+// a word-level diff for lines that changed
+func (a *App) DiffOutputs(idA, idB string) (*OutputDiff, error) {
+	entryA, ok := a.history.Find(idA)
+	if !ok {
+		return nil, fmt.Errorf("history entry %s not found", idA)
+	}
+	entryB, ok := a.history.Find(idB)
+	if !ok {
+		return nil, fmt.Errorf("history entry %s not found", idB)
+	}
+
+	diff := &OutputDiff{A: entryA, B: entryB, Lines: diffLines(entryA.Output, entryB.Output)}
+	for _, line := range diff.Lines {
+		switch line.Op {
+		case DiffInsert:
+			diff.Added++
+		case DiffDelete:
+			diff.Removed++
+		}
+	}
+	diff.Same = diff.Added == 0 && diff.Removed == 0
+	return diff, nil
+}
+
+// diffLines diffs two texts by line and adds word-level spans to changed lines
+func diffLines(a, b string) []DiffLine {
+	linesA := splitLines(a)
+	linesB := splitLines(b)
+
+	lines := []DiffLine{}
+	for _, e := range myersDiff(linesA, linesB) {
+		switch e.op {
+		case DiffEqual:
+			lines = append(lines, DiffLine{Op: DiffEqual, Text: linesA[e.a], LineA: e.a + 1, LineB: e.b + 1})
+		case DiffDelete:
+			lines = append(lines, DiffLine{Op: DiffDelete, Text: linesA[e.a], LineA: e.a + 1})
+		case DiffInsert:
+			lines = append(lines, DiffLine{Op: DiffInsert, Text: linesB[e.b], LineB: e.b + 1})
+		}
+	}
+
+	// Within each block of deletes followed by inserts, pair lines up in order
+	// and show what changed inside them
+	for i := 0; i < len(lines); {
+		if lines[i].Op != DiffDelete {
+			i++
+			continue
+		}
+		delStart := i
+		for i < len(lines) && lines[i].Op == DiffDelete {
+			i++
+		}
+		insStart := i
+		for i < len(lines) && lines[i].Op == DiffInsert {
+			i++
+		}
+		pairs := min(insStart-delStart, i-insStart)
+		for p := 0; p < pairs; p++ {
+			del, ins := &lines[delStart+p], &lines[insStart+p]
+			del.Words, ins.Words = diffWords(del.Text, ins.Text)
+		}
+	}
+	return lines
+}
+
+// diffWords returns the spans of a and b, marking the words removed from a
+// and the words added in b
+func diffWords(a, b string) (spansA, spansB []DiffSpan) {
+	wordsA := splitWords(a)
+	wordsB := splitWords(b)
+
+	for _, e := range myersDiff(wordsA, wordsB) {
+		switch e.op {
+		case DiffEqual:
+			spansA = appendSpan(spansA, DiffEqual, wordsA[e.a])
+			spansB = appendSpan(spansB, DiffEqual, wordsB[e.b])
+		case DiffDelete:
+			spansA = appendSpan(spansA, DiffDelete, wordsA[e.a])
+		case DiffInsert:
+			spansB = appendSpan(spansB, DiffInsert, wordsB[e.b])
+		}
+	}
+	return spansA, spansB
+}
+
+// appendSpan adds text to spans, extending the last span when the op matches
+func appendSpan(spans []DiffSpan, op, text string) []DiffSpan {
+	if n := len(spans); n > 0 && spans[n-1].Op == op {
+		spans[n-1].Text += text
+		return spans
+	}
+	return append(spans, DiffSpan{Op: op, Text: text})
+}
+
+// splitLines splits text into lines, ignoring a trailing newline
+func splitLines(text string) []string {
+	text = strings.TrimSuffix(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// wordPattern splits a line into words, whitespace and punctuation so that
+// joining the pieces gives back the original line
+var wordPattern = regexp.MustCompile(`\s+|\w+|[^\s\w]`)
+
+func splitWords(line string) []string {
+	return wordPattern.FindAllString(line, -1)
+}
+
+// diffEdit is one step of an edit script. a and b index into the two inputs;
+// only a is meaningful for deletes and only b for inserts.
+type diffEdit struct {
+	op   string
+	a, b int
+}
+
+// myersDiff returns the shortest edit script turning a into b, using Myers'
+// O((N+M)D) algorithm
+func myersDiff[T comparable](a, b []T) []diffEdit {
+	// Common prefix and suffix need no search
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]diffEdit, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		edits = append(edits, diffEdit{op: DiffEqual, a: i, b: i})
+	}
+	edits = append(edits, myersMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], prefix)...)
+	for i := suffix; i > 0; i-- {
+		edits = append(edits, diffEdit{op: DiffEqual, a: len(a) - i, b: len(b) - i})
+	}
+	return edits
+}
+
+// myersMiddle diffs the part of the inputs between the common prefix and
+// suffix. offset is added to every index in the result.
+func myersMiddle[T comparable](a, b []T, offset int) []diffEdit {
+	n, m := len(a), len(b)
+	total := n + m
+	if total == 0 {
+		return nil
+	}
+
+	// v[k+total] is the furthest x reached on diagonal k. trace[d] is a copy of
+	// v[-d..d] taken before step d, used to walk the path back afterwards.
+	v := make([]int, 2*total+2)
+	var trace [][]int
+	found := false
+	for d := 0; d <= total && d <= maxDiffEdits; d++ {
+		trace = append(trace, append([]int(nil), v[total-d:total+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[total+k-1] < v[total+k+1]) {
+				x = v[total+k+1]
+			} else {
+				x = v[total+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[total+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+		if found {
+			break
+		}
+	}
+
+	if !found {
+		edits := make([]diffEdit, 0, n+m)
+		for i := 0; i < n; i++ {
+			edits = append(edits, diffEdit{op: DiffDelete, a: offset + i})
+		}
+		for i := 0; i < m; i++ {
+			edits = append(edits, diffEdit{op: DiffInsert, b: offset + i})
+		}
+		return edits
+	}
+
+	// Walk back from (n, m), collecting edits in reverse
+	var edits []diffEdit
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d]
+		at := func(k int) int { return prev[k+d] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, diffEdit{op: DiffEqual, a: offset + x, b: offset + y})
+		}
+		if x == prevX {
+			y--
+			edits = append(edits, diffEdit{op: DiffInsert, b: offset + y})
+		} else {
+			x--
+			edits = append(edits, diffEdit{op: DiffDelete, a: offset + x})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		edits = append(edits, diffEdit{op: DiffEqual, a: offset + x, b: offset + y})
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPSettings configure SendOutputByEmail
+type SMTPSettings struct {
+	Host     string `json:"host"`     // mail is opened in the mail app instead when empty
+	Port     int    `json:"port"`     // defaults to 465 for tls and 587 otherwise
+	Security string `json:"security"` // starttls (default), tls or none
+	Username string `json:"username"`
+	Password string `json:"password"`
+	From     string `json:"from"` // sender address, the username when empty
+}
+
+const (
+	// smtpTimeout bounds connecting to and talking to the mail server
+	smtpTimeout = 30 * time.Second
+	// maxMailtoBody keeps mailto links within what mail apps accept
+	maxMailtoBody = 1800
+)
+
+// Email delivery methods SendOutputByEmail reports
+const (
+	EmailSentSMTP   = "smtp"
+	EmailSentMailto = "mailto"
+)
+
+// GetSMTPSettings returns the SMTP settings
+func (a *App) GetSMTPSettings() SMTPSettings {
+	if prefs, err := a.loadPreferences(); err == nil {
+		return prefs.SMTP
+	}
+	return SMTPSettings{}
+}
+
+// SaveSMTPSettings stores the SMTP settings
+func (a *App) SaveSMTPSettings(settings SMTPSettings) error {
+	switch settings.Security {
+	case "", "starttls", "tls", "none":
+	default:
+		return fmt.Errorf("unknown SMTP security %q", settings.Security)
+	}
+	if settings.From != "" {
+		if _, err := mail.ParseAddress(settings.From); err != nil {
+			return fmt.Errorf("invalid sender address: %v", err)
+		}
+	}
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	prefs.SMTP = settings
+	return a.SavePreferences(*prefs)
+}
+
+// SendOutputByEmail mails a history entry's output to the comma-separated
+// addresses in to, as HTML with a plain text alternative. Without an SMTP
+// server configured the mail app is opened with the message instead. The
+// subject defaults to the entry's title. Returns EmailSentSMTP or
+// EmailSentMailto.
+func (a *App) SendOutputByEmail(entryID, to, subject string) (string, error) {
+	entry, ok := a.history.Find(entryID)
+	if !ok {
+		return "", fmt.Errorf("history entry not found: %s", entryID)
+	}
+	recipients, err := mail.ParseAddressList(to)
+	if err != nil {
+		return "", fmt.Errorf("invalid recipient: %v", err)
+	}
+	if subject == "" {
+		subject = defaultNoteTitle(entry)
+	}
+
+	settings := a.GetSMTPSettings()
+	if settings.Host == "" {
+		if err := openPath(mailtoURL(recipients, subject, entry.Output)); err != nil {
+			return "", err
+		}
+		return EmailSentMailto, nil
+	}
+
+	from := settings.From
+	if from == "" {
+		from = settings.Username
+	}
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return "", fmt.Errorf("invalid sender address %q, set one in the SMTP settings", from)
+	}
+
+	message, err := buildEmail(sender, recipients, subject, entry)
+	if err != nil {
+		return "", err
+	}
+	if err := sendSMTP(settings, sender, recipients, message); err != nil {
+		return "", err
+	}
+	a.log.Info("emailed output", "entry", entryID, "recipients", len(recipients))
+	return EmailSentSMTP, nil
+}
+
+// buildEmail renders the message: multipart/alternative with the markdown
+// as text and the rendered HTML
+func buildEmail(sender *mail.Address, recipients []*mail.Address, subject string, entry HistoryEntry) ([]byte, error) {
+	html, err := renderHTMLDocument(entry.Output, HTMLOptions{
+		Title:   subject,
+		Pattern: entry.Pattern,
+		Model:   entry.Model,
+		Time:    entry.Time,
+		Theme:   "light",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	to := make([]string, len(recipients))
+	for i, r := range recipients {
+		to[i] = r.String()
+	}
+	domain := "localhost"
+	if at := strings.LastIndex(sender.Address, "@"); at >= 0 {
+		domain = sender.Address[at+1:]
+	}
+
+	var buf bytes.Buffer
+	body := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "From: %s\r\n", sender.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", newHistoryID(), domain)
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", body.Boundary())
+
+	for _, part := range []struct {
+		contentType string
+		data        []byte
+	}{
+		{"text/plain; charset=utf-8", []byte(entry.Output)},
+		{"text/html; charset=utf-8", html},
+	} {
+		w, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to build email: %v", err)
+		}
+		qp := quotedprintable.NewWriter(w)
+		qp.Write(part.data)
+		qp.Close()
+	}
+	if err := body.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build email: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// sendSMTP delivers message through the configured server
+func sendSMTP(settings SMTPSettings, sender *mail.Address, recipients []*mail.Address, message []byte) error {
+	port := settings.Port
+	if port == 0 {
+		port = 587
+		if settings.Security == "tls" {
+			port = 465
+		}
+	}
+	addr := net.JoinHostPort(settings.Host, strconv.Itoa(port))
+	tlsConfig := &tls.Config{ServerName: settings.Host}
+
+	conn, err := net.DialTimeout("tcp", addr, smtpTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %v", addr, err)
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+	if settings.Security == "tls" {
+		conn = tls.Client(conn, tlsConfig)
+	}
+	client, err := smtp.NewClient(conn, settings.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to connect to %s: %v", addr, err)
+	}
+	defer client.Close()
+
+	if settings.Security == "" || settings.Security == "starttls" {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("%s does not support STARTTLS, choose tls or none security", settings.Host)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("failed to start TLS: %v", err)
+		}
+	}
+	if settings.Username != "" {
+		// PlainAuth refuses to send the password unencrypted, except to localhost
+		if err := client.Auth(smtp.PlainAuth("", settings.Username, settings.Password, settings.Host)); err != nil {
+			return fmt.Errorf("SMTP login failed: %v", err)
+		}
+	}
+
+	if err := client.Mail(sender.Address); err != nil {
+		return fmt.Errorf("server rejected sender: %v", err)
+	}
+	for _, r := range recipients {
+		if err := client.Rcpt(r.Address); err != nil {
+			return fmt.Errorf("server rejected %s: %v", r.Address, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to send email: %v", err)
+	}
+	if _, err := w.Write(message); err != nil {
+		return fmt.Errorf("failed to send email: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send email: %v", err)
+	}
+	return client.Quit()
+}
+
+// mailtoURL builds a mailto link with the output as a plain text body,
+// shortened to what mail apps accept
+func mailtoURL(recipients []*mail.Address, subject, body string) string {
+	to := make([]string, len(recipients))
+	for i, r := range recipients {
+		to[i] = r.Address
+	}
+	body = markdownToText(body)
+	if runes := []rune(body); len(runes) > maxMailtoBody {
+		body = string(runes[:maxMailtoBody]) + "\n\n[output shortened]"
+	}
+	query := url.Values{"subject": {subject}, "body": {body}}.Encode()
+	// Mail apps read + literally, spaces must be %20
+	return "mailto:" + strings.Join(to, ",") + "?" + strings.ReplaceAll(query, "+", "%20")
+}
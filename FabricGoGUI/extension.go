@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// extensionOrigins are the origins browsers give extension pages and
+// service workers
+var extensionOrigins = []string{"chrome-extension://", "moz-extension://", "safari-web-extension://"}
+
+// ExtensionRequest is the body of POST /v1/extension/run, sent by the
+// companion browser extension with the page the user is on. The input is
+// the selection, else the page's text, else the page fetched from URL.
+type ExtensionRequest struct {
+	URL       string            `json:"url"`
+	Title     string            `json:"title"`
+	Selection string            `json:"selection"`
+	Text      string            `json:"text"` // the page's text as the extension read it, for pages behind a login
+	Preset    string            `json:"preset"`
+	Pattern   string            `json:"pattern"`
+	Vendor    string            `json:"vendor"`
+	Model     string            `json:"model"`
+	Variables map[string]string `json:"variables"`
+	Open      bool              `json:"open"`  // load it into the window instead of running it for the extension
+	Async     bool              `json:"async"` // return the job at once instead of waiting for the output
+}
+
+// isExtensionOrigin reports whether an Origin header is a browser extension's
+func isExtensionOrigin(origin string) bool {
+	for _, prefix := range extensionOrigins {
+		if strings.HasPrefix(origin, prefix) && len(origin) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowExtensionOrigin lets an extension read the response and send the
+// token. Web pages get no such headers, and have no token.
+func allowExtensionOrigin(w http.ResponseWriter, origin string) {
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+	w.Header().Set("Access-Control-Max-Age", "600")
+	w.Header().Add("Vary", "Origin")
+}
+
+// apiExtensionRun handles POST /v1/extension/run. The output is returned
+// for the extension to show, or with open set the page is loaded into the
+// window to run there.
+func (a *App) apiExtensionRun(w http.ResponseWriter, r *http.Request) {
+	var req ExtensionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apiError(w, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", err))
+		return
+	}
+	input, err := a.extensionInput(r.Context(), req)
+	if err != nil {
+		apiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	a.log.Info("browser extension request", "url", req.URL, "pattern", req.Pattern, "preset", req.Preset, "open", req.Open)
+
+	if req.Open {
+		link := &DeepLink{Pattern: req.Pattern, Vendor: req.Vendor, Model: req.Model, Input: input}
+		if err := a.completeDeepLink(link, req.Preset); err != nil {
+			apiError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if a.isFrontendReady() {
+			a.showWindow()
+		}
+		a.emitWhenReady("deeplink:open", link)
+		apiJSON(w, http.StatusAccepted, link)
+		return
+	}
+
+	prompt, err := a.apiPrompt(APIRunRequest{
+		Preset:    req.Preset,
+		Pattern:   req.Pattern,
+		Vendor:    req.Vendor,
+		Model:     req.Model,
+		Input:     input,
+		Variables: req.Variables,
+	})
+	if err != nil {
+		apiError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	a.serveRun(w, r, prompt, req.Preset, req.Async)
+}
+
+// extensionInput returns the input of an extension request, fetching the
+// page when the extension sent no text. The page's title and address head
+// the input so patterns can cite it.
+func (a *App) extensionInput(ctx context.Context, req ExtensionRequest) (string, error) {
+	text := strings.TrimSpace(req.Selection)
+	if text == "" {
+		text = strings.TrimSpace(req.Text)
+	}
+	if text == "" {
+		if req.URL == "" {
+			return "", fmt.Errorf("a selection, text or url is required")
+		}
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		var err error
+		if text, err = a.fetchPageText(ctx, req.URL); err != nil {
+			return "", err
+		}
+	}
+
+	var header []string
+	if req.Title != "" {
+		header = append(header, "# "+req.Title)
+	}
+	if req.URL != "" {
+		header = append(header, "Source: "+req.URL)
+	}
+	if len(header) == 0 {
+		return text, nil
+	}
+	return strings.Join(header, "\n") + "\n\n" + text, nil
+}
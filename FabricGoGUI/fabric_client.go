@@ -0,0 +1,407 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// FabricClient is everything the app needs from a Fabric server
+type FabricClient interface {
+	// Health returns nil when the server answers
+	Health(ctx context.Context) error
+	// Patterns lists the available pattern names
+	Patterns(ctx context.Context) ([]string, error)
+	// Models lists the available models grouped by vendor
+	Models(ctx context.Context) (*ModelsResponse, error)
+	// Strategies lists the prompting strategies
+	Strategies(ctx context.Context) ([]Strategy, error)
+	// Chat streams the response to a prompt and returns the full output
+	Chat(ctx context.Context, prompt PromptRequest, onChunk func(string), onUsage func(streamUsage)) (string, error)
+	// Version returns the server's Fabric version, if it reports one
+	Version(ctx context.Context) (string, error)
+	// HasEndpoint reports whether the server answers GET requests on path
+	HasEndpoint(ctx context.Context, path string) (bool, error)
+	// StorageNames lists the items of a storage kind, such as contexts
+	StorageNames(ctx context.Context, kind string) ([]string, error)
+	// StorageGet returns an item as the JSON the server sends
+	StorageGet(ctx context.Context, kind, name string) ([]byte, error)
+	// StorageSave creates or replaces an item
+	StorageSave(ctx context.Context, kind, name string, content []byte) error
+	// StorageDelete removes an item
+	StorageDelete(ctx context.Context, kind, name string) error
+	// YouTubeTranscript fetches a YouTube video's transcript and title
+	YouTubeTranscript(ctx context.Context, videoURL string) (*YouTubeTranscript, error)
+}
+
+// Storage kinds the server manages through its /<kind>/... endpoints
+const (
+	storageContexts = "contexts"
+	storageSessions = "sessions"
+)
+
+// httpFabricClient talks to the Fabric REST API
+type httpFabricClient struct {
+	baseURL    string
+	client     *http.Client
+	streamIdle time.Duration
+	log        *slog.Logger
+	debug      func(string)
+}
+
+// fabricClient returns the client for the current server. A client set on
+// the App, such as a mock, takes precedence.
+func (a *App) fabricClient() FabricClient {
+	if a.fabric != nil {
+		return a.fabric
+	}
+	a.connMutex.RLock()
+	defer a.connMutex.RUnlock()
+	return &httpFabricClient{
+		baseURL:    a.baseURL,
+		client:     a.client,
+		streamIdle: a.streamIdleTimeout,
+		log:        a.log,
+		debug: func(msg string) {
+			a.log.Debug(msg)
+			a.emit("debug:log", a.redact.String(msg))
+		},
+	}
+}
+
+// Health checks that the pattern list can be fetched
+func (c *httpFabricClient) Health(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/patterns/names", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("server returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Patterns fetches the list of available patterns
+func (c *httpFabricClient) Patterns(ctx context.Context) ([]string, error) {
+	var patterns []string
+	if err := c.getJSON(ctx, "/patterns/names", &patterns); err != nil {
+		return nil, fmt.Errorf("failed to fetch patterns: %v", err)
+	}
+	return patterns, nil
+}
+
+// Models fetches the list of available models grouped by vendor
+func (c *httpFabricClient) Models(ctx context.Context) (*ModelsResponse, error) {
+	var models ModelsResponse
+	if err := c.getJSON(ctx, "/models/names", &models); err != nil {
+		return nil, fmt.Errorf("failed to fetch models: %v", err)
+	}
+	return &models, nil
+}
+
+// Strategies fetches the list of prompting strategies
+func (c *httpFabricClient) Strategies(ctx context.Context) ([]Strategy, error) {
+	var strategies []Strategy
+	if err := c.getJSON(ctx, "/strategies", &strategies); err != nil {
+		return nil, fmt.Errorf("failed to fetch strategies: %v", err)
+	}
+	return strategies, nil
+}
+
+// Version asks the server for its version. Older servers have no version
+// endpoint, in which case an error is returned.
+func (c *httpFabricClient) Version(ctx context.Context) (string, error) {
+	var body struct {
+		Version string `json:"version"`
+	}
+	if err := c.getJSON(ctx, "/version", &body); err != nil {
+		return "", fmt.Errorf("failed to fetch version: %v", err)
+	}
+	if body.Version == "" {
+		return "", fmt.Errorf("server did not report a version")
+	}
+	return body.Version, nil
+}
+
+// HasEndpoint probes path, treating anything but a 404 as present
+func (c *httpFabricClient) HasEndpoint(ctx context.Context, path string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode != http.StatusNotFound, nil
+}
+
+// StorageNames fetches /<kind>/names
+func (c *httpFabricClient) StorageNames(ctx context.Context, kind string) ([]string, error) {
+	var names []string
+	if err := c.getJSON(ctx, "/"+kind+"/names", &names); err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %v", kind, err)
+	}
+	return names, nil
+}
+
+// StorageGet fetches /<kind>/<name>
+func (c *httpFabricClient) StorageGet(ctx context.Context, kind, name string) ([]byte, error) {
+	var item json.RawMessage
+	if err := c.getJSON(ctx, "/"+kind+"/"+url.PathEscape(name), &item); err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %v", name, err)
+	}
+	return item, nil
+}
+
+// StorageSave posts content to /<kind>/<name>
+func (c *httpFabricClient) StorageSave(ctx context.Context, kind, name string, content []byte) error {
+	return c.send(ctx, "POST", "/"+kind+"/"+url.PathEscape(name), content)
+}
+
+// StorageDelete deletes /<kind>/<name>
+func (c *httpFabricClient) StorageDelete(ctx context.Context, kind, name string) error {
+	return c.send(ctx, "DELETE", "/"+kind+"/"+url.PathEscape(name), nil)
+}
+
+// send makes a request that only reports success or failure
+func (c *httpFabricClient) send(ctx context.Context, method, path string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.log.Warn("request failed", "method", method, "path", path, "error", err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 500))
+		c.log.Warn("request failed", "method", method, "path", path, "status", resp.StatusCode)
+		return fmt.Errorf("server returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// getJSON fetches path and decodes the JSON response into v
+func (c *httpFabricClient) getJSON(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.log.Warn("request failed", "path", path, "error", err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		c.log.Warn("endpoint missing", "path", path)
+		return fmt.Errorf("the server has no %s endpoint, it may be older than Fabric %s", path, minFabricVersion)
+	}
+	if resp.StatusCode != 200 {
+		c.log.Warn("request failed", "path", path, "status", resp.StatusCode)
+		return fmt.Errorf("server returned status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid response: %v", err)
+	}
+	return nil
+}
+
+// YouTubeTranscript posts the video to /youtube/transcript
+func (c *httpFabricClient) YouTubeTranscript(ctx context.Context, videoURL string) (*YouTubeTranscript, error) {
+	body, err := json.Marshal(map[string]any{"url": videoURL})
+	if err != nil {
+		return nil, err
+	}
+	var transcript YouTubeTranscript
+	if err := c.postJSON(ctx, "/youtube/transcript", body, &transcript); err != nil {
+		return nil, fmt.Errorf("failed to fetch transcript: %v", err)
+	}
+	return &transcript, nil
+}
+
+// postJSON posts body to path and decodes the JSON response into v
+func (c *httpFabricClient) postJSON(ctx context.Context, path string, body []byte, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.log.Warn("request failed", "path", path, "error", err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		c.log.Warn("endpoint missing", "path", path)
+		return fmt.Errorf("the server has no %s endpoint, it may be older than Fabric %s", path, minFabricVersion)
+	}
+	if resp.StatusCode != 200 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 500))
+		c.log.Warn("request failed", "path", path, "status", resp.StatusCode)
+		return fmt.Errorf("server returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid response: %v", err)
+	}
+	return nil
+}
+
+// Chat posts a prompt to the server, calling onChunk for every content chunk
+// received and onUsage when the server reports token counts, and returns the
+// full output once the stream ends.
+// Cancelling ctx aborts the request. If the stream breaks off, the output
+// received so far is returned together with an interrupted chatError.
+func (c *httpFabricClient) Chat(ctx context.Context, prompt PromptRequest, onChunk func(string), onUsage func(streamUsage)) (string, error) {
+	// Build request
+	reqBody := ChatRequest{
+		Prompts:     []PromptRequest{prompt},
+		ChatOptions: prompt.Options,
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	reqCtx, cancelReq := context.WithCancel(ctx)
+	defer cancelReq()
+
+	req, err := http.NewRequestWithContext(reqCtx, "POST", c.baseURL+"/chat", strings.NewReader(string(jsonBody)))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.log.Warn("chat request failed", "error", err)
+		return "", newNetworkChatError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		c.log.Warn("chat request failed", "status", resp.StatusCode, "body", truncateRunes(string(body), 500))
+		ce := newHTTPChatError(resp.StatusCode, string(body))
+		ce.RetryAfter = parseRetryAfter(resp.Header)
+		if resp.StatusCode == http.StatusNotFound {
+			ce.Message = fmt.Sprintf("the server has no /chat endpoint, it may be older than Fabric %s", minFabricVersion)
+		}
+		return "", ce
+	}
+
+	// A server that stops sending data without closing the connection would
+	// otherwise hang the stream forever
+	idle := c.streamIdle
+	var stalled atomic.Bool
+	watchdog := time.AfterFunc(idle, func() {
+		stalled.Store(true)
+		cancelReq()
+	})
+	defer watchdog.Stop()
+
+	// Read streaming response (SSE format: "data: {...json...}")
+	// Use Scanner for robust line reading
+	scanner := bufio.NewScanner(resp.Body)
+	// Increase buffer size just in case (max 1MB lines)
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 1024*1024)
+
+	var fullOutput strings.Builder
+	var parseErr error
+
+	for scanner.Scan() {
+		watchdog.Reset(idle)
+		line := scanner.Text()
+
+		if len(line) > 0 {
+			line = strings.TrimSpace(line)
+			if line != "" {
+				// Strip "data: " prefix
+				if strings.HasPrefix(line, "data: ") {
+					line = strings.TrimPrefix(line, "data: ")
+				} else if strings.HasPrefix(line, "data:") {
+					line = strings.TrimPrefix(line, "data:")
+				}
+
+				if line != "" {
+					var event StreamEvent
+					if err := json.Unmarshal([]byte(line), &event); err != nil {
+						c.log.Warn("failed to parse stream event", "error", err, "line", truncateRunes(line, 200))
+						parseErr = err
+					} else {
+						switch event.Type {
+						case "content":
+							onChunk(event.Content)
+							fullOutput.WriteString(event.Content)
+						case "complete":
+							// Some servers/models might send the final chunk in the complete event
+							if event.Content != "" {
+								c.debug(fmt.Sprintf("Complete event had content: %q", event.Content))
+								onChunk(event.Content)
+								fullOutput.WriteString(event.Content)
+							}
+							c.debug("Backend received complete event")
+							return fullOutput.String(), nil
+						case "error":
+							// The vendor failed mid-stream, the server forwards its message
+							c.log.Warn("vendor error during stream", "error", event.Content)
+							return "", classifyVendorError(event.Content)
+						case "usage":
+							if event.Usage != nil {
+								onUsage(*event.Usage)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// Partial output is returned alongside stream errors so it can be resumed
+	if err := scanner.Err(); err != nil {
+		c.debug(fmt.Sprintf("Stream scanner error: %v", err))
+		c.log.Warn("chat stream broke off", "error", err, "received", fullOutput.Len())
+		if stalled.Load() {
+			return fullOutput.String(), &chatError{Code: ChatErrInterrupted, Message: fmt.Sprintf("no data received for %s", idle), Retryable: true}
+		}
+		if ctx.Err() != nil {
+			return fullOutput.String(), newNetworkChatError(ctx.Err())
+		}
+		return fullOutput.String(), &chatError{Code: ChatErrInterrupted, Message: fmt.Sprintf("error reading stream: %v", err), Retryable: true}
+	}
+
+	// Nothing usable arrived, most likely the server is not speaking SSE JSON
+	if fullOutput.Len() == 0 && parseErr != nil {
+		return "", &chatError{Code: ChatErrParse, Message: fmt.Sprintf("failed to parse stream: %v", parseErr)}
+	}
+
+	// The connection closed without a complete event, the generation was cut short
+	c.debug("Stream ended without complete event")
+	return fullOutput.String(), &chatError{Code: ChatErrInterrupted, Message: "stream ended before the response was complete", Retryable: true}
+}
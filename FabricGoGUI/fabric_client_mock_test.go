@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// mockFabricClient is a scripted FabricClient for exercising the app without
+// a server. Chat streams Chunks, reports Usage, then returns ChatErr.
+type mockFabricClient struct {
+	HealthErr   error
+	PatternSet  []string
+	ModelSet    *ModelsResponse
+	StrategySet []Strategy
+	Chunks      []string
+	Usage       *streamUsage
+	ChatErr     error
+	// FailFirst holds errors returned, one per call, before Chat plays back
+	// the scripted response
+	FailFirst []error
+	// ServerVersion is returned by Version, which fails when it is empty
+	ServerVersion string
+	// Missing lists endpoints HasEndpoint reports as absent
+	Missing []string
+	// Storage holds stored items by kind and name
+	Storage map[string]map[string][]byte
+	// Transcripts holds YouTube transcripts by video URL
+	Transcripts map[string]*YouTubeTranscript
+
+	mu      sync.Mutex
+	prompts []PromptRequest
+}
+
+// Health returns HealthErr
+func (m *mockFabricClient) Health(ctx context.Context) error {
+	return m.HealthErr
+}
+
+// Patterns returns PatternSet
+func (m *mockFabricClient) Patterns(ctx context.Context) ([]string, error) {
+	return m.PatternSet, nil
+}
+
+// Models returns ModelSet
+func (m *mockFabricClient) Models(ctx context.Context) (*ModelsResponse, error) {
+	if m.ModelSet == nil {
+		return &ModelsResponse{Vendors: map[string][]string{}}, nil
+	}
+	return m.ModelSet, nil
+}
+
+// Strategies returns StrategySet
+func (m *mockFabricClient) Strategies(ctx context.Context) ([]Strategy, error) {
+	return m.StrategySet, nil
+}
+
+// Chat records the prompt and plays back the scripted response. Cancelling
+// ctx stops it between chunks like a real stream.
+func (m *mockFabricClient) Chat(ctx context.Context, prompt PromptRequest, onChunk func(string), onUsage func(streamUsage)) (string, error) {
+	m.mu.Lock()
+	m.prompts = append(m.prompts, prompt)
+	var fail error
+	if len(m.FailFirst) > 0 {
+		fail, m.FailFirst = m.FailFirst[0], m.FailFirst[1:]
+	}
+	m.mu.Unlock()
+	if fail != nil {
+		return "", fail
+	}
+
+	var output strings.Builder
+	for _, chunk := range m.Chunks {
+		if ctx.Err() != nil {
+			return output.String(), newNetworkChatError(ctx.Err())
+		}
+		onChunk(chunk)
+		output.WriteString(chunk)
+	}
+	if m.Usage != nil {
+		onUsage(*m.Usage)
+	}
+	return output.String(), m.ChatErr
+}
+
+// Version returns ServerVersion
+func (m *mockFabricClient) Version(ctx context.Context) (string, error) {
+	if m.ServerVersion == "" {
+		return "", fmt.Errorf("server did not report a version")
+	}
+	return m.ServerVersion, nil
+}
+
+// HasEndpoint reports every path not listed in Missing as present
+func (m *mockFabricClient) HasEndpoint(ctx context.Context, path string) (bool, error) {
+	return !slices.Contains(m.Missing, path), nil
+}
+
+// StorageNames lists the names in Storage[kind]
+func (m *mockFabricClient) StorageNames(ctx context.Context, kind string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Sorted(maps.Keys(m.Storage[kind])), nil
+}
+
+// StorageGet returns Storage[kind][name]
+func (m *mockFabricClient) StorageGet(ctx context.Context, kind, name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item, ok := m.Storage[kind][name]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", name)
+	}
+	return item, nil
+}
+
+// StorageSave stores content in Storage[kind][name]
+func (m *mockFabricClient) StorageSave(ctx context.Context, kind, name string, content []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Storage == nil {
+		m.Storage = map[string]map[string][]byte{}
+	}
+	if m.Storage[kind] == nil {
+		m.Storage[kind] = map[string][]byte{}
+	}
+	m.Storage[kind][name] = content
+	return nil
+}
+
+// StorageDelete removes Storage[kind][name]
+func (m *mockFabricClient) StorageDelete(ctx context.Context, kind, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.Storage[kind], name)
+	return nil
+}
+
+// YouTubeTranscript returns Transcripts[videoURL]
+func (m *mockFabricClient) YouTubeTranscript(ctx context.Context, videoURL string) (*YouTubeTranscript, error) {
+	transcript, ok := m.Transcripts[videoURL]
+	if !ok {
+		return nil, fmt.Errorf("failed to fetch transcript: no transcript for %s", videoURL)
+	}
+	return transcript, nil
+}
+
+// Prompts returns every prompt sent to Chat so far
+func (m *mockFabricClient) Prompts() []PromptRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	prompts := make([]PromptRequest, len(m.prompts))
+	copy(prompts, m.prompts)
+	return prompts
+}
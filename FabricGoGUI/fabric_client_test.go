@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testFabricClient returns an httpFabricClient for a test server answering
+// /chat with handler
+func testFabricClient(t *testing.T, handler http.HandlerFunc) *httpFabricClient {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return &httpFabricClient{
+		baseURL:    srv.URL,
+		client:     srv.Client(),
+		streamIdle: 5 * time.Second,
+		log:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+		debug:      func(string) {},
+	}
+}
+
+// sseHandler streams lines as server-sent events, flushing after each one
+func sseHandler(lines ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, line := range lines {
+			fmt.Fprintf(w, "%s\n\n", line)
+			w.(http.Flusher).Flush()
+		}
+	}
+}
+
+func chatWith(c *httpFabricClient) (string, []string, []streamUsage, error) {
+	var chunks []string
+	var usage []streamUsage
+	output, err := c.Chat(context.Background(), PromptRequest{PatternName: "summarize"},
+		func(chunk string) { chunks = append(chunks, chunk) },
+		func(u streamUsage) { usage = append(usage, u) })
+	return output, chunks, usage, err
+}
+
+func TestChatParsesStream(t *testing.T) {
+	c := testFabricClient(t, sseHandler(
+		`data: {"type":"content","content":"Hello"}`,
+		`data:{"type":"content","content":", "}`,
+		`data: {"type":"usage","usage":{"inputTokens":3,"outputTokens":2}}`,
+		`data: {"type":"complete","content":"world"}`,
+		`data: {"type":"content","content":"ignored after complete"}`,
+	))
+
+	output, chunks, usage, err := chatWith(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != "Hello, world" {
+		t.Errorf("output = %q", output)
+	}
+	if strings.Join(chunks, "|") != "Hello|, |world" {
+		t.Errorf("chunks = %q", chunks)
+	}
+	if len(usage) != 1 {
+		t.Errorf("usage events = %d, want 1", len(usage))
+	}
+}
+
+func TestChatSkipsBadLinesBetweenEvents(t *testing.T) {
+	c := testFabricClient(t, sseHandler(
+		`data: {"type":"content","content":"kept"}`,
+		`data: not json`,
+		`data: {"type":"complete"}`,
+	))
+
+	output, _, _, err := chatWith(c)
+	if err != nil || output != "kept" {
+		t.Errorf("got %q, %v", output, err)
+	}
+}
+
+func TestChatStreamErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		lines     []string
+		code      string
+		output    string
+		retryable bool
+	}{
+		{"vendor error", []string{`data: {"type":"error","content":"401 invalid api key"}`}, ChatErrAuth, "", false},
+		{"vendor rate limit", []string{`data: {"type":"error","content":"rate limit exceeded"}`}, ChatErrRateLimit, "", true},
+		{"not json", []string{`<html>`, `data: oops`}, ChatErrParse, "", false},
+		{"no complete event", []string{`data: {"type":"content","content":"partial"}`}, ChatErrInterrupted, "partial", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, _, _, err := chatWith(testFabricClient(t, sseHandler(tt.lines...)))
+			var ce *chatError
+			if !errors.As(err, &ce) {
+				t.Fatalf("err = %v, want a chatError", err)
+			}
+			if ce.Code != tt.code || ce.Retryable != tt.retryable {
+				t.Errorf("got code %q retryable %v, want %q %v", ce.Code, ce.Retryable, tt.code, tt.retryable)
+			}
+			if output != tt.output {
+				t.Errorf("output = %q, want %q", output, tt.output)
+			}
+		})
+	}
+}
+
+func TestChatHTTPErrors(t *testing.T) {
+	tests := []struct {
+		status     int
+		code       string
+		retryable  bool
+		retryAfter time.Duration
+	}{
+		{http.StatusUnauthorized, ChatErrAuth, false, 0},
+		{http.StatusTooManyRequests, ChatErrRateLimit, true, 7 * time.Second},
+		{http.StatusBadGateway, ChatErrServer, true, 0},
+		{http.StatusInternalServerError, ChatErrServer, false, 0},
+		{http.StatusNotFound, ChatErrServer, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			c := testFabricClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if tt.retryAfter > 0 {
+					w.Header().Set("Retry-After", fmt.Sprint(int(tt.retryAfter.Seconds())))
+				}
+				http.Error(w, "failed", tt.status)
+			})
+
+			_, _, _, err := chatWith(c)
+			var ce *chatError
+			if !errors.As(err, &ce) {
+				t.Fatalf("err = %v, want a chatError", err)
+			}
+			if ce.Status != tt.status || ce.Code != tt.code || ce.Retryable != tt.retryable || ce.RetryAfter != tt.retryAfter {
+				t.Errorf("got %+v", ce)
+			}
+			if tt.status == http.StatusNotFound && !strings.Contains(ce.Message, "no /chat endpoint") {
+				t.Errorf("404 message = %q", ce.Message)
+			}
+		})
+	}
+}
+
+func TestChatStalledStream(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	c := testFabricClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data: {\"type\":\"content\",\"content\":\"partial\"}\n\n")
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	c.streamIdle = 100 * time.Millisecond
+
+	output, _, _, err := chatWith(c)
+	ce := toChatError(err)
+	if ce.Code != ChatErrInterrupted || !ce.Retryable || output != "partial" {
+		t.Errorf("got %q, %+v", output, ce)
+	}
+}
+
+func TestChatCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := testFabricClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data: {\"type\":\"content\",\"content\":\"partial\"}\n\n")
+		w.(http.Flusher).Flush()
+		cancel()
+		<-r.Context().Done()
+	})
+
+	_, err := c.Chat(ctx, PromptRequest{}, func(string) {}, func(streamUsage) {})
+	if ce := toChatError(err); ce.Code != ChatErrCancelled {
+		t.Errorf("got %+v, want cancelled", ce)
+	}
+}
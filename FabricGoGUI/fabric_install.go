@@ -0,0 +1,375 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	goruntime "runtime"
+	"slices"
+	"strings"
+)
+
+// fabricReleaseURL is the GitHub API endpoint for the latest Fabric release
+const fabricReleaseURL = "https://api.github.com/repos/danielmiessler/fabric/releases/latest"
+
+// githubRelease is the part of the GitHub release API response we need
+type githubRelease struct {
+	TagName string        `json:"tag_name"`
+	Body    string        `json:"body"`
+	HTMLURL string        `json:"html_url"`
+	Assets  []githubAsset `json:"assets"`
+}
+
+type githubAsset struct {
+	Name        string `json:"name"`
+	DownloadURL string `json:"browser_download_url"`
+	Size        int64  `json:"size"`
+	Digest      string `json:"digest"` // "sha256:<hex>" on newer releases
+}
+
+// FabricInstall describes the result of InstallFabric or UpdateFabric
+type FabricInstall struct {
+	Path     string `json:"path"`
+	Version  string `json:"version"`
+	Previous string `json:"previous,omitempty"`
+	Updated  bool   `json:"updated"`
+}
+
+// FabricDownload is emitted as "fabric:download", or "update:download" for
+// GUI updates, while a release downloads
+type FabricDownload struct {
+	Asset      string  `json:"asset"`
+	Downloaded int64   `json:"downloaded"`
+	Total      int64   `json:"total"`
+	Percent    float64 `json:"percent"`
+}
+
+// managedFabricPath is where the app installs its own copy of fabric
+func (a *App) managedFabricPath() string {
+	dir := a.getConfigDir()
+	if dir == "" {
+		return ""
+	}
+	name := "fabric"
+	if goruntime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return filepath.Join(dir, "bin", name)
+}
+
+// InstallFabric downloads the latest Fabric release for this platform into
+// the app's own bin directory, which StartServer then prefers over PATH
+func (a *App) InstallFabric() (*FabricInstall, error) {
+	return a.installFabric(false)
+}
+
+// UpdateFabric replaces the managed fabric binary with the latest release if
+// it is newer. A server started by the app is restarted afterwards.
+func (a *App) UpdateFabric() (*FabricInstall, error) {
+	return a.installFabric(true)
+}
+
+func (a *App) installFabric(onlyIfNewer bool) (*FabricInstall, error) {
+	target := a.managedFabricPath()
+	if target == "" {
+		return nil, fmt.Errorf("could not determine config directory")
+	}
+
+	job, ctx := a.startJob("install", "Installing Fabric")
+	result, err := a.downloadFabric(ctx, job, target, onlyIfNewer)
+	a.finishJob(job, err)
+	if err != nil {
+		a.log.Error("failed to install fabric", "error", err)
+		return nil, err
+	}
+
+	a.log.Info("installed fabric", "version", result.Version, "path", result.Path, "updated", result.Updated)
+	a.emit("fabric:installed", result)
+	return result, nil
+}
+
+func (a *App) downloadFabric(ctx context.Context, j *job, target string, onlyIfNewer bool) (*FabricInstall, error) {
+	a.updateJob(j, -1, "Looking up the latest release")
+	release, err := a.latestRelease(ctx, fabricReleaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FabricInstall{Path: target, Version: release.TagName}
+	if current, err := a.fabricPath(); err == nil {
+		result.Previous = fabricBinaryVersion(current)
+		if onlyIfNewer && compareVersions(result.Previous, release.TagName) >= 0 {
+			result.Path = current
+			return result, nil
+		}
+	}
+
+	asset, err := release.assetFor(goruntime.GOOS, goruntime.GOARCH)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := a.assetChecksum(ctx, release, asset)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := a.downloadAsset(ctx, j, asset, "fabric:download")
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyChecksum(asset, data, sum); err != nil {
+		return nil, err
+	}
+
+	binary, err := extractExecutable(asset.Name, data, "fabric", "fabric.exe")
+	if err != nil {
+		return nil, err
+	}
+
+	// A running server holds the binary open on Windows, so stop it first
+	restart := a.IsServerRunning()
+	if restart {
+		a.StopServer()
+	}
+
+	a.updateJob(j, 100, "Installing")
+	err = writeFabricBinary(target, binary)
+
+	if restart {
+		if startErr := a.StartServer(); startErr != nil && err == nil {
+			err = fmt.Errorf("fabric was installed but the server did not restart: %v", startErr)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Updated = true
+	return result, nil
+}
+
+// writeFabricBinary installs an executable at target
+func writeFabricBinary(target string, binary []byte) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create install directory: %v", err)
+	}
+	if err := writeFileAtomic(target, binary, 0755); err != nil {
+		return fmt.Errorf("failed to install fabric: %v", err)
+	}
+	return nil
+}
+
+// latestRelease asks GitHub for the newest release at a releases/latest URL
+func (a *App) latestRelease(ctx context.Context, url string) (*githubRelease, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := a.externalClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach GitHub: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("GitHub returned status %d", resp.StatusCode)
+	}
+
+	var release githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to parse release: %v", err)
+	}
+	return &release, nil
+}
+
+// assetFor picks the release asset for a platform. Releases have shipped both
+// bare binaries (fabric-linux-amd64) and archives (fabric_Linux_x86_64.tar.gz).
+func (r *githubRelease) assetFor(goos, goarch string) (githubAsset, error) {
+	archNames := map[string]string{"amd64": "x86_64", "386": "i386", "arm64": "arm64"}
+	osName := strings.ToUpper(goos[:1]) + goos[1:]
+
+	var candidates []string
+	if goos == "windows" {
+		candidates = append(candidates, fmt.Sprintf("fabric-windows-%s.exe", goarch))
+	} else {
+		candidates = append(candidates, fmt.Sprintf("fabric-%s-%s", goos, goarch))
+	}
+	if arch, ok := archNames[goarch]; ok {
+		candidates = append(candidates,
+			fmt.Sprintf("fabric_%s_%s.tar.gz", osName, arch),
+			fmt.Sprintf("fabric_%s_%s.zip", osName, arch),
+		)
+	}
+
+	for _, name := range candidates {
+		for _, asset := range r.Assets {
+			if strings.EqualFold(asset.Name, name) {
+				return asset, nil
+			}
+		}
+	}
+	return githubAsset{}, fmt.Errorf("release %s has no build for %s/%s", r.TagName, goos, goarch)
+}
+
+// assetChecksum returns the expected SHA-256 of an asset, from GitHub's
+// digest or the release's checksums file
+func (a *App) assetChecksum(ctx context.Context, release *githubRelease, asset githubAsset) (string, error) {
+	if sum, ok := strings.CutPrefix(asset.Digest, "sha256:"); ok {
+		return strings.ToLower(sum), nil
+	}
+
+	for _, candidate := range release.Assets {
+		name := strings.ToLower(candidate.Name)
+		if name != "checksums.txt" && !strings.HasSuffix(name, "_checksums.txt") {
+			continue
+		}
+
+		data, err := a.fetch(ctx, candidate.DownloadURL)
+		if err != nil {
+			return "", fmt.Errorf("failed to download checksums: %v", err)
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == asset.Name {
+				return strings.ToLower(fields[0]), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("release %s publishes no checksum for %s", release.TagName, asset.Name)
+}
+
+// verifyChecksum checks a downloaded asset against its expected SHA-256
+func verifyChecksum(asset githubAsset, data []byte, sum string) error {
+	got := sha256.Sum256(data)
+	if hex.EncodeToString(got[:]) != sum {
+		return fmt.Errorf("checksum mismatch for %s, the download may be corrupt", asset.Name)
+	}
+	return nil
+}
+
+// downloadAsset fetches an asset, reporting progress as event
+func (a *App) downloadAsset(ctx context.Context, j *job, asset githubAsset, event string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", asset.DownloadURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.externalClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %v", asset.Name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("download of %s returned status %d", asset.Name, resp.StatusCode)
+	}
+
+	total := resp.ContentLength
+	if total <= 0 {
+		total = asset.Size
+	}
+
+	var buf bytes.Buffer
+	chunk := make([]byte, 64*1024)
+	lastPercent := -1.0
+	for {
+		n, err := resp.Body.Read(chunk)
+		buf.Write(chunk[:n])
+
+		progress := FabricDownload{Asset: asset.Name, Downloaded: int64(buf.Len()), Total: total, Percent: -1}
+		if total > 0 {
+			progress.Percent = float64(buf.Len()) / float64(total) * 100
+		}
+		// Only report whole-percent changes to keep the event rate sane
+		if progress.Percent < 0 || progress.Percent-lastPercent >= 1 || err == io.EOF {
+			lastPercent = progress.Percent
+			a.updateJob(j, progress.Percent, fmt.Sprintf("Downloading %s", asset.Name))
+			a.emit(event, progress)
+		}
+
+		if err == io.EOF {
+			return buf.Bytes(), nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to download %s: %v", asset.Name, err)
+		}
+	}
+}
+
+// fetch downloads a small file
+func (a *App) fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.externalClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// extractExecutable returns the executable called one of names from a
+// downloaded asset, unpacking it when the asset is an archive
+func extractExecutable(name string, data []byte, names ...string) ([]byte, error) {
+	isWanted := func(entry string) bool {
+		return slices.Contains(names, path.Base(entry))
+	}
+
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"):
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open archive: %v", err)
+		}
+		tr := tar.NewReader(gz)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read archive: %v", err)
+			}
+			if header.Typeflag == tar.TypeReg && isWanted(header.Name) {
+				return io.ReadAll(tr)
+			}
+		}
+	case strings.HasSuffix(name, ".zip"):
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open archive: %v", err)
+		}
+		for _, f := range zr.File {
+			if isWanted(f.Name) {
+				rc, err := f.Open()
+				if err != nil {
+					return nil, err
+				}
+				defer rc.Close()
+				return io.ReadAll(rc)
+			}
+		}
+	default:
+		return data, nil
+	}
+	return nil, fmt.Errorf("no %s executable found in %s", names[0], name)
+}
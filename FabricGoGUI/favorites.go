@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+// FavoriteModel is a model pinned above the full model list
+type FavoriteModel struct {
+	Vendor string `json:"vendor"`
+	Model  string `json:"model"`
+}
+
+// GetFavoriteModels returns the pinned models in the order they were added
+func (a *App) GetFavoriteModels() []FavoriteModel {
+	prefs, err := a.loadPreferences()
+	if err != nil || prefs.FavoriteModels == nil {
+		return []FavoriteModel{}
+	}
+	return prefs.FavoriteModels
+}
+
+// SetFavoriteModel pins or unpins a model
+func (a *App) SetFavoriteModel(vendor, model string, favorite bool) error {
+	if vendor == "" || model == "" {
+		return fmt.Errorf("a vendor and model are required")
+	}
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+
+	fav := FavoriteModel{Vendor: vendor, Model: model}
+	prefs.FavoriteModels = slices.DeleteFunc(prefs.FavoriteModels, func(f FavoriteModel) bool { return f == fav })
+	if favorite {
+		prefs.FavoriteModels = append(prefs.FavoriteModels, fav)
+	}
+	if err := a.SavePreferences(*prefs); err != nil {
+		return err
+	}
+	a.emit("favorites:updated", prefs.FavoriteModels)
+	return nil
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// maxFeedSize bounds how much of a feed is downloaded
+const maxFeedSize = 16 << 20
+
+// Feed is an RSS or Atom feed
+type Feed struct {
+	Title string     `json:"title"`
+	Link  string     `json:"link"`
+	Items []FeedItem `json:"items"`
+}
+
+// FeedItem is an entry of a feed. Content is its text, converted from HTML.
+type FeedItem struct {
+	ID        string `json:"id"` // guid or link, what marks the item as seen
+	Title     string `json:"title"`
+	Link      string `json:"link"`
+	Published string `json:"published,omitempty"`
+	Content   string `json:"content"`
+	Audio     string `json:"audio,omitempty"` // enclosure of a podcast episode
+}
+
+// rssDocument is the part of an RSS or Atom document that is read. Go's
+// XML decoder matches local names, so one struct reads both.
+type rssDocument struct {
+	XMLName xml.Name
+	Channel struct {
+		Title string    `xml:"title"`
+		Links []string  `xml:"link"` // atom:link elements match too, and are empty
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	// RSS 1.0 keeps its items beside the channel
+	RDFItems []rssItem `xml:"item"`
+	// Atom
+	Title   string      `xml:"title"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	GUID        string `xml:"guid"`
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	PubDate     string `xml:"pubDate"`
+	Description string `xml:"description"`
+	Encoded     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Enclosure   struct {
+		URL  string `xml:"url,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"enclosure"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Summary   string     `xml:"summary"`
+	Content   string     `xml:"content"`
+}
+
+// fetchFeed downloads and parses an RSS or Atom feed
+func (a *App) fetchFeed(ctx context.Context, url string) (*Feed, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid feed URL: %v", err)
+	}
+	req.Header.Set("User-Agent", "FabricGUI/"+appVersion)
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.5")
+
+	resp, err := a.externalClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("feed returned status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed: %v", err)
+	}
+	return parseFeed(data)
+}
+
+// parseFeed reads an RSS or Atom document
+func parseFeed(data []byte) (*Feed, error) {
+	var doc rssDocument
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	// Feeds declare all sorts of encodings, and are mostly UTF-8 regardless
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) { return input, nil }
+	decoder.Strict = false
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("not an RSS or Atom feed: %v", err)
+	}
+
+	feed := &Feed{}
+	switch doc.XMLName.Local {
+	case "rss", "RDF":
+		feed.Title = strings.TrimSpace(doc.Channel.Title)
+		for _, link := range doc.Channel.Links {
+			if feed.Link = strings.TrimSpace(link); feed.Link != "" {
+				break
+			}
+		}
+		for _, item := range append(doc.Channel.Items, doc.RDFItems...) {
+			content := item.Encoded
+			if content == "" {
+				content = item.Description
+			}
+			fi := FeedItem{
+				ID:        strings.TrimSpace(item.GUID),
+				Title:     strings.TrimSpace(item.Title),
+				Link:      strings.TrimSpace(item.Link),
+				Published: strings.TrimSpace(item.PubDate),
+				Content:   feedText(content),
+			}
+			if isAudioType(item.Enclosure.Type, item.Enclosure.URL) {
+				fi.Audio = strings.TrimSpace(item.Enclosure.URL)
+			}
+			if fi.ID == "" {
+				fi.ID = fi.Link
+			}
+			feed.Items = append(feed.Items, fi)
+		}
+	case "feed":
+		feed.Title = strings.TrimSpace(doc.Title)
+		feed.Link = atomHref(doc.Links)
+		for _, entry := range doc.Entries {
+			content := entry.Content
+			if content == "" {
+				content = entry.Summary
+			}
+			published := entry.Published
+			if published == "" {
+				published = entry.Updated
+			}
+			fi := FeedItem{
+				ID:        strings.TrimSpace(entry.ID),
+				Title:     strings.TrimSpace(entry.Title),
+				Link:      atomHref(entry.Links),
+				Published: strings.TrimSpace(published),
+				Content:   feedText(content),
+			}
+			for _, link := range entry.Links {
+				if link.Rel == "enclosure" && isAudioType(link.Type, link.Href) {
+					fi.Audio = strings.TrimSpace(link.Href)
+					break
+				}
+			}
+			if fi.ID == "" {
+				fi.ID = fi.Link
+			}
+			feed.Items = append(feed.Items, fi)
+		}
+	default:
+		return nil, fmt.Errorf("not an RSS or Atom feed: <%s> document", doc.XMLName.Local)
+	}
+	return feed, nil
+}
+
+// atomHref returns the alternate link of an Atom feed or entry
+func atomHref(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return strings.TrimSpace(link.Href)
+		}
+	}
+	if len(links) > 0 {
+		return strings.TrimSpace(links[0].Href)
+	}
+	return ""
+}
+
+// feedText converts an item's HTML content to text
+func feedText(content string) string {
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return tidyText(content)
+	}
+	return htmlToText(doc)
+}
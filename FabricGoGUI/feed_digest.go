@@ -0,0 +1,362 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// FeedSubscription is a feed whose new items a digest runs through a
+// preset or pattern, label_and_rate for example
+type FeedSubscription struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"` // defaults to the feed's title
+	URL          string `json:"url"`
+	Preset       string `json:"preset"` // runs the preset, otherwise the pattern with the model
+	Pattern      string `json:"pattern"`
+	Vendor       string `json:"vendor"`
+	Model        string `json:"model"`        // defaults to the pattern's model
+	FullArticles bool   `json:"fullArticles"` // fetch each item's page instead of running the feed's excerpt
+}
+
+// FeedDigest is the result of running the new items of subscribed feeds,
+// emitted as "feeds:digest"
+type FeedDigest struct {
+	Time     int64            `json:"time"`
+	Items    []FeedDigestItem `json:"items"`
+	Errors   []string         `json:"errors,omitempty"` // feeds that could not be fetched
+	Markdown string           `json:"markdown"`         // the outputs under the items' titles, by feed
+}
+
+// FeedDigestItem is one item of a digest
+type FeedDigestItem struct {
+	Feed    string `json:"feed"`
+	Title   string `json:"title"`
+	Link    string `json:"link"`
+	Output  string `json:"output,omitempty"`
+	EntryID string `json:"entryId,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+const (
+	// maxDigestItems is how many new items of a feed one digest runs. Older
+	// new items are skipped, so a first digest does not run a whole archive.
+	maxDigestItems = 10
+	// minArticleLength is the excerpt length under which the item's page is
+	// fetched for its full text
+	minArticleLength = 500
+)
+
+// FetchFeed downloads an RSS or Atom feed and returns its items
+func (a *App) FetchFeed(url string) (*Feed, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return a.fetchFeed(ctx, strings.TrimSpace(url))
+}
+
+// GetFeedSubscriptions returns the subscribed feeds
+func (a *App) GetFeedSubscriptions() []FeedSubscription {
+	prefs, err := a.loadPreferences()
+	if err != nil || prefs.Feeds == nil {
+		return []FeedSubscription{}
+	}
+	return prefs.Feeds
+}
+
+// SaveFeedSubscription subscribes to a feed, or replaces the subscription
+// with the same ID, and returns it as saved. The feed is fetched to check it
+// and to name the subscription.
+func (a *App) SaveFeedSubscription(sub FeedSubscription) (FeedSubscription, error) {
+	sub.Name = strings.TrimSpace(sub.Name)
+	sub.URL = strings.TrimSpace(sub.URL)
+	if sub.Preset == "" && sub.Pattern == "" {
+		return sub, fmt.Errorf("a preset or a pattern is required")
+	}
+	feed, err := a.FetchFeed(sub.URL)
+	if err != nil {
+		return sub, err
+	}
+	if sub.Name == "" {
+		sub.Name = feed.Title
+	}
+	if sub.Name == "" {
+		sub.Name = sub.URL
+	}
+	if sub.ID == "" {
+		sub.ID = newHistoryID()
+	}
+
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return sub, err
+	}
+	i := slices.IndexFunc(prefs.Feeds, func(f FeedSubscription) bool { return f.ID == sub.ID })
+	if i >= 0 {
+		prefs.Feeds[i] = sub
+	} else {
+		prefs.Feeds = append(prefs.Feeds, sub)
+	}
+	return sub, a.SavePreferences(*prefs)
+}
+
+// DeleteFeedSubscription unsubscribes from a feed
+func (a *App) DeleteFeedSubscription(id string) error {
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(prefs.Feeds, func(f FeedSubscription) bool { return f.ID == id })
+	if i < 0 {
+		return fmt.Errorf("feed not found: %s", id)
+	}
+	prefs.Feeds = slices.Delete(prefs.Feeds, i, i+1)
+	if err := a.SavePreferences(*prefs); err != nil {
+		return err
+	}
+	a.feedsMutex.Lock()
+	defer a.feedsMutex.Unlock()
+	seen := a.loadFeedsSeen()
+	delete(seen, id)
+	return a.saveFeedsSeen(seen)
+}
+
+// RunFeedDigest runs the new items of the given subscriptions, or of all
+// of them when ids is empty, and returns the digest
+func (a *App) RunFeedDigest(ids []string) (*FeedDigest, error) {
+	var subs []FeedSubscription
+	for _, sub := range a.GetFeedSubscriptions() {
+		if len(ids) == 0 || slices.Contains(ids, sub.ID) {
+			subs = append(subs, sub)
+		}
+	}
+	if len(subs) == 0 {
+		return nil, fmt.Errorf("no feeds to run")
+	}
+
+	j, ctx := a.startJob("feeds", "Feed digest")
+	digest, err := a.buildFeedDigest(ctx, j, subs)
+	a.finishJob(j, err)
+	if err != nil {
+		return nil, err
+	}
+	a.emit("feeds:digest", digest)
+	return digest, nil
+}
+
+// buildFeedDigest fetches the subscriptions and runs their new items one at
+// a time. Items that ran are not run again; items that failed are retried
+// by the next digest.
+func (a *App) buildFeedDigest(ctx context.Context, j *job, subs []FeedSubscription) (*FeedDigest, error) {
+	// Digests run one at a time, so items are not run twice
+	a.feedsMutex.Lock()
+	defer a.feedsMutex.Unlock()
+	seen := a.loadFeedsSeen()
+
+	type pendingItem struct {
+		sub  FeedSubscription
+		item FeedItem
+	}
+	digest := &FeedDigest{Time: time.Now().Unix(), Items: []FeedDigestItem{}}
+	var pending []pendingItem
+	for _, sub := range subs {
+		a.updateJob(j, -1, "Fetching "+sub.Name)
+		fetchCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		feed, err := a.fetchFeed(fetchCtx, sub.URL)
+		cancel()
+		if err != nil {
+			digest.Errors = append(digest.Errors, fmt.Sprintf("%s: %v", sub.Name, err))
+			continue
+		}
+		n := 0
+		for _, item := range feed.Items {
+			if item.ID == "" || slices.Contains(seen[sub.ID], item.ID) {
+				continue
+			}
+			if n < maxDigestItems {
+				pending = append(pending, pendingItem{sub, item})
+				n++
+			} else {
+				seen[sub.ID] = append(seen[sub.ID], item.ID)
+			}
+		}
+	}
+
+	for i, p := range pending {
+		if ctx.Err() != nil {
+			break
+		}
+		a.updateJob(j, float64(i)/float64(len(pending))*100, fmt.Sprintf("%d of %d: %s", i+1, len(pending), p.item.Title))
+		result := FeedDigestItem{Feed: p.sub.Name, Title: p.item.Title, Link: p.item.Link}
+		entryID, output, err := a.runFeedItem(ctx, p.sub, p.item)
+		result.EntryID, result.Output = entryID, output
+		if err != nil {
+			result.Error = toChatError(err).Message
+		} else {
+			seen[p.sub.ID] = append(seen[p.sub.ID], p.item.ID)
+		}
+		digest.Items = append(digest.Items, result)
+	}
+
+	for id, ids := range seen {
+		if len(ids) > maxScheduleSeen {
+			seen[id] = ids[len(ids)-maxScheduleSeen:]
+		}
+	}
+	if err := a.saveFeedsSeen(seen); err != nil {
+		a.log.Error("failed to save seen feed items", "error", err)
+	}
+	digest.Markdown = feedDigestMarkdown(digest)
+	if ctx.Err() != nil {
+		return digest, ctx.Err()
+	}
+	return digest, nil
+}
+
+// runFeedItem runs a feed item through its subscription's pattern, with the
+// full article when the subscription asks for it or the excerpt is short
+func (a *App) runFeedItem(ctx context.Context, sub FeedSubscription, item FeedItem) (string, string, error) {
+	text := item.Content
+	if item.Link != "" && (sub.FullArticles || len(text) < minArticleLength) {
+		fetchCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		article, err := a.fetchPageText(fetchCtx, item.Link)
+		cancel()
+		if err == nil {
+			text = article
+		} else {
+			a.log.Debug("failed to fetch article, using the feed's text", "link", item.Link, "error", err)
+		}
+	}
+	if strings.TrimSpace(text) == "" {
+		return "", "", fmt.Errorf("the item has no text")
+	}
+	input := strings.TrimSpace(fmt.Sprintf("# %s\n%s", item.Title, item.Link)) + "\n\n" + text
+
+	prompt, err := a.automatedPrompt(sub.Preset, sub.Pattern, sub.Vendor, sub.Model, input)
+	if err != nil {
+		return "", "", err
+	}
+	entryID, output, err := a.runAutomated(ctx, prompt)
+	return entryID, output, err
+}
+
+// feedDigestMarkdown lays out a digest's outputs by feed
+func feedDigestMarkdown(digest *FeedDigest) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Feed digest, %s\n", time.Unix(digest.Time, 0).Format("2006-01-02 15:04"))
+	feed := ""
+	for _, item := range digest.Items {
+		if item.Feed != feed {
+			feed = item.Feed
+			fmt.Fprintf(&b, "\n## %s\n", feed)
+		}
+		if item.Link != "" {
+			fmt.Fprintf(&b, "\n### [%s](%s)\n\n", item.Title, item.Link)
+		} else {
+			fmt.Fprintf(&b, "\n### %s\n\n", item.Title)
+		}
+		if item.Error != "" {
+			fmt.Fprintf(&b, "*Failed: %s*\n", item.Error)
+		} else {
+			b.WriteString(strings.TrimSpace(item.Output) + "\n")
+		}
+	}
+	if len(digest.Items) == 0 {
+		b.WriteString("\nNo new items.\n")
+	}
+	for _, err := range digest.Errors {
+		fmt.Fprintf(&b, "\n*%s*\n", err)
+	}
+	return b.String()
+}
+
+// runScheduledDigest runs the feed digest for a schedule and writes it to
+// the schedule's output folder
+func (a *App) runScheduledDigest(ctx context.Context, j *job, sched Schedule, run *ScheduleRun) error {
+	subs := a.GetFeedSubscriptions()
+	if len(subs) == 0 {
+		return fmt.Errorf("no feeds are subscribed")
+	}
+	digest, err := a.buildFeedDigest(ctx, j, subs)
+	if digest == nil {
+		return err
+	}
+	run.Items = len(digest.Items)
+	for _, item := range digest.Items {
+		if item.EntryID != "" {
+			run.EntryIDs = append(run.EntryIDs, item.EntryID)
+		}
+		if item.Error != "" {
+			run.Errors = append(run.Errors, fmt.Sprintf("%s: %s", item.Title, item.Error))
+		} else {
+			run.Succeeded++
+		}
+	}
+	if run.Items == 0 && err == nil && len(digest.Errors) > 0 {
+		// Every feed failed to load, which is not a run with nothing new
+		return fmt.Errorf("%s", strings.Join(digest.Errors, "; "))
+	}
+	run.Errors = append(run.Errors, digest.Errors...)
+	if run.Items > 0 {
+		a.emit("feeds:digest", digest)
+	}
+	if err != nil || run.Items == 0 || sched.OutputDir == "" {
+		return err
+	}
+
+	template := sched.OutputTemplate
+	if template == "" {
+		template = defaultScheduleTemplate
+	}
+	path := filepath.Join(sched.OutputDir, expandOutputTemplate(template, fileNamePart(sched.Name), "", "digest", "", 0))
+	if err := os.MkdirAll(sched.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output folder: %v", err)
+	}
+	if err := writeFileAtomic(path, []byte(digest.Markdown), 0644); err != nil {
+		return fmt.Errorf("failed to save digest: %v", err)
+	}
+	run.Outputs = append(run.Outputs, path)
+	return nil
+}
+
+// feedsSeenPath returns where the items digests have run are remembered
+func (a *App) feedsSeenPath() string {
+	dir := a.getConfigDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "feeds_seen.json")
+}
+
+// loadFeedsSeen returns the item IDs each subscription has run
+func (a *App) loadFeedsSeen() map[string][]string {
+	seen := map[string][]string{}
+	if path := a.feedsSeenPath(); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			if err := json.Unmarshal(data, &seen); err != nil {
+				a.log.Error("failed to load seen feed items", "error", err)
+			}
+		}
+	}
+	if seen == nil {
+		seen = map[string][]string{}
+	}
+	return seen
+}
+
+// saveFeedsSeen stores the item IDs each subscription has run
+func (a *App) saveFeedsSeen(seen map[string][]string) error {
+	path := a.feedsSeenPath()
+	if path == "" {
+		return fmt.Errorf("could not determine config directory")
+	}
+	data, err := json.MarshalIndent(seen, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
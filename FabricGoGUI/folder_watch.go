@@ -0,0 +1,360 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// FolderWatch runs new files that appear in a folder, such as an export
+// folder for meeting transcripts, through a preset or pattern
+type FolderWatch struct {
+	ID             string `json:"id"`
+	Dir            string `json:"dir"`
+	Glob           string `json:"glob"` // files to run, defaults to every importable file
+	Enabled        bool   `json:"enabled"`
+	Preset         string `json:"preset"` // runs the preset, otherwise the pattern with the model
+	Pattern        string `json:"pattern"`
+	Vendor         string `json:"vendor"`
+	Model          string `json:"model"`          // defaults to the pattern's model
+	OutputDir      string `json:"outputDir"`      // defaults to the folder the file appeared in
+	OutputTemplate string `json:"outputTemplate"` // file names, as the batch template; defaults to defaultBatchTemplate
+}
+
+// FolderWatchStatus is a folder watch and whether it is watching
+type FolderWatchStatus struct {
+	FolderWatch
+	Watching bool   `json:"watching"`
+	Error    string `json:"error,omitempty"` // why the folder is not watched
+}
+
+// FolderWatchEvent reports a watched file, emitted as "watch:file"
+type FolderWatchEvent struct {
+	WatchID string `json:"watchId"`
+	File    string `json:"file"`
+	Output  string `json:"output,omitempty"`
+	EntryID string `json:"entryId,omitempty"`
+	Status  string `json:"status"` // started, complete, error
+	Error   string `json:"error,omitempty"`
+}
+
+// watchSettleDelay is how long a new file must go unchanged before it is
+// run, so files still being written or copied are not read half done
+const watchSettleDelay = 2 * time.Second
+
+// folderWatcher watches the enabled folders and runs new files one at a time
+type folderWatcher struct {
+	fs      *fsnotify.Watcher
+	watches []FolderWatch
+	errors  map[string]string      // watch ID to why its folder is not watched
+	pending map[string]*time.Timer // new files waiting to settle
+	written map[string]bool        // outputs the watcher wrote, not to be run
+	queue   chan string
+	stop    chan struct{}
+	mu      sync.Mutex
+}
+
+// GetFolderWatches returns the folder watches and whether each is watching
+func (a *App) GetFolderWatches() []FolderWatchStatus {
+	a.folderWatchMutex.Lock()
+	w := a.folderWatch
+	a.folderWatchMutex.Unlock()
+
+	statuses := []FolderWatchStatus{}
+	for _, watch := range a.folderWatches() {
+		status := FolderWatchStatus{FolderWatch: watch}
+		if w != nil && watch.Enabled {
+			w.mu.Lock()
+			status.Error = w.errors[watch.ID]
+			status.Watching = status.Error == "" && slices.ContainsFunc(w.watches, func(fw FolderWatch) bool { return fw.ID == watch.ID })
+			w.mu.Unlock()
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// SaveFolderWatch creates a folder watch, or replaces the one with the same
+// ID, and returns it as saved. The watches are restarted to match.
+func (a *App) SaveFolderWatch(watch FolderWatch) (FolderWatch, error) {
+	watch.Dir = strings.TrimSpace(watch.Dir)
+	if info, err := os.Stat(watch.Dir); err != nil || !info.IsDir() {
+		return watch, fmt.Errorf("folder not found: %s", watch.Dir)
+	}
+	if _, err := filepath.Match(watch.Glob, ""); err != nil {
+		return watch, fmt.Errorf("invalid file pattern: %v", err)
+	}
+	if watch.Preset == "" && watch.Pattern == "" {
+		return watch, fmt.Errorf("a preset or a pattern is required")
+	}
+	if watch.ID == "" {
+		watch.ID = newHistoryID()
+	}
+
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return watch, err
+	}
+	i := slices.IndexFunc(prefs.FolderWatches, func(w FolderWatch) bool { return w.ID == watch.ID })
+	if i >= 0 {
+		prefs.FolderWatches[i] = watch
+	} else {
+		prefs.FolderWatches = append(prefs.FolderWatches, watch)
+	}
+	if err := a.SavePreferences(*prefs); err != nil {
+		return watch, err
+	}
+	a.restartFolderWatcher()
+	return watch, nil
+}
+
+// DeleteFolderWatch removes a folder watch and stops watching its folder
+func (a *App) DeleteFolderWatch(id string) error {
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(prefs.FolderWatches, func(w FolderWatch) bool { return w.ID == id })
+	if i < 0 {
+		return fmt.Errorf("folder watch not found: %s", id)
+	}
+	prefs.FolderWatches = slices.Delete(prefs.FolderWatches, i, i+1)
+	if err := a.SavePreferences(*prefs); err != nil {
+		return err
+	}
+	a.restartFolderWatcher()
+	return nil
+}
+
+// folderWatches returns the configured folder watches
+func (a *App) folderWatches() []FolderWatch {
+	prefs, err := a.loadPreferences()
+	if err != nil || prefs.FolderWatches == nil {
+		return []FolderWatch{}
+	}
+	return prefs.FolderWatches
+}
+
+// restartFolderWatcher watches the enabled folders, stopping the watcher
+// when none are
+func (a *App) restartFolderWatcher() {
+	a.stopFolderWatcher()
+
+	var watches []FolderWatch
+	for _, watch := range a.folderWatches() {
+		if watch.Enabled {
+			watches = append(watches, watch)
+		}
+	}
+	if len(watches) == 0 {
+		return
+	}
+
+	fs, err := fsnotify.NewWatcher()
+	if err != nil {
+		a.log.Error("failed to start folder watcher", "error", err)
+		return
+	}
+	w := &folderWatcher{
+		fs:      fs,
+		errors:  map[string]string{},
+		pending: map[string]*time.Timer{},
+		written: map[string]bool{},
+		queue:   make(chan string, 100),
+		stop:    make(chan struct{}),
+	}
+	for _, watch := range watches {
+		if err := fs.Add(watch.Dir); err != nil {
+			w.errors[watch.ID] = fmt.Sprintf("failed to watch %s: %v", watch.Dir, err)
+			a.log.Warn("failed to watch folder", "folder", watch.Dir, "error", err)
+			continue
+		}
+		w.watches = append(w.watches, watch)
+		a.log.Info("watching folder", "folder", watch.Dir, "pattern", watch.Pattern, "preset", watch.Preset)
+	}
+
+	a.folderWatchMutex.Lock()
+	a.folderWatch = w
+	a.folderWatchMutex.Unlock()
+	go a.watchFolders(w)
+	go a.runWatchedFiles(w)
+}
+
+// stopFolderWatcher stops watching folders. A file being run finishes.
+func (a *App) stopFolderWatcher() {
+	a.folderWatchMutex.Lock()
+	w := a.folderWatch
+	a.folderWatch = nil
+	a.folderWatchMutex.Unlock()
+	if w == nil {
+		return
+	}
+	close(w.stop)
+	w.fs.Close()
+	w.mu.Lock()
+	for _, timer := range w.pending {
+		timer.Stop()
+	}
+	w.mu.Unlock()
+}
+
+// watchFolders queues new files once they have settled
+func (a *App) watchFolders(w *folderWatcher) {
+	for {
+		select {
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) {
+				continue
+			}
+			path := event.Name
+			w.mu.Lock()
+			if timer, ok := w.pending[path]; ok {
+				// Still being written
+				timer.Reset(watchSettleDelay)
+			} else if event.Has(fsnotify.Create) && !w.written[path] && w.watchFor(path) != nil {
+				w.pending[path] = time.AfterFunc(watchSettleDelay, func() {
+					w.mu.Lock()
+					delete(w.pending, path)
+					// Outputs are marked once written, which can be after their event
+					written := w.written[path]
+					w.mu.Unlock()
+					if written {
+						return
+					}
+					select {
+					case w.queue <- path:
+					case <-w.stop:
+					}
+				})
+			}
+			w.mu.Unlock()
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+			a.log.Warn("folder watcher error", "error", err)
+		}
+	}
+}
+
+// watchFor returns the watch a file belongs to, or nil when it is not one
+// to run
+func (w *folderWatcher) watchFor(path string) *FolderWatch {
+	name := filepath.Base(path)
+	// Hidden files, and Office's lock files
+	if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "~$") {
+		return nil
+	}
+	if !importableExtensions[strings.ToLower(filepath.Ext(name))] {
+		return nil
+	}
+	for i, watch := range w.watches {
+		if filepath.Clean(watch.Dir) != filepath.Dir(path) {
+			continue
+		}
+		if watch.Glob != "" {
+			if ok, _ := filepath.Match(watch.Glob, name); !ok {
+				continue
+			}
+		}
+		return &w.watches[i]
+	}
+	return nil
+}
+
+// runWatchedFiles runs queued files one at a time until the watcher stops
+func (a *App) runWatchedFiles(w *folderWatcher) {
+	for {
+		select {
+		case <-w.stop:
+			return
+		case path := <-w.queue:
+			w.mu.Lock()
+			watch := w.watchFor(path)
+			w.mu.Unlock()
+			if watch == nil {
+				continue
+			}
+			if info, err := os.Stat(path); err != nil || info.IsDir() {
+				continue // gone again, or a folder named like a file
+			}
+			a.runWatchedFile(w, *watch, path)
+		}
+	}
+}
+
+// runWatchedFile runs a new file through its watch's pattern and writes the
+// output, reporting progress as "watch:file"
+func (a *App) runWatchedFile(w *folderWatcher, watch FolderWatch, path string) {
+	event := FolderWatchEvent{WatchID: watch.ID, File: path, Status: "started"}
+	a.emit("watch:file", event)
+	a.log.Info("running watched file", "file", path)
+
+	j, ctx := a.startJob("watch", "Watch: "+filepath.Base(path))
+	output, entryID, err := a.processWatchedFile(ctx, w, watch, path)
+	a.finishJob(j, err)
+
+	event.Output, event.EntryID = output, entryID
+	if err != nil {
+		event.Status = "error"
+		event.Error = toChatError(err).Message
+		a.log.Warn("failed to run watched file", "file", path, "error", err)
+	} else {
+		event.Status = "complete"
+	}
+	a.emit("watch:file", event)
+}
+
+// processWatchedFile reads, runs and saves one file, returning the output
+// file and the history entry
+func (a *App) processWatchedFile(ctx context.Context, w *folderWatcher, watch FolderWatch, path string) (string, string, error) {
+	input, err := readImportFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	prompt, err := a.automatedPrompt(watch.Preset, watch.Pattern, watch.Vendor, watch.Model, input)
+	if err != nil {
+		return "", "", err
+	}
+	entryID, output, err := a.runAutomated(ctx, prompt)
+	if err != nil {
+		return "", entryID, err
+	}
+
+	dir := watch.OutputDir
+	if dir == "" {
+		dir = filepath.Dir(path)
+	}
+	template := watch.OutputTemplate
+	if template == "" {
+		template = defaultBatchTemplate
+	}
+	name := expandBatchTemplate(template, path, prompt.PatternName, prompt.Model, 0)
+	if filepath.Join(dir, name) == path {
+		return "", entryID, fmt.Errorf("the output file name is the input's, change the output template")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", entryID, fmt.Errorf("failed to create output folder: %v", err)
+	}
+	// Files sharing a name, such as notes.txt and notes.md, each keep their output
+	ext := filepath.Ext(name)
+	outPath, err := writeNewFile(dir, strings.TrimSuffix(name, ext), ext, []byte(output))
+	if err != nil {
+		return "", entryID, fmt.Errorf("failed to save output: %v", err)
+	}
+	// The output appears in a watched folder too, and is not to be run
+	w.mu.Lock()
+	w.written[outPath] = true
+	w.mu.Unlock()
+	return outPath, entryID, nil
+}
@@ -2,9 +2,19 @@
 // Communicates with Go backend via Wails bindings
 
 import {
-    GetPatterns, GetModels, SendChat, CheckHealth, GetBaseURL, SetBaseURL,
-    OpenFileDialog, SaveFileDialog, AddHistoryEntry, GetHistoryCount, GetHistoryEntry,
-    SavePreferences, LoadPreferences, StartServer, StopServer, IsServerRunning
+    GetPatterns, GetModels, StartChat, CancelStream, CheckHealth, GetBaseURL, SetBaseURL,
+    OpenFileDialog, SaveFileDialog, GetHistoryCount, GetHistoryEntry,
+    SavePreferences, LoadPreferences, StartServer, StopServer, IsServerRunning,
+    ReadClipboard, WriteClipboard, CopyOutput, ExportToPDF, ExportToHTML,
+    GetObsidianSettings, SelectObsidianVault, SaveToObsidian, AppendToDailyNote,
+    ChooseOutputFile, SaveDraft, GetSavedSession, RestoreSession, DiscardSession,
+    SpeakOutput, PauseSpeech, ResumeSpeech, StopSpeech, GetWebhooks, SendToWebhook,
+    SendToSlack, SendToDiscord, ExportToNotion, SendOutputByEmail,
+    ExtractCodeBlocks, CopyCodeBlock, SaveCodeBlock,
+    ListPresets, RunPreset, ShowMiniWindow, HideMiniWindow, OpenOutputWindow,
+    GetFabricVersion, SearchPatterns, GetPatternModel,
+    CountTokens, ProbeVendors, GetFavoriteModels, GetHistoryEncryption, UnlockHistory,
+    FrontendReady
 } from '../wailsjs/go/main/App.js';
 import { EventsOn } from '../wailsjs/runtime/runtime.js';
 
@@ -17,6 +27,18 @@ const state = {
     selectedPattern: '',
     selectedModel: '',
     selectedVendor: '',
+    outputId: '', // history entry shown in the output pane
+    variables: {},
+    context: '',
+    strategy: '',
+    session: '',
+    systemPrompt: '',
+    systemMode: 'replace',
+    chunk: true,
+    outputFile: '', // streamed to as the output arrives
+    speech: '', // text-to-speech: '', 'speaking' or 'paused'
+    emailTo: '', // last recipients outputs were emailed to
+    hotkeyRunning: false, // the hotkey popup is streaming a result
     isProcessing: false,
     serverOnline: false,
     serverStarting: false,
@@ -24,6 +46,10 @@ const state = {
     historyIndex: -1,
     historyCount: 0,
     currentOutput: '',
+    streamId: '',
+    prefs: {},
+    vendorStatus: {}, // vendor -> VendorStatus from ProbeVendors
+    favorites: [],
 };
 
 // ============================================
@@ -44,6 +70,7 @@ const elements = {
 
     // Preview
     commandPreview: document.getElementById('commandPreview'),
+    tokenCount: document.getElementById('tokenCount'),
 
     // I/O
     inputText: document.getElementById('inputText'),
@@ -52,13 +79,30 @@ const elements = {
     // Buttons
     sendBtn: document.getElementById('sendBtn'),
     cancelBtn: document.getElementById('cancelBtn'),
+    outputFileBtn: document.getElementById('outputFileBtn'),
     copyBtn: document.getElementById('copyBtn'),
+    copyFormat: document.getElementById('copyFormat'),
     clearInputBtn: document.getElementById('clearInputBtn'),
     clearOutputBtn: document.getElementById('clearOutputBtn'),
     pasteBtn: document.getElementById('pasteBtn'),
     settingsBtn: document.getElementById('settingsBtn'),
+    miniBtn: document.getElementById('miniBtn'),
     importBtn: document.getElementById('importBtn'),
     saveBtn: document.getElementById('saveBtn'),
+    exportPdfBtn: document.getElementById('exportPdfBtn'),
+    exportHtmlBtn: document.getElementById('exportHtmlBtn'),
+    obsidianBtn: document.getElementById('obsidianBtn'),
+    dailyNoteBtn: document.getElementById('dailyNoteBtn'),
+    copyCodeBtn: document.getElementById('copyCodeBtn'),
+    saveCodeBtn: document.getElementById('saveCodeBtn'),
+    notionBtn: document.getElementById('notionBtn'),
+    emailBtn: document.getElementById('emailBtn'),
+    webhookBtn: document.getElementById('webhookBtn'),
+    slackBtn: document.getElementById('slackBtn'),
+    discordBtn: document.getElementById('discordBtn'),
+    detachBtn: document.getElementById('detachBtn'),
+    speakBtn: document.getElementById('speakBtn'),
+    pauseSpeechBtn: document.getElementById('pauseSpeechBtn'),
 
     // History
     historyPrevBtn: document.getElementById('historyPrevBtn'),
@@ -74,6 +118,19 @@ const elements = {
     cancelSettingsBtn: document.getElementById('cancelSettingsBtn'),
     closeSettingsBtn: document.getElementById('closeSettingsBtn'),
 
+    // Mini Window
+    miniPreset: document.getElementById('miniPreset'),
+    miniRunBtn: document.getElementById('miniRunBtn'),
+    miniExitBtn: document.getElementById('miniExitBtn'),
+
+    // Hotkey Popup
+    hotkeyModal: document.getElementById('hotkeyModal'),
+    hotkeyTitle: document.getElementById('hotkeyTitle'),
+    hotkeyOutput: document.getElementById('hotkeyOutput'),
+    copyHotkeyBtn: document.getElementById('copyHotkeyBtn'),
+    doneHotkeyBtn: document.getElementById('doneHotkeyBtn'),
+    closeHotkeyBtn: document.getElementById('closeHotkeyBtn'),
+
     // Loading
     loadingOverlay: document.getElementById('loadingOverlay'),
     loadingText: document.getElementById('loadingText'),
@@ -100,11 +157,15 @@ async function init() {
     // Check server status
     await checkServerStatus();
 
-    // Load data if server is online
-    if (state.serverOnline) {
-        await loadPatterns();
-        await loadModels();
-    }
+    // Load data, cached lists are available even before the server is up
+    await loadPatterns();
+    await loadModels();
+
+    // Encrypted history needs its passphrase before it can be shown
+    await unlockHistory();
+
+    // A crash leaves the last input and output behind
+    await offerSessionRestore();
 
     // Update history display
     await updateHistoryDisplay();
@@ -115,6 +176,9 @@ async function init() {
     // Set up Wails events for streaming
     setupWailsEvents();
 
+    // Events raised during startup, such as deep links, arrive now
+    await FrontendReady();
+
     // Start periodic health check
     setInterval(checkServerStatus, 5000);
 
@@ -128,6 +192,8 @@ async function loadPreferences() {
     try {
         const prefs = await LoadPreferences();
         if (prefs) {
+            // Keep every field so settings managed elsewhere survive a save
+            state.prefs = prefs;
             if (prefs.baseUrl) {
                 elements.baseUrlInput.value = prefs.baseUrl;
             }
@@ -153,6 +219,7 @@ async function loadPreferences() {
 async function savePreferences() {
     try {
         await SavePreferences({
+            ...state.prefs,
             baseUrl: elements.baseUrlInput.value,
             theme: state.theme,
             lastPattern: state.selectedPattern,
@@ -177,6 +244,7 @@ async function checkServerStatus() {
             await loadPatterns();
             await loadModels();
             showToast('Connected to Fabric server', 'success');
+            GetFabricVersion();
         }
 
         state.serverOnline = isOnline;
@@ -297,16 +365,30 @@ async function loadPatterns() {
 
 async function loadModels() {
     try {
-        const response = await GetModels();
+        const [response, favorites] = await Promise.all([GetModels(), GetFavoriteModels()]);
         state.models = response?.vendors || {};
+        state.favorites = favorites || [];
         renderModels(state.models);
         restoreModelSelection();
+        probeVendors();
     } catch (e) {
         console.error('Failed to load models:', e);
         elements.modelSelect.innerHTML = '<option disabled>Failed to load models</option>';
     }
 }
 
+// probeVendors checks which vendors are usable and greys out the others
+async function probeVendors() {
+    try {
+        const statuses = await ProbeVendors();
+        state.vendorStatus = Object.fromEntries((statuses || []).map(s => [s.vendor, s]));
+        renderModels(state.models);
+        restoreModelSelection();
+    } catch (e) {
+        console.error('Failed to probe vendors:', e);
+    }
+}
+
 function renderPatterns(patterns) {
     elements.patternSelect.innerHTML = '';
 
@@ -317,8 +399,9 @@ function renderPatterns(patterns) {
 
     patterns.forEach(pattern => {
         const option = document.createElement('option');
-        option.value = pattern;
-        option.textContent = pattern;
+        option.value = pattern.name;
+        option.textContent = pattern.source === 'custom' ? `${pattern.name} (custom)` : pattern.name;
+        option.title = pattern.description || '';
         elements.patternSelect.appendChild(option);
     });
 }
@@ -331,9 +414,30 @@ function renderModels(vendors) {
         return;
     }
 
+    // Pinned models come first, as long as the server still offers them
+    const favorites = state.favorites.filter(f => (vendors[f.vendor] || []).includes(f.model));
+    if (favorites.length > 0) {
+        const optgroup = document.createElement('optgroup');
+        optgroup.label = '★ FAVORITES';
+        favorites.forEach(f => {
+            const option = document.createElement('option');
+            option.value = `${f.vendor}:${f.model}`;
+            option.textContent = f.model;
+            optgroup.appendChild(option);
+        });
+        elements.modelSelect.appendChild(optgroup);
+    }
+
     for (const [vendor, models] of Object.entries(vendors)) {
         const optgroup = document.createElement('optgroup');
         optgroup.label = vendor.toUpperCase();
+        const status = state.vendorStatus[vendor];
+        if (status && !status.usable) {
+            // Keep the current selection usable so it is not silently switched
+            optgroup.label += ' (unavailable)';
+            optgroup.title = status.error || '';
+            optgroup.disabled = vendor !== state.selectedVendor;
+        }
 
         models.forEach(model => {
             const option = document.createElement('option');
@@ -349,11 +453,22 @@ function renderModels(vendors) {
 // ============================================
 // Pattern Search
 // ============================================
-function filterPatterns(query) {
-    const filtered = state.patterns.filter(p =>
-        p.toLowerCase().includes(query.toLowerCase())
-    );
-    renderPatterns(filtered);
+let searchSeq = 0;
+
+async function filterPatterns(query) {
+    const seq = ++searchSeq;
+    try {
+        const results = await SearchPatterns(query);
+        // Ignore results for a query that has since been typed over
+        if (seq === searchSeq) {
+            renderPatterns(results);
+        }
+    } catch (e) {
+        const filtered = state.patterns.filter(p =>
+            p.name.toLowerCase().includes(query.toLowerCase())
+        );
+        renderPatterns(filtered);
+    }
 }
 
 // ============================================
@@ -393,16 +508,87 @@ function restoreModelSelection() {
 // Command Preview
 // ============================================
 function updateCommandPreview() {
-    const pattern = state.selectedPattern || '[pattern]';
-    const model = state.selectedModel || '[model]';
-
     // Simulate input piping for display
     let inputPreview = '';
     if (elements.inputText && elements.inputText.value) {
         inputPreview = 'echo "..." | ';
     }
 
-    elements.commandPreview.textContent = `${inputPreview}fabric --pattern ${pattern} --model ${model}`;
+    elements.commandPreview.textContent = inputPreview + fabricCommand();
+}
+
+// fabricCommand is the CLI equivalent of the current selection. Without a
+// pattern the input goes to the model as a plain chat.
+function fabricCommand() {
+    const model = state.selectedModel || '[model]';
+    if (!state.selectedPattern) {
+        return `fabric --model ${model}`;
+    }
+    return `fabric --pattern ${state.selectedPattern} --model ${model}`;
+}
+
+// Token count of the input against the selected model's context window,
+// refreshed shortly after typing stops
+let tokenCountTimer = null;
+function updateTokenCount() {
+    clearTimeout(tokenCountTimer);
+    tokenCountTimer = setTimeout(async () => {
+        const text = elements.inputText.value;
+        if (!text) {
+            elements.tokenCount.textContent = '';
+            elements.tokenCount.classList.remove('over');
+            return;
+        }
+        const count = await CountTokens(state.selectedModel, text).catch(() => null);
+        if (!count) return;
+        elements.tokenCount.textContent = count.contextWindow
+            ? `~${count.tokens.toLocaleString()} / ${count.contextWindow.toLocaleString()} tokens`
+            : `~${count.tokens.toLocaleString()} tokens`;
+        elements.tokenCount.classList.toggle('over', count.exceeds);
+    }, 300);
+}
+
+// The unsent input is kept by the backend so a crash does not lose it
+let draftTimer = null;
+function saveDraft() {
+    clearTimeout(draftTimer);
+    draftTimer = setTimeout(() => {
+        SaveDraft({
+            input: elements.inputText.value,
+            pattern: state.selectedPattern,
+            vendor: state.selectedVendor,
+            model: state.selectedModel,
+        }).catch(() => {});
+    }, 1000);
+}
+
+// offerSessionRestore reloads the input and partial output left behind when
+// the app last closed without shutting down, if the user wants them back
+async function offerSessionRestore() {
+    const saved = await GetSavedSession().catch(() => null);
+    if (!saved) return;
+    if (!window.confirm('Fabric GUI did not close properly last time. Restore the unsaved input and output?')) {
+        await DiscardSession().catch(() => {});
+        return;
+    }
+
+    try {
+        const session = await RestoreSession();
+        if (session.draft && session.draft.input) {
+            elements.inputText.value = session.draft.input;
+        }
+        const stream = session.streams[session.streams.length - 1];
+        if (stream) {
+            elements.outputText.textContent = stream.output;
+            if (!elements.inputText.value) {
+                elements.inputText.value = stream.input;
+            }
+        }
+        updateCommandPreview();
+        showToast('Previous session restored, partial outputs are in history', 'success');
+    } catch (e) {
+        showToast(`Failed to restore session: ${e}`, 'error');
+    }
 }
 
 // ============================================
@@ -423,6 +609,28 @@ async function updateHistoryDisplay() {
     }
 }
 
+// unlockHistory asks for the passphrase of encrypted history until it is
+// right or the user gives up
+async function unlockHistory() {
+    const status = await GetHistoryEncryption().catch(() => null);
+    if (!status || !status.locked) return;
+
+    let message = 'Enter the passphrase to unlock your history';
+    for (;;) {
+        const passphrase = window.prompt(message);
+        if (passphrase === null) {
+            showToast('History stays locked until restart', 'warning');
+            return;
+        }
+        try {
+            await UnlockHistory(passphrase);
+            return;
+        } catch (e) {
+            message = `${e}. Try again`;
+        }
+    }
+}
+
 async function navigateHistory(direction) {
     const newIndex = state.historyIndex + direction;
 
@@ -432,6 +640,7 @@ async function navigateHistory(direction) {
         const entry = await GetHistoryEntry(newIndex);
         if (entry) {
             state.historyIndex = newIndex;
+            state.outputId = entry.id;
 
             // Load the entry
             state.selectedPattern = entry.pattern;
@@ -489,6 +698,267 @@ async function saveOutput() {
     }
 }
 
+async function exportPdf() {
+    const content = elements.outputText.textContent;
+    if (!content) {
+        showToast('No output to export', 'warning');
+        return;
+    }
+
+    try {
+        const path = await ExportToPDF(content, '', {
+            pattern: state.selectedPattern,
+            model: state.selectedModel,
+            header: true,
+            footer: true,
+        });
+        if (path) {
+            showToast('PDF exported', 'success');
+        }
+    } catch (e) {
+        showToast(`Failed to export PDF: ${e}`, 'error');
+    }
+}
+
+async function exportHtml() {
+    const content = elements.outputText.textContent;
+    if (!content) {
+        showToast('No output to export', 'warning');
+        return;
+    }
+
+    try {
+        const path = await ExportToHTML(content, '', {
+            pattern: state.selectedPattern,
+            model: state.selectedModel,
+            theme: state.theme,
+        });
+        if (path) {
+            showToast('HTML exported', 'success');
+        }
+    } catch (e) {
+        showToast(`Failed to export HTML: ${e}`, 'error');
+    }
+}
+
+// saveToObsidian writes the shown history entry to the vault, asking for
+// the vault folder the first time
+async function saveToObsidian() {
+    if (!state.outputId) {
+        showToast('No saved output to send to Obsidian', 'warning');
+        return;
+    }
+
+    try {
+        const settings = await GetObsidianSettings();
+        if (!settings.vault && !(await SelectObsidianVault())) {
+            return;
+        }
+        const path = await SaveToObsidian(state.outputId, '', '');
+        showToast(`Saved to Obsidian: ${path}`, 'success');
+    } catch (e) {
+        showToast(`Failed to save to Obsidian: ${e}`, 'error');
+    }
+}
+
+// pickCodeBlock returns the index of a code block in the output, asking
+// which one when there are several, or -1
+async function pickCodeBlock(action) {
+    const blocks = await ExtractCodeBlocks(elements.outputText.textContent);
+    if (blocks.length === 0) {
+        showToast('The output has no code blocks', 'warning');
+        return -1;
+    }
+    if (blocks.length === 1) return 0;
+
+    const list = blocks.map(b => `${b.index + 1}: ${b.language || 'text'}, line ${b.line}`).join('\n');
+    const answer = window.prompt(`${action} which code block?\n${list}`, '1');
+    if (!answer) return -1;
+    const n = parseInt(answer, 10);
+    if (!(n >= 1 && n <= blocks.length)) {
+        showToast(`Enter a number from 1 to ${blocks.length}`, 'warning');
+        return -1;
+    }
+    return n - 1;
+}
+
+async function copyCodeBlock(index) {
+    try {
+        await ExtractCodeBlocks(elements.outputText.textContent);
+        await CopyCodeBlock(index);
+        showToast(`Copied code block ${index + 1}`, 'success');
+    } catch (e) {
+        showToast(`${e}`, 'error');
+    }
+}
+
+async function saveCodeBlock() {
+    try {
+        const index = await pickCodeBlock('Save');
+        if (index < 0) return;
+        const path = await SaveCodeBlock(index, '');
+        if (path) showToast(`Saved to ${path}`, 'success');
+    } catch (e) {
+        showToast(`Failed to save code block: ${e}`, 'error');
+    }
+}
+
+// detachOutput keeps the output in view in a window of its own
+async function detachOutput() {
+    if (!state.outputId) {
+        showToast('No saved output to detach', 'warning');
+        return;
+    }
+    try {
+        await OpenOutputWindow(state.outputId);
+    } catch (e) {
+        showToast(`Failed to open output window: ${e}`, 'error');
+    }
+}
+
+async function exportToNotion() {
+    if (!state.outputId) {
+        showToast('No saved output to send to Notion', 'warning');
+        return;
+    }
+
+    try {
+        const url = await ExportToNotion(state.outputId);
+        showToast(`Exported to Notion: ${url}`, 'success');
+    } catch (e) {
+        showToast(`Failed to export to Notion: ${e}`, 'error');
+    }
+}
+
+// emailOutput mails the output, remembering the recipients for next time
+async function emailOutput() {
+    if (!state.outputId) {
+        showToast('No saved output to email', 'warning');
+        return;
+    }
+    const to = window.prompt('Email to (comma-separated):', state.emailTo);
+    if (!to) return;
+    state.emailTo = to;
+
+    try {
+        const method = await SendOutputByEmail(state.outputId, to, '');
+        showToast(method === 'mailto' ? 'Opened in your mail app' : `Emailed to ${to}`, 'success');
+    } catch (e) {
+        showToast(`Failed to send email: ${e}`, 'error');
+    }
+}
+
+// sendToWebhook delivers the output to a webhook, asking which one when
+// several are configured
+async function sendToWebhook() {
+    if (!state.outputId) {
+        showToast('No saved output to send', 'warning');
+        return;
+    }
+
+    try {
+        const webhooks = await GetWebhooks();
+        if (webhooks.length === 0) {
+            showToast('No webhooks configured', 'warning');
+            return;
+        }
+        let name = webhooks[0].name;
+        if (webhooks.length > 1) {
+            name = window.prompt(`Send to which webhook? (${webhooks.map(w => w.name).join(', ')})`, name);
+            if (!name) return;
+        }
+        await SendToWebhook(name, state.outputId);
+        showToast(`Sent to ${name}`, 'success');
+    } catch (e) {
+        showToast(`Failed to send to webhook: ${e}`, 'error');
+    }
+}
+
+// postOutput sends the output to a team chat with send, SendToSlack or
+// SendToDiscord
+async function postOutput(service, send) {
+    const content = elements.outputText.textContent;
+    if (!content) {
+        showToast('No output to post', 'warning');
+        return;
+    }
+
+    try {
+        await send(content);
+        showToast(`Posted to ${service}`, 'success');
+    } catch (e) {
+        showToast(`${e}`, 'error');
+    }
+}
+
+async function appendToDailyNote() {
+    const content = elements.outputText.textContent;
+    if (!content) {
+        showToast('No output to append', 'warning');
+        return;
+    }
+
+    try {
+        const path = await AppendToDailyNote(content);
+        showToast(`Appended to ${path}`, 'success');
+    } catch (e) {
+        showToast(`Failed to append to daily note: ${e}`, 'error');
+    }
+}
+
+// toggleSpeech reads the output aloud, or stops reading it
+async function toggleSpeech() {
+    if (state.speech) {
+        StopSpeech();
+        return;
+    }
+    const content = elements.outputText.textContent;
+    if (!content) {
+        showToast('No output to read', 'warning');
+        return;
+    }
+
+    try {
+        await SpeakOutput(content);
+    } catch (e) {
+        showToast(`Failed to read output: ${e}`, 'error');
+    }
+}
+
+async function togglePauseSpeech() {
+    try {
+        await (state.speech === 'paused' ? ResumeSpeech() : PauseSpeech());
+    } catch (e) {
+        showToast(`${e}`, 'error');
+    }
+}
+
+// updateSpeechButtons reflects state.speech: '', 'speaking' or 'paused'
+function updateSpeechButtons() {
+    elements.speakBtn.textContent = state.speech ? 'Stop' : 'Listen';
+    elements.speakBtn.classList.toggle('active', !!state.speech);
+    elements.pauseSpeechBtn.classList.toggle('hidden', !state.speech);
+    elements.pauseSpeechBtn.textContent = state.speech === 'paused' ? 'Resume' : 'Pause';
+}
+
+// toggleOutputFile picks a file for the next runs to stream to, or stops
+// streaming to one
+async function toggleOutputFile() {
+    if (state.outputFile) {
+        state.outputFile = '';
+    } else {
+        try {
+            state.outputFile = await ChooseOutputFile();
+        } catch (e) {
+            showToast(`Failed to choose file: ${e}`, 'error');
+        }
+    }
+    elements.outputFileBtn.classList.toggle('active', !!state.outputFile);
+    elements.outputFileBtn.title = state.outputFile
+        ? `Streaming output to ${state.outputFile} (click to stop)`
+        : 'Stream the output to a file as it arrives';
+}
+
 // ============================================
 // Send Request
 // ============================================
@@ -500,11 +970,6 @@ async function sendRequest() {
         return;
     }
 
-    if (!state.selectedPattern) {
-        showToast('Please select a pattern', 'warning');
-        return;
-    }
-
     if (!state.selectedModel) {
         showToast('Please select a model', 'warning');
         return;
@@ -516,19 +981,28 @@ async function sendRequest() {
     }
 
     // Set processing state
-    const command = `echo "..." | fabric --pattern ${state.selectedPattern} --model ${state.selectedModel}`;
+    const command = `echo "..." | ${fabricCommand()}`;
     elements.loadingText.textContent = command;
     setProcessingState(true);
     elements.outputText.textContent = '';
     state.currentOutput = '';
+    state.outputId = '';
 
     try {
-        await SendChat(state.selectedPattern, state.selectedVendor, state.selectedModel, input);
+        state.streamId = await StartChat(state.selectedPattern, state.selectedVendor, state.selectedModel, input, {
+            variables: state.variables,
+            context: state.context,
+            strategy: state.strategy,
+            session: state.session,
+            systemPrompt: state.systemPrompt,
+            systemMode: state.systemMode,
+            chunk: state.chunk,
+            outputFile: state.outputFile,
+        });
     } catch (e) {
         console.error('Send failed:', e);
         elements.outputText.textContent = `Error: ${e}`;
         showToast('Request failed', 'error');
-    } finally {
         setProcessingState(false);
     }
 }
@@ -536,6 +1010,7 @@ async function sendRequest() {
 function setProcessingState(processing) {
     state.isProcessing = processing;
     elements.sendBtn.disabled = processing;
+    elements.miniRunBtn.disabled = processing;
     elements.sendBtn.classList.toggle('hidden', processing);
     elements.cancelBtn.classList.toggle('hidden', !processing);
     elements.loadingOverlay.classList.toggle('hidden', !processing);
@@ -555,40 +1030,215 @@ function setupWailsEvents() {
         console.log('[BACKEND]', msg);
     });
 
-    EventsOn('chat:chunk', (content) => {
-        state.currentOutput += content;
+    EventsOn('chat:chunk', (chunk) => {
+        if (chunk.streamId !== state.streamId) return;
+        state.currentOutput += chunk.content;
         elements.outputText.textContent = state.currentOutput;
         // Auto-scroll to bottom
         elements.outputText.scrollTop = elements.outputText.scrollHeight;
     });
 
-    EventsOn('chat:error', (error) => {
-        console.error('Chat error:', error);
-        showToast(error, 'error');
+    EventsOn('chat:error', (event) => {
+        if (event.streamId !== state.streamId) return;
+        console.error('Chat error:', event.error);
+        state.streamId = '';
+        setProcessingState(false);
+        if (event.resumable) {
+            // Keep the partial output visible instead of replacing it with the error
+            showToast(`Output interrupted, partial result kept: ${event.error}`, 'warning');
+            return;
+        }
+        elements.outputText.textContent = `Error: ${event.error}`;
+        showToast('Request failed', 'error');
+    });
+
+    EventsOn('chat:retry', (retry) => {
+        if (retry.streamId !== state.streamId) return;
+        showToast(`Retrying (${retry.attempt}/${retry.maxAttempts}): ${retry.error}`, 'warning');
     });
 
-    EventsOn('chat:complete', async () => {
+    EventsOn('chat:complete', async (event) => {
+        if (event.streamId !== state.streamId) return;
         console.log('[FRONTEND] Received chat:complete');
+        state.streamId = '';
+        state.outputId = event.historyId || '';
         // Small delay to ensure all chunks are rendered
         await new Promise(r => setTimeout(r, 200));
 
         setProcessingState(false);
 
-        // Add to history
+        // The backend records the history entry
         if (state.currentOutput) {
-            await AddHistoryEntry(
-                state.selectedPattern,
-                state.selectedModel,
-                elements.inputText.value,
-                state.currentOutput
-            );
-            state.historyCount++;
+            state.historyCount = await GetHistoryCount();
             state.historyIndex = state.historyCount - 1;
             await updateHistoryDisplay();
             showToast('Request completed', 'success');
         }
     });
 
+    EventsOn('input:loaded', (file) => {
+        elements.inputText.value = file.content;
+        updateCommandPreview();
+        showToast(`Loaded ${file.filename}`, 'success');
+    });
+
+    EventsOn('input:error', (error) => {
+        showToast(`Failed to import: ${error}`, 'error');
+    });
+
+    EventsOn('profile:switched', (result) => {
+        elements.baseUrlInput.value = result.profile.baseUrl;
+        state.prefs = { ...state.prefs, baseUrl: result.profile.baseUrl };
+        state.serverOnline = result.online;
+        updateServerStatus(result.online);
+        if (result.online) {
+            state.patterns = result.patterns || [];
+            renderPatterns(state.patterns);
+            restorePatternSelection();
+            state.models = result.models?.vendors || {};
+            renderModels(state.models);
+            restoreModelSelection();
+        }
+        showToast(`Switched to ${result.profile.name}`, result.error ? 'error' : 'success');
+    });
+
+    EventsOn('profile:insecure', (name) => {
+        showToast(`Certificate checks are disabled for ${name}`, 'error');
+    });
+
+    EventsOn('patterns:updated', (update) => {
+        if (update.patterns) {
+            state.patterns = update.patterns;
+            renderPatterns(state.patterns);
+            restorePatternSelection();
+        }
+        showToast(`Patterns updated: ${update.added.length} added, ${update.changed.length} changed`, 'success');
+    });
+
+    EventsOn('favorites:updated', (favorites) => {
+        state.favorites = favorites || [];
+        renderModels(state.models);
+        restoreModelSelection();
+    });
+
+    EventsOn('catalog:updated', (update) => {
+        if (update.patterns) loadPatterns();
+        if (update.models) loadModels();
+    });
+
+    EventsOn('chunk:progress', (progress) => {
+        if (progress.streamId !== state.streamId) return;
+        elements.loadingText.textContent = progress.status === 'combining'
+            ? `Combining ${progress.total} parts...`
+            : `Processing part ${progress.part} of ${progress.total}...`;
+    });
+
+    EventsOn('chat:contextWarning', (count) => {
+        showToast(`Input is about ${count.tokens.toLocaleString()} tokens, more than the model's ${count.contextWindow.toLocaleString()}`, 'warning');
+    });
+
+    EventsOn('fabric:incompatible', (info) => {
+        showToast(info.warnings.join('; '), info.compatible ? 'warning' : 'error');
+    });
+
+    EventsOn('deeplink:fetching', (url) => {
+        showToast(`Fetching ${url}...`, 'info');
+    });
+
+    EventsOn('deeplink:open', (link) => {
+        if (link.pattern) {
+            state.selectedPattern = link.pattern;
+            restorePatternSelection();
+        }
+        if (link.model && link.vendor) {
+            state.selectedVendor = link.vendor;
+            state.selectedModel = link.model;
+            restoreModelSelection();
+        }
+        elements.inputText.value = link.input;
+        updateCommandPreview();
+        updateTokenCount();
+        // A link can come from any web page, so it never runs unasked
+        if (link.run && link.input.trim() &&
+            window.confirm(`A link asks to run ${link.pattern || 'the input'}${link.model ? ` with ${link.model}` : ''}. Run it now?`)) {
+            sendRequest();
+        }
+    });
+
+    EventsOn('deeplink:error', (error) => {
+        showToast(`Failed to open link: ${error}`, 'error');
+    });
+
+    EventsOn('update:available', (info) => {
+        showToast(`Fabric GUI ${info.latest} is available, you have ${info.current}`, 'info');
+    });
+
+    EventsOn('update:applied', (info) => {
+        showToast(`Fabric GUI ${info.latest} is installed and runs from the next start`, 'success');
+    });
+
+    EventsOn('schedule:finished', (run) => {
+        if (run.status === 'failed' || run.status === 'partial') {
+            showToast(`Schedule ${run.name} failed: ${run.errors[0]}`, 'error');
+        } else if (run.status === 'complete') {
+            showToast(`Schedule ${run.name} finished ${run.succeeded} run${run.succeeded === 1 ? '' : 's'}`, 'success');
+        }
+    });
+
+    EventsOn('feeds:digest', (digest) => {
+        const failed = digest.items.filter((item) => item.error).length;
+        if (digest.items.length === 0) {
+            showToast('No new feed items', 'info');
+        } else {
+            showToast(`Feed digest: ${digest.items.length - failed} of ${digest.items.length} items processed`, failed ? 'warning' : 'success');
+        }
+    });
+
+    EventsOn('watch:file', (event) => {
+        const name = event.file.split(/[\\/]/).pop();
+        if (event.status === 'complete') {
+            showToast(`Processed ${name}`, 'success');
+        } else if (event.status === 'error') {
+            showToast(`Failed to process ${name}: ${event.error}`, 'error');
+        }
+    });
+
+    EventsOn('podcast:progress', (progress) => {
+        if (progress.stage === 'complete') {
+            showToast(`Processed ${progress.message}`, 'success');
+        } else if (progress.stage === 'error') {
+            showToast(`Podcast failed: ${progress.error}`, 'error');
+        }
+    });
+
+    EventsOn('youtube:complete', (summary) => {
+        const total = summary.succeeded + summary.failed;
+        showToast(`YouTube: ${summary.succeeded} of ${total} videos processed`, summary.failed ? 'warning' : 'success');
+    });
+
+    EventsOn('webhook:failed', (result) => {
+        showToast(`Webhook ${result.name} failed: ${result.error}`, 'error');
+    });
+
+    EventsOn('hook:failed', (result) => {
+        const detail = result.stderr ? `: ${result.stderr.trim().slice(0, 200)}` : '';
+        showToast(`Hook ${result.name} ${result.error}${detail}`, 'error');
+    });
+
+    EventsOn('hook:finished', (result) => {
+        if (result.stderr.trim()) {
+            showToast(`Hook ${result.name}: ${result.stderr.trim().slice(0, 200)}`, 'info');
+        }
+    });
+
+    EventsOn('tts:started', () => { state.speech = 'speaking'; updateSpeechButtons(); });
+    EventsOn('tts:paused', () => { state.speech = 'paused'; updateSpeechButtons(); });
+    EventsOn('tts:resumed', () => { state.speech = 'speaking'; updateSpeechButtons(); });
+    EventsOn('tts:stopped', () => { state.speech = ''; updateSpeechButtons(); });
+    EventsOn('tts:error', (error) => {
+        showToast(`Text-to-speech failed: ${error}`, 'error');
+    });
+
     EventsOn('server:started', () => {
         showToast('Server started', 'success');
     });
@@ -596,6 +1246,55 @@ function setupWailsEvents() {
     EventsOn('server:stopped', () => {
         showToast('Server stopped', 'info');
     });
+
+    EventsOn('server:error', (error) => {
+        showToast(`Server: ${error}`, 'error');
+    });
+
+    EventsOn('quick:error', (error) => {
+        showToast(`Quick run failed: ${error}`, 'error');
+        if (state.hotkeyRunning) {
+            state.hotkeyRunning = false;
+            elements.hotkeyOutput.textContent = `Error: ${error}`;
+        }
+    });
+
+    EventsOn('window:mini', (mini) => {
+        document.body.classList.toggle('mini', mini);
+        if (mini) {
+            loadMiniPresets();
+            elements.inputText.focus();
+        }
+    });
+
+    EventsOn('notification:clicked', async (click) => {
+        if (click.index < 0) {
+            showToast('That result is no longer in history', 'warning');
+            return;
+        }
+        state.historyCount = await GetHistoryCount();
+        await navigateHistory(click.index - state.historyIndex);
+    });
+
+    EventsOn('hotkey:started', (preset) => {
+        state.hotkeyRunning = true;
+        elements.hotkeyTitle.textContent = preset;
+        elements.hotkeyOutput.textContent = '';
+        elements.hotkeyModal.classList.remove('hidden');
+    });
+
+    EventsOn('hotkey:chunk', (chunk) => {
+        if (!state.hotkeyRunning) return;
+        elements.hotkeyOutput.textContent += chunk;
+        elements.hotkeyOutput.scrollTop = elements.hotkeyOutput.scrollHeight;
+    });
+
+    EventsOn('quick:result', (result) => {
+        if (!state.hotkeyRunning) return;
+        // The final output replaces chunks a retry may have repeated
+        state.hotkeyRunning = false;
+        elements.hotkeyOutput.textContent = result.output;
+    });
 }
 
 // ============================================
@@ -652,8 +1351,16 @@ function setupEventListeners() {
     });
 
     // Pattern selection
-    elements.patternSelect.addEventListener('change', (e) => {
+    elements.patternSelect.addEventListener('change', async (e) => {
         state.selectedPattern = e.target.value;
+        // Switch to the pattern's default model, the user can still pick another
+        const mapped = await GetPatternModel(state.selectedPattern).catch(() => null);
+        if (mapped && mapped.vendor) {
+            state.selectedVendor = mapped.vendor;
+            state.selectedModel = mapped.model;
+            restoreModelSelection();
+            updateTokenCount();
+        }
         updateCommandPreview();
         savePreferences(); // Persist selection
     });
@@ -664,6 +1371,7 @@ function setupEventListeners() {
         state.selectedVendor = vendor;
         state.selectedModel = model;
         updateCommandPreview();
+        updateTokenCount();
         savePreferences(); // Persist selection
     });
 
@@ -671,17 +1379,31 @@ function setupEventListeners() {
     elements.sendBtn.addEventListener('click', sendRequest);
 
     // Cancel button
-    elements.cancelBtn.addEventListener('click', () => {
+    elements.cancelBtn.addEventListener('click', async () => {
+        if (state.streamId) {
+            await CancelStream(state.streamId).catch(() => {});
+            state.streamId = '';
+        }
         setProcessingState(false);
         showToast('Request cancelled', 'info');
     });
 
-    // Copy button
+    // Copy button. Rendered formats are produced by the backend from the
+    // history entry, which also copes with outputs too large for the webview.
     elements.copyBtn.addEventListener('click', async () => {
         const text = elements.outputText.textContent;
         if (text) {
-            await navigator.clipboard.writeText(text);
-            showToast('Copied to clipboard', 'success');
+            const format = elements.copyFormat.value;
+            try {
+                if (format === 'markdown' || !state.outputId) {
+                    await WriteClipboard(text);
+                } else {
+                    await CopyOutput(state.outputId, format);
+                }
+                showToast('Copied to clipboard', 'success');
+            } catch (e) {
+                showToast(`Copy failed: ${e}`, 'error');
+            }
         }
     });
 
@@ -693,14 +1415,19 @@ function setupEventListeners() {
     elements.clearOutputBtn.addEventListener('click', () => {
         elements.outputText.textContent = '';
         state.currentOutput = '';
+        state.outputId = '';
     });
 
     // Paste button
     elements.pasteBtn.addEventListener('click', async () => {
-        const text = await navigator.clipboard.readText();
-        elements.inputText.value = text;
-        showToast('Text pasted', 'success');
-        updateCommandPreview();
+        try {
+            const text = await ReadClipboard();
+            elements.inputText.value = text;
+            showToast('Text pasted', 'success');
+            updateCommandPreview();
+        } catch (e) {
+            showToast(`Paste failed: ${e}`, 'error');
+        }
     });
 
     // Copy Command button
@@ -708,16 +1435,14 @@ function setupEventListeners() {
     if (copyCommandBtn) {
         copyCommandBtn.addEventListener('click', async () => {
             // Re-generate command content to ensure it's up to date
-            const pattern = state.selectedPattern || '[pattern]';
-            const model = state.selectedModel || '[model]';
             let inputPrefix = '';
 
             if (elements.inputText && elements.inputText.value) {
                 inputPrefix = 'echo "..." | ';
             }
 
-            const command = `${inputPrefix}fabric --pattern ${pattern} --model ${model}`;
-            await navigator.clipboard.writeText(command);
+            const command = inputPrefix + fabricCommand();
+            await WriteClipboard(command);
             showToast('Command copied to clipboard', 'success');
         });
     }
@@ -725,6 +1450,8 @@ function setupEventListeners() {
     // Input text change
     elements.inputText.addEventListener('input', () => {
         updateCommandPreview();
+        updateTokenCount();
+        saveDraft();
     });
 
     // Import button
@@ -732,6 +1459,24 @@ function setupEventListeners() {
 
     // Save button
     elements.saveBtn.addEventListener('click', saveOutput);
+    elements.exportPdfBtn.addEventListener('click', exportPdf);
+    elements.exportHtmlBtn.addEventListener('click', exportHtml);
+    elements.obsidianBtn.addEventListener('click', saveToObsidian);
+    elements.dailyNoteBtn.addEventListener('click', appendToDailyNote);
+    elements.outputFileBtn.addEventListener('click', toggleOutputFile);
+    elements.copyCodeBtn.addEventListener('click', async () => {
+        const index = await pickCodeBlock('Copy');
+        if (index >= 0) copyCodeBlock(index);
+    });
+    elements.saveCodeBtn.addEventListener('click', saveCodeBlock);
+    elements.notionBtn.addEventListener('click', exportToNotion);
+    elements.emailBtn.addEventListener('click', emailOutput);
+    elements.webhookBtn.addEventListener('click', sendToWebhook);
+    elements.slackBtn.addEventListener('click', () => postOutput('Slack', SendToSlack));
+    elements.discordBtn.addEventListener('click', () => postOutput('Discord', SendToDiscord));
+    elements.detachBtn.addEventListener('click', detachOutput);
+    elements.speakBtn.addEventListener('click', toggleSpeech);
+    elements.pauseSpeechBtn.addEventListener('click', togglePauseSpeech);
 
     // History navigation
     elements.historyPrevBtn.addEventListener('click', () => navigateHistory(-1));
@@ -747,6 +1492,24 @@ function setupEventListeners() {
 
     elements.settingsModal.querySelector('.modal-backdrop').addEventListener('click', closeSettings);
 
+    // Mini window
+    elements.miniBtn.addEventListener('click', () => ShowMiniWindow());
+    elements.miniExitBtn.addEventListener('click', () => HideMiniWindow());
+    elements.miniRunBtn.addEventListener('click', runMiniPreset);
+
+    // Hotkey popup
+    elements.copyHotkeyBtn.addEventListener('click', async () => {
+        try {
+            await WriteClipboard(elements.hotkeyOutput.textContent);
+            showToast('Copied to clipboard', 'success');
+        } catch (err) {
+            showToast(`Copy failed: ${err}`, 'error');
+        }
+    });
+    elements.doneHotkeyBtn.addEventListener('click', closeHotkeyPopup);
+    elements.closeHotkeyBtn.addEventListener('click', closeHotkeyPopup);
+    elements.hotkeyModal.querySelector('.modal-backdrop').addEventListener('click', closeHotkeyPopup);
+
     elements.testConnectionBtn.addEventListener('click', async () => {
         const result = elements.connectionResult;
         result.textContent = 'Testing...';
@@ -805,6 +1568,12 @@ function setupEventListeners() {
             saveOutput();
         }
 
+        // Ctrl+Shift+1..9 to copy the nth code block of the output
+        if (e.ctrlKey && e.shiftKey && /^Digit[1-9]$/.test(e.code)) {
+            e.preventDefault();
+            copyCodeBlock(Number(e.code.slice(5)) - 1);
+        }
+
         // Ctrl+O to import file
         if (e.ctrlKey && e.key === 'o') {
             e.preventDefault();
@@ -826,6 +1595,7 @@ function setupEventListeners() {
         // Escape to close modals
         if (e.key === 'Escape') {
             closeSettings();
+            closeHotkeyPopup();
         }
     });
 }
@@ -835,6 +1605,50 @@ function closeSettings() {
     elements.connectionResult.textContent = '';
 }
 
+// loadMiniPresets fills the mini window's preset picker, keeping the choice
+async function loadMiniPresets() {
+    const selected = elements.miniPreset.value;
+    try {
+        const presets = await ListPresets();
+        elements.miniPreset.innerHTML = '';
+        if (!presets.length) {
+            elements.miniPreset.innerHTML = '<option disabled>No presets saved</option>';
+            return;
+        }
+        for (const preset of presets) {
+            const option = document.createElement('option');
+            option.value = preset.name;
+            option.textContent = preset.name;
+            elements.miniPreset.appendChild(option);
+        }
+        if (presets.some(p => p.name === selected)) {
+            elements.miniPreset.value = selected;
+        }
+    } catch (e) {
+        showToast(`Failed to load presets: ${e}`, 'error');
+    }
+}
+
+// runMiniPreset streams the chosen preset's result into the output pane
+async function runMiniPreset() {
+    const input = elements.inputText.value.trim();
+    if (!elements.miniPreset.value || !input || state.isProcessing) return;
+
+    state.currentOutput = '';
+    elements.outputText.textContent = '';
+    setProcessingState(true);
+    try {
+        state.streamId = await RunPreset(elements.miniPreset.value, input);
+    } catch (e) {
+        setProcessingState(false);
+        showToast(`Failed to run preset: ${e}`, 'error');
+    }
+}
+
+function closeHotkeyPopup() {
+    elements.hotkeyModal.classList.add('hidden');
+}
+
 // ============================================
 // Start
 // ============================================
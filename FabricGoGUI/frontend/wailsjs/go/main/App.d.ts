@@ -4,34 +4,420 @@ import {main} from '../models';
 
 export function AddHistoryEntry(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
 
+export function AppendToDailyNote(arg1:string):Promise<string>;
+
+export function ApplyUpdate(arg1:boolean):Promise<main.UpdateInfo>;
+
+export function AttachImage(arg1:string):Promise<main.ImageAttachment>;
+
+export function CancelJob(arg1:string):Promise<void>;
+
+export function CancelStream(arg1:string):Promise<void>;
+
+export function CheckFabricInstalled():Promise<main.SetupStep>;
+
+export function CheckForUpdates():Promise<main.UpdateInfo>;
+
 export function CheckHealth():Promise<boolean>;
 
+export function CheckPatternsInstalled():Promise<main.SetupStep>;
+
+export function CheckVendorConfigured():Promise<main.SetupStep>;
+
+export function ChooseOutputFile():Promise<string>;
+
+export function ClearAttachments():Promise<void>;
+
+export function ClearFinishedJobs():Promise<void>;
+
+export function ClearHistory():Promise<void>;
+
+export function ContinueThread(arg1:string,arg2:string):Promise<string>;
+
+export function CopyCodeBlock(arg1:number):Promise<void>;
+
+export function CopyOutput(arg1:string,arg2:string):Promise<void>;
+
+export function CountTokens(arg1:string,arg2:string):Promise<main.TokenCount>;
+
+export function CreateSession(arg1:string):Promise<void>;
+
+export function DeleteContext(arg1:string):Promise<void>;
+
+export function DeleteFeedSubscription(arg1:string):Promise<void>;
+
+export function DeleteFolderWatch(arg1:string):Promise<void>;
+
+export function DeleteHistoryEntry(arg1:string):Promise<void>;
+
+export function DeleteModelInfo(arg1:string):Promise<void>;
+
+export function DeleteOllamaModel(arg1:string):Promise<void>;
+
+export function DeleteOpenAIEndpoint(arg1:string):Promise<void>;
+
+export function DeletePreset(arg1:string):Promise<void>;
+
+export function DeleteProfile(arg1:string):Promise<void>;
+
+export function DeleteSchedule(arg1:string):Promise<void>;
+
+export function DeleteSession(arg1:string):Promise<void>;
+
+export function DiffOutputs(arg1:string,arg2:string):Promise<main.OutputDiff>;
+
+export function DisableHistoryEncryption():Promise<void>;
+
+export function DiscardSession():Promise<void>;
+
+export function DownloadPatterns():Promise<main.SetupStep>;
+
+export function EnableHistoryEncryption(arg1:string,arg2:string):Promise<void>;
+
+export function ExportHistory(arg1:string,arg2:main.HistoryFilter,arg3:string):Promise<string>;
+
+export function ExportPatterns(arg1:Array<string>,arg2:string):Promise<string>;
+
+export function ExportToHTML(arg1:string,arg2:string,arg3:main.HTMLOptions):Promise<string>;
+
+export function ExportToNotion(arg1:string):Promise<string>;
+
+export function ExportToPDF(arg1:string,arg2:string,arg3:main.PDFOptions):Promise<string>;
+
+export function ExtractCodeBlocks(arg1:string):Promise<Array<main.CodeBlock>>;
+
+export function FetchFeed(arg1:string):Promise<main.Feed>;
+
+export function FetchGitHub(arg1:string):Promise<main.GitHubContent>;
+
+export function FrontendReady():Promise<void>;
+
+export function GenerateDiagnostics():Promise<string>;
+
+export function GetAPISettings():Promise<main.APISettings>;
+
+export function GetAPIStatus():Promise<main.APIStatus>;
+
+export function GetActiveProfile():Promise<string>;
+
+export function GetAppLogs(arg1:number):Promise<string>;
+
+export function GetAttachments():Promise<Array<main.ImageAttachment>>;
+
+export function GetAutoSaveSettings():Promise<main.AutoSaveSettings>;
+
 export function GetBaseURL():Promise<string>;
 
-export function GetHistory():Promise<Array<main.HistoryEntry>>;
+export function GetContext(arg1:string):Promise<main.FabricContext>;
+
+export function GetDailyNoteSettings():Promise<main.DailyNoteSettings>;
+
+export function GetFabricVersion():Promise<main.FabricVersionInfo>;
+
+export function GetFavoriteModels():Promise<Array<main.FavoriteModel>>;
+
+export function GetFeedSubscriptions():Promise<Array<main.FeedSubscription>>;
+
+export function GetFolderWatches():Promise<Array<main.FolderWatchStatus>>;
+
+export function GetGitDiff(arg1:string,arg2:string):Promise<string>;
+
+export function GetGitLog(arg1:string,arg2:string):Promise<string>;
+
+export function GetHistory(arg1:string):Promise<Array<main.HistoryEntry>>;
 
 export function GetHistoryCount():Promise<number>;
 
+export function GetHistoryEncryption():Promise<main.HistoryEncryptionStatus>;
+
 export function GetHistoryEntry(arg1:number):Promise<main.HistoryEntry>;
 
+export function GetHistoryTags():Promise<Array<string>>;
+
+export function GetHooks():Promise<Array<main.Hook>>;
+
+export function GetHotkeySettings():Promise<main.HotkeySettings>;
+
+export function GetHotkeyStatus():Promise<main.HotkeyStatus>;
+
+export function GetModelInfo(arg1:string):Promise<main.ModelInfo>;
+
+export function GetModelVisibility():Promise<main.ModelVisibility>;
+
 export function GetModels():Promise<main.ModelsResponse>;
 
-export function GetPatterns():Promise<Array<string>>;
+export function GetNotificationSettings():Promise<main.NotificationSettings>;
+
+export function GetNotionSettings():Promise<main.NotionSettings>;
+
+export function GetObsidianSettings():Promise<main.ObsidianSettings>;
+
+export function GetOpenAIEndpoints():Promise<Array<main.OpenAIEndpoint>>;
+
+export function GetPattern(arg1:string):Promise<main.Pattern>;
+
+export function GetPatternModel(arg1:string):Promise<main.PatternModel>;
+
+export function GetPatternStats(arg1:string):Promise<Array<main.PatternStats>>;
+
+export function GetPatternTags():Promise<Array<main.PatternTag>>;
+
+export function GetPatternVariables(arg1:string):Promise<Array<string>>;
+
+export function GetPatterns():Promise<Array<main.PatternInfo>>;
+
+export function GetPatternsByTag(arg1:string):Promise<Array<main.PatternInfo>>;
+
+export function GetPreset(arg1:string):Promise<main.Preset>;
+
+export function GetQuickModeStatus():Promise<main.QuickModeStatus>;
+
+export function GetSMTPSettings():Promise<main.SMTPSettings>;
+
+export function GetSavedSession():Promise<main.SavedSession>;
+
+export function GetScheduleRuns(arg1:string):Promise<Array<main.ScheduleRun>>;
+
+export function GetSchedules():Promise<Array<main.ScheduleStatus>>;
+
+export function GetSecretsStatus():Promise<main.SecretsStatus>;
+
+export function GetSession(arg1:string):Promise<main.FabricSession>;
+
+export function GetTTSSettings():Promise<main.TTSSettings>;
+
+export function GetThread(arg1:string):Promise<Array<main.HistoryEntry>>;
+
+export function GetTraySettings():Promise<main.TraySettings>;
+
+export function GetTrayStatus():Promise<main.TrayStatus>;
+
+export function GetWebhooks():Promise<Array<main.Webhook>>;
+
+export function HideMiniWindow():Promise<void>;
+
+export function ImportHistory(arg1:string):Promise<number>;
+
+export function ImportPatternBundle(arg1:string):Promise<main.PatternBundleImport>;
+
+export function ImportPatternFromURL(arg1:string,arg2:string):Promise<main.PatternInfo>;
+
+export function InstallFabric():Promise<main.FabricInstall>;
+
+export function IsMiniWindow():Promise<boolean>;
+
+export function IsRecording():Promise<boolean>;
 
 export function IsServerRunning():Promise<boolean>;
 
+export function IsSpeaking():Promise<boolean>;
+
+export function ListContexts():Promise<Array<string>>;
+
+export function ListJobs():Promise<Array<main.Job>>;
+
+export function ListOCRLanguages():Promise<Array<string>>;
+
+export function ListOllamaModels():Promise<Array<main.OllamaModel>>;
+
+export function ListPresets():Promise<Array<main.Preset>>;
+
+export function ListProfiles():Promise<Array<main.ConnectionProfile>>;
+
+export function ListSessions():Promise<Array<string>>;
+
+export function ListStrategies():Promise<Array<main.Strategy>>;
+
+export function ListStreams():Promise<Array<string>>;
+
+export function ListYouTubeVideos(arg1:string,arg2:number):Promise<main.YouTubeListing>;
+
 export function LoadPreferences():Promise<main.Preferences>;
 
+export function MigrateSecrets():Promise<main.SecretsStatus>;
+
+export function OCRImage(arg1:string,arg2:string):Promise<main.OCRResult>;
+
+export function OpenAutoSaveFolder():Promise<void>;
+
+export function OpenFabricInstallPage():Promise<void>;
+
 export function OpenFileDialog():Promise<string>;
 
+export function OpenLogFolder():Promise<void>;
+
+export function OpenOutputWindow(arg1:string):Promise<string>;
+
+export function PauseSpeech():Promise<void>;
+
+export function PreviewPrompt(arg1:string,arg2:string,arg3:Record<string, string>):Promise<main.PromptPreview>;
+
+export function ProbeVendors():Promise<Array<main.VendorStatus>>;
+
+export function ProcessPodcast(arg1:main.PodcastRequest):Promise<main.PodcastResult>;
+
+export function PruneHistory():Promise<number>;
+
+export function PullOllamaModel(arg1:string):Promise<void>;
+
+export function ReadClipboard():Promise<string>;
+
+export function RefreshCatalog():Promise<main.CatalogUpdate>;
+
+export function RegenerateAPIToken():Promise<string>;
+
+export function RemoveAttachment(arg1:number):Promise<void>;
+
+export function RerunHistoryEntry(arg1:string,arg2:main.RerunOverrides):Promise<string>;
+
+export function ResetPatternStats():Promise<void>;
+
+export function RestoreSession():Promise<main.SavedSession>;
+
+export function ResumeChat(arg1:string):Promise<void>;
+
+export function ResumeSpeech():Promise<void>;
+
+export function RunBatch(arg1:string,arg2:string,arg3:string,arg4:string):Promise<main.BatchSummary>;
+
+export function RunFabricSetup():Promise<void>;
+
+export function RunFeedDigest(arg1:Array<string>):Promise<main.FeedDigest>;
+
+export function RunHook(arg1:string,arg2:string):Promise<main.HookResult>;
+
+export function RunPreset(arg1:string,arg2:string):Promise<string>;
+
+export function RunScheduleNow(arg1:string):Promise<void>;
+
+export function RunSetupChecks():Promise<Array<main.SetupStep>>;
+
+export function RunYouTubeBatch(arg1:main.YouTubeBatchRequest):Promise<main.YouTubeBatchSummary>;
+
+export function SaveAPISettings(arg1:main.APISettings):Promise<main.APISettings>;
+
+export function SaveAutoSaveSettings(arg1:main.AutoSaveSettings):Promise<void>;
+
+export function SaveCodeBlock(arg1:number,arg2:string):Promise<string>;
+
+export function SaveContext(arg1:string,arg2:string):Promise<void>;
+
+export function SaveDailyNoteSettings(arg1:main.DailyNoteSettings):Promise<void>;
+
+export function SaveDraft(arg1:main.Draft):Promise<void>;
+
+export function SaveFeedSubscription(arg1:main.FeedSubscription):Promise<main.FeedSubscription>;
+
 export function SaveFileDialog(arg1:string):Promise<string>;
 
+export function SaveFolderWatch(arg1:main.FolderWatch):Promise<main.FolderWatch>;
+
+export function SaveHooks(arg1:Array<main.Hook>):Promise<void>;
+
+export function SaveHotkeySettings(arg1:main.HotkeySettings):Promise<void>;
+
+export function SaveModelInfo(arg1:main.ModelInfo):Promise<void>;
+
+export function SaveNotificationSettings(arg1:main.NotificationSettings):Promise<void>;
+
+export function SaveNotionSettings(arg1:main.NotionSettings):Promise<void>;
+
+export function SaveObsidianSettings(arg1:main.ObsidianSettings):Promise<void>;
+
+export function SaveOpenAIEndpoint(arg1:main.OpenAIEndpoint):Promise<void>;
+
+export function SavePattern(arg1:string,arg2:string):Promise<main.PatternInfo>;
+
 export function SavePreferences(arg1:main.Preferences):Promise<void>;
 
-export function SendChat(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
+export function SavePreset(arg1:main.Preset):Promise<void>;
+
+export function SaveProfile(arg1:main.ConnectionProfile):Promise<void>;
+
+export function SaveSMTPSettings(arg1:main.SMTPSettings):Promise<void>;
+
+export function SaveSchedule(arg1:main.Schedule):Promise<main.Schedule>;
+
+export function SaveTTSSettings(arg1:main.TTSSettings):Promise<void>;
+
+export function SaveToObsidian(arg1:string,arg2:string,arg3:string):Promise<string>;
+
+export function SaveTraySettings(arg1:main.TraySettings):Promise<void>;
+
+export function SaveWebhooks(arg1:Array<main.Webhook>):Promise<void>;
+
+export function SearchPatterns(arg1:string):Promise<Array<main.PatternInfo>>;
+
+export function SelectAutoSaveDir():Promise<string>;
+
+export function SelectObsidianVault():Promise<string>;
+
+export function SendChat(arg1:string,arg2:string,arg3:string,arg4:string,arg5:main.ChatExtras):Promise<void>;
+
+export function SendOutputByEmail(arg1:string,arg2:string,arg3:string):Promise<string>;
+
+export function SendToDiscord(arg1:string):Promise<void>;
+
+export function SendToSlack(arg1:string):Promise<void>;
+
+export function SendToWebhook(arg1:string,arg2:string):Promise<void>;
 
 export function SetBaseURL(arg1:string):Promise<void>;
 
+export function SetFavoriteModel(arg1:string,arg2:string,arg3:boolean):Promise<void>;
+
+export function SetGitHubToken(arg1:string):Promise<void>;
+
+export function SetModelVisibility(arg1:string,arg2:string,arg3:boolean):Promise<void>;
+
+export function SetPatternModel(arg1:string,arg2:string,arg3:string):Promise<void>;
+
+export function SetQuickMode(arg1:boolean,arg2:string,arg3:string,arg4:string):Promise<void>;
+
+export function SetShowHiddenModels(arg1:boolean):Promise<void>;
+
+export function SetTags(arg1:string,arg2:Array<string>):Promise<void>;
+
+export function ShowMiniWindow():Promise<void>;
+
+export function SpeakOutput(arg1:string):Promise<void>;
+
+export function StartChat(arg1:string,arg2:string,arg3:string,arg4:string,arg5:main.ChatExtras):Promise<string>;
+
+export function StartClipboardWatcher():Promise<void>;
+
+export function StartRecording():Promise<void>;
+
 export function StartServer():Promise<void>;
 
+export function StopClipboardWatcher():Promise<void>;
+
+export function StopRecording():Promise<string>;
+
 export function StopServer():Promise<void>;
+
+export function StopSpeech():Promise<void>;
+
+export function SuggestPatterns(arg1:string,arg2:number):Promise<Array<main.PatternSuggestion>>;
+
+export function SwitchProfile(arg1:string):Promise<main.ProfileSwitch>;
+
+export function TestNotification():Promise<void>;
+
+export function TestOpenAIEndpoint(arg1:main.OpenAIEndpoint):Promise<Array<string>>;
+
+export function ToggleMiniWindow():Promise<void>;
+
+export function TogglePin(arg1:string):Promise<boolean>;
+
+export function TranscribeAudio(arg1:string):Promise<string>;
+
+export function UnlockHistory(arg1:string):Promise<void>;
+
+export function UpdateFabric():Promise<main.FabricInstall>;
+
+export function UpdateHistoryEntryNote(arg1:string,arg2:string):Promise<void>;
+
+export function UpdatePatterns():Promise<main.PatternUpdate>;
+
+export function WriteClipboard(arg1:string):Promise<void>;
@@ -6,66 +6,838 @@ export function AddHistoryEntry(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['AddHistoryEntry'](arg1, arg2, arg3, arg4);
 }
 
+export function AppendToDailyNote(arg1) {
+  return window['go']['main']['App']['AppendToDailyNote'](arg1);
+}
+
+export function ApplyUpdate(arg1) {
+  return window['go']['main']['App']['ApplyUpdate'](arg1);
+}
+
+export function AttachImage(arg1) {
+  return window['go']['main']['App']['AttachImage'](arg1);
+}
+
+export function CancelJob(arg1) {
+  return window['go']['main']['App']['CancelJob'](arg1);
+}
+
+export function CancelStream(arg1) {
+  return window['go']['main']['App']['CancelStream'](arg1);
+}
+
+export function CheckFabricInstalled() {
+  return window['go']['main']['App']['CheckFabricInstalled']();
+}
+
+export function CheckForUpdates() {
+  return window['go']['main']['App']['CheckForUpdates']();
+}
+
 export function CheckHealth() {
   return window['go']['main']['App']['CheckHealth']();
 }
 
+export function CheckPatternsInstalled() {
+  return window['go']['main']['App']['CheckPatternsInstalled']();
+}
+
+export function CheckVendorConfigured() {
+  return window['go']['main']['App']['CheckVendorConfigured']();
+}
+
+export function ChooseOutputFile() {
+  return window['go']['main']['App']['ChooseOutputFile']();
+}
+
+export function ClearAttachments() {
+  return window['go']['main']['App']['ClearAttachments']();
+}
+
+export function ClearFinishedJobs() {
+  return window['go']['main']['App']['ClearFinishedJobs']();
+}
+
+export function ClearHistory() {
+  return window['go']['main']['App']['ClearHistory']();
+}
+
+export function ContinueThread(arg1, arg2) {
+  return window['go']['main']['App']['ContinueThread'](arg1, arg2);
+}
+
+export function CopyCodeBlock(arg1) {
+  return window['go']['main']['App']['CopyCodeBlock'](arg1);
+}
+
+export function CopyOutput(arg1, arg2) {
+  return window['go']['main']['App']['CopyOutput'](arg1, arg2);
+}
+
+export function CountTokens(arg1, arg2) {
+  return window['go']['main']['App']['CountTokens'](arg1, arg2);
+}
+
+export function CreateSession(arg1) {
+  return window['go']['main']['App']['CreateSession'](arg1);
+}
+
+export function DeleteContext(arg1) {
+  return window['go']['main']['App']['DeleteContext'](arg1);
+}
+
+export function DeleteFeedSubscription(arg1) {
+  return window['go']['main']['App']['DeleteFeedSubscription'](arg1);
+}
+
+export function DeleteFolderWatch(arg1) {
+  return window['go']['main']['App']['DeleteFolderWatch'](arg1);
+}
+
+export function DeleteHistoryEntry(arg1) {
+  return window['go']['main']['App']['DeleteHistoryEntry'](arg1);
+}
+
+export function DeleteModelInfo(arg1) {
+  return window['go']['main']['App']['DeleteModelInfo'](arg1);
+}
+
+export function DeleteOllamaModel(arg1) {
+  return window['go']['main']['App']['DeleteOllamaModel'](arg1);
+}
+
+export function DeleteOpenAIEndpoint(arg1) {
+  return window['go']['main']['App']['DeleteOpenAIEndpoint'](arg1);
+}
+
+export function DeletePreset(arg1) {
+  return window['go']['main']['App']['DeletePreset'](arg1);
+}
+
+export function DeleteProfile(arg1) {
+  return window['go']['main']['App']['DeleteProfile'](arg1);
+}
+
+export function DeleteSchedule(arg1) {
+  return window['go']['main']['App']['DeleteSchedule'](arg1);
+}
+
+export function DeleteSession(arg1) {
+  return window['go']['main']['App']['DeleteSession'](arg1);
+}
+
+export function DiffOutputs(arg1, arg2) {
+  return window['go']['main']['App']['DiffOutputs'](arg1, arg2);
+}
+
+export function DisableHistoryEncryption() {
+  return window['go']['main']['App']['DisableHistoryEncryption']();
+}
+
+export function DiscardSession() {
+  return window['go']['main']['App']['DiscardSession']();
+}
+
+export function DownloadPatterns() {
+  return window['go']['main']['App']['DownloadPatterns']();
+}
+
+export function EnableHistoryEncryption(arg1, arg2) {
+  return window['go']['main']['App']['EnableHistoryEncryption'](arg1, arg2);
+}
+
+export function ExportHistory(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportHistory'](arg1, arg2, arg3);
+}
+
+export function ExportPatterns(arg1, arg2) {
+  return window['go']['main']['App']['ExportPatterns'](arg1, arg2);
+}
+
+export function ExportToHTML(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportToHTML'](arg1, arg2, arg3);
+}
+
+export function ExportToNotion(arg1) {
+  return window['go']['main']['App']['ExportToNotion'](arg1);
+}
+
+export function ExportToPDF(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ExportToPDF'](arg1, arg2, arg3);
+}
+
+export function ExtractCodeBlocks(arg1) {
+  return window['go']['main']['App']['ExtractCodeBlocks'](arg1);
+}
+
+export function FetchFeed(arg1) {
+  return window['go']['main']['App']['FetchFeed'](arg1);
+}
+
+export function FetchGitHub(arg1) {
+  return window['go']['main']['App']['FetchGitHub'](arg1);
+}
+
+export function FrontendReady() {
+  return window['go']['main']['App']['FrontendReady']();
+}
+
+export function GenerateDiagnostics() {
+  return window['go']['main']['App']['GenerateDiagnostics']();
+}
+
+export function GetAPISettings() {
+  return window['go']['main']['App']['GetAPISettings']();
+}
+
+export function GetAPIStatus() {
+  return window['go']['main']['App']['GetAPIStatus']();
+}
+
+export function GetActiveProfile() {
+  return window['go']['main']['App']['GetActiveProfile']();
+}
+
+export function GetAppLogs(arg1) {
+  return window['go']['main']['App']['GetAppLogs'](arg1);
+}
+
+export function GetAttachments() {
+  return window['go']['main']['App']['GetAttachments']();
+}
+
+export function GetAutoSaveSettings() {
+  return window['go']['main']['App']['GetAutoSaveSettings']();
+}
+
 export function GetBaseURL() {
   return window['go']['main']['App']['GetBaseURL']();
 }
 
-export function GetHistory() {
-  return window['go']['main']['App']['GetHistory']();
+export function GetContext(arg1) {
+  return window['go']['main']['App']['GetContext'](arg1);
+}
+
+export function GetDailyNoteSettings() {
+  return window['go']['main']['App']['GetDailyNoteSettings']();
+}
+
+export function GetFabricVersion() {
+  return window['go']['main']['App']['GetFabricVersion']();
+}
+
+export function GetFavoriteModels() {
+  return window['go']['main']['App']['GetFavoriteModels']();
+}
+
+export function GetFeedSubscriptions() {
+  return window['go']['main']['App']['GetFeedSubscriptions']();
+}
+
+export function GetFolderWatches() {
+  return window['go']['main']['App']['GetFolderWatches']();
+}
+
+export function GetGitDiff(arg1, arg2) {
+  return window['go']['main']['App']['GetGitDiff'](arg1, arg2);
+}
+
+export function GetGitLog(arg1, arg2) {
+  return window['go']['main']['App']['GetGitLog'](arg1, arg2);
+}
+
+export function GetHistory(arg1) {
+  return window['go']['main']['App']['GetHistory'](arg1);
 }
 
 export function GetHistoryCount() {
   return window['go']['main']['App']['GetHistoryCount']();
 }
 
+export function GetHistoryEncryption() {
+  return window['go']['main']['App']['GetHistoryEncryption']();
+}
+
 export function GetHistoryEntry(arg1) {
   return window['go']['main']['App']['GetHistoryEntry'](arg1);
 }
 
+export function GetHistoryTags() {
+  return window['go']['main']['App']['GetHistoryTags']();
+}
+
+export function GetHooks() {
+  return window['go']['main']['App']['GetHooks']();
+}
+
+export function GetHotkeySettings() {
+  return window['go']['main']['App']['GetHotkeySettings']();
+}
+
+export function GetHotkeyStatus() {
+  return window['go']['main']['App']['GetHotkeyStatus']();
+}
+
+export function GetModelInfo(arg1) {
+  return window['go']['main']['App']['GetModelInfo'](arg1);
+}
+
+export function GetModelVisibility() {
+  return window['go']['main']['App']['GetModelVisibility']();
+}
+
 export function GetModels() {
   return window['go']['main']['App']['GetModels']();
 }
 
+export function GetNotificationSettings() {
+  return window['go']['main']['App']['GetNotificationSettings']();
+}
+
+export function GetNotionSettings() {
+  return window['go']['main']['App']['GetNotionSettings']();
+}
+
+export function GetObsidianSettings() {
+  return window['go']['main']['App']['GetObsidianSettings']();
+}
+
+export function GetOpenAIEndpoints() {
+  return window['go']['main']['App']['GetOpenAIEndpoints']();
+}
+
+export function GetPattern(arg1) {
+  return window['go']['main']['App']['GetPattern'](arg1);
+}
+
+export function GetPatternModel(arg1) {
+  return window['go']['main']['App']['GetPatternModel'](arg1);
+}
+
+export function GetPatternStats(arg1) {
+  return window['go']['main']['App']['GetPatternStats'](arg1);
+}
+
+export function GetPatternTags() {
+  return window['go']['main']['App']['GetPatternTags']();
+}
+
+export function GetPatternVariables(arg1) {
+  return window['go']['main']['App']['GetPatternVariables'](arg1);
+}
+
 export function GetPatterns() {
   return window['go']['main']['App']['GetPatterns']();
 }
 
+export function GetPatternsByTag(arg1) {
+  return window['go']['main']['App']['GetPatternsByTag'](arg1);
+}
+
+export function GetPreset(arg1) {
+  return window['go']['main']['App']['GetPreset'](arg1);
+}
+
+export function GetQuickModeStatus() {
+  return window['go']['main']['App']['GetQuickModeStatus']();
+}
+
+export function GetSMTPSettings() {
+  return window['go']['main']['App']['GetSMTPSettings']();
+}
+
+export function GetSavedSession() {
+  return window['go']['main']['App']['GetSavedSession']();
+}
+
+export function GetScheduleRuns(arg1) {
+  return window['go']['main']['App']['GetScheduleRuns'](arg1);
+}
+
+export function GetSchedules() {
+  return window['go']['main']['App']['GetSchedules']();
+}
+
+export function GetSecretsStatus() {
+  return window['go']['main']['App']['GetSecretsStatus']();
+}
+
+export function GetSession(arg1) {
+  return window['go']['main']['App']['GetSession'](arg1);
+}
+
+export function GetTTSSettings() {
+  return window['go']['main']['App']['GetTTSSettings']();
+}
+
+export function GetThread(arg1) {
+  return window['go']['main']['App']['GetThread'](arg1);
+}
+
+export function GetTraySettings() {
+  return window['go']['main']['App']['GetTraySettings']();
+}
+
+export function GetTrayStatus() {
+  return window['go']['main']['App']['GetTrayStatus']();
+}
+
+export function GetWebhooks() {
+  return window['go']['main']['App']['GetWebhooks']();
+}
+
+export function HideMiniWindow() {
+  return window['go']['main']['App']['HideMiniWindow']();
+}
+
+export function ImportHistory(arg1) {
+  return window['go']['main']['App']['ImportHistory'](arg1);
+}
+
+export function ImportPatternBundle(arg1) {
+  return window['go']['main']['App']['ImportPatternBundle'](arg1);
+}
+
+export function ImportPatternFromURL(arg1, arg2) {
+  return window['go']['main']['App']['ImportPatternFromURL'](arg1, arg2);
+}
+
+export function InstallFabric() {
+  return window['go']['main']['App']['InstallFabric']();
+}
+
+export function IsMiniWindow() {
+  return window['go']['main']['App']['IsMiniWindow']();
+}
+
+export function IsRecording() {
+  return window['go']['main']['App']['IsRecording']();
+}
+
 export function IsServerRunning() {
   return window['go']['main']['App']['IsServerRunning']();
 }
 
+export function IsSpeaking() {
+  return window['go']['main']['App']['IsSpeaking']();
+}
+
+export function ListContexts() {
+  return window['go']['main']['App']['ListContexts']();
+}
+
+export function ListJobs() {
+  return window['go']['main']['App']['ListJobs']();
+}
+
+export function ListOCRLanguages() {
+  return window['go']['main']['App']['ListOCRLanguages']();
+}
+
+export function ListOllamaModels() {
+  return window['go']['main']['App']['ListOllamaModels']();
+}
+
+export function ListPresets() {
+  return window['go']['main']['App']['ListPresets']();
+}
+
+export function ListProfiles() {
+  return window['go']['main']['App']['ListProfiles']();
+}
+
+export function ListSessions() {
+  return window['go']['main']['App']['ListSessions']();
+}
+
+export function ListStrategies() {
+  return window['go']['main']['App']['ListStrategies']();
+}
+
+export function ListStreams() {
+  return window['go']['main']['App']['ListStreams']();
+}
+
+export function ListYouTubeVideos(arg1, arg2) {
+  return window['go']['main']['App']['ListYouTubeVideos'](arg1, arg2);
+}
+
 export function LoadPreferences() {
   return window['go']['main']['App']['LoadPreferences']();
 }
 
+export function MigrateSecrets() {
+  return window['go']['main']['App']['MigrateSecrets']();
+}
+
+export function OCRImage(arg1, arg2) {
+  return window['go']['main']['App']['OCRImage'](arg1, arg2);
+}
+
+export function OpenAutoSaveFolder() {
+  return window['go']['main']['App']['OpenAutoSaveFolder']();
+}
+
+export function OpenFabricInstallPage() {
+  return window['go']['main']['App']['OpenFabricInstallPage']();
+}
+
 export function OpenFileDialog() {
   return window['go']['main']['App']['OpenFileDialog']();
 }
 
+export function OpenLogFolder() {
+  return window['go']['main']['App']['OpenLogFolder']();
+}
+
+export function OpenOutputWindow(arg1) {
+  return window['go']['main']['App']['OpenOutputWindow'](arg1);
+}
+
+export function PauseSpeech() {
+  return window['go']['main']['App']['PauseSpeech']();
+}
+
+export function PreviewPrompt(arg1, arg2, arg3) {
+  return window['go']['main']['App']['PreviewPrompt'](arg1, arg2, arg3);
+}
+
+export function ProbeVendors() {
+  return window['go']['main']['App']['ProbeVendors']();
+}
+
+export function ProcessPodcast(arg1) {
+  return window['go']['main']['App']['ProcessPodcast'](arg1);
+}
+
+export function PruneHistory() {
+  return window['go']['main']['App']['PruneHistory']();
+}
+
+export function PullOllamaModel(arg1) {
+  return window['go']['main']['App']['PullOllamaModel'](arg1);
+}
+
+export function ReadClipboard() {
+  return window['go']['main']['App']['ReadClipboard']();
+}
+
+export function RefreshCatalog() {
+  return window['go']['main']['App']['RefreshCatalog']();
+}
+
+export function RegenerateAPIToken() {
+  return window['go']['main']['App']['RegenerateAPIToken']();
+}
+
+export function RemoveAttachment(arg1) {
+  return window['go']['main']['App']['RemoveAttachment'](arg1);
+}
+
+export function RerunHistoryEntry(arg1, arg2) {
+  return window['go']['main']['App']['RerunHistoryEntry'](arg1, arg2);
+}
+
+export function ResetPatternStats() {
+  return window['go']['main']['App']['ResetPatternStats']();
+}
+
+export function RestoreSession() {
+  return window['go']['main']['App']['RestoreSession']();
+}
+
+export function ResumeChat(arg1) {
+  return window['go']['main']['App']['ResumeChat'](arg1);
+}
+
+export function ResumeSpeech() {
+  return window['go']['main']['App']['ResumeSpeech']();
+}
+
+export function RunBatch(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['RunBatch'](arg1, arg2, arg3, arg4);
+}
+
+export function RunFabricSetup() {
+  return window['go']['main']['App']['RunFabricSetup']();
+}
+
+export function RunFeedDigest(arg1) {
+  return window['go']['main']['App']['RunFeedDigest'](arg1);
+}
+
+export function RunHook(arg1, arg2) {
+  return window['go']['main']['App']['RunHook'](arg1, arg2);
+}
+
+export function RunPreset(arg1, arg2) {
+  return window['go']['main']['App']['RunPreset'](arg1, arg2);
+}
+
+export function RunScheduleNow(arg1) {
+  return window['go']['main']['App']['RunScheduleNow'](arg1);
+}
+
+export function RunSetupChecks() {
+  return window['go']['main']['App']['RunSetupChecks']();
+}
+
+export function RunYouTubeBatch(arg1) {
+  return window['go']['main']['App']['RunYouTubeBatch'](arg1);
+}
+
+export function SaveAPISettings(arg1) {
+  return window['go']['main']['App']['SaveAPISettings'](arg1);
+}
+
+export function SaveAutoSaveSettings(arg1) {
+  return window['go']['main']['App']['SaveAutoSaveSettings'](arg1);
+}
+
+export function SaveCodeBlock(arg1, arg2) {
+  return window['go']['main']['App']['SaveCodeBlock'](arg1, arg2);
+}
+
+export function SaveContext(arg1, arg2) {
+  return window['go']['main']['App']['SaveContext'](arg1, arg2);
+}
+
+export function SaveDailyNoteSettings(arg1) {
+  return window['go']['main']['App']['SaveDailyNoteSettings'](arg1);
+}
+
+export function SaveDraft(arg1) {
+  return window['go']['main']['App']['SaveDraft'](arg1);
+}
+
+export function SaveFeedSubscription(arg1) {
+  return window['go']['main']['App']['SaveFeedSubscription'](arg1);
+}
+
 export function SaveFileDialog(arg1) {
   return window['go']['main']['App']['SaveFileDialog'](arg1);
 }
 
+export function SaveFolderWatch(arg1) {
+  return window['go']['main']['App']['SaveFolderWatch'](arg1);
+}
+
+export function SaveHooks(arg1) {
+  return window['go']['main']['App']['SaveHooks'](arg1);
+}
+
+export function SaveHotkeySettings(arg1) {
+  return window['go']['main']['App']['SaveHotkeySettings'](arg1);
+}
+
+export function SaveModelInfo(arg1) {
+  return window['go']['main']['App']['SaveModelInfo'](arg1);
+}
+
+export function SaveNotificationSettings(arg1) {
+  return window['go']['main']['App']['SaveNotificationSettings'](arg1);
+}
+
+export function SaveNotionSettings(arg1) {
+  return window['go']['main']['App']['SaveNotionSettings'](arg1);
+}
+
+export function SaveObsidianSettings(arg1) {
+  return window['go']['main']['App']['SaveObsidianSettings'](arg1);
+}
+
+export function SaveOpenAIEndpoint(arg1) {
+  return window['go']['main']['App']['SaveOpenAIEndpoint'](arg1);
+}
+
+export function SavePattern(arg1, arg2) {
+  return window['go']['main']['App']['SavePattern'](arg1, arg2);
+}
+
 export function SavePreferences(arg1) {
   return window['go']['main']['App']['SavePreferences'](arg1);
 }
 
-export function SendChat(arg1, arg2, arg3, arg4) {
-  return window['go']['main']['App']['SendChat'](arg1, arg2, arg3, arg4);
+export function SavePreset(arg1) {
+  return window['go']['main']['App']['SavePreset'](arg1);
+}
+
+export function SaveProfile(arg1) {
+  return window['go']['main']['App']['SaveProfile'](arg1);
+}
+
+export function SaveSMTPSettings(arg1) {
+  return window['go']['main']['App']['SaveSMTPSettings'](arg1);
+}
+
+export function SaveSchedule(arg1) {
+  return window['go']['main']['App']['SaveSchedule'](arg1);
+}
+
+export function SaveTTSSettings(arg1) {
+  return window['go']['main']['App']['SaveTTSSettings'](arg1);
+}
+
+export function SaveToObsidian(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SaveToObsidian'](arg1, arg2, arg3);
+}
+
+export function SaveTraySettings(arg1) {
+  return window['go']['main']['App']['SaveTraySettings'](arg1);
+}
+
+export function SaveWebhooks(arg1) {
+  return window['go']['main']['App']['SaveWebhooks'](arg1);
+}
+
+export function SearchPatterns(arg1) {
+  return window['go']['main']['App']['SearchPatterns'](arg1);
+}
+
+export function SelectAutoSaveDir() {
+  return window['go']['main']['App']['SelectAutoSaveDir']();
+}
+
+export function SelectObsidianVault() {
+  return window['go']['main']['App']['SelectObsidianVault']();
+}
+
+export function SendChat(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['SendChat'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function SendOutputByEmail(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SendOutputByEmail'](arg1, arg2, arg3);
+}
+
+export function SendToDiscord(arg1) {
+  return window['go']['main']['App']['SendToDiscord'](arg1);
+}
+
+export function SendToSlack(arg1) {
+  return window['go']['main']['App']['SendToSlack'](arg1);
+}
+
+export function SendToWebhook(arg1, arg2) {
+  return window['go']['main']['App']['SendToWebhook'](arg1, arg2);
 }
 
 export function SetBaseURL(arg1) {
   return window['go']['main']['App']['SetBaseURL'](arg1);
 }
 
+export function SetFavoriteModel(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetFavoriteModel'](arg1, arg2, arg3);
+}
+
+export function SetGitHubToken(arg1) {
+  return window['go']['main']['App']['SetGitHubToken'](arg1);
+}
+
+export function SetModelVisibility(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetModelVisibility'](arg1, arg2, arg3);
+}
+
+export function SetPatternModel(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetPatternModel'](arg1, arg2, arg3);
+}
+
+export function SetQuickMode(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SetQuickMode'](arg1, arg2, arg3, arg4);
+}
+
+export function SetShowHiddenModels(arg1) {
+  return window['go']['main']['App']['SetShowHiddenModels'](arg1);
+}
+
+export function SetTags(arg1, arg2) {
+  return window['go']['main']['App']['SetTags'](arg1, arg2);
+}
+
+export function ShowMiniWindow() {
+  return window['go']['main']['App']['ShowMiniWindow']();
+}
+
+export function SpeakOutput(arg1) {
+  return window['go']['main']['App']['SpeakOutput'](arg1);
+}
+
+export function StartChat(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['StartChat'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function StartClipboardWatcher() {
+  return window['go']['main']['App']['StartClipboardWatcher']();
+}
+
+export function StartRecording() {
+  return window['go']['main']['App']['StartRecording']();
+}
+
 export function StartServer() {
   return window['go']['main']['App']['StartServer']();
 }
 
+export function StopClipboardWatcher() {
+  return window['go']['main']['App']['StopClipboardWatcher']();
+}
+
+export function StopRecording() {
+  return window['go']['main']['App']['StopRecording']();
+}
+
 export function StopServer() {
   return window['go']['main']['App']['StopServer']();
 }
+
+export function StopSpeech() {
+  return window['go']['main']['App']['StopSpeech']();
+}
+
+export function SuggestPatterns(arg1, arg2) {
+  return window['go']['main']['App']['SuggestPatterns'](arg1, arg2);
+}
+
+export function SwitchProfile(arg1) {
+  return window['go']['main']['App']['SwitchProfile'](arg1);
+}
+
+export function TestNotification() {
+  return window['go']['main']['App']['TestNotification']();
+}
+
+export function TestOpenAIEndpoint(arg1) {
+  return window['go']['main']['App']['TestOpenAIEndpoint'](arg1);
+}
+
+export function ToggleMiniWindow() {
+  return window['go']['main']['App']['ToggleMiniWindow']();
+}
+
+export function TogglePin(arg1) {
+  return window['go']['main']['App']['TogglePin'](arg1);
+}
+
+export function TranscribeAudio(arg1) {
+  return window['go']['main']['App']['TranscribeAudio'](arg1);
+}
+
+export function UnlockHistory(arg1) {
+  return window['go']['main']['App']['UnlockHistory'](arg1);
+}
+
+export function UpdateFabric() {
+  return window['go']['main']['App']['UpdateFabric']();
+}
+
+export function UpdateHistoryEntryNote(arg1, arg2) {
+  return window['go']['main']['App']['UpdateHistoryEntryNote'](arg1, arg2);
+}
+
+export function UpdatePatterns() {
+  return window['go']['main']['App']['UpdatePatterns']();
+}
+
+export function WriteClipboard(arg1) {
+  return window['go']['main']['App']['WriteClipboard'](arg1);
+}
@@ -1,37 +1,1498 @@
 export namespace main {
 	
+	export class APISettings {
+	    enabled: boolean;
+	    port: number;
+	    token: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new APISettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.port = source["port"];
+	        this.token = source["token"];
+	    }
+	}
+	export class APIStatus {
+	    running: boolean;
+	    url?: string;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new APIStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.running = source["running"];
+	        this.url = source["url"];
+	        this.error = source["error"];
+	    }
+	}
+	export class AutoSaveSettings {
+	    enabled: boolean;
+	    dir: string;
+	    template: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AutoSaveSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.dir = source["dir"];
+	        this.template = source["template"];
+	    }
+	}
+	export class BatchSummary {
+	    total: number;
+	    succeeded: number;
+	    failed: number;
+	    outputDir: string;
+	    errors: string[];
+	    durationMs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BatchSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.total = source["total"];
+	        this.succeeded = source["succeeded"];
+	        this.failed = source["failed"];
+	        this.outputDir = source["outputDir"];
+	        this.errors = source["errors"];
+	        this.durationMs = source["durationMs"];
+	    }
+	}
+	export class CatalogUpdate {
+	    patterns: boolean;
+	    models: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new CatalogUpdate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.patterns = source["patterns"];
+	        this.models = source["models"];
+	    }
+	}
+	export class ChatExtras {
+	    variables?: Record<string, string>;
+	    context?: string;
+	    strategy?: string;
+	    session?: string;
+	    systemPrompt?: string;
+	    systemMode?: string;
+	    chunk?: boolean;
+	    outputFile?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ChatExtras(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.variables = source["variables"];
+	        this.context = source["context"];
+	        this.strategy = source["strategy"];
+	        this.session = source["session"];
+	        this.systemPrompt = source["systemPrompt"];
+	        this.systemMode = source["systemMode"];
+	        this.chunk = source["chunk"];
+	        this.outputFile = source["outputFile"];
+	    }
+	}
+	export class ChatOptions {
+	    temperature?: number;
+	    topP?: number;
+	    presencePenalty?: number;
+	    frequencyPenalty?: number;
+	    seed?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ChatOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.temperature = source["temperature"];
+	        this.topP = source["topP"];
+	        this.presencePenalty = source["presencePenalty"];
+	        this.frequencyPenalty = source["frequencyPenalty"];
+	        this.seed = source["seed"];
+	    }
+	}
+	export class CodeBlock {
+	    index: number;
+	    language: string;
+	    code: string;
+	    line: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CodeBlock(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.index = source["index"];
+	        this.language = source["language"];
+	        this.code = source["code"];
+	        this.line = source["line"];
+	    }
+	}
+	export class TimeoutOptions {
+	    dialSeconds?: number;
+	    tlsHandshakeSeconds?: number;
+	    responseHeaderSeconds?: number;
+	    streamIdleSeconds?: number;
+	    keepAliveSeconds?: number;
+	    idleConnSeconds?: number;
+	    maxIdleConns?: number;
+	    maxConnsPerHost?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TimeoutOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dialSeconds = source["dialSeconds"];
+	        this.tlsHandshakeSeconds = source["tlsHandshakeSeconds"];
+	        this.responseHeaderSeconds = source["responseHeaderSeconds"];
+	        this.streamIdleSeconds = source["streamIdleSeconds"];
+	        this.keepAliveSeconds = source["keepAliveSeconds"];
+	        this.idleConnSeconds = source["idleConnSeconds"];
+	        this.maxIdleConns = source["maxIdleConns"];
+	        this.maxConnsPerHost = source["maxConnsPerHost"];
+	    }
+	}
+	export class TLSOptions {
+	    caFile?: string;
+	    certFile?: string;
+	    keyFile?: string;
+	    insecureSkipVerify?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new TLSOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.caFile = source["caFile"];
+	        this.certFile = source["certFile"];
+	        this.keyFile = source["keyFile"];
+	        this.insecureSkipVerify = source["insecureSkipVerify"];
+	    }
+	}
+	export class ConnectionProfile {
+	    name: string;
+	    baseUrl: string;
+	    apiKey?: string;
+	    tls: TLSOptions;
+	    timeouts: TimeoutOptions;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConnectionProfile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.baseUrl = source["baseUrl"];
+	        this.apiKey = source["apiKey"];
+	        this.tls = this.convertValues(source["tls"], TLSOptions);
+	        this.timeouts = this.convertValues(source["timeouts"], TimeoutOptions);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DailyNoteSettings {
+	    folder: string;
+	    path: string;
+	    heading: string;
+	    patterns: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DailyNoteSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.folder = source["folder"];
+	        this.path = source["path"];
+	        this.heading = source["heading"];
+	        this.patterns = source["patterns"];
+	    }
+	}
+	export class DiffSpan {
+	    op: string;
+	    text: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiffSpan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.op = source["op"];
+	        this.text = source["text"];
+	    }
+	}
+	export class DiffLine {
+	    op: string;
+	    text: string;
+	    lineA?: number;
+	    lineB?: number;
+	    words?: DiffSpan[];
+	
+	    static createFrom(source: any = {}) {
+	        return new DiffLine(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.op = source["op"];
+	        this.text = source["text"];
+	        this.lineA = source["lineA"];
+	        this.lineB = source["lineB"];
+	        this.words = this.convertValues(source["words"], DiffSpan);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class Draft {
+	    input: string;
+	    pattern?: string;
+	    vendor?: string;
+	    model?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Draft(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.input = source["input"];
+	        this.pattern = source["pattern"];
+	        this.vendor = source["vendor"];
+	        this.model = source["model"];
+	    }
+	}
+	export class FabricContext {
+	    name: string;
+	    content: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FabricContext(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.content = source["content"];
+	    }
+	}
+	export class FabricInstall {
+	    path: string;
+	    version: string;
+	    previous?: string;
+	    updated: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new FabricInstall(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.version = source["version"];
+	        this.previous = source["previous"];
+	        this.updated = source["updated"];
+	    }
+	}
+	export class SessionMessage {
+	    role: string;
+	    content: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SessionMessage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.role = source["role"];
+	        this.content = source["content"];
+	    }
+	}
+	export class FabricSession {
+	    name: string;
+	    messages: SessionMessage[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FabricSession(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.messages = this.convertValues(source["messages"], SessionMessage);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FabricVersionInfo {
+	    binaryPath?: string;
+	    binary?: string;
+	    server?: string;
+	    minimum: string;
+	    missingEndpoints?: string[];
+	    compatible: boolean;
+	    warnings?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FabricVersionInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.binaryPath = source["binaryPath"];
+	        this.binary = source["binary"];
+	        this.server = source["server"];
+	        this.minimum = source["minimum"];
+	        this.missingEndpoints = source["missingEndpoints"];
+	        this.compatible = source["compatible"];
+	        this.warnings = source["warnings"];
+	    }
+	}
+	export class FavoriteModel {
+	    vendor: string;
+	    model: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FavoriteModel(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.vendor = source["vendor"];
+	        this.model = source["model"];
+	    }
+	}
+	export class FeedItem {
+	    id: string;
+	    title: string;
+	    link: string;
+	    published?: string;
+	    content: string;
+	    audio?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FeedItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.link = source["link"];
+	        this.published = source["published"];
+	        this.content = source["content"];
+	        this.audio = source["audio"];
+	    }
+	}
+	export class Feed {
+	    title: string;
+	    link: string;
+	    items: FeedItem[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Feed(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.title = source["title"];
+	        this.link = source["link"];
+	        this.items = this.convertValues(source["items"], FeedItem);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FeedDigestItem {
+	    feed: string;
+	    title: string;
+	    link: string;
+	    output?: string;
+	    entryId?: string;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FeedDigestItem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.feed = source["feed"];
+	        this.title = source["title"];
+	        this.link = source["link"];
+	        this.output = source["output"];
+	        this.entryId = source["entryId"];
+	        this.error = source["error"];
+	    }
+	}
+	export class FeedDigest {
+	    time: number;
+	    items: FeedDigestItem[];
+	    errors?: string[];
+	    markdown: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FeedDigest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = source["time"];
+	        this.items = this.convertValues(source["items"], FeedDigestItem);
+	        this.errors = source["errors"];
+	        this.markdown = source["markdown"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class FeedSubscription {
+	    id: string;
+	    name: string;
+	    url: string;
+	    preset: string;
+	    pattern: string;
+	    vendor: string;
+	    model: string;
+	    fullArticles: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new FeedSubscription(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.url = source["url"];
+	        this.preset = source["preset"];
+	        this.pattern = source["pattern"];
+	        this.vendor = source["vendor"];
+	        this.model = source["model"];
+	        this.fullArticles = source["fullArticles"];
+	    }
+	}
+	export class FolderWatch {
+	    id: string;
+	    dir: string;
+	    glob: string;
+	    enabled: boolean;
+	    preset: string;
+	    pattern: string;
+	    vendor: string;
+	    model: string;
+	    outputDir: string;
+	    outputTemplate: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FolderWatch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.dir = source["dir"];
+	        this.glob = source["glob"];
+	        this.enabled = source["enabled"];
+	        this.preset = source["preset"];
+	        this.pattern = source["pattern"];
+	        this.vendor = source["vendor"];
+	        this.model = source["model"];
+	        this.outputDir = source["outputDir"];
+	        this.outputTemplate = source["outputTemplate"];
+	    }
+	}
+	export class FolderWatchStatus {
+	    id: string;
+	    dir: string;
+	    glob: string;
+	    enabled: boolean;
+	    preset: string;
+	    pattern: string;
+	    vendor: string;
+	    model: string;
+	    outputDir: string;
+	    outputTemplate: string;
+	    watching: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FolderWatchStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.dir = source["dir"];
+	        this.glob = source["glob"];
+	        this.enabled = source["enabled"];
+	        this.preset = source["preset"];
+	        this.pattern = source["pattern"];
+	        this.vendor = source["vendor"];
+	        this.model = source["model"];
+	        this.outputDir = source["outputDir"];
+	        this.outputTemplate = source["outputTemplate"];
+	        this.watching = source["watching"];
+	        this.error = source["error"];
+	    }
+	}
+	export class GitHubContent {
+	    kind: string;
+	    title: string;
+	    url: string;
+	    text: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GitHubContent(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.title = source["title"];
+	        this.url = source["url"];
+	        this.text = source["text"];
+	    }
+	}
+	export class HTMLOptions {
+	    title?: string;
+	    pattern?: string;
+	    model?: string;
+	    time?: number;
+	    theme?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HTMLOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.title = source["title"];
+	        this.pattern = source["pattern"];
+	        this.model = source["model"];
+	        this.time = source["time"];
+	        this.theme = source["theme"];
+	    }
+	}
+	export class HistoryEncryptionStatus {
+	    mode: string;
+	    locked: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryEncryptionStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mode = source["mode"];
+	        this.locked = source["locked"];
+	    }
+	}
 	export class HistoryEntry {
+	    id: string;
+	    title?: string;
+	    pattern: string;
+	    model: string;
+	    input: string;
+	    output: string;
+	    time: number;
+	    note?: string;
+	    pinned?: boolean;
+	    tags?: string[];
+	    rerunOf?: string;
+	    variables?: Record<string, string>;
+	    context?: string;
+	    strategy?: string;
+	    session?: string;
+	    systemPrompt?: string;
+	    systemMode?: string;
+	    threadId?: string;
+	    chunkOf?: string;
+	    part?: number;
+	    vendor?: string;
+	    durationMs?: number;
+	    promptTokens?: number;
+	    completionTokens?: number;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.pattern = source["pattern"];
+	        this.model = source["model"];
+	        this.input = source["input"];
+	        this.output = source["output"];
+	        this.time = source["time"];
+	        this.note = source["note"];
+	        this.pinned = source["pinned"];
+	        this.tags = source["tags"];
+	        this.rerunOf = source["rerunOf"];
+	        this.variables = source["variables"];
+	        this.context = source["context"];
+	        this.strategy = source["strategy"];
+	        this.session = source["session"];
+	        this.systemPrompt = source["systemPrompt"];
+	        this.systemMode = source["systemMode"];
+	        this.threadId = source["threadId"];
+	        this.chunkOf = source["chunkOf"];
+	        this.part = source["part"];
+	        this.vendor = source["vendor"];
+	        this.durationMs = source["durationMs"];
+	        this.promptTokens = source["promptTokens"];
+	        this.completionTokens = source["completionTokens"];
+	        this.error = source["error"];
+	    }
+	}
+	export class HistoryFilter {
+	    ids: string[];
+	    tag: string;
+	    pattern: string;
+	    since: number;
+	    until: number;
+	    pinnedOnly: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ids = source["ids"];
+	        this.tag = source["tag"];
+	        this.pattern = source["pattern"];
+	        this.since = source["since"];
+	        this.until = source["until"];
+	        this.pinnedOnly = source["pinnedOnly"];
+	    }
+	}
+	export class Hook {
+	    name: string;
+	    command: string;
+	    enabled: boolean;
+	    patterns?: string[];
+	    timeout?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Hook(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.command = source["command"];
+	        this.enabled = source["enabled"];
+	        this.patterns = source["patterns"];
+	        this.timeout = source["timeout"];
+	    }
+	}
+	export class HookResult {
+	    name: string;
+	    entryId: string;
+	    exitCode: number;
+	    stdout: string;
+	    stderr: string;
+	    durationMs: number;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HookResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.entryId = source["entryId"];
+	        this.exitCode = source["exitCode"];
+	        this.stdout = source["stdout"];
+	        this.stderr = source["stderr"];
+	        this.durationMs = source["durationMs"];
+	        this.error = source["error"];
+	    }
+	}
+	export class HotkeySettings {
+	    enabled: boolean;
+	    shortcut: string;
+	    preset: string;
+	    source: string;
+	    output: string;
+	    miniShortcut: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HotkeySettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.shortcut = source["shortcut"];
+	        this.preset = source["preset"];
+	        this.source = source["source"];
+	        this.output = source["output"];
+	        this.miniShortcut = source["miniShortcut"];
+	    }
+	}
+	export class HotkeyStatus {
+	    supported: boolean;
+	    running: boolean;
+	    trigger?: string;
+	    miniTrigger?: string;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HotkeyStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.supported = source["supported"];
+	        this.running = source["running"];
+	        this.trigger = source["trigger"];
+	        this.miniTrigger = source["miniTrigger"];
+	        this.error = source["error"];
+	    }
+	}
+	export class ImageAttachment {
+	    name: string;
+	    mimeType: string;
+	    size: number;
+	    dataUrl: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImageAttachment(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.mimeType = source["mimeType"];
+	        this.size = source["size"];
+	        this.dataUrl = source["dataUrl"];
+	    }
+	}
+	export class Job {
+	    id: string;
+	    kind: string;
+	    title: string;
+	    status: string;
+	    progress: number;
+	    message?: string;
+	    error?: string;
+	    result?: string;
+	    startedAt: number;
+	    finishedAt?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Job(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.kind = source["kind"];
+	        this.title = source["title"];
+	        this.status = source["status"];
+	        this.progress = source["progress"];
+	        this.message = source["message"];
+	        this.error = source["error"];
+	        this.result = source["result"];
+	        this.startedAt = source["startedAt"];
+	        this.finishedAt = source["finishedAt"];
+	    }
+	}
+	export class ModelInfo {
+	    model: string;
+	    contextWindow: number;
+	    inputPer1k: number;
+	    outputPer1k: number;
+	    modality: string[];
+	    custom?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModelInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.model = source["model"];
+	        this.contextWindow = source["contextWindow"];
+	        this.inputPer1k = source["inputPer1k"];
+	        this.outputPer1k = source["outputPer1k"];
+	        this.modality = source["modality"];
+	        this.custom = source["custom"];
+	    }
+	}
+	export class ModelVisibility {
+	    allowVendors?: string[];
+	    denyVendors?: string[];
+	    denyModels?: string[];
+	    showHidden?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModelVisibility(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.allowVendors = source["allowVendors"];
+	        this.denyVendors = source["denyVendors"];
+	        this.denyModels = source["denyModels"];
+	        this.showHidden = source["showHidden"];
+	    }
+	}
+	export class ModelsResponse {
+	    models: string[];
+	    vendors: Record<string, Array<string>>;
+	
+	    static createFrom(source: any = {}) {
+	        return new ModelsResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.models = source["models"];
+	        this.vendors = source["vendors"];
+	    }
+	}
+	export class NotificationSettings {
+	    enabled: boolean;
+	    onComplete: boolean;
+	    onError: boolean;
+	    minDuration: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new NotificationSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.onComplete = source["onComplete"];
+	        this.onError = source["onError"];
+	        this.minDuration = source["minDuration"];
+	    }
+	}
+	export class NotionSettings {
+	    token: string;
+	    parent: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new NotionSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.token = source["token"];
+	        this.parent = source["parent"];
+	    }
+	}
+	export class OCRResult {
+	    text: string;
+	    confidence: number;
+	    language: string;
+	    engine: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OCRResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.text = source["text"];
+	        this.confidence = source["confidence"];
+	        this.language = source["language"];
+	        this.engine = source["engine"];
+	    }
+	}
+	export class ObsidianSettings {
+	    vault: string;
+	    template: string;
+	    tags: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ObsidianSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.vault = source["vault"];
+	        this.template = source["template"];
+	        this.tags = source["tags"];
+	    }
+	}
+	export class OllamaModel {
+	    name: string;
+	    size: number;
+	    modifiedAt: string;
+	    family?: string;
+	    parameterSize?: string;
+	    quantization?: string;
+	    inFabric: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new OllamaModel(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.size = source["size"];
+	        this.modifiedAt = source["modifiedAt"];
+	        this.family = source["family"];
+	        this.parameterSize = source["parameterSize"];
+	        this.quantization = source["quantization"];
+	        this.inFabric = source["inFabric"];
+	    }
+	}
+	export class OpenAIEndpoint {
+	    name: string;
+	    baseUrl: string;
+	    apiKey?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OpenAIEndpoint(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.baseUrl = source["baseUrl"];
+	        this.apiKey = source["apiKey"];
+	    }
+	}
+	export class OutputDiff {
+	    a: HistoryEntry;
+	    b: HistoryEntry;
+	    lines: DiffLine[];
+	    added: number;
+	    removed: number;
+	    same: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new OutputDiff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.a = this.convertValues(source["a"], HistoryEntry);
+	        this.b = this.convertValues(source["b"], HistoryEntry);
+	        this.lines = this.convertValues(source["lines"], DiffLine);
+	        this.added = source["added"];
+	        this.removed = source["removed"];
+	        this.same = source["same"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PDFOptions {
+	    title?: string;
+	    pattern?: string;
+	    model?: string;
+	    time?: number;
+	    header: boolean;
+	    footer: boolean;
+	    pageSize?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PDFOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.title = source["title"];
+	        this.pattern = source["pattern"];
+	        this.model = source["model"];
+	        this.time = source["time"];
+	        this.header = source["header"];
+	        this.footer = source["footer"];
+	        this.pageSize = source["pageSize"];
+	    }
+	}
+	export class Pattern {
+	    name: string;
+	    source: string;
+	    dir?: string;
+	    description?: string;
+	    tags?: string[];
+	    category?: string;
+	    system: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Pattern(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.source = source["source"];
+	        this.dir = source["dir"];
+	        this.description = source["description"];
+	        this.tags = source["tags"];
+	        this.category = source["category"];
+	        this.system = source["system"];
+	    }
+	}
+	export class PatternBundleImport {
+	    imported: string[];
+	    skipped: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PatternBundleImport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.imported = source["imported"];
+	        this.skipped = source["skipped"];
+	    }
+	}
+	export class PatternInfo {
+	    name: string;
+	    source: string;
+	    dir?: string;
+	    description?: string;
+	    tags?: string[];
+	    category?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PatternInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.source = source["source"];
+	        this.dir = source["dir"];
+	        this.description = source["description"];
+	        this.tags = source["tags"];
+	        this.category = source["category"];
+	    }
+	}
+	export class PatternModel {
+	    vendor?: string;
+	    model: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PatternModel(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.vendor = source["vendor"];
+	        this.model = source["model"];
+	    }
+	}
+	export class PatternStats {
+	    pattern: string;
+	    runs: number;
+	    failures: number;
+	    lastUsed: number;
+	    avgDurationMs: number;
+	    avgOutputChars: number;
+	    totalDurationMs: number;
+	    totalOutputChars: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PatternStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pattern = source["pattern"];
+	        this.runs = source["runs"];
+	        this.failures = source["failures"];
+	        this.lastUsed = source["lastUsed"];
+	        this.avgDurationMs = source["avgDurationMs"];
+	        this.avgOutputChars = source["avgOutputChars"];
+	        this.totalDurationMs = source["totalDurationMs"];
+	        this.totalOutputChars = source["totalOutputChars"];
+	    }
+	}
+	export class PatternSuggestion {
+	    pattern: string;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PatternSuggestion(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pattern = source["pattern"];
+	        this.reason = source["reason"];
+	    }
+	}
+	export class PatternTag {
+	    name: string;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PatternTag(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.count = source["count"];
+	    }
+	}
+	export class PatternUpdate {
+	    added: string[];
+	    changed: string[];
+	    removed: string[];
+	    total: number;
+	    patterns: PatternInfo[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PatternUpdate(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.added = source["added"];
+	        this.changed = source["changed"];
+	        this.removed = source["removed"];
+	        this.total = source["total"];
+	        this.patterns = this.convertValues(source["patterns"], PatternInfo);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PodcastRequest {
+	    url: string;
+	    episode: string;
+	    preset: string;
+	    pattern: string;
+	    vendor: string;
+	    model: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PodcastRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.episode = source["episode"];
+	        this.preset = source["preset"];
+	        this.pattern = source["pattern"];
+	        this.vendor = source["vendor"];
+	        this.model = source["model"];
+	    }
+	}
+	export class PodcastResult {
+	    show?: string;
+	    title: string;
+	    audioUrl: string;
+	    transcript: string;
+	    output: string;
+	    entryId: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PodcastResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.show = source["show"];
+	        this.title = source["title"];
+	        this.audioUrl = source["audioUrl"];
+	        this.transcript = source["transcript"];
+	        this.output = source["output"];
+	        this.entryId = source["entryId"];
+	    }
+	}
+	export class ScheduleSource {
+	    kind: string;
+	    text: string;
+	    url: string;
+	    dir: string;
+	    glob: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScheduleSource(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.text = source["text"];
+	        this.url = source["url"];
+	        this.dir = source["dir"];
+	        this.glob = source["glob"];
+	    }
+	}
+	export class Schedule {
+	    id: string;
+	    name: string;
+	    enabled: boolean;
+	    cron: string;
+	    source: ScheduleSource;
+	    preset: string;
 	    pattern: string;
+	    vendor: string;
 	    model: string;
-	    input: string;
-	    output: string;
-	    time: number;
+	    outputDir: string;
+	    outputTemplate: string;
+	    notify: boolean;
 	
 	    static createFrom(source: any = {}) {
-	        return new HistoryEntry(source);
+	        return new Schedule(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.enabled = source["enabled"];
+	        this.cron = source["cron"];
+	        this.source = this.convertValues(source["source"], ScheduleSource);
+	        this.preset = source["preset"];
 	        this.pattern = source["pattern"];
+	        this.vendor = source["vendor"];
 	        this.model = source["model"];
-	        this.input = source["input"];
-	        this.output = source["output"];
-	        this.time = source["time"];
+	        this.outputDir = source["outputDir"];
+	        this.outputTemplate = source["outputTemplate"];
+	        this.notify = source["notify"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
-	export class ModelsResponse {
-	    models: string[];
-	    vendors: Record<string, Array<string>>;
+	export class TraySettings {
+	    enabled: boolean;
+	    closeToTray: boolean;
+	    preset: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new ModelsResponse(source);
+	        return new TraySettings(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.models = source["models"];
-	        this.vendors = source["vendors"];
+	        this.enabled = source["enabled"];
+	        this.closeToTray = source["closeToTray"];
+	        this.preset = source["preset"];
+	    }
+	}
+	export class SMTPSettings {
+	    host: string;
+	    port: number;
+	    security: string;
+	    username: string;
+	    password: string;
+	    from: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SMTPSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.host = source["host"];
+	        this.port = source["port"];
+	        this.security = source["security"];
+	        this.username = source["username"];
+	        this.password = source["password"];
+	        this.from = source["from"];
+	    }
+	}
+	export class Webhook {
+	    name: string;
+	    url: string;
+	    secret?: string;
+	    enabled: boolean;
+	    patterns?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Webhook(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.url = source["url"];
+	        this.secret = source["secret"];
+	        this.enabled = source["enabled"];
+	        this.patterns = source["patterns"];
+	    }
+	}
+	export class TTSSettings {
+	    engine: string;
+	    voice: string;
+	    rate: number;
+	    apiUrl: string;
+	    apiKey: string;
+	    apiModel: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TTSSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.engine = source["engine"];
+	        this.voice = source["voice"];
+	        this.rate = source["rate"];
+	        this.apiUrl = source["apiUrl"];
+	        this.apiKey = source["apiKey"];
+	        this.apiModel = source["apiModel"];
 	    }
 	}
 	export class Preferences {
@@ -41,6 +1502,60 @@ export namespace main {
 	    lastPattern: string;
 	    lastModel: string;
 	    lastVendor: string;
+	    quickPattern: string;
+	    quickModel: string;
+	    quickVendor: string;
+	    whisperProvider: string;
+	    whisperUrl: string;
+	    whisperApiKey: string;
+	    whisperModel: string;
+	    whisperLanguage: string;
+	    recordingDevice: string;
+	    ocrDroppedImages: boolean;
+	    ocrLanguage: string;
+	    ocrEndpoint: string;
+	    batchWorkers: number;
+	    batchTemplate: string;
+	    disableChatRetry: boolean;
+	    chatMaxRetries: number;
+	    chatRetryDelayMs: number;
+	    historyMaxEntries: number;
+	    historyMaxSizeMb: number;
+	    historyMaxAgeDays: number;
+	    historyTitles: string;
+	    historyEncryption: string;
+	    titleVendor: string;
+	    titleModel: string;
+	    proxyMode: string;
+	    proxyUrl: string;
+	    noProxy: string;
+	    logLevel: string;
+	    customPatternDirs: string[];
+	    patternModels: Record<string, PatternModel>;
+	    ollamaUrl: string;
+	    openaiEndpoints: OpenAIEndpoint[];
+	    refuseOversized: boolean;
+	    modelVisibility: ModelVisibility;
+	    favoriteModels: FavoriteModel[];
+	    obsidian: ObsidianSettings;
+	    dailyNote?: DailyNoteSettings;
+	    autoSave: AutoSaveSettings;
+	    tts: TTSSettings;
+	    webhooks: Webhook[];
+	    slackWebhookUrl: string;
+	    discordWebhookUrl: string;
+	    notion: NotionSettings;
+	    smtp: SMTPSettings;
+	    hooks: Hook[];
+	    tray: TraySettings;
+	    hotkey: HotkeySettings;
+	    notifications?: NotificationSettings;
+	    api: APISettings;
+	    schedules: Schedule[];
+	    folderWatches: FolderWatch[];
+	    feeds: FeedSubscription[];
+	    githubToken: string;
+	    githubHosts: string[];
 	
 	    static createFrom(source: any = {}) {
 	        return new Preferences(source);
@@ -54,8 +1569,655 @@ export namespace main {
 	        this.lastPattern = source["lastPattern"];
 	        this.lastModel = source["lastModel"];
 	        this.lastVendor = source["lastVendor"];
+	        this.quickPattern = source["quickPattern"];
+	        this.quickModel = source["quickModel"];
+	        this.quickVendor = source["quickVendor"];
+	        this.whisperProvider = source["whisperProvider"];
+	        this.whisperUrl = source["whisperUrl"];
+	        this.whisperApiKey = source["whisperApiKey"];
+	        this.whisperModel = source["whisperModel"];
+	        this.whisperLanguage = source["whisperLanguage"];
+	        this.recordingDevice = source["recordingDevice"];
+	        this.ocrDroppedImages = source["ocrDroppedImages"];
+	        this.ocrLanguage = source["ocrLanguage"];
+	        this.ocrEndpoint = source["ocrEndpoint"];
+	        this.batchWorkers = source["batchWorkers"];
+	        this.batchTemplate = source["batchTemplate"];
+	        this.disableChatRetry = source["disableChatRetry"];
+	        this.chatMaxRetries = source["chatMaxRetries"];
+	        this.chatRetryDelayMs = source["chatRetryDelayMs"];
+	        this.historyMaxEntries = source["historyMaxEntries"];
+	        this.historyMaxSizeMb = source["historyMaxSizeMb"];
+	        this.historyMaxAgeDays = source["historyMaxAgeDays"];
+	        this.historyTitles = source["historyTitles"];
+	        this.historyEncryption = source["historyEncryption"];
+	        this.titleVendor = source["titleVendor"];
+	        this.titleModel = source["titleModel"];
+	        this.proxyMode = source["proxyMode"];
+	        this.proxyUrl = source["proxyUrl"];
+	        this.noProxy = source["noProxy"];
+	        this.logLevel = source["logLevel"];
+	        this.customPatternDirs = source["customPatternDirs"];
+	        this.patternModels = this.convertValues(source["patternModels"], PatternModel, true);
+	        this.ollamaUrl = source["ollamaUrl"];
+	        this.openaiEndpoints = this.convertValues(source["openaiEndpoints"], OpenAIEndpoint);
+	        this.refuseOversized = source["refuseOversized"];
+	        this.modelVisibility = this.convertValues(source["modelVisibility"], ModelVisibility);
+	        this.favoriteModels = this.convertValues(source["favoriteModels"], FavoriteModel);
+	        this.obsidian = this.convertValues(source["obsidian"], ObsidianSettings);
+	        this.dailyNote = this.convertValues(source["dailyNote"], DailyNoteSettings);
+	        this.autoSave = this.convertValues(source["autoSave"], AutoSaveSettings);
+	        this.tts = this.convertValues(source["tts"], TTSSettings);
+	        this.webhooks = this.convertValues(source["webhooks"], Webhook);
+	        this.slackWebhookUrl = source["slackWebhookUrl"];
+	        this.discordWebhookUrl = source["discordWebhookUrl"];
+	        this.notion = this.convertValues(source["notion"], NotionSettings);
+	        this.smtp = this.convertValues(source["smtp"], SMTPSettings);
+	        this.hooks = this.convertValues(source["hooks"], Hook);
+	        this.tray = this.convertValues(source["tray"], TraySettings);
+	        this.hotkey = this.convertValues(source["hotkey"], HotkeySettings);
+	        this.notifications = this.convertValues(source["notifications"], NotificationSettings);
+	        this.api = this.convertValues(source["api"], APISettings);
+	        this.schedules = this.convertValues(source["schedules"], Schedule);
+	        this.folderWatches = this.convertValues(source["folderWatches"], FolderWatch);
+	        this.feeds = this.convertValues(source["feeds"], FeedSubscription);
+	        this.githubToken = source["githubToken"];
+	        this.githubHosts = source["githubHosts"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Preset {
+	    name: string;
+	    pattern: string;
+	    vendor: string;
+	    model: string;
+	    params: ChatOptions;
+	    inputTemplate: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Preset(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.pattern = source["pattern"];
+	        this.vendor = source["vendor"];
+	        this.model = source["model"];
+	        this.params = this.convertValues(source["params"], ChatOptions);
+	        this.inputTemplate = source["inputTemplate"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ProfileSwitch {
+	    profile: ConnectionProfile;
+	    online: boolean;
+	    patterns: PatternInfo[];
+	    models?: ModelsResponse;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProfileSwitch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.profile = this.convertValues(source["profile"], ConnectionProfile);
+	        this.online = source["online"];
+	        this.patterns = this.convertValues(source["patterns"], PatternInfo);
+	        this.models = this.convertValues(source["models"], ModelsResponse);
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PromptPreview {
+	    pattern: string;
+	    source: string;
+	    system: string;
+	    user: string;
+	    missingVariables?: string[];
+	    estimatedTokens: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PromptPreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pattern = source["pattern"];
+	        this.source = source["source"];
+	        this.system = source["system"];
+	        this.user = source["user"];
+	        this.missingVariables = source["missingVariables"];
+	        this.estimatedTokens = source["estimatedTokens"];
+	    }
+	}
+	export class QuickModeStatus {
+	    watching: boolean;
+	    armed: boolean;
+	    pattern: string;
+	    vendor: string;
+	    model: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new QuickModeStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.watching = source["watching"];
+	        this.armed = source["armed"];
+	        this.pattern = source["pattern"];
+	        this.vendor = source["vendor"];
+	        this.model = source["model"];
+	    }
+	}
+	export class RerunOverrides {
+	    pattern: string;
+	    vendor: string;
+	    model: string;
+	    input: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RerunOverrides(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pattern = source["pattern"];
+	        this.vendor = source["vendor"];
+	        this.model = source["model"];
+	        this.input = source["input"];
+	    }
+	}
+	
+	export class StreamDraft {
+	    streamId: string;
+	    pattern?: string;
+	    vendor?: string;
+	    model?: string;
+	    input: string;
+	    output: string;
+	    startedAt: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new StreamDraft(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.streamId = source["streamId"];
+	        this.pattern = source["pattern"];
+	        this.vendor = source["vendor"];
+	        this.model = source["model"];
+	        this.input = source["input"];
+	        this.output = source["output"];
+	        this.startedAt = source["startedAt"];
+	    }
+	}
+	export class SavedSession {
+	    savedAt: number;
+	    draft: Draft;
+	    streams: StreamDraft[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SavedSession(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.savedAt = source["savedAt"];
+	        this.draft = this.convertValues(source["draft"], Draft);
+	        this.streams = this.convertValues(source["streams"], StreamDraft);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class ScheduleRun {
+	    scheduleId: string;
+	    name: string;
+	    manual: boolean;
+	    startedAt: number;
+	    finishedAt: number;
+	    status: string;
+	    items: number;
+	    succeeded: number;
+	    errors?: string[];
+	    entryIds?: string[];
+	    outputs?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ScheduleRun(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.scheduleId = source["scheduleId"];
+	        this.name = source["name"];
+	        this.manual = source["manual"];
+	        this.startedAt = source["startedAt"];
+	        this.finishedAt = source["finishedAt"];
+	        this.status = source["status"];
+	        this.items = source["items"];
+	        this.succeeded = source["succeeded"];
+	        this.errors = source["errors"];
+	        this.entryIds = source["entryIds"];
+	        this.outputs = source["outputs"];
+	    }
+	}
+	
+	export class ScheduleStatus {
+	    id: string;
+	    name: string;
+	    enabled: boolean;
+	    cron: string;
+	    source: ScheduleSource;
+	    preset: string;
+	    pattern: string;
+	    vendor: string;
+	    model: string;
+	    outputDir: string;
+	    outputTemplate: string;
+	    notify: boolean;
+	    nextRun?: number;
+	    lastRun?: ScheduleRun;
+	    running: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScheduleStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.enabled = source["enabled"];
+	        this.cron = source["cron"];
+	        this.source = this.convertValues(source["source"], ScheduleSource);
+	        this.preset = source["preset"];
+	        this.pattern = source["pattern"];
+	        this.vendor = source["vendor"];
+	        this.model = source["model"];
+	        this.outputDir = source["outputDir"];
+	        this.outputTemplate = source["outputTemplate"];
+	        this.notify = source["notify"];
+	        this.nextRun = source["nextRun"];
+	        this.lastRun = this.convertValues(source["lastRun"], ScheduleRun);
+	        this.running = source["running"];
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SecretsStatus {
+	    backend: string;
+	    stored: string[];
+	    plaintext: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SecretsStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.backend = source["backend"];
+	        this.stored = source["stored"];
+	        this.plaintext = source["plaintext"];
+	    }
+	}
+	
+	export class SetupStep {
+	    id: string;
+	    title: string;
+	    status: string;
+	    detail?: string;
+	    remedy?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SetupStep(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.status = source["status"];
+	        this.detail = source["detail"];
+	        this.remedy = source["remedy"];
+	    }
+	}
+	export class Strategy {
+	    name: string;
+	    description: string;
+	    prompt: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Strategy(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.prompt = source["prompt"];
+	    }
+	}
+	
+	
+	
+	
+	export class TokenCount {
+	    tokens: number;
+	    contextWindow?: number;
+	    remaining?: number;
+	    exceeds: boolean;
+	    inputCost?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TokenCount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tokens = source["tokens"];
+	        this.contextWindow = source["contextWindow"];
+	        this.remaining = source["remaining"];
+	        this.exceeds = source["exceeds"];
+	        this.inputCost = source["inputCost"];
+	    }
+	}
+	
+	export class TrayStatus {
+	    supported: boolean;
+	    running: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TrayStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.supported = source["supported"];
+	        this.running = source["running"];
+	        this.error = source["error"];
+	    }
+	}
+	export class UpdateInfo {
+	    current: string;
+	    latest: string;
+	    available: boolean;
+	    notes?: string;
+	    url?: string;
+	    asset?: string;
+	    applied: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new UpdateInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.current = source["current"];
+	        this.latest = source["latest"];
+	        this.available = source["available"];
+	        this.notes = source["notes"];
+	        this.url = source["url"];
+	        this.asset = source["asset"];
+	        this.applied = source["applied"];
+	    }
+	}
+	export class VendorStatus {
+	    vendor: string;
+	    usable: boolean;
+	    method: string;
+	    error?: string;
+	    latencyMs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new VendorStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.vendor = source["vendor"];
+	        this.usable = source["usable"];
+	        this.method = source["method"];
+	        this.error = source["error"];
+	        this.latencyMs = source["latencyMs"];
+	    }
+	}
+	
+	export class YouTubeBatchRequest {
+	    url: string;
+	    limit: number;
+	    preset: string;
+	    pattern: string;
+	    vendor: string;
+	    model: string;
+	    outputDir: string;
+	    digest: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new YouTubeBatchRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.limit = source["limit"];
+	        this.preset = source["preset"];
+	        this.pattern = source["pattern"];
+	        this.vendor = source["vendor"];
+	        this.model = source["model"];
+	        this.outputDir = source["outputDir"];
+	        this.digest = source["digest"];
+	    }
+	}
+	export class YouTubeVideoEvent {
+	    id: string;
+	    title: string;
+	    url: string;
+	    index: number;
+	    total: number;
+	    status: string;
+	    output?: string;
+	    entryId?: string;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new YouTubeVideoEvent(source);
 	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.url = source["url"];
+	        this.index = source["index"];
+	        this.total = source["total"];
+	        this.status = source["status"];
+	        this.output = source["output"];
+	        this.entryId = source["entryId"];
+	        this.error = source["error"];
+	    }
+	}
+	export class YouTubeBatchSummary {
+	    title: string;
+	    videos: YouTubeVideoEvent[];
+	    succeeded: number;
+	    failed: number;
+	    digest?: string;
+	    digestPath?: string;
+	    durationMs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new YouTubeBatchSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.title = source["title"];
+	        this.videos = this.convertValues(source["videos"], YouTubeVideoEvent);
+	        this.succeeded = source["succeeded"];
+	        this.failed = source["failed"];
+	        this.digest = source["digest"];
+	        this.digestPath = source["digestPath"];
+	        this.durationMs = source["durationMs"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class YouTubeVideo {
+	    id: string;
+	    title: string;
+	    url: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new YouTubeVideo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.url = source["url"];
+	    }
+	}
+	export class YouTubeListing {
+	    title: string;
+	    videos: YouTubeVideo[];
+	
+	    static createFrom(source: any = {}) {
+	        return new YouTubeListing(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.title = source["title"];
+	        this.videos = this.convertValues(source["videos"], YouTubeVideo);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
+	
 
 }
 
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	// maxGitOutput is how much of a diff or log is kept; models cannot take more
+	maxGitOutput = 4 << 20
+	// defaultGitLogCommits is how many commits GetGitLog returns without a range
+	defaultGitLogCommits = 50
+)
+
+// GetGitDiff returns the diff of a local repository, for patterns such as
+// write_commit_message. An empty ref diffs every uncommitted change
+// against HEAD, "staged" only the staged ones, and anything else is passed
+// to git diff as it is: a commit to diff the working tree against, or a
+// range such as "main..feature". Untracked files are not included.
+func (a *App) GetGitDiff(repoPath, ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if err := checkGitRevision(ref); err != nil {
+		return "", err
+	}
+	args := []string{"diff", "--no-color", "--no-ext-diff"}
+	switch ref {
+	case "":
+		args = append(args, "HEAD")
+	case "staged":
+		args = append(args, "--cached")
+	default:
+		args = append(args, ref)
+	}
+
+	diff, err := runGit(repoPath, append(args, "--")...)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(diff) == "" {
+		return "", fmt.Errorf("no changes to diff")
+	}
+	return diff, nil
+}
+
+// GetGitLog returns the log of a local repository with each commit's full
+// message, for patterns such as summarize_git_changes. The range is passed
+// to git log, such as "v1.2.0..HEAD" or "main..feature"; without one the
+// last defaultGitLogCommits commits are returned.
+func (a *App) GetGitLog(repoPath, revRange string) (string, error) {
+	revRange = strings.TrimSpace(revRange)
+	if err := checkGitRevision(revRange); err != nil {
+		return "", err
+	}
+	args := []string{"log", "--no-color", "--date=iso", "--stat"}
+	if revRange == "" {
+		args = append(args, fmt.Sprintf("--max-count=%d", defaultGitLogCommits))
+	} else {
+		args = append(args, revRange)
+	}
+
+	log, err := runGit(repoPath, append(args, "--")...)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(log) == "" {
+		return "", fmt.Errorf("no commits to list")
+	}
+	return log, nil
+}
+
+// checkGitRevision refuses revisions git would read as options
+func checkGitRevision(rev string) error {
+	if strings.HasPrefix(rev, "-") {
+		return fmt.Errorf("invalid revision %q", rev)
+	}
+	return nil
+}
+
+// runGit runs a git command in a repository and returns its output, cut
+// short at maxGitOutput
+func runGit(repoPath string, args ...string) (string, error) {
+	git, err := exec.LookPath("git")
+	if err != nil {
+		return "", fmt.Errorf("git not found in PATH: %v", err)
+	}
+	if info, err := os.Stat(repoPath); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("folder not found: %s", repoPath)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, git, append([]string{"-C", repoPath, "-c", "core.quotepath=off"}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() > 0 {
+			lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+			return "", fmt.Errorf("git %s failed: %s", args[0], strings.TrimPrefix(lines[len(lines)-1], "fatal: "))
+		}
+		return "", fmt.Errorf("git %s failed: %v", args[0], err)
+	}
+	if len(out) > maxGitOutput {
+		out = append(out[:maxGitOutput], fmt.Sprintf("\n... cut short at %d MB\n", maxGitOutput/(1024*1024))...)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,478 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	githubAPI = "https://api.github.com"
+	// githubMaxPages bounds how many pages of 100 comments, commits or
+	// files are read for one list
+	githubMaxPages = 30
+	// maxGitHubDiff is how much of a diff is kept; models cannot take more
+	maxGitHubDiff = 512 << 10
+)
+
+// githubLinkPath matches the path of an issue, pull request or comparison:
+// owner, repository, kind and the rest, the number or the compared refs.
+// Refs may hold slashes, as in compare/main...feature/foo.
+var githubLinkPath = regexp.MustCompile(`^/([^/]+)/([^/]+)/(issues|pull|compare)/(.+)`)
+
+// githubNextLink finds the next page in a Link header
+var githubNextLink = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// GitHubContent is an issue, pull request or comparison laid out as Markdown,
+// with its discussion and diff, ready to run through a pattern
+type GitHubContent struct {
+	Kind  string `json:"kind"` // issue, pull or compare
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	Text  string `json:"text"`
+}
+
+// githubLink is a parsed issue, pull request or comparison link
+type githubLink struct {
+	api, owner, repo, kind, ref string
+}
+
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+type githubComment struct {
+	User        githubUser `json:"user"`
+	Body        string     `json:"body"`
+	CreatedAt   time.Time  `json:"created_at"`
+	SubmittedAt time.Time  `json:"submitted_at"` // reviews only, which have no created_at
+	State       string     `json:"state"`        // reviews only
+	Path        string     `json:"path"`         // review comments only
+	Line        int        `json:"line"`
+}
+
+type githubCommit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name string `json:"name"`
+		} `json:"author"`
+	} `json:"commit"`
+}
+
+type githubFile struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename"`
+	Status           string `json:"status"`
+	Patch            string `json:"patch"`
+}
+
+// FetchGitHub reads an issue, pull request or comparison link through the
+// GitHub API, including every page of its discussion, commits and changed
+// files. The token set with SetGitHubToken is used when there is one, which
+// private repositories need. GitHub Enterprise links work too, and get the
+// token when their server is listed in the preferences.
+func (a *App) FetchGitHub(link string) (*GitHubContent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	return a.fetchGitHub(ctx, link)
+}
+
+// fetchGitHub reads a GitHub link, see FetchGitHub
+func (a *App) fetchGitHub(ctx context.Context, link string) (*GitHubContent, error) {
+	l, err := parseGitHubLink(link)
+	if err != nil {
+		return nil, err
+	}
+	prefs, _ := a.loadPreferences()
+	token, err := githubTokenFor(l, strings.TrimSpace(prefs.GitHubToken), prefs.GitHubHosts)
+	if err != nil {
+		return nil, err
+	}
+
+	var content *GitHubContent
+	switch l.kind {
+	case "issues":
+		content, err = a.fetchGitHubIssue(ctx, token, l)
+	case "pull":
+		content, err = a.fetchGitHubPull(ctx, token, l)
+	case "compare":
+		content, err = a.fetchGitHubCompare(ctx, token, l)
+	}
+	if err != nil {
+		return nil, err
+	}
+	a.log.Info("fetched from GitHub", "link", link, "size", len(content.Text))
+	return content, nil
+}
+
+// parseGitHubLink reads the repository and item of a link. Hosts other than
+// github.com are taken to be GitHub Enterprise servers.
+func parseGitHubLink(link string) (githubLink, error) {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return githubLink{}, fmt.Errorf("not a web address: %s", link)
+	}
+	m := githubLinkPath.FindStringSubmatch(u.EscapedPath())
+	if m == nil {
+		return githubLink{}, fmt.Errorf("not a GitHub issue, pull request or compare link: %s", link)
+	}
+	l := githubLink{api: githubAPI, owner: m[1], repo: m[2], kind: m[3], ref: m[4]}
+	if !strings.EqualFold(u.Host, "github.com") && !strings.EqualFold(u.Host, "www.github.com") {
+		l.api = u.Scheme + "://" + u.Host + "/api/v3"
+	}
+	if l.kind == "compare" {
+		l.ref = strings.TrimSuffix(l.ref, "/")
+	} else {
+		// Tabs such as pull/12/files
+		l.ref, _, _ = strings.Cut(l.ref, "/")
+	}
+	if l.kind != "compare" && strings.Trim(l.ref, "0123456789") != "" {
+		return githubLink{}, fmt.Errorf("not a GitHub issue or pull request number: %s", l.ref)
+	}
+	return l, nil
+}
+
+// githubTokenFor returns the token to send to a link's server. Only GitHub
+// and the Enterprise servers listed in hosts get it, and only over https.
+func githubTokenFor(l githubLink, token string, hosts []string) (string, error) {
+	if token == "" || l.api == githubAPI {
+		return token, nil
+	}
+	u, err := url.Parse(l.api)
+	if err != nil {
+		return "", nil
+	}
+	listed := slices.ContainsFunc(hosts, func(host string) bool {
+		host = strings.TrimSpace(host)
+		return strings.EqualFold(host, u.Host) || strings.EqualFold(host, u.Hostname())
+	})
+	if !listed {
+		return "", nil
+	}
+	if u.Scheme != "https" {
+		return "", fmt.Errorf("the GitHub token is only sent over https, use an https link to %s", u.Host)
+	}
+	return token, nil
+}
+
+// SetGitHubToken stores the token FetchGitHub uses in secret storage, or
+// removes it when empty
+func (a *App) SetGitHubToken(token string) error {
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	prefs.GitHubToken = strings.TrimSpace(token)
+	return a.SavePreferences(*prefs)
+}
+
+// isGitHubLink reports whether a link is a github.com issue, pull request
+// or comparison
+func isGitHubLink(link string) bool {
+	l, err := parseGitHubLink(link)
+	return err == nil && l.api == githubAPI
+}
+
+// repoPath returns the API path of the link's repository
+func (l githubLink) repoPath() string {
+	return "/repos/" + l.owner + "/" + l.repo
+}
+
+// fetchGitHubIssue lays out an issue and its comments
+func (a *App) fetchGitHubIssue(ctx context.Context, token string, l githubLink) (*GitHubContent, error) {
+	var issue struct {
+		Number      int        `json:"number"`
+		Title       string     `json:"title"`
+		Body        string     `json:"body"`
+		State       string     `json:"state"`
+		HTMLURL     string     `json:"html_url"`
+		User        githubUser `json:"user"`
+		CreatedAt   time.Time  `json:"created_at"`
+		PullRequest *struct{}  `json:"pull_request"`
+		Labels      []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+	}
+	if _, err := a.githubRequest(ctx, token, l.api+l.repoPath()+"/issues/"+l.ref, &issue); err != nil {
+		return nil, err
+	}
+	// Pull requests are issues too, and are read as pull requests
+	if issue.PullRequest != nil {
+		l.kind = "pull"
+		return a.fetchGitHubPull(ctx, token, l)
+	}
+	comments, err := githubList[githubComment](ctx, a, token, l.api+l.repoPath()+"/issues/"+l.ref+"/comments")
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s (#%d)\n%s\n\n", issue.Title, issue.Number, issue.HTMLURL)
+	fmt.Fprintf(&b, "Issue opened by @%s on %s, %s\n", issue.User.Login, issue.CreatedAt.Format("2006-01-02"), issue.State)
+	var labels []string
+	for _, label := range issue.Labels {
+		labels = append(labels, label.Name)
+	}
+	if len(labels) > 0 {
+		fmt.Fprintf(&b, "Labels: %s\n", strings.Join(labels, ", "))
+	}
+	writeGitHubBody(&b, issue.Body)
+	writeGitHubDiscussion(&b, comments)
+	return &GitHubContent{Kind: "issue", Title: issue.Title, URL: issue.HTMLURL, Text: b.String()}, nil
+}
+
+// fetchGitHubPull lays out a pull request with its discussion, reviews,
+// commits and diff
+func (a *App) fetchGitHubPull(ctx context.Context, token string, l githubLink) (*GitHubContent, error) {
+	var pull struct {
+		Number    int        `json:"number"`
+		Title     string     `json:"title"`
+		Body      string     `json:"body"`
+		State     string     `json:"state"`
+		Merged    bool       `json:"merged"`
+		Draft     bool       `json:"draft"`
+		HTMLURL   string     `json:"html_url"`
+		User      githubUser `json:"user"`
+		CreatedAt time.Time  `json:"created_at"`
+		Base      struct {
+			Label string `json:"label"`
+		} `json:"base"`
+		Head struct {
+			Label string `json:"label"`
+		} `json:"head"`
+	}
+	path := l.api + l.repoPath() + "/pulls/" + l.ref
+	if _, err := a.githubRequest(ctx, token, path, &pull); err != nil {
+		return nil, err
+	}
+	comments, err := githubList[githubComment](ctx, a, token, l.api+l.repoPath()+"/issues/"+l.ref+"/comments")
+	if err != nil {
+		return nil, err
+	}
+	reviews, err := githubList[githubComment](ctx, a, token, path+"/reviews")
+	if err != nil {
+		return nil, err
+	}
+	reviewComments, err := githubList[githubComment](ctx, a, token, path+"/comments")
+	if err != nil {
+		return nil, err
+	}
+	commits, err := githubList[githubCommit](ctx, a, token, path+"/commits")
+	if err != nil {
+		return nil, err
+	}
+	files, err := githubList[githubFile](ctx, a, token, path+"/files")
+	if err != nil {
+		return nil, err
+	}
+
+	state := pull.State
+	switch {
+	case pull.Merged:
+		state = "merged"
+	case pull.Draft:
+		state = "draft"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s (#%d)\n%s\n\n", pull.Title, pull.Number, pull.HTMLURL)
+	fmt.Fprintf(&b, "Pull request by @%s on %s, %s, merging %s into %s\n", pull.User.Login, pull.CreatedAt.Format("2006-01-02"), state, pull.Head.Label, pull.Base.Label)
+	writeGitHubBody(&b, pull.Body)
+	writeGitHubCommits(&b, commits)
+
+	// Reviews without a summary are only their line comments
+	for _, review := range reviews {
+		if strings.TrimSpace(review.Body) != "" {
+			review.CreatedAt = review.SubmittedAt
+			review.Body = fmt.Sprintf("*Review: %s*\n\n%s", strings.ToLower(strings.ReplaceAll(review.State, "_", " ")), review.Body)
+			comments = append(comments, review)
+		}
+	}
+	writeGitHubDiscussion(&b, comments)
+	if len(reviewComments) > 0 {
+		b.WriteString("\n## Review comments\n")
+		for _, c := range reviewComments {
+			fmt.Fprintf(&b, "\n### @%s on %s:%d\n\n%s\n", c.User.Login, c.Path, c.Line, strings.TrimSpace(c.Body))
+		}
+	}
+	writeGitHubDiff(&b, files)
+	return &GitHubContent{Kind: "pull", Title: pull.Title, URL: pull.HTMLURL, Text: b.String()}, nil
+}
+
+// fetchGitHubCompare lays out the commits and diff between two refs
+func (a *App) fetchGitHubCompare(ctx context.Context, token string, l githubLink) (*GitHubContent, error) {
+	type comparison struct {
+		HTMLURL  string         `json:"html_url"`
+		Status   string         `json:"status"`
+		AheadBy  int            `json:"ahead_by"`
+		BehindBy int            `json:"behind_by"`
+		Commits  []githubCommit `json:"commits"`
+		Files    []githubFile   `json:"files"`
+	}
+	// Comparisons page their commits and files together
+	var compare comparison
+	next := l.api + l.repoPath() + "/compare/" + l.ref + "?per_page=100"
+	for page := 0; next != "" && page < githubMaxPages; page++ {
+		var part comparison
+		var err error
+		if next, err = a.githubRequest(ctx, token, next, &part); err != nil {
+			return nil, err
+		}
+		if page == 0 {
+			compare = part
+		} else {
+			compare.Commits = append(compare.Commits, part.Commits...)
+			compare.Files = append(compare.Files, part.Files...)
+		}
+	}
+
+	base, head, _ := strings.Cut(strings.Replace(l.ref, "...", "..", 1), "..")
+	title := fmt.Sprintf("%s/%s: %s...%s", l.owner, l.repo, base, head)
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Changes in %s\n%s\n\n", title, compare.HTMLURL)
+	fmt.Fprintf(&b, "Commits in %s and not %s: %d; in %s and not %s: %d\n", head, base, compare.AheadBy, base, head, compare.BehindBy)
+	writeGitHubCommits(&b, compare.Commits)
+	writeGitHubDiff(&b, compare.Files)
+	return &GitHubContent{Kind: "compare", Title: title, URL: compare.HTMLURL, Text: b.String()}, nil
+}
+
+// writeGitHubBody writes the description of an issue or pull request
+func writeGitHubBody(b *strings.Builder, body string) {
+	if body = strings.TrimSpace(body); body != "" {
+		b.WriteString("\n" + body + "\n")
+	}
+}
+
+// writeGitHubDiscussion writes comments in the order they were made
+func writeGitHubDiscussion(b *strings.Builder, comments []githubComment) {
+	if len(comments) == 0 {
+		return
+	}
+	slices.SortStableFunc(comments, func(x, y githubComment) int { return x.CreatedAt.Compare(y.CreatedAt) })
+	b.WriteString("\n## Discussion\n")
+	for _, c := range comments {
+		fmt.Fprintf(b, "\n### @%s, %s\n\n%s\n", c.User.Login, c.CreatedAt.Format("2006-01-02 15:04"), strings.TrimSpace(c.Body))
+	}
+}
+
+// writeGitHubCommits writes the commits, one per line
+func writeGitHubCommits(b *strings.Builder, commits []githubCommit) {
+	if len(commits) == 0 {
+		return
+	}
+	b.WriteString("\n## Commits\n\n")
+	for _, c := range commits {
+		subject, _, _ := strings.Cut(c.Commit.Message, "\n")
+		fmt.Fprintf(b, "- %.7s %s (%s)\n", c.SHA, subject, c.Commit.Author.Name)
+	}
+}
+
+// writeGitHubDiff writes the changed files as a unified diff, up to
+// maxGitHubDiff
+func writeGitHubDiff(b *strings.Builder, files []githubFile) {
+	if len(files) == 0 {
+		return
+	}
+	b.WriteString("\n## Diff\n\n```diff\n")
+	size := 0
+	for i, f := range files {
+		if size > maxGitHubDiff {
+			fmt.Fprintf(b, "\n... diff cut short, %d more files changed\n", len(files)-i)
+			break
+		}
+		old := f.Filename
+		if f.PreviousFilename != "" {
+			old = f.PreviousFilename
+		}
+		fmt.Fprintf(b, "diff --git a/%s b/%s\n", old, f.Filename)
+		if f.Patch == "" {
+			// GitHub leaves out binary files and very large changes
+			fmt.Fprintf(b, "(%s, no diff shown)\n", f.Status)
+			continue
+		}
+		fmt.Fprintf(b, "--- a/%s\n+++ b/%s\n%s\n", old, f.Filename, strings.TrimRight(f.Patch, "\n"))
+		size += len(f.Patch)
+	}
+	b.WriteString("```\n")
+}
+
+// githubList reads every page of a list, up to githubMaxPages
+func githubList[T any](ctx context.Context, a *App, token, path string) ([]T, error) {
+	var items []T
+	next := path + "?per_page=100"
+	for page := 0; next != "" && page < githubMaxPages; page++ {
+		var part []T
+		var err error
+		if next, err = a.githubRequest(ctx, token, next, &part); err != nil {
+			return nil, err
+		}
+		items = append(items, part...)
+	}
+	return items, nil
+}
+
+// githubRequest GETs an API URL, decoding the response into out, and
+// returns the URL of the next page, empty on the last
+func (a *App) githubRequest(ctx context.Context, token, apiURL string, out any) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("User-Agent", "FabricGUI/"+appVersion)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := a.externalClient().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach GitHub: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(data, &apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized:
+			return "", fmt.Errorf("GitHub rejected the token: %s", apiErr.Message)
+		case (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests) && resp.Header.Get("X-RateLimit-Remaining") == "0":
+			if token == "" {
+				return "", fmt.Errorf("GitHub rate limit reached, set a GitHub token for a higher limit")
+			}
+			return "", fmt.Errorf("GitHub rate limit reached, try again later")
+		case resp.StatusCode == http.StatusNotFound && token == "":
+			return "", fmt.Errorf("not found on GitHub; private repositories need a GitHub token")
+		}
+		return "", fmt.Errorf("GitHub error %d: %s", resp.StatusCode, apiErr.Message)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return "", fmt.Errorf("failed to parse GitHub response: %v", err)
+	}
+	// Pages are only followed on the same server, which the token was meant for
+	next := ""
+	if m := githubNextLink.FindStringSubmatch(resp.Header.Get("Link")); m != nil && sameOrigin(m[1], apiURL) {
+		next = m[1]
+	}
+	return next, nil
+}
+
+// sameOrigin reports whether two URLs have the same scheme and host
+func sameOrigin(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	return errA == nil && errB == nil && ua.Scheme == ub.Scheme && strings.EqualFold(ua.Host, ub.Host)
+}
@@ -0,0 +1,716 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultHistoryMaxEntries is used when no entry limit is configured
+const defaultHistoryMaxEntries = 50
+
+// historyRetention limits how much history is kept. Zero means unlimited,
+// except MaxEntries which falls back to defaultHistoryMaxEntries.
+type historyRetention struct {
+	MaxEntries int
+	MaxBytes   int64
+	MaxAge     time.Duration
+}
+
+// historyStore is a mutex-protected list of history entries. It is shared by
+// chat goroutines and frontend bindings, so it only ever hands out copies.
+// When a path is set, every change is written to disk.
+type historyStore struct {
+	mu        sync.RWMutex
+	entries   []HistoryEntry
+	retention historyRetention
+	path      string
+	crypt     historyCrypt
+	// onDisk holds the IDs in the file as last read or written, and stamp
+	// its size and time, so other processes' changes can be merged in
+	onDisk map[string]bool
+	stamp  fileStamp
+}
+
+// fileStamp tells whether a file changed since it was last seen
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+func statFile(path string) (fileStamp, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}, false
+	}
+	return fileStamp{size: info.Size(), modTime: info.ModTime()}, true
+}
+
+func newHistoryStore(maxEntries int) *historyStore {
+	return &historyStore{entries: []HistoryEntry{}, retention: historyRetention{MaxEntries: maxEntries}}
+}
+
+// Load reads persisted entries from path and keeps saving there afterwards
+func (h *historyStore) Load(path string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.path = path
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read history: %v", err)
+	}
+	h.stamp, _ = statFile(path)
+
+	if salt, sealed, ok := parseHistoryFile(data); ok {
+		// Kept aside until Unlock supplies the key
+		h.crypt = historyCrypt{salt: salt, sealed: sealed, locked: true}
+		return errHistoryLocked
+	}
+
+	var entries []HistoryEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to parse history: %v", err)
+	}
+	h.onDisk = historyIDs(entries)
+
+	// Entries saved before IDs existed get one now
+	for i := range entries {
+		if entries[i].ID == "" {
+			entries[i].ID = newHistoryID()
+		}
+	}
+	h.entries = entries
+	return nil
+}
+
+func historyIDs(entries []HistoryEntry) map[string]bool {
+	ids := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		if e.ID != "" {
+			ids[e.ID] = true
+		}
+	}
+	return ids
+}
+
+// SetRetention changes the limits applied by Add and Prune
+func (h *historyStore) SetRetention(retention historyRetention) {
+	h.mu.Lock()
+	h.retention = retention
+	h.mu.Unlock()
+}
+
+// Add appends an entry, dropping the oldest ones beyond the retention limits,
+// and returns the entry's ID
+func (h *historyStore) Add(entry HistoryEntry) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if entry.ID == "" {
+		entry.ID = newHistoryID()
+	}
+	h.entries = append(h.entries, entry)
+	h.pruneLocked(time.Now())
+	h.saveLocked()
+	return entry.ID
+}
+
+// Merge adds entries that are not already present, matching on timestamp and
+// content hash, keeps everything in time order and returns how many were added
+func (h *historyStore) Merge(entries []HistoryEntry) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	known := make(map[string]bool, len(h.entries))
+	ids := make(map[string]bool, len(h.entries))
+	for _, e := range h.entries {
+		known[e.dedupeKey()] = true
+		ids[e.ID] = true
+	}
+
+	added := 0
+	for _, e := range entries {
+		key := e.dedupeKey()
+		if known[key] {
+			continue
+		}
+		if e.ID == "" || ids[e.ID] {
+			e.ID = newHistoryID()
+		}
+		known[key] = true
+		ids[e.ID] = true
+		h.entries = append(h.entries, e)
+		added++
+	}
+
+	if added > 0 {
+		sort.SliceStable(h.entries, func(i, k int) bool {
+			return h.entries[i].Time < h.entries[k].Time
+		})
+		h.pruneLocked(time.Now())
+		h.saveLocked()
+	}
+	return added
+}
+
+// Find returns a copy of the entry with the given ID
+func (h *historyStore) Find(id string) (HistoryEntry, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if i := h.indexLocked(id); i >= 0 {
+		return h.entries[i], true
+	}
+	return HistoryEntry{}, false
+}
+
+// Index returns the position of the entry with the given ID, or -1
+func (h *historyStore) Index(id string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.indexLocked(id)
+}
+
+// Delete removes the entry with the given ID
+func (h *historyStore) Delete(id string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	i := h.indexLocked(id)
+	if i < 0 {
+		return false
+	}
+	h.entries = append(h.entries[:i], h.entries[i+1:]...)
+	h.saveLocked()
+	return true
+}
+
+// Clear removes every entry
+func (h *historyStore) Clear() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries = []HistoryEntry{}
+	h.saveLocked()
+}
+
+// Update applies fn to the entry with the given ID and saves the result
+func (h *historyStore) Update(id string, fn func(*HistoryEntry)) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	i := h.indexLocked(id)
+	if i < 0 {
+		return false
+	}
+	fn(&h.entries[i])
+	h.saveLocked()
+	return true
+}
+
+func (h *historyStore) indexLocked(id string) int {
+	for i := range h.entries {
+		if h.entries[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Prune applies the retention limits and returns how many entries were removed
+func (h *historyStore) Prune() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	removed := h.pruneLocked(time.Now())
+	if removed > 0 {
+		h.saveLocked()
+	}
+	return removed
+}
+
+// pruneLocked drops entries that are too old, then the oldest entries until
+// the count and size limits are met. Entries are kept oldest first. Pinned
+// entries are never pruned and do not count towards the limits.
+func (h *historyStore) pruneLocked(now time.Time) int {
+	r := h.retention
+	maxEntries := r.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultHistoryMaxEntries
+	}
+
+	var size int64
+	count := 0
+	for _, e := range h.entries {
+		if !e.Pinned {
+			size += e.size()
+			count++
+		}
+	}
+
+	kept := make([]HistoryEntry, 0, len(h.entries))
+	for _, e := range h.entries {
+		if !e.Pinned {
+			tooOld := r.MaxAge > 0 && now.Sub(time.Unix(e.Time, 0)) > r.MaxAge
+			tooMany := count > maxEntries
+			tooBig := r.MaxBytes > 0 && size > r.MaxBytes
+			if tooOld || tooMany || tooBig {
+				size -= e.size()
+				count--
+				continue
+			}
+		}
+		kept = append(kept, e)
+	}
+
+	dropped := len(h.entries) - len(kept)
+	if dropped > 0 {
+		h.entries = kept
+	}
+	return dropped
+}
+
+// saveLocked writes the entries to disk, if persistence is enabled. The
+// command line and the app can run at once, so the file is locked and what
+// the other process saved is merged in first.
+func (h *historyStore) saveLocked() error {
+	// A locked store would overwrite the encrypted history with what little
+	// was added since startup
+	if h.path == "" || h.crypt.locked {
+		return nil
+	}
+
+	unlock, err := lockFile(h.path)
+	if err != nil {
+		return fmt.Errorf("failed to save history: %v", err)
+	}
+	defer unlock()
+	h.mergeDiskLocked()
+
+	data, err := json.Marshal(h.entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode history: %v", err)
+	}
+	if h.crypt.key != nil {
+		if data, err = encodeHistoryFile(h.crypt.key, h.crypt.salt, data); err != nil {
+			return fmt.Errorf("failed to encrypt history: %v", err)
+		}
+	}
+	if err := writeFileAtomic(h.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to save history: %v", err)
+	}
+	h.onDisk = historyIDs(h.entries)
+	h.stamp, _ = statFile(h.path)
+	return nil
+}
+
+// mergeDiskLocked picks up what another process changed in the file since
+// it was last read or written: its new entries are added and the ones it
+// removed are dropped. Entries removed here stay removed, and for entries
+// both changed the in-memory version wins.
+func (h *historyStore) mergeDiskLocked() {
+	stamp, ok := statFile(h.path)
+	if !ok || stamp == h.stamp {
+		return
+	}
+	data, err := os.ReadFile(h.path)
+	if err != nil {
+		return
+	}
+	if _, sealed, ok := parseHistoryFile(data); ok {
+		// A file encrypted with another key cannot be merged
+		if h.crypt.key == nil {
+			return
+		}
+		if data, err = openSealed(h.crypt.key, sealed); err != nil {
+			return
+		}
+	}
+	var disk []HistoryEntry
+	if err := json.Unmarshal(data, &disk); err != nil {
+		return
+	}
+	onDisk := historyIDs(disk)
+
+	ids := make(map[string]bool, len(h.entries))
+	kept := h.entries[:0]
+	for _, e := range h.entries {
+		if h.onDisk[e.ID] && !onDisk[e.ID] {
+			continue
+		}
+		ids[e.ID] = true
+		kept = append(kept, e)
+	}
+	h.entries = kept
+	added := false
+	for _, e := range disk {
+		if e.ID != "" && !ids[e.ID] && !h.onDisk[e.ID] {
+			h.entries = append(h.entries, e)
+			added = true
+		}
+	}
+	if added {
+		sort.SliceStable(h.entries, func(i, k int) bool {
+			return h.entries[i].Time < h.entries[k].Time
+		})
+	}
+	h.pruneLocked(time.Now())
+}
+
+// lockFile takes a lock on path shared with other processes, by creating
+// path.lock, and returns the function that releases it. A lock left behind
+// by a crashed process is taken over once it is staleLockAge old.
+func lockFile(path string) (func(), error) {
+	const staleLockAge = 30 * time.Second
+	lock := path + ".lock"
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lock) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(lock)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s is locked by another process", filepath.Base(path))
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// All returns a copy of every entry, oldest first
+func (h *historyStore) All() []HistoryEntry {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	entries := make([]HistoryEntry, len(h.entries))
+	copy(entries, h.entries)
+	return entries
+}
+
+// Len returns the number of entries
+func (h *historyStore) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.entries)
+}
+
+// Get returns a copy of the entry at index
+func (h *historyStore) Get(index int) (HistoryEntry, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if index < 0 || index >= len(h.entries) {
+		return HistoryEntry{}, false
+	}
+	return h.entries[index], true
+}
+
+// Last returns a copy of the most recent entry
+func (h *historyStore) Last() (HistoryEntry, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if len(h.entries) == 0 {
+		return HistoryEntry{}, false
+	}
+	return h.entries[len(h.entries)-1], true
+}
+
+// dedupeKey identifies an entry by its timestamp and a hash of its content
+func (e HistoryEntry) dedupeKey() string {
+	sum := sha256.Sum256([]byte(e.Pattern + "\x00" + e.Model + "\x00" + e.Input + "\x00" + e.Output))
+	return strconv.FormatInt(e.Time, 10) + ":" + hex.EncodeToString(sum[:])
+}
+
+// size approximates the storage used by an entry
+func (e HistoryEntry) size() int64 {
+	return int64(len(e.Pattern) + len(e.Model) + len(e.Input) + len(e.Output))
+}
+
+// runStats describes how a chat run went, for the history log
+type runStats struct {
+	usage    streamUsage
+	duration time.Duration
+	err      error
+}
+
+// addUsage accumulates token counts across the requests making up a run
+func (s *runStats) addUsage(u streamUsage) {
+	s.usage.InputTokens += u.InputTokens
+	s.usage.OutputTokens += u.OutputTokens
+	s.usage.TotalTokens += u.TotalTokens
+}
+
+// recordHistory stores the result of a finished chat run and returns the new entry's ID
+func (a *App) recordHistory(run chatRun, output string, stats runStats) string {
+	entry := HistoryEntry{
+		Pattern:          run.Prompt.PatternName,
+		Model:            run.Prompt.Model,
+		Input:            run.Prompt.UserInput,
+		Output:           output,
+		Time:             time.Now().Unix(),
+		RerunOf:          run.RerunOf,
+		Variables:        run.Prompt.Variables,
+		Context:          run.Prompt.ContextName,
+		Strategy:         run.Prompt.StrategyName,
+		Session:          run.Prompt.SessionName,
+		SystemPrompt:     run.Prompt.SystemPrompt,
+		SystemMode:       run.Prompt.SystemMode,
+		ThreadID:         run.ThreadID,
+		ChunkOf:          run.ChunkOf,
+		Part:             run.Part,
+		Vendor:           run.Prompt.Vendor,
+		DurationMs:       stats.duration.Milliseconds(),
+		PromptTokens:     stats.usage.InputTokens,
+		CompletionTokens: stats.usage.OutputTokens,
+	}
+	titles := a.historyTitleMode()
+	if stats.err != nil {
+		entry.Error = toChatError(stats.err).Message
+	} else if titles != titlesOff {
+		entry.Title = heuristicTitle(entry.Input, entry.Output)
+	}
+
+	id := a.history.Add(entry)
+	a.patternStats.Record(entry.Pattern, entry.DurationMs, len(entry.Output), stats.err != nil)
+	titled := make(chan struct{})
+	if stats.err == nil && titles == titlesModel {
+		a.goTask(func() {
+			defer close(titled)
+			a.generateTitle(id, entry)
+		})
+	} else {
+		close(titled)
+	}
+	if stats.err == nil && run.Part == 0 {
+		entry.ID = id
+		a.goTask(func() { a.appendRunToDailyNote(entry) })
+		a.goTask(func() {
+			// Saved files are named after the title, so wait for the model's
+			<-titled
+			if e, ok := a.history.Find(id); ok {
+				entry.Title = e.Title
+			}
+			a.autoSaveRun(entry)
+		})
+		a.goTask(func() { a.deliverRunToWebhooks(entry) })
+		a.goTask(func() { a.runOutputHooks(entry) })
+	}
+	return id
+}
+
+// RerunOverrides optionally replaces parts of a history entry when it is replayed
+type RerunOverrides struct {
+	Pattern string `json:"pattern"`
+	Vendor  string `json:"vendor"`
+	Model   string `json:"model"`
+	Input   string `json:"input"`
+}
+
+// RerunHistoryEntry replays a past run, optionally with a different pattern,
+// model or input, and returns the stream ID. The new history entry links
+// back to the original so the two can be compared.
+func (a *App) RerunHistoryEntry(id string, overrides RerunOverrides) (string, error) {
+	entry, ok := a.history.Find(id)
+	if !ok {
+		return "", fmt.Errorf("history entry %s not found", id)
+	}
+
+	pattern := entry.Pattern
+	if overrides.Pattern != "" {
+		pattern = overrides.Pattern
+	}
+	model := entry.Model
+	if overrides.Model != "" {
+		model = overrides.Model
+	}
+	input := entry.Input
+	if overrides.Input != "" {
+		input = overrides.Input
+	}
+	extras := entry.extras()
+	// Variables belong to the pattern, another pattern will not know them
+	if pattern != entry.Pattern {
+		extras.Variables = nil
+	}
+
+	vendor := overrides.Vendor
+	if vendor == "" {
+		var err error
+		if vendor, err = a.vendorForModel(model); err != nil {
+			return "", err
+		}
+	}
+
+	prompt := PromptRequest{
+		UserInput:   input,
+		Vendor:      vendor,
+		Model:       model,
+		PatternName: pattern,
+	}
+	extras.apply(&prompt)
+
+	streamID, job, ctx := a.openStream(chatTitle(pattern) + " (rerun)")
+	go a.runStream(streamID, job, ctx, chatRun{Prompt: prompt, RerunOf: entry.ID})
+	return streamID, nil
+}
+
+// DeleteHistoryEntry removes a single history entry
+func (a *App) DeleteHistoryEntry(id string) error {
+	if !a.history.Delete(id) {
+		return fmt.Errorf("history entry %s not found", id)
+	}
+	return nil
+}
+
+// ClearHistory removes all history entries
+func (a *App) ClearHistory() {
+	a.history.Clear()
+}
+
+// UpdateHistoryEntryNote sets the annotation on a history entry
+func (a *App) UpdateHistoryEntryNote(id, note string) error {
+	if !a.history.Update(id, func(e *HistoryEntry) { e.Note = note }) {
+		return fmt.Errorf("history entry %s not found", id)
+	}
+	return nil
+}
+
+// TogglePin pins or unpins a history entry and returns the new state
+func (a *App) TogglePin(id string) (bool, error) {
+	var pinned bool
+	if !a.history.Update(id, func(e *HistoryEntry) {
+		e.Pinned = !e.Pinned
+		pinned = e.Pinned
+	}) {
+		return false, fmt.Errorf("history entry %s not found", id)
+	}
+	return pinned, nil
+}
+
+// SetTags replaces the tags on a history entry
+func (a *App) SetTags(id string, tags []string) error {
+	tags = normalizeTags(tags)
+	if !a.history.Update(id, func(e *HistoryEntry) { e.Tags = tags }) {
+		return fmt.Errorf("history entry %s not found", id)
+	}
+	return nil
+}
+
+// GetHistoryTags returns every tag in use, sorted
+func (a *App) GetHistoryTags() []string {
+	seen := map[string]bool{}
+	tags := []string{}
+	for _, e := range a.history.All() {
+		for _, tag := range e.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// extras returns the optional request settings the entry was run with
+func (e HistoryEntry) extras() ChatExtras {
+	return ChatExtras{
+		Variables:    e.Variables,
+		Context:      e.Context,
+		Strategy:     e.Strategy,
+		Session:      e.Session,
+		SystemPrompt: e.SystemPrompt,
+		SystemMode:   e.SystemMode,
+	}
+}
+
+// hasTag reports whether the entry carries tag, ignoring case
+func (e HistoryEntry) hasTag(tag string) bool {
+	for _, t := range e.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeTags trims tags and drops empty and duplicate ones
+func normalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+// PruneHistory applies the retention preferences now and returns how many entries were removed
+func (a *App) PruneHistory() int {
+	return a.history.Prune()
+}
+
+// applyHistoryRetention configures the history limits from preferences
+func (a *App) applyHistoryRetention(prefs *Preferences) {
+	a.history.SetRetention(historyRetention{
+		MaxEntries: prefs.HistoryMaxEntries,
+		MaxBytes:   int64(prefs.HistoryMaxSizeMB) * 1024 * 1024,
+		MaxAge:     time.Duration(prefs.HistoryMaxAgeDays) * 24 * time.Hour,
+	})
+}
+
+// newHistoryID returns a random identifier for a history entry
+func newHistoryID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// writeFileAtomic writes data to a temporary file and renames it over path,
+// so a crash mid-write never leaves a truncated file behind
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// History encryption modes
+const (
+	HistoryEncryptionOff        = "off"
+	HistoryEncryptionKeychain   = "keychain"   // random key kept in secret storage
+	HistoryEncryptionPassphrase = "passphrase" // key derived from a passphrase asked for at startup
+)
+
+// historyFileMagic starts an encrypted history file, followed by the salt
+// and the AES-GCM sealed JSON
+const historyFileMagic = "FABRICGUI-HISTORY-1\n"
+
+// historyKeySecret names the key of keychain-mode encryption in secret
+// storage
+const historyKeySecret = "fabric-gui-history-key"
+
+// historySaltSize is the length of the passphrase salt
+const historySaltSize = 16
+
+// errHistoryLocked is returned by historyStore.Load for an encrypted file
+var errHistoryLocked = errors.New("history is encrypted")
+
+// historyCrypt is the encryption state of a historyStore
+type historyCrypt struct {
+	key    []byte // encrypts the file when set
+	salt   []byte
+	locked bool   // the file could not be read yet, nothing is saved
+	sealed []byte // the file's ciphertext, while locked
+}
+
+// HistoryEncryptionStatus reports how history is stored
+type HistoryEncryptionStatus struct {
+	Mode   string `json:"mode"`
+	Locked bool   `json:"locked"` // waiting for UnlockHistory
+}
+
+// encodeHistoryFile seals history JSON for writing to disk
+func encodeHistoryFile(key, salt, data []byte) ([]byte, error) {
+	sealed, err := seal(key, data)
+	if err != nil {
+		return nil, err
+	}
+	out := append([]byte(historyFileMagic), salt...)
+	return append(out, sealed...), nil
+}
+
+// parseHistoryFile splits an encrypted history file into salt and
+// ciphertext, reporting false for a plaintext one
+func parseHistoryFile(data []byte) ([]byte, []byte, bool) {
+	if !bytes.HasPrefix(data, []byte(historyFileMagic)) || len(data) < len(historyFileMagic)+historySaltSize {
+		return nil, nil, false
+	}
+	rest := data[len(historyFileMagic):]
+	return rest[:historySaltSize], rest[historySaltSize:], true
+}
+
+// deriveHistoryKey stretches a passphrase into an AES-256 key
+func deriveHistoryKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+}
+
+// Unlock decrypts the history read by Load, keeping any entries added while
+// it was locked
+func (h *historyStore) Unlock(key []byte) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.crypt.locked {
+		return nil
+	}
+	data, err := openSealed(key, h.crypt.sealed)
+	if err != nil {
+		return fmt.Errorf("wrong passphrase or damaged history")
+	}
+	var entries []HistoryEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to parse history: %v", err)
+	}
+
+	h.onDisk = historyIDs(entries)
+	h.entries = append(entries, h.entries...)
+	h.crypt = historyCrypt{key: key, salt: h.crypt.salt}
+	h.pruneLocked(time.Now())
+	h.saveLocked()
+	return nil
+}
+
+// Salt returns the salt of the encrypted file
+func (h *historyStore) Salt() []byte {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.crypt.salt
+}
+
+// Locked reports whether the store is waiting for its key
+func (h *historyStore) Locked() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.crypt.locked
+}
+
+// Seal encrypts data like the history file, for other files holding history
+// content. With encryption off the data is returned as is.
+func (h *historyStore) Seal(data []byte) ([]byte, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.crypt.locked {
+		return nil, errHistoryLocked
+	}
+	if h.crypt.key == nil {
+		return data, nil
+	}
+	return encodeHistoryFile(h.crypt.key, h.crypt.salt, data)
+}
+
+// Open reverses Seal. Plaintext data is returned as is.
+func (h *historyStore) Open(data []byte) ([]byte, error) {
+	_, sealed, ok := parseHistoryFile(data)
+	if !ok {
+		return data, nil
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.crypt.locked {
+		return nil, errHistoryLocked
+	}
+	if h.crypt.key == nil {
+		return nil, fmt.Errorf("file is encrypted but history encryption is off")
+	}
+	return openSealed(h.crypt.key, sealed)
+}
+
+// SetKey re-saves the history encrypted with key, or as plaintext when key
+// is nil
+func (h *historyStore) SetKey(key, salt []byte) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.crypt.locked {
+		return fmt.Errorf("unlock the history first")
+	}
+	previous := h.crypt
+	h.crypt = historyCrypt{key: key, salt: salt}
+	if err := h.saveLocked(); err != nil {
+		h.crypt = previous
+		return err
+	}
+	return nil
+}
+
+// Key returns the key and salt the history is encrypted with, nil when
+// encryption is off
+func (h *historyStore) Key() ([]byte, []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.crypt.key, h.crypt.salt
+}
+
+// GetHistoryEncryption reports the encryption mode and whether the history
+// still needs unlocking
+func (a *App) GetHistoryEncryption() HistoryEncryptionStatus {
+	status := HistoryEncryptionStatus{Mode: HistoryEncryptionOff, Locked: a.history.Locked()}
+	if prefs, err := a.loadPreferences(); err == nil && prefs.HistoryEncryption != "" {
+		status.Mode = prefs.HistoryEncryption
+	}
+	return status
+}
+
+// EnableHistoryEncryption encrypts the history file, existing entries
+// included, with a key kept in secret storage or derived from passphrase
+func (a *App) EnableHistoryEncryption(mode, passphrase string) error {
+	if a.history.Locked() {
+		return fmt.Errorf("unlock the history first")
+	}
+	salt := make([]byte, historySaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	var key []byte
+	switch mode {
+	case HistoryEncryptionKeychain:
+		if a.secrets == nil {
+			return fmt.Errorf("secret storage is not available")
+		}
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return err
+		}
+	case HistoryEncryptionPassphrase:
+		if len(passphrase) < 8 {
+			return fmt.Errorf("the passphrase must be at least 8 characters")
+		}
+		var err error
+		if key, err = deriveHistoryKey(passphrase, salt); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown history encryption mode %q", mode)
+	}
+
+	previousKey, previousSalt := a.history.Key()
+	if err := a.history.SetKey(key, salt); err != nil {
+		return err
+	}
+	// The key is only stored once the history is encrypted with it, and the
+	// history goes back to the old key if it cannot be stored
+	if mode == HistoryEncryptionKeychain {
+		if err := a.secrets.Set(historyKeySecret, hex.EncodeToString(key)); err != nil {
+			if restoreErr := a.history.SetKey(previousKey, previousSalt); restoreErr != nil {
+				a.log.Error("failed to restore the history key", "error", restoreErr)
+			}
+			return fmt.Errorf("failed to store the history key: %v", err)
+		}
+	}
+	if err := a.setHistoryEncryption(mode); err != nil {
+		return err
+	}
+	a.log.Info("history encryption enabled", "mode", mode)
+	return nil
+}
+
+// DisableHistoryEncryption writes the history back as plaintext
+func (a *App) DisableHistoryEncryption() error {
+	if err := a.history.SetKey(nil, nil); err != nil {
+		return err
+	}
+	if a.secrets != nil {
+		a.secrets.Delete(historyKeySecret)
+	}
+	if err := a.setHistoryEncryption(HistoryEncryptionOff); err != nil {
+		return err
+	}
+	a.log.Info("history encryption disabled")
+	return nil
+}
+
+// UnlockHistory decrypts passphrase-protected history, emitting
+// "history:unlocked" once the entries are available
+func (a *App) UnlockHistory(passphrase string) error {
+	key, err := deriveHistoryKey(passphrase, a.history.Salt())
+	if err != nil {
+		return err
+	}
+	if err := a.history.Unlock(key); err != nil {
+		return err
+	}
+	a.emit("history:unlocked", a.history.Len())
+	return nil
+}
+
+// unlockHistoryOnStartup opens encrypted history with the keychain key, or
+// emits "history:locked" for the frontend to ask for the passphrase
+func (a *App) unlockHistoryOnStartup(prefs *Preferences) {
+	if prefs.HistoryEncryption == HistoryEncryptionKeychain && a.secrets != nil {
+		encoded, err := a.secrets.Get(historyKeySecret)
+		if key, decodeErr := hex.DecodeString(encoded); err == nil && decodeErr == nil && len(key) == 32 {
+			if err := a.history.Unlock(key); err == nil {
+				return
+			}
+		}
+		a.log.Error("failed to unlock history with the stored key", "error", err)
+	}
+	a.log.Info("history is locked until the passphrase is entered")
+	a.emit("history:locked", "")
+}
+
+func (a *App) setHistoryEncryption(mode string) error {
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	prefs.HistoryEncryption = mode
+	return a.SavePreferences(*prefs)
+}
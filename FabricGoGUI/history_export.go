@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// HistoryFilter selects history entries. Empty fields match everything.
+type HistoryFilter struct {
+	IDs        []string `json:"ids"`
+	Tag        string   `json:"tag"`
+	Pattern    string   `json:"pattern"`
+	Since      int64    `json:"since"` // Unix seconds
+	Until      int64    `json:"until"` // Unix seconds
+	PinnedOnly bool     `json:"pinnedOnly"`
+}
+
+// matches reports whether an entry passes the filter
+func (f HistoryFilter) matches(e HistoryEntry) bool {
+	if len(f.IDs) > 0 {
+		found := false
+		for _, id := range f.IDs {
+			if id == e.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Tag != "" && !e.hasTag(f.Tag) {
+		return false
+	}
+	if f.Pattern != "" && e.Pattern != f.Pattern {
+		return false
+	}
+	if f.Since > 0 && e.Time < f.Since {
+		return false
+	}
+	if f.Until > 0 && e.Time > f.Until {
+		return false
+	}
+	if f.PinnedOnly && !e.Pinned {
+		return false
+	}
+	return true
+}
+
+// historyExportFormats maps export formats to their file extension and dialog filter
+var historyExportFormats = map[string]runtime.FileFilter{
+	"markdown": {DisplayName: "Markdown", Pattern: "*.md"},
+	"json":     {DisplayName: "JSON", Pattern: "*.json"},
+	"csv":      {DisplayName: "CSV", Pattern: "*.csv"},
+}
+
+// ExportHistory writes the entries matching filter as markdown, json or csv.
+// When path is empty a save dialog is shown. Returns the path written.
+func (a *App) ExportHistory(format string, filter HistoryFilter, path string) (string, error) {
+	fileFilter, ok := historyExportFormats[format]
+	if !ok {
+		return "", fmt.Errorf("unsupported export format %q", format)
+	}
+
+	var entries []HistoryEntry
+	for _, e := range a.history.All() {
+		if filter.matches(e) {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) == 0 {
+		return "", fmt.Errorf("no history entries match the filter")
+	}
+
+	if path == "" {
+		var err error
+		path, err = runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+			Title:           "Export History",
+			DefaultFilename: "fabric_history" + strings.TrimPrefix(fileFilter.Pattern, "*"),
+			Filters:         []runtime.FileFilter{fileFilter},
+		})
+		if err != nil {
+			return "", err
+		}
+		if path == "" {
+			return "", nil // User cancelled
+		}
+	}
+
+	var data []byte
+	var err error
+	switch format {
+	case "markdown":
+		data = historyToMarkdown(entries)
+	case "json":
+		data, err = json.MarshalIndent(entries, "", "  ")
+	case "csv":
+		data, err = historyToCSV(entries)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to export history: %v", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to save file: %v", err)
+	}
+	return path, nil
+}
+
+// ImportHistory merges entries from a JSON file created by ExportHistory,
+// skipping ones already present. When path is empty an open dialog is shown.
+// Returns the number of entries added.
+func (a *App) ImportHistory(path string) (int, error) {
+	if path == "" {
+		var err error
+		path, err = runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+			Title:   "Import History",
+			Filters: []runtime.FileFilter{historyExportFormats["json"]},
+		})
+		if err != nil {
+			return 0, err
+		}
+		if path == "" {
+			return 0, nil // User cancelled
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read file: %v", err)
+	}
+
+	var entries []HistoryEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return 0, fmt.Errorf("file is not a history export: %v", err)
+	}
+
+	added := a.history.Merge(entries)
+	a.emit("history:imported", added)
+	return added, nil
+}
+
+// historyToMarkdown renders entries as a document with one section per run
+func historyToMarkdown(entries []HistoryEntry) []byte {
+	var sb strings.Builder
+	sb.WriteString("# Fabric History\n\n")
+	fmt.Fprintf(&sb, "Exported %s, %d entries.\n", time.Now().Format("2006-01-02 15:04"), len(entries))
+
+	for _, e := range entries {
+		heading := e.Title
+		if heading == "" {
+			heading = e.Pattern
+		}
+		fmt.Fprintf(&sb, "\n---\n\n## %s — %s\n\n", heading, time.Unix(e.Time, 0).Format("2006-01-02 15:04:05"))
+		fmt.Fprintf(&sb, "- **Pattern:** %s\n", e.Pattern)
+		fmt.Fprintf(&sb, "- **Model:** %s\n", e.Model)
+		if e.Vendor != "" {
+			fmt.Fprintf(&sb, "- **Vendor:** %s\n", e.Vendor)
+		}
+		if e.DurationMs > 0 {
+			fmt.Fprintf(&sb, "- **Duration:** %s\n", time.Duration(e.DurationMs)*time.Millisecond)
+		}
+		if e.PromptTokens > 0 || e.CompletionTokens > 0 {
+			fmt.Fprintf(&sb, "- **Tokens:** %d prompt, %d completion\n", e.PromptTokens, e.CompletionTokens)
+		}
+		if e.Error != "" {
+			fmt.Fprintf(&sb, "- **Error:** %s\n", e.Error)
+		}
+		fmt.Fprintf(&sb, "- **Time:** %s\n", time.Unix(e.Time, 0).Format(time.RFC3339))
+		if len(e.Tags) > 0 {
+			fmt.Fprintf(&sb, "- **Tags:** %s\n", strings.Join(e.Tags, ", "))
+		}
+		if e.Note != "" {
+			fmt.Fprintf(&sb, "- **Note:** %s\n", e.Note)
+		}
+		fmt.Fprintf(&sb, "\n### Input\n\n%s\n\n### Output\n\n%s\n", fenceText(e.Input), e.Output)
+	}
+	return []byte(sb.String())
+}
+
+// fenceText wraps text in a code fence, longer than any fence inside it, so
+// stray Markdown in the input cannot break the document structure
+func fenceText(text string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + "\n" + strings.TrimRight(text, "\n") + "\n" + fence
+}
+
+// historyToCSV renders entries as one row per run
+func historyToCSV(entries []HistoryEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	w.Write([]string{"id", "title", "time", "pattern", "vendor", "model", "duration_ms", "prompt_tokens", "completion_tokens", "error", "tags", "pinned", "note", "input", "output"})
+	for _, e := range entries {
+		w.Write([]string{
+			e.ID,
+			e.Title,
+			time.Unix(e.Time, 0).Format(time.RFC3339),
+			e.Pattern,
+			e.Vendor,
+			e.Model,
+			strconv.FormatInt(e.DurationMs, 10),
+			strconv.Itoa(e.PromptTokens),
+			strconv.Itoa(e.CompletionTokens),
+			e.Error,
+			strings.Join(e.Tags, ";"),
+			strconv.FormatBool(e.Pinned),
+			e.Note,
+			e.Input,
+			e.Output,
+		})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestHistoryStoreConcurrentAccess(t *testing.T) {
+	h := newHistoryStore(10000)
+
+	const writers, perWriter = 8, 50
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				id := h.Add(HistoryEntry{Pattern: "summarize", Input: fmt.Sprintf("%d-%d", w, i)})
+				// Every other entry is deleted again while others read
+				if i%2 == 1 && !h.Delete(id) {
+					t.Errorf("Delete(%s) found no entry", id)
+				}
+			}
+		}(w)
+	}
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				for _, e := range h.All() {
+					h.Find(e.ID)
+				}
+				h.Last()
+				h.Len()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got, want := h.Len(), writers*perWriter/2; got != want {
+		t.Fatalf("Len() = %d, want %d", got, want)
+	}
+	ids := map[string]bool{}
+	for _, e := range h.All() {
+		if ids[e.ID] {
+			t.Fatalf("duplicate ID %s", e.ID)
+		}
+		ids[e.ID] = true
+	}
+}
+
+func TestHistoryStoreReturnsCopies(t *testing.T) {
+	h := newHistoryStore(10)
+	id := h.Add(HistoryEntry{Pattern: "summarize", Output: "original"})
+	h.Add(HistoryEntry{Pattern: "summarize", Output: "second"})
+
+	all := h.All()
+	all[0].Output = "changed"
+	copy(all, all[1:])
+	if e, _ := h.Find(id); e.Output != "original" {
+		t.Errorf("changing All()'s result changed the store: %q", e.Output)
+	}
+	if h.Len() != 2 {
+		t.Errorf("Len() = %d after changing All()'s result, want 2", h.Len())
+	}
+
+	found, _ := h.Find(id)
+	found.Output = "changed"
+	got, _ := h.Get(0)
+	got.Output = "changed"
+	last, _ := h.Last()
+	last.Output = "changed"
+	for i, e := range h.All() {
+		if e.Output == "changed" {
+			t.Errorf("entry %d was changed through a returned copy", i)
+		}
+	}
+}
+
+func TestHistoryStoreRetention(t *testing.T) {
+	h := newHistoryStore(3)
+	pinned := h.Add(HistoryEntry{Output: "pinned", Pinned: true})
+	for i := 0; i < 5; i++ {
+		h.Add(HistoryEntry{Output: fmt.Sprint(i), Time: int64(i + 1)})
+	}
+
+	var outputs []string
+	for _, e := range h.All() {
+		outputs = append(outputs, e.Output)
+	}
+	if want := "[pinned 2 3 4]"; fmt.Sprint(outputs) != want {
+		t.Errorf("kept %v, want %s", outputs, want)
+	}
+	if _, ok := h.Find(pinned); !ok {
+		t.Error("pinned entry was pruned")
+	}
+}
+
+func TestHistoryStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	h := newHistoryStore(10)
+	if err := h.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	id := h.Add(HistoryEntry{Pattern: "summarize", Output: "saved"})
+	h.Update(id, func(e *HistoryEntry) { e.Note = "note" })
+
+	reloaded := newHistoryStore(10)
+	if err := reloaded.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	e, ok := reloaded.Find(id)
+	if !ok || e.Output != "saved" || e.Note != "note" {
+		t.Errorf("reloaded entry = %+v, %v", e, ok)
+	}
+}
+
+func TestHistoryStoreMergesOtherProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	gui := newHistoryStore(10)
+	if err := gui.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	kept := gui.Add(HistoryEntry{Output: "kept", Time: 1})
+	removed := gui.Add(HistoryEntry{Output: "removed by cli", Time: 2})
+
+	cli := newHistoryStore(10)
+	if err := cli.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	cli.Add(HistoryEntry{Output: "from cli", Time: 3})
+	cli.Delete(removed)
+
+	gui.Add(HistoryEntry{Output: "from gui", Time: 4})
+	gui.Delete(kept)
+
+	reloaded := newHistoryStore(10)
+	if err := reloaded.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	var outputs []string
+	for _, e := range reloaded.All() {
+		outputs = append(outputs, e.Output)
+	}
+	if want := "[from cli from gui]"; fmt.Sprint(outputs) != want {
+		t.Errorf("saved %v, want %s", outputs, want)
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Hook is a shell command run on an output after a run completes. The
+// output is written to its stdin and the run's details are in FABRIC_*
+// environment variables, see hookEnv.
+type Hook struct {
+	Name     string   `json:"name"`
+	Command  string   `json:"command"`            // run with sh -c, or cmd /C on Windows
+	Enabled  bool     `json:"enabled"`            // run after every completed run
+	Patterns []string `json:"patterns,omitempty"` // limits automatic runs to these patterns
+	Timeout  int      `json:"timeout,omitempty"`  // seconds, defaultHookTimeout when 0
+}
+
+// HookResult is emitted as "hook:finished" or "hook:failed"
+type HookResult struct {
+	Name       string `json:"name"`
+	EntryID    string `json:"entryId"`
+	ExitCode   int    `json:"exitCode"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	DurationMs int64  `json:"durationMs"`
+	Error      string `json:"error,omitempty"`
+}
+
+const (
+	// defaultHookTimeout is how long a hook may run when it sets no timeout
+	defaultHookTimeout = 60 * time.Second
+	// maxHookOutput caps the stdout and stderr kept from a hook
+	maxHookOutput = 64 * 1024
+)
+
+// GetHooks returns the configured hooks
+func (a *App) GetHooks() []Hook {
+	prefs, err := a.loadPreferences()
+	if err != nil || prefs.Hooks == nil {
+		return []Hook{}
+	}
+	return prefs.Hooks
+}
+
+// SaveHooks replaces the configured hooks. Names must be unique.
+func (a *App) SaveHooks(hooks []Hook) error {
+	names := []string{}
+	for i := range hooks {
+		hook := &hooks[i]
+		hook.Name = strings.TrimSpace(hook.Name)
+		if hook.Name == "" {
+			return fmt.Errorf("hook %d has no name", i+1)
+		}
+		if containsFold(names, hook.Name) {
+			return fmt.Errorf("duplicate hook name: %s", hook.Name)
+		}
+		names = append(names, hook.Name)
+		if strings.TrimSpace(hook.Command) == "" {
+			return fmt.Errorf("hook %s has no command", hook.Name)
+		}
+		if hook.Timeout < 0 {
+			return fmt.Errorf("hook %s: timeout cannot be negative", hook.Name)
+		}
+	}
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	prefs.Hooks = hooks
+	return a.SavePreferences(*prefs)
+}
+
+// RunHook runs the named hook on a history entry now, whether or not it is
+// enabled. An empty entryID uses the latest entry.
+func (a *App) RunHook(name, entryID string) (HookResult, error) {
+	var hook Hook
+	found := false
+	for _, h := range a.GetHooks() {
+		if strings.EqualFold(h.Name, name) {
+			hook, found = h, true
+			break
+		}
+	}
+	if !found {
+		return HookResult{}, fmt.Errorf("hook not found: %s", name)
+	}
+	entry, ok := a.historyEntryOrLast(entryID)
+	if !ok {
+		return HookResult{}, fmt.Errorf("history entry not found: %s", entryID)
+	}
+	return a.runHook(hook, entry), nil
+}
+
+// runOutputHooks runs the enabled hooks whose patterns match on a finished
+// run, one after another in the configured order
+func (a *App) runOutputHooks(entry HistoryEntry) {
+	for _, hook := range a.GetHooks() {
+		if !hook.Enabled || (len(hook.Patterns) > 0 && !containsFold(hook.Patterns, entry.Pattern)) {
+			continue
+		}
+		result := a.runHook(hook, entry)
+		if result.Error != "" {
+			a.emit("hook:failed", result)
+		} else {
+			a.emit("hook:finished", result)
+		}
+	}
+}
+
+// runHook runs one hook, killing it when it exceeds its timeout
+func (a *App) runHook(hook Hook, entry HistoryEntry) HookResult {
+	timeout := defaultHookTimeout
+	if hook.Timeout > 0 {
+		timeout = time.Duration(hook.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := hookCommand(ctx, hook.Command)
+	cmd.Env = append(os.Environ(), hookEnv(entry)...)
+	cmd.Stdin = strings.NewReader(entry.Output)
+	stdout := &limitedBuffer{max: maxHookOutput}
+	stderr := &limitedBuffer{max: maxHookOutput}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Background processes the command started must not keep it waiting
+	cmd.WaitDelay = 2 * time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	result := HookResult{
+		Name:       hook.Name,
+		EntryID:    entry.ID,
+		Stdout:     stdout.String(),
+		Stderr:     stderr.String(),
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		a.log.Info("ran output hook", "hook", hook.Name, "duration", result.DurationMs)
+		return result
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.Error = fmt.Sprintf("timed out after %s", timeout)
+	case errors.As(err, &exitErr):
+		result.Error = fmt.Sprintf("exited with status %d", result.ExitCode)
+	default:
+		result.Error = fmt.Sprintf("failed to run: %v", err)
+	}
+	a.log.Warn("output hook failed", "hook", hook.Name, "error", result.Error, "stderr", result.Stderr)
+	return result
+}
+
+// hookCommand runs command through the platform's shell
+func hookCommand(ctx context.Context, command string) *exec.Cmd {
+	if goruntime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// hookEnv describes the run to a hook: FABRIC_ENTRY_ID, FABRIC_PATTERN,
+// FABRIC_MODEL, FABRIC_VENDOR, FABRIC_TITLE, FABRIC_TIME (RFC 3339),
+// FABRIC_TAGS (comma-separated), FABRIC_SOURCE (the input URL, when the
+// input was one) and FABRIC_VAR_<NAME> for each pattern variable
+func hookEnv(entry HistoryEntry) []string {
+	env := []string{
+		"FABRIC_ENTRY_ID=" + entry.ID,
+		"FABRIC_PATTERN=" + entry.Pattern,
+		"FABRIC_MODEL=" + entry.Model,
+		"FABRIC_VENDOR=" + entry.Vendor,
+		"FABRIC_TITLE=" + entry.Title,
+		"FABRIC_TIME=" + time.Unix(entry.Time, 0).Format(time.RFC3339),
+		"FABRIC_TAGS=" + strings.Join(entry.Tags, ","),
+		"FABRIC_SOURCE=" + inputSourceURL(entry.Input),
+		"FABRIC_OUTPUT_LENGTH=" + strconv.Itoa(len(entry.Output)),
+	}
+	for name, value := range entry.Variables {
+		key := strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' {
+				return r - 'a' + 'A'
+			}
+			if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+				return r
+			}
+			return '_'
+		}, name)
+		env = append(env, "FABRIC_VAR_"+key+"="+value)
+	}
+	return env
+}
+
+// limitedBuffer keeps the first max bytes written to it and discards the rest
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); room < len(p) {
+		b.buf.Write(p[:max(room, 0)])
+		b.truncated = true
+	} else {
+		b.buf.Write(p)
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	if b.truncated {
+		return b.buf.String() + "\n[truncated]"
+	}
+	return b.buf.String()
+}
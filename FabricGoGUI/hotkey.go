@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	goruntime "runtime"
+	"strings"
+)
+
+// HotkeySettings configure the global hotkey
+type HotkeySettings struct {
+	Enabled      bool   `json:"enabled"`
+	Shortcut     string `json:"shortcut"`     // preferred trigger such as CTRL+ALT+F, the desktop may bind another
+	Preset       string `json:"preset"`       // preset the hotkey runs, the shortcut is not bound when empty
+	Source       string `json:"source"`       // clipboard (default) or selection
+	Output       string `json:"output"`       // popup (default) or clipboard
+	MiniShortcut string `json:"miniShortcut"` // toggles the mini window, not bound when empty
+}
+
+// HotkeyStatus describes the global hotkey
+type HotkeyStatus struct {
+	Supported   bool   `json:"supported"`
+	Running     bool   `json:"running"`
+	Trigger     string `json:"trigger,omitempty"`     // the preset shortcut as the desktop bound it
+	MiniTrigger string `json:"miniTrigger,omitempty"` // the mini window shortcut as the desktop bound it
+	Error       string `json:"error,omitempty"`       // why the hotkey could not be bound
+}
+
+// Hotkey input sources and result outputs
+const (
+	HotkeySourceClipboard = "clipboard"
+	HotkeySourceSelection = "selection" // the selected text, where the desktop has a primary selection
+	HotkeyOutputPopup     = "popup"     // streamed into a popup over the main window
+	HotkeyOutputClipboard = "clipboard" // copied back to the clipboard
+)
+
+// defaultHotkeyShortcut is bound when the settings name no shortcut
+const defaultHotkeyShortcut = "CTRL+ALT+F"
+
+// Global shortcut IDs
+const (
+	hotkeyRunPreset  = "run-preset"
+	hotkeyToggleMini = "toggle-mini"
+)
+
+// GetHotkeySettings returns the global hotkey settings
+func (a *App) GetHotkeySettings() HotkeySettings {
+	if prefs, err := a.loadPreferences(); err == nil {
+		return prefs.Hotkey
+	}
+	return HotkeySettings{}
+}
+
+// SaveHotkeySettings stores the global hotkey settings and binds the hotkey
+// again to match
+func (a *App) SaveHotkeySettings(settings HotkeySettings) error {
+	switch settings.Source {
+	case "", HotkeySourceClipboard, HotkeySourceSelection:
+	default:
+		return fmt.Errorf("unknown hotkey source %q", settings.Source)
+	}
+	switch settings.Output {
+	case "", HotkeyOutputPopup, HotkeyOutputClipboard:
+	default:
+		return fmt.Errorf("unknown hotkey output %q", settings.Output)
+	}
+	if settings.Enabled && settings.Preset == "" && settings.MiniShortcut == "" {
+		return fmt.Errorf("choose a preset for the hotkey to run or a mini window shortcut")
+	}
+	if settings.Preset != "" {
+		if _, err := a.GetPreset(settings.Preset); err != nil {
+			return err
+		}
+	}
+	settings.Shortcut = strings.ToUpper(strings.ReplaceAll(settings.Shortcut, " ", ""))
+	settings.MiniShortcut = strings.ToUpper(strings.ReplaceAll(settings.MiniShortcut, " ", ""))
+
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	previous := prefs.Hotkey
+	prefs.Hotkey = settings
+	if err := a.SavePreferences(*prefs); err != nil {
+		return err
+	}
+
+	if !settings.Enabled {
+		a.stopHotkey()
+		return nil
+	}
+	// The preset and source are read on every press, only new shortcuts
+	// need binding again
+	if previous.Enabled && hotkeyBindings(previous) == hotkeyBindings(settings) && a.hotkeyRunning() {
+		return nil
+	}
+	a.stopHotkey()
+	return a.startHotkey(settings)
+}
+
+// GetHotkeyStatus reports whether the global hotkey is bound
+func (a *App) GetHotkeyStatus() HotkeyStatus {
+	a.hotkeyMutex.Lock()
+	defer a.hotkeyMutex.Unlock()
+	status := HotkeyStatus{Supported: goruntime.GOOS == "linux", Running: a.hotkey != nil, Error: a.hotkeyError}
+	if a.hotkey != nil {
+		status.Trigger = a.hotkey.trigger(hotkeyRunPreset)
+		status.MiniTrigger = a.hotkey.trigger(hotkeyToggleMini)
+	}
+	return status
+}
+
+// startHotkey binds the global hotkeys if they are not bound yet. Only Linux
+// desktops with the GlobalShortcuts portal are supported so far.
+func (a *App) startHotkey(settings HotkeySettings) error {
+	a.hotkeyMutex.Lock()
+	defer a.hotkeyMutex.Unlock()
+	if a.hotkey != nil {
+		return nil
+	}
+	if goruntime.GOOS != "linux" {
+		a.hotkeyError = fmt.Sprintf("the global hotkey is not supported on %s yet", goruntime.GOOS)
+		return fmt.Errorf("%s", a.hotkeyError)
+	}
+	bindings := []shortcutBinding{}
+	if settings.Preset != "" {
+		shortcut := settings.Shortcut
+		if shortcut == "" {
+			shortcut = defaultHotkeyShortcut
+		}
+		bindings = append(bindings, shortcutBinding{ID: hotkeyRunPreset, Trigger: shortcut, Description: "Run a Fabric preset on the clipboard"})
+	}
+	if settings.MiniShortcut != "" {
+		bindings = append(bindings, shortcutBinding{ID: hotkeyToggleMini, Trigger: settings.MiniShortcut, Description: "Show or hide the Fabric mini window"})
+	}
+
+	hotkey, err := newGlobalShortcuts(bindings, func(id string) {
+		switch id {
+		case hotkeyRunPreset:
+			go a.runHotkey()
+		case hotkeyToggleMini:
+			go a.ToggleMiniWindow()
+		}
+	})
+	if err != nil {
+		a.hotkeyError = err.Error()
+		a.log.Warn("failed to bind global hotkey", "error", err)
+		return err
+	}
+	a.hotkey = hotkey
+	a.hotkeyError = ""
+	a.log.Info("bound global hotkey", "trigger", hotkey.trigger(hotkeyRunPreset), "mini", hotkey.trigger(hotkeyToggleMini))
+	return nil
+}
+
+// hotkeyBindings summarises what startHotkey binds for settings, to tell
+// whether changed settings need binding again
+func hotkeyBindings(settings HotkeySettings) string {
+	return fmt.Sprintf("%s|%t|%s", settings.Shortcut, settings.Preset != "", settings.MiniShortcut)
+}
+
+// stopHotkey releases the global hotkey
+func (a *App) stopHotkey() {
+	a.hotkeyMutex.Lock()
+	hotkey := a.hotkey
+	a.hotkey = nil
+	a.hotkeyMutex.Unlock()
+	if hotkey != nil {
+		hotkey.close()
+	}
+}
+
+// hotkeyRunning reports whether the global hotkey is bound
+func (a *App) hotkeyRunning() bool {
+	a.hotkeyMutex.Lock()
+	defer a.hotkeyMutex.Unlock()
+	return a.hotkey != nil
+}
+
+// runHotkey runs the hotkey preset on the selected or copied text. Presses
+// while a run is in progress are ignored.
+func (a *App) runHotkey() {
+	if !a.hotkeyBusy.CompareAndSwap(false, true) {
+		return
+	}
+	defer a.hotkeyBusy.Store(false)
+
+	settings := a.GetHotkeySettings()
+	text, err := a.hotkeyInput(settings.Source)
+	var prompt PromptRequest
+	if err == nil {
+		prompt, err = a.presetPrompt(settings.Preset, text)
+	}
+	if err != nil {
+		a.log.Warn("hotkey run failed", "preset", settings.Preset, "error", err)
+		a.emit("quick:error", err.Error())
+		return
+	}
+
+	if settings.Output == HotkeyOutputClipboard {
+		output, err := a.runQuick(prompt, settings.Preset, nil)
+		if err == nil {
+			err = a.WriteClipboard(output)
+		}
+		if err != nil {
+			a.log.Warn("hotkey run failed", "preset", settings.Preset, "error", err)
+		}
+		return
+	}
+
+	a.showWindow()
+	a.emit("hotkey:started", settings.Preset)
+	a.runQuick(prompt, settings.Preset, func(chunk string) {
+		a.emit("hotkey:chunk", chunk)
+	})
+}
+
+// hotkeyInput reads the text the hotkey runs on. The selection falls back to
+// the clipboard when nothing is selected or the desktop has no selection.
+func (a *App) hotkeyInput(source string) (string, error) {
+	if source == HotkeySourceSelection && goruntime.GOOS == "linux" {
+		if text, err := readSelectionLinux(); err == nil && strings.TrimSpace(text) != "" {
+			return text, nil
+		}
+	}
+	text, err := a.ReadClipboard()
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("the clipboard is empty")
+	}
+	return text, nil
+}
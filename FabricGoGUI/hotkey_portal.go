@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Global hotkeys are bound through the xdg-desktop-portal GlobalShortcuts
+// interface, which works on Wayland as well as X11
+const (
+	portalName      = "org.freedesktop.portal.Desktop"
+	portalPath      = dbus.ObjectPath("/org/freedesktop/portal/desktop")
+	portalShortcuts = "org.freedesktop.portal.GlobalShortcuts"
+	portalRequest   = "org.freedesktop.portal.Request"
+	portalSession   = "org.freedesktop.portal.Session"
+	// portalTimeout bounds waiting for a portal reply, which waits on the
+	// user when the desktop asks them to confirm the shortcut
+	portalTimeout = 2 * time.Minute
+)
+
+// globalShortcuts are shortcuts bound in a GlobalShortcuts portal session
+type globalShortcuts struct {
+	conn      *dbus.Conn
+	session   dbus.ObjectPath
+	triggers  map[string]string // shortcut ID to the shortcut as the desktop describes it
+	activated func(id string)
+	mutex     sync.Mutex
+	pending   map[dbus.ObjectPath]chan *dbus.Signal // portal requests awaiting a Response
+	tokens    int
+}
+
+// shortcutBinding is a shortcut to bind
+type shortcutBinding struct {
+	ID          string
+	Trigger     string // preferred trigger in the XDG shortcut syntax, such as CTRL+ALT+F
+	Description string
+}
+
+// portalShortcut is the (sa{sv}) shortcut format
+type portalShortcut struct {
+	ID      string
+	Options map[string]dbus.Variant
+}
+
+// newGlobalShortcuts binds the shortcuts and calls activated with a
+// shortcut's ID on every press
+func newGlobalShortcuts(bindings []shortcutBinding, activated func(id string)) (*globalShortcuts, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the session bus: %v", err)
+	}
+	g := &globalShortcuts{
+		conn:      conn,
+		triggers:  map[string]string{},
+		activated: activated,
+		pending:   map[dbus.ObjectPath]chan *dbus.Signal{},
+	}
+	err = conn.AddMatchSignal(dbus.WithMatchInterface(portalRequest), dbus.WithMatchMember("Response"))
+	if err == nil {
+		err = conn.AddMatchSignal(dbus.WithMatchObjectPath(portalPath), dbus.WithMatchInterface(portalShortcuts), dbus.WithMatchMember("Activated"))
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to watch the desktop portal: %v", err)
+	}
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+	go g.dispatch(signals)
+
+	results, err := g.request("CreateSession", map[string]dbus.Variant{
+		"session_handle_token": dbus.MakeVariant("fabric_gui"),
+	})
+	if err != nil {
+		conn.Close()
+		if dbusErr, ok := err.(dbus.Error); ok && (dbusErr.Name == "org.freedesktop.DBus.Error.UnknownMethod" || dbusErr.Name == "org.freedesktop.DBus.Error.ServiceUnknown") {
+			return nil, fmt.Errorf("the desktop does not support global shortcuts, it needs the GlobalShortcuts portal (KDE Plasma 5.27 or GNOME 48 and later)")
+		}
+		return nil, fmt.Errorf("failed to create shortcut session: %v", err)
+	}
+	var session string
+	// Portals disagree on whether the handle is a string or an object path
+	switch handle := results["session_handle"].Value().(type) {
+	case string:
+		session = handle
+	case dbus.ObjectPath:
+		session = string(handle)
+	}
+	if !dbus.ObjectPath(session).IsValid() {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create shortcut session: the portal returned no session")
+	}
+	g.mutex.Lock()
+	g.session = dbus.ObjectPath(session)
+	g.mutex.Unlock()
+
+	shortcuts := make([]portalShortcut, len(bindings))
+	for i, binding := range bindings {
+		g.triggers[binding.ID] = binding.Trigger
+		shortcuts[i] = portalShortcut{
+			ID: binding.ID,
+			Options: map[string]dbus.Variant{
+				"description":       dbus.MakeVariant(binding.Description),
+				"preferred_trigger": dbus.MakeVariant(binding.Trigger),
+			},
+		}
+	}
+	results, err = g.request("BindShortcuts", map[string]dbus.Variant{}, g.session, shortcuts, "")
+	if err != nil {
+		g.close()
+		return nil, fmt.Errorf("failed to bind the shortcut: %v", err)
+	}
+	var bound []portalShortcut
+	if value, ok := results["shortcuts"]; ok && value.Store(&bound) == nil {
+		g.mutex.Lock()
+		for _, shortcut := range bound {
+			if description, ok := shortcut.Options["trigger_description"].Value().(string); ok && description != "" {
+				g.triggers[shortcut.ID] = description
+			}
+		}
+		g.mutex.Unlock()
+	}
+	return g, nil
+}
+
+// request calls a GlobalShortcuts method and waits for the portal's
+// Response, which comes as a signal on a request object. options is the
+// method's trailing a{sv} argument.
+func (g *globalShortcuts) request(method string, options map[string]dbus.Variant, args ...interface{}) (map[string]dbus.Variant, error) {
+	g.mutex.Lock()
+	g.tokens++
+	token := fmt.Sprintf("fabric_gui_%d", g.tokens)
+	// The request path is predictable, so the Response cannot be missed
+	sender := strings.NewReplacer(":", "", ".", "_").Replace(g.conn.Names()[0])
+	path := dbus.ObjectPath(fmt.Sprintf("/org/freedesktop/portal/desktop/request/%s/%s", sender, token))
+	response := make(chan *dbus.Signal, 1)
+	g.pending[path] = response
+	g.mutex.Unlock()
+	defer func() {
+		g.mutex.Lock()
+		delete(g.pending, path)
+		g.mutex.Unlock()
+	}()
+
+	options["handle_token"] = dbus.MakeVariant(token)
+	portal := g.conn.Object(portalName, portalPath)
+	if call := portal.Call(portalShortcuts+"."+method, 0, append(args, options)...); call.Err != nil {
+		return nil, call.Err
+	}
+
+	select {
+	case signal := <-response:
+		code, _ := signal.Body[0].(uint32)
+		results, _ := signal.Body[1].(map[string]dbus.Variant)
+		switch code {
+		case 0:
+			return results, nil
+		case 1:
+			return nil, fmt.Errorf("the shortcut was declined")
+		default:
+			return nil, fmt.Errorf("the desktop portal refused the request")
+		}
+	case <-time.After(portalTimeout):
+		return nil, fmt.Errorf("timed out waiting for the desktop portal")
+	}
+}
+
+// dispatch routes portal signals until the connection closes
+func (g *globalShortcuts) dispatch(signals chan *dbus.Signal) {
+	for signal := range signals {
+		switch signal.Name {
+		case portalRequest + ".Response":
+			g.mutex.Lock()
+			response := g.pending[signal.Path]
+			g.mutex.Unlock()
+			if response != nil && len(signal.Body) == 2 {
+				select {
+				case response <- signal:
+				default:
+				}
+			}
+		case portalShortcuts + ".Activated":
+			if len(signal.Body) < 2 {
+				continue
+			}
+			session, _ := signal.Body[0].(dbus.ObjectPath)
+			id, _ := signal.Body[1].(string)
+			g.mutex.Lock()
+			_, known := g.triggers[id]
+			ours := session == g.session && known
+			g.mutex.Unlock()
+			if ours {
+				g.activated(id)
+			}
+		}
+	}
+}
+
+// trigger describes the shortcut bound to id, "" when it is not bound
+func (g *globalShortcuts) trigger(id string) string {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.triggers[id]
+}
+
+// close ends the portal session, which releases the shortcut
+func (g *globalShortcuts) close() {
+	g.mutex.Lock()
+	session := g.session
+	g.mutex.Unlock()
+	if session != "" {
+		g.conn.Object(portalName, session).Call(portalSession+".Close", 0)
+	}
+	g.conn.Close()
+}
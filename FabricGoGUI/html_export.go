@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// HTMLOptions control ExportToHTML
+type HTMLOptions struct {
+	Title   string `json:"title,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+	Model   string `json:"model,omitempty"`
+	Time    int64  `json:"time,omitempty"`  // unix seconds, now when unset
+	Theme   string `json:"theme,omitempty"` // "dark" or "light", the app's theme when unset
+}
+
+// htmlThemes hold the app's colours and the matching code highlighting style
+var htmlThemes = map[string]struct {
+	vars      string
+	codeStyle string
+}{
+	"dark": {
+		vars:      "--bg: #0d1117; --bg-code: #161b22; --bg-header: #21262d; --text: #f0f6fc; --text-secondary: #8b949e; --accent: #58a6ff; --border: #30363d;",
+		codeStyle: "github-dark",
+	},
+	"light": {
+		vars:      "--bg: #ffffff; --bg-code: #f5f5f7; --bg-header: #f0f0f2; --text: #1d1d1f; --text-secondary: #6e6e73; --accent: #0071e3; --border: #d2d2d7;",
+		codeStyle: "github",
+	},
+}
+
+// codeFormatter renders highlighted code with CSS classes, so the colours
+// live in the document's stylesheet
+var codeFormatter = chromahtml.New(chromahtml.WithClasses(true))
+
+// highlightedMarkdown is markdown with syntax-highlighted code blocks
+var highlightedMarkdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(renderer.WithNodeRenderers(util.Prioritized(codeBlockRenderer{}, 100))),
+)
+
+// ExportToHTML writes markdown output as a standalone HTML file with the
+// styles embedded, so it can be emailed or published as is. When path is
+// empty a save dialog is shown. Returns the path written, or "" if the
+// dialog was cancelled.
+func (a *App) ExportToHTML(content, path string, options HTMLOptions) (string, error) {
+	if strings.TrimSpace(content) == "" {
+		return "", fmt.Errorf("no content to export")
+	}
+	if options.Theme == "" {
+		options.Theme = "dark"
+		if prefs, err := a.loadPreferences(); err == nil && prefs.Theme == "light" {
+			options.Theme = "light"
+		}
+	}
+
+	if path == "" {
+		name := "fabric_output"
+		if options.Pattern != "" {
+			name = options.Pattern
+		}
+		var err error
+		path, err = runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+			Title:           "Export to HTML",
+			DefaultFilename: name + ".html",
+			Filters:         []runtime.FileFilter{{DisplayName: "HTML Files", Pattern: "*.html"}},
+		})
+		if err != nil {
+			return "", err
+		}
+		if path == "" {
+			return "", nil // User cancelled
+		}
+	}
+
+	data, err := renderHTMLDocument(content, options)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to save file: %v", err)
+	}
+	a.log.Info("exported HTML", "path", path)
+	return path, nil
+}
+
+// htmlDocument is the page around the rendered output
+var htmlDocument = template.Must(template.New("export").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="generator" content="Fabric GUI">
+{{if .Pattern}}<meta name="fabric:pattern" content="{{.Pattern}}">
+{{end}}{{if .Model}}<meta name="fabric:model" content="{{.Model}}">
+{{end}}<title>{{.Title}}</title>
+<style>
+:root { {{.Vars}} }
+body { margin: 0; background: var(--bg); color: var(--text); font: 15px/1.6 'Inter', -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; }
+main { max-width: 820px; margin: 0 auto; padding: 32px 24px 48px; }
+header.meta { border-bottom: 1px solid var(--border); margin-bottom: 24px; padding-bottom: 12px; color: var(--text-secondary); font-size: 13px; }
+header.meta h1 { color: var(--text); font-size: 22px; margin: 0 0 4px; }
+header.meta span + span::before { content: " · "; }
+a { color: var(--accent); }
+h1, h2, h3, h4 { line-height: 1.3; margin: 1.4em 0 0.5em; }
+h1, h2 { border-bottom: 1px solid var(--border); padding-bottom: 0.3em; }
+code { font-family: 'Fira Code', 'Cascadia Code', Consolas, monospace; font-size: 0.9em; background: var(--bg-code); padding: 0.15em 0.35em; border-radius: 4px; }
+pre { background: var(--bg-code); border: 1px solid var(--border); border-radius: 8px; padding: 12px 16px; overflow-x: auto; }
+pre code { background: none; padding: 0; }
+blockquote { margin: 0; padding: 0 1em; color: var(--text-secondary); border-left: 3px solid var(--border); }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid var(--border); padding: 6px 12px; text-align: left; }
+th { background: var(--bg-header); }
+hr { border: 0; border-top: 1px solid var(--border); }
+{{.CodeCSS}}
+</style>
+</head>
+<body>
+<main>
+<header class="meta">
+<h1>{{.Title}}</h1>
+{{if .Pattern}}<span>Pattern: {{.Pattern}}</span>{{end}}{{if .Model}}<span>Model: {{.Model}}</span>{{end}}<span>{{.Date}}</span>
+</header>
+<article>
+{{.Body}}
+</article>
+</main>
+</body>
+</html>
+`))
+
+// renderHTMLDocument renders markdown into the export page
+func renderHTMLDocument(content string, options HTMLOptions) ([]byte, error) {
+	theme, ok := htmlThemes[options.Theme]
+	if !ok {
+		return nil, fmt.Errorf("unsupported theme %q", options.Theme)
+	}
+
+	var body bytes.Buffer
+	if err := highlightedMarkdown.Convert([]byte(content), &body); err != nil {
+		return nil, fmt.Errorf("failed to render markdown: %v", err)
+	}
+	var css bytes.Buffer
+	if err := codeFormatter.WriteCSS(&css, styles.Get(theme.codeStyle)); err != nil {
+		return nil, fmt.Errorf("failed to render code styles: %v", err)
+	}
+
+	stamp := time.Now()
+	if options.Time > 0 {
+		stamp = time.Unix(options.Time, 0)
+	}
+	title := options.Title
+	if title == "" {
+		title = options.Pattern
+	}
+	if title == "" {
+		title = "Fabric output"
+	}
+
+	var out bytes.Buffer
+	err := htmlDocument.Execute(&out, map[string]any{
+		"Title":   title,
+		"Pattern": options.Pattern,
+		"Model":   options.Model,
+		"Date":    stamp.Format("2006-01-02 15:04"),
+		"Vars":    template.CSS(theme.vars),
+		"CodeCSS": template.CSS(css.String()),
+		"Body":    template.HTML(body.String()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render HTML: %v", err)
+	}
+	return out.Bytes(), nil
+}
+
+// codeBlockRenderer renders fenced code blocks with syntax highlighting,
+// guessing the language when the fence does not name one
+type codeBlockRenderer struct{}
+
+func (r codeBlockRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.render)
+}
+
+func (r codeBlockRenderer) render(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	block := node.(*ast.FencedCodeBlock)
+	code := blockLines(block, src) + "\n"
+
+	lexer := lexers.Get(string(block.Language(src)))
+	if lexer == nil {
+		lexer = lexers.Analyse(code)
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	tokens, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err == nil {
+		err = codeFormatter.Format(w, styles.Fallback, tokens)
+	}
+	if err != nil {
+		// Unhighlighted is better than missing
+		fmt.Fprintf(w, "<pre><code>%s</code></pre>\n", template.HTMLEscapeString(code))
+	}
+	return ast.WalkSkipChildren, nil
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// InputLoaded is emitted when a file has been read into the input box
+type InputLoaded struct {
+	Filename string `json:"filename"`
+	Path     string `json:"path"`
+	Content  string `json:"content"`
+}
+
+// importableExtensions lists the file types accepted for import
+var importableExtensions = map[string]bool{
+	".txt":  true,
+	".md":   true,
+	".pdf":  true,
+	".docx": true,
+	".odt":  true,
+}
+
+// imageExtensions lists dropped file types that are attached as images
+var imageExtensions = map[string]bool{
+	".png":  true,
+	".jpg":  true,
+	".jpeg": true,
+	".gif":  true,
+	".webp": true,
+}
+
+// handleFileDrop is registered with the Wails runtime and loads dropped files
+func (a *App) handleFileDrop(x, y int, paths []string) {
+	prefs, _ := a.loadPreferences()
+
+	for _, path := range paths {
+		isImage := imageExtensions[strings.ToLower(filepath.Ext(path))]
+
+		// Images become text for models without vision support
+		if isImage && prefs.OCRDroppedImages {
+			result, err := a.OCRImage(path, "")
+			if err != nil {
+				a.emit("input:error", fmt.Sprintf("%s: %v", filepath.Base(path), err))
+				continue
+			}
+			a.emit("ocr:complete", result)
+			a.emit("input:loaded", InputLoaded{
+				Filename: filepath.Base(path),
+				Path:     path,
+				Content:  result.Text,
+			})
+			continue
+		}
+
+		if isImage {
+			attachment, err := a.AttachImage(path)
+			if err != nil {
+				a.emit("input:error", fmt.Sprintf("%s: %v", filepath.Base(path), err))
+				continue
+			}
+			a.emit("attachment:added", attachment)
+			continue
+		}
+
+		content, err := readImportFile(path)
+		if err != nil {
+			a.emit("input:error", fmt.Sprintf("%s: %v", filepath.Base(path), err))
+			continue
+		}
+
+		a.emit("input:loaded", InputLoaded{
+			Filename: filepath.Base(path),
+			Path:     path,
+			Content:  content,
+		})
+	}
+}
+
+// openFile loads a file the app was opened with, from the command line or
+// "Open With", into the input box
+func (a *App) openFile(path string) {
+	if a.isFrontendReady() {
+		a.showWindow()
+	}
+	content, err := readImportFile(path)
+	if err != nil {
+		a.log.Warn("failed to open file", "path", path, "error", err)
+		a.emitWhenReady("input:error", fmt.Sprintf("%s: %v", filepath.Base(path), err))
+		return
+	}
+	a.log.Info("opened file", "path", path)
+	a.emitWhenReady("input:loaded", InputLoaded{
+		Filename: filepath.Base(path),
+		Path:     path,
+		Content:  content,
+	})
+}
+
+// readImportFile reads a file and converts it to plain text based on its extension
+func readImportFile(path string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if !importableExtensions[ext] {
+		return "", fmt.Errorf("unsupported file type %q", ext)
+	}
+
+	switch ext {
+	case ".pdf":
+		return readPDFFile(path)
+	case ".docx":
+		return readDOCXFile(path)
+	case ".odt":
+		return readODTFile(path)
+	default:
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read file: %v", err)
+		}
+		return string(content), nil
+	}
+}
+
+// readPDFFile extracts text from a PDF, inserting a marker before each page.
+// Falls back to pdftotext (poppler-utils) when the built-in parser fails.
+func readPDFFile(path string) (string, error) {
+	text, err := extractPDFText(path)
+	if err == nil && strings.TrimSpace(text) != "" {
+		return text, nil
+	}
+
+	if fallback, ferr := extractPDFTextPoppler(path); ferr == nil {
+		return fallback, nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to extract PDF text: %v", err)
+	}
+	return "", fmt.Errorf("no text found in PDF (scanned documents need OCR)")
+}
+
+// extractPDFText extracts page text using the pure Go PDF reader
+func extractPDFText(path string) (text string, err error) {
+	// The PDF parser panics on some malformed documents
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("malformed PDF: %v", r)
+		}
+	}()
+
+	f, reader, err := pdf.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var pages []string
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			pages = append(pages, "")
+			continue
+		}
+		content, err := page.GetPlainText(nil)
+		if err != nil {
+			return "", fmt.Errorf("page %d: %v", i, err)
+		}
+		pages = append(pages, content)
+	}
+
+	return joinPDFPages(pages), nil
+}
+
+// extractPDFTextPoppler extracts page text with pdftotext, which separates pages with form feeds
+func extractPDFTextPoppler(path string) (string, error) {
+	pdftotext, err := exec.LookPath("pdftotext")
+	if err != nil {
+		return "", fmt.Errorf("pdftotext not found in PATH: %v", err)
+	}
+
+	out, err := exec.Command(pdftotext, "-layout", path, "-").Output()
+	if err != nil {
+		return "", fmt.Errorf("pdftotext failed: %v", err)
+	}
+
+	pages := strings.Split(strings.TrimRight(string(out), "\f"), "\f")
+	return joinPDFPages(pages), nil
+}
+
+// joinPDFPages combines page texts with "--- Page N ---" markers
+func joinPDFPages(pages []string) string {
+	var sb strings.Builder
+	for i, page := range pages {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		fmt.Fprintf(&sb, "--- Page %d ---\n\n", i+1)
+		sb.WriteString(strings.TrimSpace(page))
+	}
+	return sb.String()
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"github.com/wailsapp/wails/v2/pkg/options"
+)
+
+// singleInstanceID identifies the app to the single instance lock. A
+// second launch hands its arguments to the running app and exits, so one
+// app owns the Fabric server and the history file.
+const singleInstanceID = "com.digitalgods.fabricgui"
+
+// secondInstanceLaunched handles a second launch of the app: the window
+// comes to the front and the files and links it was given open here
+func (a *App) secondInstanceLaunched(data options.SecondInstanceData) {
+	a.log.Info("second instance launched", "args", data.Args)
+	a.showWindow()
+	a.handleLaunchArgs(data.Args, data.WorkingDirectory)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Job statuses
+const (
+	JobRunning   = "running"
+	JobComplete  = "complete"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// Job is a snapshot of a long-running background operation
+type Job struct {
+	ID         string  `json:"id"`
+	Kind       string  `json:"kind"` // chat, batch, transcribe, ...
+	Title      string  `json:"title"`
+	Status     string  `json:"status"`
+	Progress   float64 `json:"progress"` // 0-100, or -1 when unknown
+	Message    string  `json:"message,omitempty"`
+	Error      string  `json:"error,omitempty"`
+	Result     string  `json:"result,omitempty"` // what a finished job produced, such as a history entry ID
+	StartedAt  int64   `json:"startedAt"`
+	FinishedAt int64   `json:"finishedAt,omitempty"`
+}
+
+// job is the manager's internal record for a Job
+type job struct {
+	Job
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// ListJobs returns all known jobs, newest first
+func (a *App) ListJobs() []Job {
+	a.jobsMutex.Lock()
+	defer a.jobsMutex.Unlock()
+
+	jobs := make([]Job, 0, len(a.jobs))
+	for _, j := range a.jobs {
+		jobs = append(jobs, j.Job)
+	}
+	sort.Slice(jobs, func(i, k int) bool {
+		return jobs[i].StartedAt > jobs[k].StartedAt
+	})
+	return jobs
+}
+
+// CancelJob requests cancellation of a running job
+func (a *App) CancelJob(id string) error {
+	a.jobsMutex.Lock()
+	defer a.jobsMutex.Unlock()
+
+	j, ok := a.jobs[id]
+	if !ok {
+		return fmt.Errorf("job %s not found", id)
+	}
+	if j.Status != JobRunning {
+		return fmt.Errorf("job %s is not running", id)
+	}
+
+	j.cancel()
+	return nil
+}
+
+// ClearFinishedJobs forgets every job that is no longer running
+func (a *App) ClearFinishedJobs() {
+	a.jobsMutex.Lock()
+	defer a.jobsMutex.Unlock()
+
+	for id, j := range a.jobs {
+		if j.Status != JobRunning {
+			delete(a.jobs, id)
+		}
+	}
+}
+
+// startJob registers a new running job and returns a context that is
+// cancelled when the job is cancelled
+func (a *App) startJob(kind, title string) (*job, context.Context) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	a.jobsMutex.Lock()
+	a.jobSeq++
+	j := &job{
+		Job: Job{
+			ID:        fmt.Sprintf("%s-%d", kind, a.jobSeq),
+			Kind:      kind,
+			Title:     title,
+			Status:    JobRunning,
+			Progress:  -1,
+			StartedAt: time.Now().UnixMilli(),
+		},
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	if a.jobs == nil {
+		a.jobs = make(map[string]*job)
+	}
+	a.jobs[j.ID] = j
+	snapshot := j.Job
+	a.jobsMutex.Unlock()
+
+	a.emit("job:created", snapshot)
+	return j, ctx
+}
+
+// updateJob records progress (0-100, or -1 when unknown) and a status message
+func (a *App) updateJob(j *job, progress float64, message string) {
+	a.jobsMutex.Lock()
+	j.Progress = progress
+	j.Message = message
+	snapshot := j.Job
+	a.jobsMutex.Unlock()
+
+	a.emit("job:updated", snapshot)
+}
+
+// setJobResult records what a job produced, before it finishes
+func (a *App) setJobResult(j *job, result string) {
+	a.jobsMutex.Lock()
+	j.Result = result
+	a.jobsMutex.Unlock()
+}
+
+// jobSnapshot returns a job by ID
+func (a *App) jobSnapshot(id string) (Job, bool) {
+	a.jobsMutex.Lock()
+	defer a.jobsMutex.Unlock()
+	j, ok := a.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return j.Job, true
+}
+
+// finishJob marks a job as complete, failed or cancelled depending on err
+func (a *App) finishJob(j *job, err error) {
+	a.jobsMutex.Lock()
+	switch {
+	case err == nil:
+		j.Status = JobComplete
+		j.Progress = 100
+	case j.ctx.Err() != nil:
+		j.Status = JobCancelled
+	default:
+		j.Status = JobFailed
+		j.Error = err.Error()
+	}
+	j.FinishedAt = time.Now().UnixMilli()
+	snapshot := j.Job
+	a.jobsMutex.Unlock()
+
+	j.cancel()
+	a.emit("job:finished", snapshot)
+}
+
+// cancelAllJobs cancels every running job, used on shutdown
+func (a *App) cancelAllJobs() {
+	a.jobsMutex.Lock()
+	defer a.jobsMutex.Unlock()
+
+	for _, j := range a.jobs {
+		if j.Status == JobRunning {
+			j.cancel()
+		}
+	}
+}
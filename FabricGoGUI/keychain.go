@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"sync"
+)
+
+// keychainService names the app's entries in the OS keychain
+const keychainService = "fabric-gui"
+
+// newKeychainBackend returns the OS keychain: the macOS Keychain through
+// security, the Secret Service (GNOME Keyring, KWallet) through secret-tool,
+// or a DPAPI-protected file on Windows. It fails when none is usable.
+func newKeychainBackend(dir string) (secretBackend, error) {
+	var backend secretBackend
+	switch goruntime.GOOS {
+	case "darwin":
+		backend = macKeychain{}
+	case "windows":
+		backend = &dpapiBackend{path: filepath.Join(dir, "secrets.dpapi")}
+	default:
+		backend = secretToolKeychain{}
+	}
+
+	// A lookup that fails for any reason but a missing entry means the
+	// keychain cannot be used, e.g. no Secret Service is running
+	if _, err := backend.Get("fabric-gui-probe"); err != nil && !errors.Is(err, errSecretNotFound) {
+		return nil, err
+	}
+	return backend, nil
+}
+
+// runSecretTool runs a keychain command with stdin, returning its output
+func runSecretTool(stdin string, name string, args ...string) (string, string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return stdout.String(), strings.TrimSpace(stderr.String()), err
+}
+
+// macKeychain stores generic passwords in the login keychain
+type macKeychain struct{}
+
+func (macKeychain) Name() string { return "keychain" }
+
+func (macKeychain) Get(name string) (string, error) {
+	out, stderr, err := runSecretTool("", "security", "find-generic-password", "-s", keychainService, "-a", name, "-w")
+	if err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) && exit.ExitCode() == 44 {
+			return "", errSecretNotFound
+		}
+		return "", fmt.Errorf("%v: %s", err, stderr)
+	}
+	return strings.TrimSuffix(out, "\n"), nil
+}
+
+func (macKeychain) Set(name, value string) error {
+	// Passed through security's interactive mode so the key is not visible
+	// in the process list
+	command := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", shellQuote(keychainService), shellQuote(name), shellQuote(value))
+	if _, stderr, err := runSecretTool(command, "security", "-i"); err != nil || stderr != "" {
+		return fmt.Errorf("security: %v %s", err, stderr)
+	}
+	return nil
+}
+
+func (macKeychain) Delete(name string) error {
+	_, stderr, err := runSecretTool("", "security", "delete-generic-password", "-s", keychainService, "-a", name)
+	if err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) && exit.ExitCode() == 44 {
+			return errSecretNotFound
+		}
+		return fmt.Errorf("%v: %s", err, stderr)
+	}
+	return nil
+}
+
+// shellQuote quotes s for security's interactive mode
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+// secretToolKeychain stores secrets through libsecret's secret-tool
+type secretToolKeychain struct{}
+
+func (secretToolKeychain) Name() string { return "libsecret" }
+
+func (secretToolKeychain) Get(name string) (string, error) {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return "", err
+	}
+	out, stderr, err := runSecretTool("", "secret-tool", "lookup", "service", keychainService, "account", name)
+	if err != nil {
+		// A missing entry exits with 1 and prints nothing
+		if stderr == "" {
+			return "", errSecretNotFound
+		}
+		return "", fmt.Errorf("%v: %s", err, stderr)
+	}
+	return out, nil
+}
+
+func (secretToolKeychain) Set(name, value string) error {
+	_, stderr, err := runSecretTool(value, "secret-tool", "store", "--label", "Fabric GUI: "+name, "service", keychainService, "account", name)
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, stderr)
+	}
+	return nil
+}
+
+func (secretToolKeychain) Delete(name string) error {
+	_, stderr, err := runSecretTool("", "secret-tool", "clear", "service", keychainService, "account", name)
+	if err != nil && stderr != "" {
+		return fmt.Errorf("%v: %s", err, stderr)
+	}
+	return nil
+}
+
+// dpapiBackend keeps the secrets as one JSON object encrypted with the
+// Windows Data Protection API, so only the same Windows user can read it.
+// PowerShell does the DPAPI calls.
+type dpapiBackend struct {
+	mu   sync.Mutex
+	path string
+}
+
+const (
+	dpapiProtect   = `Add-Type -AssemblyName System.Security; $d = [Text.Encoding]::UTF8.GetBytes([Console]::In.ReadToEnd()); [Convert]::ToBase64String([Security.Cryptography.ProtectedData]::Protect($d, $null, 'CurrentUser'))`
+	dpapiUnprotect = `Add-Type -AssemblyName System.Security; $d = [Convert]::FromBase64String([Console]::In.ReadToEnd()); [Text.Encoding]::UTF8.GetString([Security.Cryptography.ProtectedData]::Unprotect($d, $null, 'CurrentUser'))`
+)
+
+func (d *dpapiBackend) Name() string { return "dpapi" }
+
+func (d *dpapiBackend) Get(name string) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	secrets, err := d.load()
+	if err != nil {
+		return "", err
+	}
+	value, ok := secrets[name]
+	if !ok {
+		return "", errSecretNotFound
+	}
+	return value, nil
+}
+
+func (d *dpapiBackend) Set(name, value string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	secrets, err := d.load()
+	if err != nil {
+		return err
+	}
+	secrets[name] = value
+	return d.save(secrets)
+}
+
+func (d *dpapiBackend) Delete(name string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	secrets, err := d.load()
+	if err != nil {
+		return err
+	}
+	delete(secrets, name)
+	return d.save(secrets)
+}
+
+func (d *dpapiBackend) load() (map[string]string, error) {
+	secrets := map[string]string{}
+	data, err := os.ReadFile(d.path)
+	if os.IsNotExist(err) {
+		// Check PowerShell can reach DPAPI before anything is stored
+		_, stderr, err := runSecretTool("", "powershell", "-NoProfile", "-NonInteractive", "-Command", dpapiProtect)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %s", err, stderr)
+		}
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	out, stderr, err := runSecretTool(string(data), "powershell", "-NoProfile", "-NonInteractive", "-Command", dpapiUnprotect)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %s", err, stderr)
+	}
+	return secrets, json.Unmarshal([]byte(strings.TrimSpace(out)), &secrets)
+}
+
+func (d *dpapiBackend) save(secrets map[string]string) error {
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	out, stderr, err := runSecretTool(string(plain), "powershell", "-NoProfile", "-NonInteractive", "-Command", dpapiProtect)
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, stderr)
+	}
+	if _, err := base64.StdEncoding.DecodeString(strings.TrimSpace(out)); err != nil {
+		return fmt.Errorf("unexpected DPAPI output")
+	}
+	return writeFileAtomic(d.path, []byte(strings.TrimSpace(out)), 0600)
+}
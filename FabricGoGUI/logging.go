@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"sync"
+)
+
+const (
+	// logFileName is the current log file inside the logs directory
+	logFileName = "app.log"
+	// maxLogSize is the size at which the log file is rotated
+	maxLogSize = 5 * 1024 * 1024
+	// maxLogBackups is how many rotated log files are kept
+	maxLogBackups = 3
+	// defaultLogLines is how many lines GetAppLogs returns by default
+	defaultLogLines = 500
+)
+
+// discardLogger is used until logging is set up, and when it cannot be
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// rotatingFile is an append-only log file that is rotated once it grows past
+// maxLogSize, keeping maxLogBackups older files as app.log.1, app.log.2, ...
+type rotatingFile struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	size int64
+}
+
+func openRotatingFile(path string) (*rotatingFile, error) {
+	r := &rotatingFile{path: path}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	if r.size+int64(len(p)) > maxLogSize && r.size > 0 {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the backups along, dropping the oldest, and starts a new file
+func (r *rotatingFile) rotate() error {
+	r.file.Close()
+	r.file = nil
+
+	os.Remove(fmt.Sprintf("%s.%d", r.path, maxLogBackups))
+	for i := maxLogBackups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	os.Rename(r.path, r.path+".1")
+
+	return r.open()
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// parseLogLevel maps the preference to a slog level, defaulting to info
+func parseLogLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// logDir returns the directory holding the log files
+func (a *App) logDir() string {
+	dir := a.getConfigDir()
+	if dir == "" {
+		return ""
+	}
+	logs := filepath.Join(dir, "logs")
+	os.MkdirAll(logs, 0755)
+	return logs
+}
+
+// initLogging opens the log file and sets up the application logger. If the
+// file cannot be opened the app keeps running without a log.
+func (a *App) initLogging(prefs *Preferences) {
+	a.logLevel.Set(parseLogLevel(prefs.LogLevel))
+
+	dir := a.logDir()
+	if dir == "" {
+		return
+	}
+	file, err := openRotatingFile(filepath.Join(dir, logFileName))
+	if err != nil {
+		return
+	}
+
+	a.logFile = file
+	a.log = slog.New(slog.NewTextHandler(&redactingWriter{w: file, r: a.redact}, &slog.HandlerOptions{Level: &a.logLevel}))
+	a.log.Info("starting", "os", goruntime.GOOS, "arch", goruntime.GOARCH)
+}
+
+// closeLogging flushes and closes the log file on shutdown
+func (a *App) closeLogging() {
+	if a.logFile == nil {
+		return
+	}
+	a.log.Info("shutting down")
+	a.log = discardLogger
+	a.logFile.Close()
+}
+
+// GetAppLogs returns the last lines of the application log, 500 by default
+func (a *App) GetAppLogs(lines int) (string, error) {
+	if lines <= 0 {
+		lines = defaultLogLines
+	}
+
+	dir := a.logDir()
+	if dir == "" {
+		return "", fmt.Errorf("could not determine config directory")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, logFileName))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read log: %v", err)
+	}
+
+	all := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(all) > lines {
+		all = all[len(all)-lines:]
+	}
+	return strings.Join(all, "\n"), nil
+}
+
+// OpenLogFolder shows the log directory in the system file manager
+func (a *App) OpenLogFolder() error {
+	dir := a.logDir()
+	if dir == "" {
+		return fmt.Errorf("could not determine config directory")
+	}
+	return openPath(dir)
+}
+
+// openPath opens a file or folder with the platform's default handler
+func openPath(path string) error {
+	var cmd *exec.Cmd
+	switch goruntime.GOOS {
+	case "windows":
+		cmd = exec.Command("explorer", path)
+	case "darwin":
+		cmd = exec.Command("open", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	go cmd.Wait()
+	return nil
+}
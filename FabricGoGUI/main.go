@@ -2,6 +2,7 @@ package main
 
 import (
 	"embed"
+	"os"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -14,7 +15,19 @@ import (
 //go:embed all:frontend/dist
 var assets embed.FS
 
+// Smallest size of the full window, the mini window goes below it
+const (
+	windowMinWidth  = 900
+	windowMinHeight = 600
+)
+
 func main() {
+	// Flags run a pattern from the command line without a window
+	if isCLI(os.Args[1:]) {
+		os.Exit(runCLI(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+	}
+	waitForRestart()
+
 	// Create an instance of the app structure
 	app := NewApp()
 
@@ -23,14 +36,22 @@ func main() {
 		Title:     "Fabric GUI - Go Edition",
 		Width:     1280,
 		Height:    800,
-		MinWidth:  900,
-		MinHeight: 600,
+		MinWidth:  windowMinWidth,
+		MinHeight: windowMinHeight,
 		AssetServer: &assetserver.Options{
 			Assets: assets,
 		},
 		BackgroundColour: &options.RGBA{R: 13, G: 17, B: 23, A: 1}, // Match --bg-primary
-		OnStartup:        app.startup,
-		OnShutdown:       app.shutdown,
+		DragAndDrop: &options.DragAndDrop{
+			EnableFileDrop: true,
+		},
+		OnStartup:     app.startup,
+		OnShutdown:    app.shutdown,
+		OnBeforeClose: app.beforeClose,
+		SingleInstanceLock: &options.SingleInstanceLock{
+			UniqueId:               singleInstanceID,
+			OnSecondInstanceLaunch: app.secondInstanceLaunched,
+		},
 		Bind: []interface{}{
 			app,
 		},
@@ -51,6 +72,9 @@ func main() {
 				Title:   "Fabric GUI",
 				Message: "A native GUI for Fabric AI",
 			},
+			// Links are handled in the background, they may fetch a page first
+			OnFileOpen: func(path string) { go app.openFile(path) },
+			OnUrlOpen:  func(link string) { go app.openDeepLink(link) },
 		},
 		Linux: &linux.Options{
 			Icon:                nil, // Use default
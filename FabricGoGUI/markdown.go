@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// markdown renders Fabric output, which is GitHub-flavoured markdown. Raw
+// HTML in the output is not passed through.
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// mdKind is the kind of a markdown block
+type mdKind int
+
+const (
+	mdParagraph mdKind = iota
+	mdHeading
+	mdCode
+	mdRule
+	mdTable
+)
+
+// mdBlock is one block of rendered markdown, with the inline markup removed.
+// Exporters that lay out text themselves work from these.
+type mdBlock struct {
+	Kind   mdKind
+	Level  int        // heading level
+	Indent int        // list nesting depth
+	Marker string     // list bullet or number, on the first block of an item
+	Quote  bool       // inside a blockquote
+	Text   string     // paragraph, heading or code text
+	Lang   string     // code block language
+	Rows   [][]string // table cells, the header row first
+}
+
+// inlineRenderer renders the inline content of a paragraph or heading
+type inlineRenderer func(n ast.Node, src []byte) string
+
+// markdownBlocks parses markdown into blocks
+func markdownBlocks(source string) []mdBlock {
+	return markdownBlocksWith(source, inlineText)
+}
+
+// markdownBlocksWith parses markdown into blocks, rendering paragraph and
+// heading text with inline. Table cells are always plain text, as tables are
+// laid out as text.
+func markdownBlocksWith(source string, inline inlineRenderer) []mdBlock {
+	src := []byte(source)
+	doc := markdown.Parser().Parse(text.NewReader(src))
+	blocks := []mdBlock{}
+	collectBlocks(doc, src, 0, false, inline, &blocks)
+	return blocks
+}
+
+func collectBlocks(n ast.Node, src []byte, indent int, quote bool, inline inlineRenderer, blocks *[]mdBlock) {
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		switch node := c.(type) {
+		case *ast.Heading:
+			*blocks = append(*blocks, mdBlock{Kind: mdHeading, Level: node.Level, Quote: quote, Text: inline(node, src)})
+		case *ast.Paragraph, *ast.TextBlock:
+			*blocks = append(*blocks, mdBlock{Kind: mdParagraph, Indent: indent, Quote: quote, Text: inline(node, src)})
+		case *ast.FencedCodeBlock:
+			*blocks = append(*blocks, mdBlock{Kind: mdCode, Indent: indent, Quote: quote, Lang: string(node.Language(src)), Text: blockLines(node, src)})
+		case *ast.CodeBlock, *ast.HTMLBlock:
+			*blocks = append(*blocks, mdBlock{Kind: mdCode, Indent: indent, Quote: quote, Text: blockLines(node, src)})
+		case *ast.ThematicBreak:
+			*blocks = append(*blocks, mdBlock{Kind: mdRule})
+		case *ast.Blockquote:
+			collectBlocks(node, src, indent, true, inline, blocks)
+		case *ast.List:
+			number := node.Start
+			for item := node.FirstChild(); item != nil; item = item.NextSibling() {
+				marker := "•"
+				if node.IsOrdered() {
+					marker = fmt.Sprintf("%d.", number)
+					number++
+				}
+				first := len(*blocks)
+				collectBlocks(item, src, indent+1, quote, inline, blocks)
+				if first < len(*blocks) {
+					(*blocks)[first].Marker = marker
+				}
+			}
+		case *east.Table:
+			rows := [][]string{}
+			for row := node.FirstChild(); row != nil; row = row.NextSibling() {
+				cells := []string{}
+				for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+					cells = append(cells, inlineText(cell, src))
+				}
+				rows = append(rows, cells)
+			}
+			*blocks = append(*blocks, mdBlock{Kind: mdTable, Quote: quote, Rows: rows})
+		default:
+			collectBlocks(c, src, indent, quote, inline, blocks)
+		}
+	}
+}
+
+// inlineText returns the text of a node's inline content without markup
+func inlineText(n ast.Node, src []byte) string {
+	var sb strings.Builder
+	var walk func(ast.Node)
+	walk = func(n ast.Node) {
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			switch node := c.(type) {
+			case *ast.Text:
+				sb.Write(node.Segment.Value(src))
+				if node.HardLineBreak() {
+					sb.WriteString("\n")
+				} else if node.SoftLineBreak() {
+					sb.WriteString(" ")
+				}
+			case *ast.String:
+				sb.Write(node.Value)
+			case *ast.AutoLink:
+				sb.Write(node.Label(src))
+			case *east.TaskCheckBox:
+				if node.IsChecked {
+					sb.WriteString("[x] ")
+				} else {
+					sb.WriteString("[ ] ")
+				}
+			default:
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return strings.TrimSpace(sb.String())
+}
+
+// blockLines returns the raw lines of a code or HTML block
+func blockLines(n ast.Node, src []byte) string {
+	var sb strings.Builder
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		sb.Write(line.Value(src))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// markdownToText renders markdown as plain text, keeping list markers,
+// code and table layout but dropping the markup
+func markdownToText(source string) string {
+	var sb strings.Builder
+	blocks := markdownBlocks(source)
+	for i, b := range blocks {
+		// Items of the same list sit on consecutive lines
+		if i > 0 {
+			if b.Indent > 0 && blocks[i-1].Indent > 0 {
+				sb.WriteString("\n")
+			} else {
+				sb.WriteString("\n\n")
+			}
+		}
+
+		prefix := ""
+		if b.Quote {
+			prefix = "    "
+		}
+		if b.Indent > 0 {
+			prefix += strings.Repeat("  ", b.Indent-1)
+			if b.Marker != "" {
+				prefix += b.Marker + " "
+			} else {
+				prefix += "  "
+			}
+		}
+
+		switch b.Kind {
+		case mdRule:
+			sb.WriteString(strings.Repeat("-", 40))
+		case mdTable:
+			sb.WriteString(indentLines(textTable(b.Rows), prefix))
+		default:
+			sb.WriteString(indentLines(b.Text, prefix))
+		}
+	}
+	return sb.String()
+}
+
+// indentLines puts prefix before the first line and the same width of
+// spaces before the rest
+func indentLines(text, prefix string) string {
+	if prefix == "" {
+		return text
+	}
+	pad := strings.Repeat(" ", utf8.RuneCountInString(prefix))
+	return prefix + strings.ReplaceAll(text, "\n", "\n"+pad)
+}
+
+// textTable lays out table rows in space-padded columns
+func textTable(rows [][]string) string {
+	widths := []int{}
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	lines := []string{}
+	for r, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = cell + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(cells, "  "), " "))
+		if r == 0 && len(rows) > 1 {
+			rule := make([]string, len(widths))
+			for i, w := range widths {
+				rule[i] = strings.Repeat("-", w)
+			}
+			lines = append(lines, strings.Join(rule, "  "))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Wails v2 apps have a single window, so the mini window is the main window
+// shrunk to a compact, always-on-top layout. The frontend switches layouts
+// on "window:mini" events.
+
+// Mini window size
+const (
+	miniWindowWidth  = 440
+	miniWindowHeight = 520
+)
+
+// windowGeometry is where the full window was before it became the mini one
+type windowGeometry struct {
+	x, y          int
+	width, height int
+	maximised     bool
+}
+
+// ShowMiniWindow switches to the mini window and brings it to the front
+func (a *App) ShowMiniWindow() {
+	a.miniMutex.Lock()
+	if a.mini == nil {
+		geometry := &windowGeometry{maximised: runtime.WindowIsMaximised(a.ctx)}
+		if geometry.maximised {
+			runtime.WindowUnmaximise(a.ctx)
+		}
+		geometry.width, geometry.height = runtime.WindowGetSize(a.ctx)
+		geometry.x, geometry.y = runtime.WindowGetPosition(a.ctx)
+		a.mini = geometry
+
+		runtime.WindowSetMinSize(a.ctx, miniWindowWidth, miniWindowHeight)
+		runtime.WindowSetSize(a.ctx, miniWindowWidth, miniWindowHeight)
+		runtime.WindowSetAlwaysOnTop(a.ctx, true)
+		a.emit("window:mini", true)
+	}
+	a.miniMutex.Unlock()
+	a.showWindow()
+	a.refreshTray()
+}
+
+// HideMiniWindow switches back to the full window where it was
+func (a *App) HideMiniWindow() {
+	a.miniMutex.Lock()
+	defer a.miniMutex.Unlock()
+	geometry := a.mini
+	if geometry == nil {
+		return
+	}
+	a.mini = nil
+
+	runtime.WindowSetAlwaysOnTop(a.ctx, false)
+	runtime.WindowSetMinSize(a.ctx, windowMinWidth, windowMinHeight)
+	runtime.WindowSetSize(a.ctx, geometry.width, geometry.height)
+	runtime.WindowSetPosition(a.ctx, geometry.x, geometry.y)
+	if geometry.maximised {
+		runtime.WindowMaximise(a.ctx)
+	}
+	a.emit("window:mini", false)
+	a.refreshTray()
+}
+
+// ToggleMiniWindow switches between the mini and the full window
+func (a *App) ToggleMiniWindow() {
+	if a.IsMiniWindow() {
+		a.HideMiniWindow()
+		return
+	}
+	a.ShowMiniWindow()
+}
+
+// IsMiniWindow reports whether the mini window is shown
+func (a *App) IsMiniWindow() bool {
+	a.miniMutex.Lock()
+	defer a.miniMutex.Unlock()
+	return a.mini != nil
+}
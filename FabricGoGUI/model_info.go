@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ModelInfo describes a model's limits and price. Prices are in US dollars;
+// zero means free or unknown.
+type ModelInfo struct {
+	Model         string   `json:"model"`
+	ContextWindow int      `json:"contextWindow"` // tokens
+	InputPer1K    float64  `json:"inputPer1k"`
+	OutputPer1K   float64  `json:"outputPer1k"`
+	Modality      []string `json:"modality"`         // text, image, audio
+	Custom        bool     `json:"custom,omitempty"` // from model_info.json rather than the bundled registry
+}
+
+// modelRegistry is the bundled metadata, keyed by model name or name prefix.
+// Prices are the vendors' list prices and drift; override them with
+// SaveModelInfo.
+var modelRegistry = map[string]ModelInfo{
+	// OpenAI
+	"gpt-4o":        {ContextWindow: 128000, InputPer1K: 0.0025, OutputPer1K: 0.01, Modality: []string{"text", "image"}},
+	"gpt-4o-mini":   {ContextWindow: 128000, InputPer1K: 0.00015, OutputPer1K: 0.0006, Modality: []string{"text", "image"}},
+	"gpt-4.1":       {ContextWindow: 1047576, InputPer1K: 0.002, OutputPer1K: 0.008, Modality: []string{"text", "image"}},
+	"gpt-4.1-mini":  {ContextWindow: 1047576, InputPer1K: 0.0004, OutputPer1K: 0.0016, Modality: []string{"text", "image"}},
+	"gpt-4.1-nano":  {ContextWindow: 1047576, InputPer1K: 0.0001, OutputPer1K: 0.0004, Modality: []string{"text", "image"}},
+	"gpt-4-turbo":   {ContextWindow: 128000, InputPer1K: 0.01, OutputPer1K: 0.03, Modality: []string{"text", "image"}},
+	"gpt-3.5-turbo": {ContextWindow: 16385, InputPer1K: 0.0005, OutputPer1K: 0.0015, Modality: []string{"text"}},
+	"o3":            {ContextWindow: 200000, InputPer1K: 0.002, OutputPer1K: 0.008, Modality: []string{"text", "image"}},
+	"o3-mini":       {ContextWindow: 200000, InputPer1K: 0.0011, OutputPer1K: 0.0044, Modality: []string{"text"}},
+	"o4-mini":       {ContextWindow: 200000, InputPer1K: 0.0011, OutputPer1K: 0.0044, Modality: []string{"text", "image"}},
+
+	// Anthropic
+	"claude-opus-4":     {ContextWindow: 200000, InputPer1K: 0.015, OutputPer1K: 0.075, Modality: []string{"text", "image"}},
+	"claude-sonnet-4":   {ContextWindow: 200000, InputPer1K: 0.003, OutputPer1K: 0.015, Modality: []string{"text", "image"}},
+	"claude-3-7-sonnet": {ContextWindow: 200000, InputPer1K: 0.003, OutputPer1K: 0.015, Modality: []string{"text", "image"}},
+	"claude-3-5-sonnet": {ContextWindow: 200000, InputPer1K: 0.003, OutputPer1K: 0.015, Modality: []string{"text", "image"}},
+	"claude-3-5-haiku":  {ContextWindow: 200000, InputPer1K: 0.0008, OutputPer1K: 0.004, Modality: []string{"text"}},
+	"claude-3-opus":     {ContextWindow: 200000, InputPer1K: 0.015, OutputPer1K: 0.075, Modality: []string{"text", "image"}},
+	"claude-3-haiku":    {ContextWindow: 200000, InputPer1K: 0.00025, OutputPer1K: 0.00125, Modality: []string{"text", "image"}},
+
+	// Google
+	"gemini-2.5-pro":   {ContextWindow: 1048576, InputPer1K: 0.00125, OutputPer1K: 0.01, Modality: []string{"text", "image", "audio"}},
+	"gemini-2.5-flash": {ContextWindow: 1048576, InputPer1K: 0.0003, OutputPer1K: 0.0025, Modality: []string{"text", "image", "audio"}},
+	"gemini-2.0-flash": {ContextWindow: 1048576, InputPer1K: 0.0001, OutputPer1K: 0.0004, Modality: []string{"text", "image", "audio"}},
+	"gemini-1.5-pro":   {ContextWindow: 2097152, InputPer1K: 0.00125, OutputPer1K: 0.005, Modality: []string{"text", "image", "audio"}},
+	"gemini-1.5-flash": {ContextWindow: 1048576, InputPer1K: 0.000075, OutputPer1K: 0.0003, Modality: []string{"text", "image", "audio"}},
+
+	// Others
+	"deepseek-chat":     {ContextWindow: 64000, InputPer1K: 0.00027, OutputPer1K: 0.0011, Modality: []string{"text"}},
+	"deepseek-reasoner": {ContextWindow: 64000, InputPer1K: 0.00055, OutputPer1K: 0.00219, Modality: []string{"text"}},
+	"mistral-large":     {ContextWindow: 128000, InputPer1K: 0.002, OutputPer1K: 0.006, Modality: []string{"text"}},
+
+	// Local models, as named by Ollama
+	"llama3":   {ContextWindow: 8192, Modality: []string{"text"}},
+	"llama3.1": {ContextWindow: 131072, Modality: []string{"text"}},
+	"llama3.2": {ContextWindow: 131072, Modality: []string{"text"}},
+	"mistral":  {ContextWindow: 32768, Modality: []string{"text"}},
+	"qwen2.5":  {ContextWindow: 32768, Modality: []string{"text"}},
+	"gemma2":   {ContextWindow: 8192, Modality: []string{"text"}},
+	"llava":    {ContextWindow: 4096, Modality: []string{"text", "image"}},
+}
+
+// GetModelInfo returns what is known about a model, or nil if nothing is.
+// Dated and tagged names such as gpt-4o-2024-08-06 or llama3.1:8b match
+// their base entry, and overrides win over the bundled registry.
+func (a *App) GetModelInfo(model string) *ModelInfo {
+	info, ok := lookupModelInfo(a.modelInfoOverrides(), model)
+	if !ok {
+		info, ok = lookupModelInfo(modelRegistry, model)
+	}
+	if !ok {
+		return nil
+	}
+	info.Model = model
+	return &info
+}
+
+// SaveModelInfo overrides the registry entry for info.Model, or adds one
+func (a *App) SaveModelInfo(info ModelInfo) error {
+	if info.Model == "" {
+		return fmt.Errorf("no model given")
+	}
+	if info.ContextWindow < 0 || info.InputPer1K < 0 || info.OutputPer1K < 0 {
+		return fmt.Errorf("model limits and prices cannot be negative")
+	}
+	overrides := a.modelInfoOverrides()
+	info.Custom = true
+	overrides[strings.ToLower(info.Model)] = info
+	return a.saveModelInfoOverrides(overrides)
+}
+
+// DeleteModelInfo removes an override, restoring the bundled entry if any
+func (a *App) DeleteModelInfo(model string) error {
+	overrides := a.modelInfoOverrides()
+	delete(overrides, strings.ToLower(model))
+	return a.saveModelInfoOverrides(overrides)
+}
+
+// lookupModelInfo finds model in registry by exact name, then by the longest
+// key the name starts with. Vendor prefixes such as "openai/" are ignored.
+func lookupModelInfo(registry map[string]ModelInfo, model string) (ModelInfo, bool) {
+	name := strings.ToLower(strings.TrimSpace(model))
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSuffix(name, ":latest")
+
+	if info, ok := registry[name]; ok {
+		return info, true
+	}
+	best := ""
+	for key := range registry {
+		if len(key) > len(best) && strings.HasPrefix(name, key) && strings.ContainsRune("-:@", rune(name[len(key)])) {
+			best = key
+		}
+	}
+	if best == "" {
+		return ModelInfo{}, false
+	}
+	return registry[best], true
+}
+
+// modelInfoPath is the file holding the user's overrides
+func (a *App) modelInfoPath() string {
+	dir := a.getConfigDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "model_info.json")
+}
+
+// modelInfoOverrides reads the user's overrides, keyed by lower-cased model
+func (a *App) modelInfoOverrides() map[string]ModelInfo {
+	overrides := map[string]ModelInfo{}
+	path := a.modelInfoPath()
+	if path == "" {
+		return overrides
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return overrides
+	}
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		a.log.Warn("ignoring invalid model info overrides", "error", err)
+		return map[string]ModelInfo{}
+	}
+	return overrides
+}
+
+func (a *App) saveModelInfoOverrides(overrides map[string]ModelInfo) error {
+	path := a.modelInfoPath()
+	if path == "" {
+		return fmt.Errorf("could not determine config directory")
+	}
+	data, err := json.MarshalIndent(overrides, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save model info: %v", err)
+	}
+	return nil
+}
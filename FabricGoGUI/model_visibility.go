@@ -0,0 +1,121 @@
+package main
+
+import (
+	"slices"
+	"strings"
+)
+
+// ModelVisibility filters the vendors and models GetModels lists
+type ModelVisibility struct {
+	AllowVendors []string `json:"allowVendors,omitempty"` // when set, only these vendors are listed
+	DenyVendors  []string `json:"denyVendors,omitempty"`
+	DenyModels   []string `json:"denyModels,omitempty"` // "vendor:model"
+	ShowHidden   bool     `json:"showHidden,omitempty"` // list everything regardless
+}
+
+// vendorVisible reports whether vendor passes the allow and deny lists
+func (v ModelVisibility) vendorVisible(vendor string) bool {
+	if containsFold(v.DenyVendors, vendor) {
+		return false
+	}
+	return len(v.AllowVendors) == 0 || containsFold(v.AllowVendors, vendor)
+}
+
+// modelVisible reports whether a vendor's model is listed
+func (v ModelVisibility) modelVisible(vendor, model string) bool {
+	return v.vendorVisible(vendor) && !containsFold(v.DenyModels, vendor+":"+model)
+}
+
+// filter returns the models the lists allow
+func (v ModelVisibility) filter(models *ModelsResponse) *ModelsResponse {
+	if v.ShowHidden || models == nil {
+		return models
+	}
+
+	filtered := &ModelsResponse{Models: []string{}, Vendors: map[string][]string{}}
+	listed := map[string]bool{}
+	for vendor, names := range models.Vendors {
+		var kept []string
+		for _, name := range names {
+			if v.modelVisible(vendor, name) {
+				kept = append(kept, name)
+				listed[name] = true
+			}
+		}
+		if len(kept) > 0 {
+			filtered.Vendors[vendor] = kept
+		}
+	}
+	for _, name := range models.Models {
+		if listed[name] {
+			filtered.Models = append(filtered.Models, name)
+		}
+	}
+	return filtered
+}
+
+// GetModelVisibility returns the vendor and model filters
+func (a *App) GetModelVisibility() ModelVisibility {
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return ModelVisibility{}
+	}
+	return prefs.ModelVisibility
+}
+
+// SetModelVisibility shows or hides a vendor, when model is empty, or a
+// single model of a vendor
+func (a *App) SetModelVisibility(vendor, model string, visible bool) error {
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	v := &prefs.ModelVisibility
+
+	if model == "" {
+		v.DenyVendors = removeFold(v.DenyVendors, vendor)
+		if visible {
+			if len(v.AllowVendors) > 0 && !containsFold(v.AllowVendors, vendor) {
+				v.AllowVendors = append(v.AllowVendors, vendor)
+			}
+		} else {
+			v.AllowVendors = removeFold(v.AllowVendors, vendor)
+			v.DenyVendors = append(v.DenyVendors, vendor)
+		}
+	} else {
+		key := vendor + ":" + model
+		v.DenyModels = removeFold(v.DenyModels, key)
+		if !visible {
+			v.DenyModels = append(v.DenyModels, key)
+		}
+	}
+	return a.saveModelVisibility(prefs)
+}
+
+// SetShowHiddenModels lists hidden vendors and models again without
+// forgetting which they are
+func (a *App) SetShowHiddenModels(show bool) error {
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	prefs.ModelVisibility.ShowHidden = show
+	return a.saveModelVisibility(prefs)
+}
+
+// saveModelVisibility saves the filters and has the model list reloaded
+func (a *App) saveModelVisibility(prefs *Preferences) error {
+	if err := a.SavePreferences(*prefs); err != nil {
+		return err
+	}
+	a.emit("catalog:updated", CatalogUpdate{Models: true})
+	return nil
+}
+
+func containsFold(list []string, s string) bool {
+	return slices.ContainsFunc(list, func(item string) bool { return strings.EqualFold(item, s) })
+}
+
+func removeFold(list []string, s string) []string {
+	return slices.DeleteFunc(list, func(item string) bool { return strings.EqualFold(item, s) })
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	goruntime "runtime"
+	"strings"
+	"time"
+)
+
+// NotificationSettings configure the desktop notifications shown when runs
+// finish
+type NotificationSettings struct {
+	Enabled     bool `json:"enabled"`
+	OnComplete  bool `json:"onComplete"`  // when a chat or batch completes
+	OnError     bool `json:"onError"`     // when a chat or batch fails
+	MinDuration int  `json:"minDuration"` // seconds a run must take for its completion to be announced
+}
+
+// Notification is a desktop notification. Clicking it shows the window and
+// opens the history entry or the path.
+type Notification struct {
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	EntryID string `json:"entryId,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Error   bool   `json:"error"`
+}
+
+// NotificationClick is emitted as "notification:clicked" with the history
+// entry a clicked notification was about
+type NotificationClick struct {
+	EntryID string `json:"entryId"`
+	Index   int    `json:"index"` // position in history, -1 when the entry is gone
+}
+
+const (
+	// defaultNotifyMinDuration keeps quick runs, which are watched anyway,
+	// from raising notifications
+	defaultNotifyMinDuration = 10
+	// maxNotificationBody is how much of an output a notification previews
+	maxNotificationBody = 160
+)
+
+// GetNotificationSettings returns the notification settings, with defaults
+// filled in
+func (a *App) GetNotificationSettings() NotificationSettings {
+	if prefs, err := a.loadPreferences(); err == nil && prefs.Notifications != nil {
+		return *prefs.Notifications
+	}
+	return NotificationSettings{Enabled: true, OnComplete: true, OnError: true, MinDuration: defaultNotifyMinDuration}
+}
+
+// SaveNotificationSettings stores the notification settings
+func (a *App) SaveNotificationSettings(settings NotificationSettings) error {
+	if settings.MinDuration < 0 {
+		return fmt.Errorf("minimum duration cannot be negative")
+	}
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	prefs.Notifications = &settings
+	return a.SavePreferences(*prefs)
+}
+
+// TestNotification shows a sample notification
+func (a *App) TestNotification() error {
+	return a.notify(Notification{Title: "Fabric GUI", Body: "Notifications are working"})
+}
+
+// notifyRun announces a finished chat run, as the settings allow
+func (a *App) notifyRun(run chatRun, historyID, output string, duration time.Duration, err error) {
+	settings := a.GetNotificationSettings()
+	if !settings.Enabled {
+		return
+	}
+	name := run.Prompt.PatternName
+	if name == "" {
+		name = "Chat"
+	}
+
+	n := Notification{EntryID: historyID}
+	if err != nil {
+		ce := toChatError(err)
+		if !settings.OnError || ce.Code == ChatErrCancelled {
+			return
+		}
+		n.Title = name + " failed"
+		n.Body = ce.Message
+		n.Error = true
+	} else {
+		if !settings.OnComplete || duration < time.Duration(settings.MinDuration)*time.Second {
+			return
+		}
+		n.Title = name + " finished"
+		n.Body = notificationPreview(output)
+	}
+	if err := a.notify(n); err != nil {
+		a.log.Warn("failed to show notification", "error", err)
+	}
+}
+
+// notifyBatch announces a finished batch, as the settings allow
+func (a *App) notifyBatch(summary *BatchSummary) {
+	settings := a.GetNotificationSettings()
+	if !settings.Enabled {
+		return
+	}
+	n := Notification{Path: summary.OutputDir}
+	switch {
+	case summary.Failed > 0:
+		if !settings.OnError {
+			return
+		}
+		n.Title = "Batch finished with errors"
+		n.Body = fmt.Sprintf("%d of %d files failed: %s", summary.Failed, summary.Total, summary.Errors[0])
+		n.Error = true
+	case settings.OnComplete && summary.DurationMs >= int64(settings.MinDuration)*1000:
+		n.Title = "Batch finished"
+		n.Body = fmt.Sprintf("%d of %d files processed", summary.Succeeded, summary.Total)
+	default:
+		return
+	}
+	if err := a.notify(n); err != nil {
+		a.log.Warn("failed to show notification", "error", err)
+	}
+}
+
+// notifySchedule announces a finished scheduled run, as the settings allow
+func (a *App) notifySchedule(run ScheduleRun) {
+	settings := a.GetNotificationSettings()
+	if !settings.Enabled {
+		return
+	}
+	n := Notification{}
+	if len(run.EntryIDs) > 0 {
+		n.EntryID = run.EntryIDs[len(run.EntryIDs)-1]
+	}
+	switch {
+	case run.Status == ScheduleRunFailed || run.Status == ScheduleRunPartial:
+		if !settings.OnError {
+			return
+		}
+		n.Title = run.Name + " failed"
+		n.Body = fmt.Sprintf("%d of %d runs failed: %s", run.Items-run.Succeeded, run.Items, run.Errors[0])
+		if run.Items == 0 {
+			n.Body = run.Errors[0]
+		}
+		n.Error = true
+	case settings.OnComplete:
+		n.Title = run.Name + " finished"
+		n.Body = fmt.Sprintf("%d of %d runs completed", run.Succeeded, run.Items)
+	default:
+		return
+	}
+	if err := a.notify(n); err != nil {
+		a.log.Warn("failed to show notification", "error", err)
+	}
+}
+
+// notify shows a notification with the platform's notification service.
+// Only Linux reports clicks; elsewhere the notification is informational.
+func (a *App) notify(n Notification) error {
+	var cmd *exec.Cmd
+	if goruntime.GOOS == "linux" {
+		a.notifierMutex.Lock()
+		defer a.notifierMutex.Unlock()
+		if a.notifier == nil {
+			notifier, err := newDesktopNotifier(a.openNotification)
+			if err != nil {
+				// notify-send still shows it, just without the click action
+				path, lookErr := exec.LookPath("notify-send")
+				if lookErr != nil {
+					return err
+				}
+				cmd = notificationCommand(path, n)
+			}
+			a.notifier = notifier
+		}
+		if a.notifier != nil {
+			return a.notifier.show(n)
+		}
+	} else {
+		cmd = notificationCommand("", n)
+	}
+
+	if cmd == nil {
+		return fmt.Errorf("notifications are not supported on %s", goruntime.GOOS)
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to show notification: %v", err)
+	}
+	return nil
+}
+
+// closeNotifier disconnects from the notification service
+func (a *App) closeNotifier() {
+	a.notifierMutex.Lock()
+	defer a.notifierMutex.Unlock()
+	if a.notifier != nil {
+		a.notifier.close()
+		a.notifier = nil
+	}
+}
+
+// notificationCommand returns the command that shows n: osascript on macOS,
+// a toast through PowerShell on Windows and notifySend elsewhere
+func notificationCommand(notifySend string, n Notification) *exec.Cmd {
+	switch goruntime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(n.Body), appleScriptString(n.Title))
+		return exec.Command("osascript", "-e", script)
+	case "windows":
+		script := `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $template.GetElementsByTagName('text')
+$text.Item(0).AppendChild($template.CreateTextNode($env:FABRIC_NOTIFY_TITLE)) | Out-Null
+$text.Item(1).AppendChild($template.CreateTextNode($env:FABRIC_NOTIFY_BODY)) | Out-Null
+$appID = '{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe'
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($appID).Show([Windows.UI.Notifications.ToastNotification]::new($template))`
+		cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+		cmd.Env = append(cmd.Environ(), "FABRIC_NOTIFY_TITLE="+n.Title, "FABRIC_NOTIFY_BODY="+n.Body)
+		return cmd
+	default:
+		if notifySend == "" {
+			return nil
+		}
+		args := []string{"--app-name=Fabric GUI"}
+		if n.Error {
+			args = append(args, "--urgency=critical")
+		}
+		return exec.Command(notifySend, append(args, "--", n.Title, n.Body)...)
+	}
+}
+
+// openNotification shows what a clicked notification was about
+func (a *App) openNotification(n Notification) {
+	a.showWindow()
+	if n.EntryID != "" {
+		a.emit("notification:clicked", NotificationClick{EntryID: n.EntryID, Index: a.history.Index(n.EntryID)})
+	}
+	if n.Path != "" {
+		if err := openPath(n.Path); err != nil {
+			a.log.Warn("failed to open notification path", "path", n.Path, "error", err)
+		}
+	}
+}
+
+// notificationPreview shortens an output to a line or two of plain text
+func notificationPreview(output string) string {
+	text := strings.Join(strings.Fields(markdownToText(output)), " ")
+	if runes := []rune(text); len(runes) > maxNotificationBody {
+		text = string(runes[:maxNotificationBody-1]) + "…"
+	}
+	return text
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
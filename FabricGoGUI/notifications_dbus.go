@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Linux notifications go to the org.freedesktop.Notifications service,
+// which reports clicks back as ActionInvoked signals
+const (
+	notificationsName  = "org.freedesktop.Notifications"
+	notificationsPath  = dbus.ObjectPath("/org/freedesktop/Notifications")
+	notificationsIface = "org.freedesktop.Notifications"
+	// notificationTimeout is how long a notification stays up, in ms
+	notificationTimeout = int32(10000)
+)
+
+// desktopNotifier shows notifications and routes clicks on them
+type desktopNotifier struct {
+	conn    *dbus.Conn
+	clicked func(Notification)
+	mutex   sync.Mutex
+	shown   map[uint32]Notification // open notifications by server ID
+}
+
+// newDesktopNotifier connects to the notification service. clicked is
+// called when the user clicks a notification.
+func newDesktopNotifier(clicked func(Notification)) (*desktopNotifier, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the session bus: %v", err)
+	}
+	d := &desktopNotifier{conn: conn, clicked: clicked, shown: map[uint32]Notification{}}
+	for _, member := range []string{"ActionInvoked", "NotificationClosed"} {
+		err := conn.AddMatchSignal(dbus.WithMatchObjectPath(notificationsPath), dbus.WithMatchInterface(notificationsIface), dbus.WithMatchMember(member))
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to watch notifications: %v", err)
+		}
+	}
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+	go d.dispatch(signals)
+	return d, nil
+}
+
+// show sends a notification with a default action, which is what most
+// notification servers run when the notification is clicked
+func (d *desktopNotifier) show(n Notification) error {
+	hints := map[string]dbus.Variant{}
+	if n.Error {
+		hints["urgency"] = dbus.MakeVariant(byte(2))
+	}
+	actions := []string{"default", "Open"}
+	// Servers may read the body as markup
+	body := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(n.Body)
+
+	var id uint32
+	call := d.conn.Object(notificationsName, notificationsPath).Call(notificationsIface+".Notify", 0,
+		"Fabric GUI", uint32(0), "", n.Title, body, actions, hints, notificationTimeout)
+	if err := call.Store(&id); err != nil {
+		return fmt.Errorf("failed to show notification: %v", err)
+	}
+	d.mutex.Lock()
+	d.shown[id] = n
+	d.mutex.Unlock()
+	return nil
+}
+
+// dispatch handles clicks and forgets closed notifications
+func (d *desktopNotifier) dispatch(signals chan *dbus.Signal) {
+	for signal := range signals {
+		if len(signal.Body) < 2 {
+			continue
+		}
+		id, _ := signal.Body[0].(uint32)
+		d.mutex.Lock()
+		n, ok := d.shown[id]
+		delete(d.shown, id)
+		d.mutex.Unlock()
+		if ok && signal.Name == notificationsIface+".ActionInvoked" {
+			go d.clicked(n)
+		}
+	}
+}
+
+// close disconnects from the notification service
+func (d *desktopNotifier) close() {
+	d.conn.Close()
+}
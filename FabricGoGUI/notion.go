@@ -0,0 +1,486 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// NotionSettings configure ExportToNotion
+type NotionSettings struct {
+	Token  string `json:"token"`  // internal integration secret
+	Parent string `json:"parent"` // database or page to create pages in, as an ID or a link
+}
+
+const (
+	notionAPI     = "https://api.notion.com/v1"
+	notionVersion = "2022-06-28"
+	// notionMaxText is the longest content a rich text object may hold
+	notionMaxText = 2000
+	// notionMaxBlocks is the most blocks a request may create at once
+	notionMaxBlocks = 100
+	// notionMaxDepth is how deeply a request may nest blocks
+	notionMaxDepth = 2
+)
+
+// notionID finds the 32 digit ID in a Notion link or ID
+var notionID = regexp.MustCompile(`[0-9a-fA-F]{8}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{12}`)
+
+// notionLanguages are the code block languages Notion accepts, with the
+// aliases Markdown fences commonly use
+var notionLanguages = map[string]string{
+	"bash": "bash", "sh": "shell", "shell": "shell", "zsh": "shell", "powershell": "powershell", "ps1": "powershell",
+	"c": "c", "cpp": "c++", "c++": "c++", "cs": "c#", "csharp": "c#", "go": "go", "golang": "go", "rust": "rust", "rs": "rust",
+	"java": "java", "kotlin": "kotlin", "kt": "kotlin", "swift": "swift", "scala": "scala", "dart": "dart",
+	"javascript": "javascript", "js": "javascript", "jsx": "javascript", "typescript": "typescript", "ts": "typescript", "tsx": "typescript",
+	"python": "python", "py": "python", "ruby": "ruby", "rb": "ruby", "php": "php", "perl": "perl", "lua": "lua", "r": "r",
+	"html": "html", "xml": "xml", "css": "css", "scss": "scss", "json": "json", "yaml": "yaml", "yml": "yaml", "toml": "plain text",
+	"sql": "sql", "graphql": "graphql", "markdown": "markdown", "md": "markdown", "dockerfile": "docker", "docker": "docker",
+	"diff": "diff", "makefile": "makefile", "mermaid": "mermaid", "latex": "latex", "tex": "latex",
+}
+
+// GetNotionSettings returns the Notion settings
+func (a *App) GetNotionSettings() NotionSettings {
+	if prefs, err := a.loadPreferences(); err == nil {
+		return prefs.Notion
+	}
+	return NotionSettings{}
+}
+
+// SaveNotionSettings stores the Notion settings
+func (a *App) SaveNotionSettings(settings NotionSettings) error {
+	settings.Token = strings.TrimSpace(settings.Token)
+	settings.Parent = strings.TrimSpace(settings.Parent)
+	if settings.Parent != "" && notionID.FindString(settings.Parent) == "" {
+		return fmt.Errorf("no Notion page or database ID found in %q", settings.Parent)
+	}
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	prefs.Notion = settings
+	return a.SavePreferences(*prefs)
+}
+
+// ExportToNotion creates a page for a history entry under the configured
+// database or page, with the output converted to Notion blocks. In a
+// database the title, "Pattern", "Model" and "Date" properties are filled in
+// where the database has them. Returns the new page's URL.
+func (a *App) ExportToNotion(entryID string) (string, error) {
+	settings := a.GetNotionSettings()
+	if settings.Token == "" {
+		return "", fmt.Errorf("no Notion integration token configured")
+	}
+	parentID := strings.ReplaceAll(notionID.FindString(settings.Parent), "-", "")
+	if parentID == "" {
+		return "", fmt.Errorf("no Notion page or database configured")
+	}
+	entry, ok := a.history.Find(entryID)
+	if !ok {
+		return "", fmt.Errorf("history entry not found: %s", entryID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	title := defaultNoteTitle(entry)
+	var page map[string]any
+	var database struct {
+		Properties map[string]struct {
+			Type string `json:"type"`
+		} `json:"properties"`
+	}
+	status, err := a.notionRequest(ctx, settings.Token, "GET", "/databases/"+parentID, nil, &database)
+	switch {
+	case err == nil:
+		props := map[string]any{}
+		for name, prop := range database.Properties {
+			if value := notionProperty(name, prop.Type, entry, title); value != nil {
+				props[name] = value
+			}
+		}
+		page = map[string]any{"parent": map[string]string{"database_id": parentID}, "properties": props}
+	case status == http.StatusNotFound || status == http.StatusBadRequest:
+		// Not a database, or not one shared with the integration: try it as a page
+		page = map[string]any{
+			"parent":     map[string]string{"page_id": parentID},
+			"properties": map[string]any{"title": map[string]any{"title": notionRichText(title)}},
+		}
+	default:
+		return "", err
+	}
+
+	blocks := notionBlocks(entry.Output)
+	first := blocks[:min(len(blocks), notionMaxBlocks)]
+	page["children"] = first
+
+	var created struct {
+		ID  string `json:"id"`
+		URL string `json:"url"`
+	}
+	if _, err := a.notionRequest(ctx, settings.Token, "POST", "/pages", page, &created); err != nil {
+		return "", err
+	}
+	for rest := blocks[len(first):]; len(rest) > 0; {
+		batch := rest[:min(len(rest), notionMaxBlocks)]
+		rest = rest[len(batch):]
+		body := map[string]any{"children": batch}
+		if _, err := a.notionRequest(ctx, settings.Token, "PATCH", "/blocks/"+created.ID+"/children", body, nil); err != nil {
+			return created.URL, fmt.Errorf("page created but not all of the output was added: %v", err)
+		}
+	}
+	a.log.Info("exported to Notion", "page", created.ID, "blocks", len(blocks))
+	return created.URL, nil
+}
+
+// notionRequest calls the Notion API, decoding the response into out when
+// it is not nil. Returns the HTTP status, 0 when there was no response.
+func (a *App) notionRequest(ctx context.Context, token, method, path string, body, out any) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode Notion request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, notionAPI+path, reader)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Notion-Version", notionVersion)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.externalClient().Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reach Notion: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(data, &apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		return resp.StatusCode, fmt.Errorf("Notion error %d: %s", resp.StatusCode, apiErr.Message)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to parse Notion response: %v", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// notionProperty returns the value for a database property this app knows
+// how to fill, or nil
+func notionProperty(name, kind string, entry HistoryEntry, title string) any {
+	if kind == "title" {
+		return map[string]any{"title": notionRichText(title)}
+	}
+	var value string
+	switch strings.ToLower(name) {
+	case "pattern":
+		value = entry.Pattern
+	case "model":
+		value = entry.Model
+	case "vendor":
+		value = entry.Vendor
+	case "source", "url":
+		value = inputSourceURL(entry.Input)
+	case "date", "created":
+		if kind == "date" {
+			return map[string]any{"date": map[string]string{"start": time.Unix(entry.Time, 0).Format(time.RFC3339)}}
+		}
+		return nil
+	case "tags":
+		if kind == "multi_select" && len(entry.Tags) > 0 {
+			options := []map[string]string{}
+			for _, tag := range entry.Tags {
+				// Notion rejects commas in option names
+				options = append(options, map[string]string{"name": strings.ReplaceAll(tag, ",", " ")})
+			}
+			return map[string]any{"multi_select": options}
+		}
+		return nil
+	default:
+		return nil
+	}
+	if value == "" {
+		return nil
+	}
+	switch kind {
+	case "rich_text":
+		return map[string]any{"rich_text": notionRichText(value)}
+	case "select":
+		return map[string]any{"select": map[string]string{"name": strings.ReplaceAll(value, ",", " ")}}
+	case "url":
+		return map[string]any{"url": value}
+	}
+	return nil
+}
+
+// notionBlocks converts markdown to Notion blocks
+func notionBlocks(source string) []map[string]any {
+	src := []byte(source)
+	doc := markdown.Parser().Parse(text.NewReader(src))
+	blocks := convertNotionBlocks(doc, src, 0)
+	if len(blocks) == 0 {
+		blocks = append(blocks, notionBlock("paragraph", map[string]any{"rich_text": notionRichText("")}))
+	}
+	return blocks
+}
+
+// convertNotionBlocks converts the children of n
+func convertNotionBlocks(n ast.Node, src []byte, depth int) []map[string]any {
+	blocks := []map[string]any{}
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		blocks = append(blocks, convertNotionNode(c, src, depth)...)
+	}
+	return blocks
+}
+
+// convertNotionNode converts one block node. Blocks deeper than
+// notionMaxDepth follow their parent instead of nesting in it.
+func convertNotionNode(c ast.Node, src []byte, depth int) []map[string]any {
+	switch node := c.(type) {
+	case *ast.Heading:
+		kind := fmt.Sprintf("heading_%d", min(node.Level, 3))
+		return []map[string]any{notionBlock(kind, map[string]any{"rich_text": notionInline(node, src)})}
+	case *ast.Paragraph, *ast.TextBlock:
+		return []map[string]any{notionBlock("paragraph", map[string]any{"rich_text": notionInline(node, src)})}
+	case *ast.FencedCodeBlock:
+		language := notionLanguages[strings.ToLower(string(node.Language(src)))]
+		if language == "" {
+			language = "plain text"
+		}
+		return []map[string]any{notionBlock("code", map[string]any{"rich_text": notionRichText(blockLines(node, src)), "language": language})}
+	case *ast.CodeBlock, *ast.HTMLBlock:
+		return []map[string]any{notionBlock("code", map[string]any{"rich_text": notionRichText(blockLines(node, src)), "language": "plain text"})}
+	case *ast.ThematicBreak:
+		return []map[string]any{notionBlock("divider", map[string]any{})}
+	case *ast.Blockquote:
+		inner := convertNotionBlocks(node, src, depth+1)
+		quote := map[string]any{"rich_text": []map[string]any{}}
+		if len(inner) > 0 && inner[0]["type"] == "paragraph" {
+			quote["rich_text"] = inner[0]["paragraph"].(map[string]any)["rich_text"]
+			inner = inner[1:]
+		}
+		return nestNotionBlock(notionBlock("quote", quote), inner, depth)
+	case *ast.List:
+		blocks := []map[string]any{}
+		for item := node.FirstChild(); item != nil; item = item.NextSibling() {
+			blocks = append(blocks, notionListItem(node, item, src, depth)...)
+		}
+		return blocks
+	case *east.Table:
+		return []map[string]any{notionTable(node, src)}
+	default:
+		return convertNotionBlocks(c, src, depth)
+	}
+}
+
+// notionListItem converts a list item: its first paragraph is the item's
+// text and the rest its children
+func notionListItem(list *ast.List, item ast.Node, src []byte, depth int) []map[string]any {
+	kind := "bulleted_list_item"
+	if list.IsOrdered() {
+		kind = "numbered_list_item"
+	}
+	content := map[string]any{"rich_text": []map[string]any{}}
+	c := item.FirstChild()
+	if c != nil && (c.Kind() == ast.KindParagraph || c.Kind() == ast.KindTextBlock) {
+		if box, ok := c.FirstChild().(*east.TaskCheckBox); ok {
+			kind = "to_do"
+			content["checked"] = box.IsChecked
+		}
+		content["rich_text"] = notionInline(c, src)
+		c = c.NextSibling()
+	}
+	children := []map[string]any{}
+	for ; c != nil; c = c.NextSibling() {
+		children = append(children, convertNotionNode(c, src, depth+1)...)
+	}
+	return nestNotionBlock(notionBlock(kind, content), children, depth)
+}
+
+// nestNotionBlock puts children inside block, or after it past the depth
+// Notion allows in one request
+func nestNotionBlock(block map[string]any, children []map[string]any, depth int) []map[string]any {
+	if len(children) == 0 {
+		return []map[string]any{block}
+	}
+	if depth < notionMaxDepth {
+		block[block["type"].(string)].(map[string]any)["children"] = children
+		return []map[string]any{block}
+	}
+	return append([]map[string]any{block}, children...)
+}
+
+// notionTable converts a GFM table, the first row being the header
+func notionTable(table *east.Table, src []byte) map[string]any {
+	rows := []map[string]any{}
+	width := 0
+	for row := table.FirstChild(); row != nil; row = row.NextSibling() {
+		cells := [][]map[string]any{}
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			cells = append(cells, notionInline(cell, src))
+		}
+		width = max(width, len(cells))
+		rows = append(rows, notionBlock("table_row", map[string]any{"cells": cells}))
+	}
+	// Every row needs table_width cells
+	for _, row := range rows {
+		content := row["table_row"].(map[string]any)
+		cells := content["cells"].([][]map[string]any)
+		for len(cells) < width {
+			cells = append(cells, []map[string]any{})
+		}
+		content["cells"] = cells
+	}
+	return notionBlock("table", map[string]any{
+		"table_width":       width,
+		"has_column_header": true,
+		"has_row_header":    false,
+		"children":          rows,
+	})
+}
+
+func notionBlock(kind string, content map[string]any) map[string]any {
+	return map[string]any{"object": "block", "type": kind, kind: content}
+}
+
+// notionAnnotations is the styling of a run of rich text
+type notionAnnotations struct {
+	Bold          bool `json:"bold"`
+	Italic        bool `json:"italic"`
+	Strikethrough bool `json:"strikethrough"`
+	Code          bool `json:"code"`
+}
+
+// notionInline converts inline markdown to rich text, keeping emphasis,
+// code spans and links
+func notionInline(n ast.Node, src []byte) []map[string]any {
+	rich := []map[string]any{}
+	add := func(content string, style notionAnnotations, link string) {
+		for _, part := range splitNotionText(content) {
+			textObject := map[string]any{"content": part}
+			if link != "" {
+				textObject["link"] = map[string]string{"url": link}
+			}
+			rich = append(rich, map[string]any{"type": "text", "text": textObject, "annotations": style})
+		}
+	}
+
+	var walk func(ast.Node, notionAnnotations, string)
+	walk = func(n ast.Node, style notionAnnotations, link string) {
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			switch node := c.(type) {
+			case *ast.Text:
+				content := string(node.Segment.Value(src))
+				if node.HardLineBreak() {
+					content += "\n"
+				} else if node.SoftLineBreak() {
+					content += " "
+				}
+				add(content, style, link)
+			case *ast.String:
+				add(string(node.Value), style, link)
+			case *ast.CodeSpan:
+				code := style
+				code.Code = true
+				add(inlineText(node, src), code, link)
+			case *ast.Emphasis:
+				inner := style
+				if node.Level == 2 {
+					inner.Bold = true
+				} else {
+					inner.Italic = true
+				}
+				walk(node, inner, link)
+			case *east.Strikethrough:
+				inner := style
+				inner.Strikethrough = true
+				walk(node, inner, link)
+			case *ast.Link:
+				walk(node, style, notionLink(string(node.Destination)))
+			case *ast.AutoLink:
+				url := string(node.URL(src))
+				add(string(node.Label(src)), style, notionLink(url))
+			case *ast.RawHTML:
+				for i := 0; i < node.Segments.Len(); i++ {
+					segment := node.Segments.At(i)
+					add(string(segment.Value(src)), style, link)
+				}
+			case *east.TaskCheckBox:
+				// Shown by the to_do block instead
+			default:
+				walk(c, style, link)
+			}
+		}
+	}
+	walk(n, notionAnnotations{}, "")
+
+	// Trailing breaks would show as empty lines
+	if len(rich) > 0 {
+		last := rich[len(rich)-1]["text"].(map[string]any)
+		last["content"] = strings.TrimRight(last["content"].(string), " \n")
+	}
+	return rich
+}
+
+// notionLink returns url if Notion accepts it as a link, which it only
+// does for absolute URLs
+func notionLink(url string) string {
+	if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "mailto:") {
+		return url
+	}
+	return ""
+}
+
+// notionRichText is plain rich text, split to Notion's length limit
+func notionRichText(content string) []map[string]any {
+	rich := []map[string]any{}
+	for _, part := range splitNotionText(content) {
+		rich = append(rich, map[string]any{"type": "text", "text": map[string]string{"content": part}})
+	}
+	return rich
+}
+
+// splitNotionText cuts content into pieces of at most notionMaxText
+// characters, as Notion counts them in UTF-16 code units
+func splitNotionText(content string) []string {
+	var parts []string
+	var current strings.Builder
+	units := 0
+	for _, r := range content {
+		size := 1
+		if r > 0xFFFF {
+			size = 2
+		}
+		if units+size > notionMaxText {
+			parts = append(parts, current.String())
+			current.Reset()
+			units = 0
+		}
+		current.WriteRune(r)
+		units += size
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+	return parts
+}
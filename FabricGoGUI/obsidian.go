@@ -0,0 +1,246 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ObsidianSettings configure SaveToObsidian
+type ObsidianSettings struct {
+	Vault    string   `json:"vault"`    // vault root directory
+	Template string   `json:"template"` // note body, see expandNoteTemplate
+	Tags     []string `json:"tags"`     // added to every note's frontmatter
+}
+
+const (
+	// defaultNoteTemplate is the note body when no template is configured
+	defaultNoteTemplate = "{output}\n"
+	// maxNoteNameLength keeps note file names well within path limits
+	maxNoteNameLength = 120
+)
+
+// GetObsidianSettings returns the vault settings, with defaults filled in
+func (a *App) GetObsidianSettings() ObsidianSettings {
+	settings := ObsidianSettings{}
+	if prefs, err := a.loadPreferences(); err == nil {
+		settings = prefs.Obsidian
+	}
+	if settings.Template == "" {
+		settings.Template = defaultNoteTemplate
+	}
+	if settings.Tags == nil {
+		settings.Tags = []string{"fabric"}
+	}
+	return settings
+}
+
+// SaveObsidianSettings stores the vault settings. The vault must exist.
+func (a *App) SaveObsidianSettings(settings ObsidianSettings) error {
+	if settings.Vault != "" {
+		if info, err := os.Stat(settings.Vault); err != nil || !info.IsDir() {
+			return fmt.Errorf("vault folder not found: %s", settings.Vault)
+		}
+	}
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	prefs.Obsidian = settings
+	return a.SavePreferences(*prefs)
+}
+
+// SelectObsidianVault picks the vault folder with a dialog and stores it.
+// Returns the folder, or "" if the dialog was cancelled.
+func (a *App) SelectObsidianVault() (string, error) {
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select Obsidian Vault",
+	})
+	if err != nil || dir == "" {
+		return "", err
+	}
+	settings := a.GetObsidianSettings()
+	settings.Vault = dir
+	if err := a.SaveObsidianSettings(settings); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// SaveToObsidian writes a history entry as a note in the vault, with YAML
+// frontmatter recording the pattern, model, source URL and tags. folder is
+// relative to the vault, and title defaults to the entry's title. An
+// existing note is never overwritten. Returns the path written.
+func (a *App) SaveToObsidian(entryID, folder, title string) (string, error) {
+	settings := a.GetObsidianSettings()
+	if settings.Vault == "" {
+		return "", fmt.Errorf("no Obsidian vault configured")
+	}
+	entry, ok := a.history.Find(entryID)
+	if !ok {
+		return "", fmt.Errorf("history entry not found: %s", entryID)
+	}
+
+	dir, err := vaultPath(settings.Vault, folder)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create folder: %v", err)
+	}
+
+	if title == "" {
+		title = defaultNoteTitle(entry)
+	}
+	note := noteFrontmatter(entry, title, settings.Tags) + expandNoteTemplate(settings.Template, entry, title)
+
+	path, err := writeNewFile(dir, noteFileName(title), ".md", []byte(note))
+	if err != nil {
+		return "", fmt.Errorf("failed to save note: %v", err)
+	}
+	a.log.Info("saved note to Obsidian", "path", path)
+	return path, nil
+}
+
+// vaultPath joins a folder to the vault, refusing folders outside it
+func vaultPath(vault, folder string) (string, error) {
+	dir := filepath.Join(vault, folder)
+	rel, err := filepath.Rel(vault, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(folder) {
+		return "", fmt.Errorf("folder must be inside the vault: %s", folder)
+	}
+	return dir, nil
+}
+
+// defaultNoteTitle names a note after the entry's title, or its pattern and date
+func defaultNoteTitle(entry HistoryEntry) string {
+	if entry.Title != "" {
+		return entry.Title
+	}
+	name := entry.Pattern
+	if name == "" {
+		name = rawChatTitle
+	}
+	return name + " " + time.Unix(entry.Time, 0).Format("2006-01-02 1504")
+}
+
+// noteFrontmatter renders the YAML frontmatter block. Strings are written
+// JSON-quoted, which YAML reads as double-quoted scalars.
+func noteFrontmatter(entry HistoryEntry, title string, tags []string) string {
+	var sb strings.Builder
+	field := func(name, value string) {
+		if value != "" {
+			quoted, _ := json.Marshal(value)
+			fmt.Fprintf(&sb, "%s: %s\n", name, quoted)
+		}
+	}
+
+	sb.WriteString("---\n")
+	field("title", title)
+	field("pattern", entry.Pattern)
+	field("model", entry.Model)
+	field("vendor", entry.Vendor)
+	field("created", time.Unix(entry.Time, 0).Format(time.RFC3339))
+	field("source", inputSourceURL(entry.Input))
+
+	all := []string{}
+	for _, tag := range append(append([]string{}, tags...), entry.Tags...) {
+		// Obsidian tags cannot contain spaces
+		tag = strings.Join(strings.Fields(strings.TrimPrefix(tag, "#")), "-")
+		if tag != "" && !containsFold(all, tag) {
+			all = append(all, tag)
+		}
+	}
+	if len(all) > 0 {
+		sb.WriteString("tags:\n")
+		for _, tag := range all {
+			quoted, _ := json.Marshal(tag)
+			fmt.Fprintf(&sb, "  - %s\n", quoted)
+		}
+	}
+	sb.WriteString("---\n\n")
+	return sb.String()
+}
+
+// expandNoteTemplate fills in the note placeholders: {title}, {output},
+// {input}, {pattern}, {model}, {vendor}, {date}, {time}, {source} and {note}
+func expandNoteTemplate(template string, entry HistoryEntry, title string) string {
+	created := time.Unix(entry.Time, 0)
+	return strings.NewReplacer(
+		"{title}", title,
+		"{output}", entry.Output,
+		"{input}", entry.Input,
+		"{pattern}", entry.Pattern,
+		"{model}", entry.Model,
+		"{vendor}", entry.Vendor,
+		"{date}", created.Format("2006-01-02"),
+		"{time}", created.Format("15:04"),
+		"{source}", inputSourceURL(entry.Input),
+		"{note}", entry.Note,
+	).Replace(template)
+}
+
+// inputSourceURL returns the input when it is just a URL, such as a video or
+// article the pattern was run on
+func inputSourceURL(input string) string {
+	input = strings.TrimSpace(input)
+	if input == "" || strings.ContainsAny(input, " \t\r\n") {
+		return ""
+	}
+	u, err := url.Parse(input)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	return input
+}
+
+// noteFileName makes a title safe as a note name, dropping the characters
+// Obsidian and the file system reject
+func noteFileName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`*"\/<>:|?#^[]`, r) || r < 32 {
+			return ' '
+		}
+		return r
+	}, title)
+	name = strings.Join(strings.Fields(name), " ")
+	for utf8.RuneCountInString(name) > maxNoteNameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	name = strings.Trim(name, " .")
+	if name == "" {
+		name = "Fabric output"
+	}
+	return name
+}
+
+// writeNewFile creates dir/name+ext, adding " 2", " 3", ... to the name
+// until it does not collide with an existing file
+func writeNewFile(dir, name, ext string, data []byte) (string, error) {
+	for n := 1; ; n++ {
+		path := filepath.Join(dir, name+ext)
+		if n > 1 {
+			path = filepath.Join(dir, fmt.Sprintf("%s %d%s", name, n, ext))
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.Write(data); err != nil {
+			f.Close()
+			return "", err
+		}
+		return path, f.Close()
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// OCRResult holds text recognized from an image
+type OCRResult struct {
+	Text       string  `json:"text"`
+	Confidence float64 `json:"confidence"` // Mean word confidence, 0-100
+	Language   string  `json:"language"`
+	Engine     string  `json:"engine"`
+}
+
+// OCRImage converts an image to text using tesseract or the configured OCR endpoint.
+// An empty language falls back to the preference, then to English.
+func (a *App) OCRImage(path, language string) (*OCRResult, error) {
+	prefs, _ := a.loadPreferences()
+
+	if language == "" {
+		language = prefs.OCRLanguage
+	}
+	if language == "" {
+		language = "eng"
+	}
+
+	if prefs.OCREndpoint != "" {
+		return ocrWithEndpoint(a.externalClient(), prefs.OCREndpoint, path, language)
+	}
+	return ocrWithTesseract(path, language)
+}
+
+// ListOCRLanguages returns the languages installed for tesseract
+func (a *App) ListOCRLanguages() ([]string, error) {
+	tesseract, err := exec.LookPath("tesseract")
+	if err != nil {
+		return nil, fmt.Errorf("tesseract not found in PATH: %v", err)
+	}
+
+	out, err := exec.Command(tesseract, "--list-langs").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list OCR languages: %v", err)
+	}
+
+	// First line is a header: List of available languages in "..." (N):
+	var languages []string
+	for i, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		if i == 0 || line == "" {
+			continue
+		}
+		languages = append(languages, line)
+	}
+	return languages, nil
+}
+
+// ocrWithTesseract runs tesseract in TSV mode so per-word confidence is available
+func ocrWithTesseract(path, language string) (*OCRResult, error) {
+	tesseract, err := exec.LookPath("tesseract")
+	if err != nil {
+		return nil, fmt.Errorf("tesseract not found in PATH: %v", err)
+	}
+
+	out, err := exec.Command(tesseract, path, "stdout", "-l", language, "tsv").Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("tesseract failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("tesseract failed: %v", err)
+	}
+
+	text, confidence := parseTesseractTSV(string(out))
+	return &OCRResult{
+		Text:       text,
+		Confidence: confidence,
+		Language:   language,
+		Engine:     "tesseract",
+	}, nil
+}
+
+// parseTesseractTSV rebuilds lines and paragraphs from tesseract TSV output
+// and returns the text with the mean word confidence
+func parseTesseractTSV(tsv string) (string, float64) {
+	var (
+		sb        strings.Builder
+		lastBlock = ""
+		lastLine  = ""
+		total     float64
+		words     int
+	)
+
+	for i, row := range strings.Split(tsv, "\n") {
+		// Columns: level page_num block_num par_num line_num word_num left top width height conf text
+		cols := strings.Split(row, "\t")
+		if i == 0 || len(cols) < 12 {
+			continue
+		}
+
+		conf, err := strconv.ParseFloat(cols[10], 64)
+		word := strings.TrimSpace(cols[11])
+		if err != nil || conf < 0 || word == "" {
+			continue
+		}
+
+		block := cols[1] + "." + cols[2] + "." + cols[3]
+		line := block + "." + cols[4]
+		switch {
+		case sb.Len() == 0:
+		case block != lastBlock:
+			sb.WriteString("\n\n")
+		case line != lastLine:
+			sb.WriteString("\n")
+		default:
+			sb.WriteString(" ")
+		}
+		sb.WriteString(word)
+		lastBlock, lastLine = block, line
+
+		total += conf
+		words++
+	}
+
+	if words == 0 {
+		return "", 0
+	}
+	return sb.String(), total / float64(words)
+}
+
+// ocrWithEndpoint posts the image to an HTTP OCR service which is expected
+// to answer with {"text": "...", "confidence": 0-100}
+func ocrWithEndpoint(client *http.Client, endpoint, path, language string) (*OCRResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %v", err)
+	}
+	defer f.Close()
+
+	pr, pw := io.Pipe()
+	form := multipart.NewWriter(pw)
+	// Built first, since nothing would read the pipe if it failed
+	req, err := http.NewRequest("POST", endpoint, pr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	go func() {
+		part, err := form.CreateFormFile("file", filepath.Base(path))
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		if _, err := io.Copy(part, f); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		form.WriteField("language", language)
+		pw.CloseWithError(form.Close())
+	}()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send image: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("OCR error %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result OCRResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse OCR response: %v", err)
+	}
+	result.Language = language
+	result.Engine = "endpoint"
+	return &result, nil
+}
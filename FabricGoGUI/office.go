@@ -0,0 +1,290 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// markdownWriter accumulates document blocks as Markdown
+type markdownWriter struct {
+	sb       strings.Builder
+	lastList bool
+}
+
+func (w *markdownWriter) separate(list bool) {
+	if w.sb.Len() == 0 {
+		return
+	}
+	// Consecutive list items stay together, everything else gets a blank line
+	if list && w.lastList {
+		w.sb.WriteString("\n")
+	} else {
+		w.sb.WriteString("\n\n")
+	}
+}
+
+func (w *markdownWriter) heading(level int, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	if level < 1 {
+		level = 1
+	} else if level > 6 {
+		level = 6
+	}
+	w.separate(false)
+	w.sb.WriteString(strings.Repeat("#", level) + " " + text)
+	w.lastList = false
+}
+
+func (w *markdownWriter) listItem(depth int, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	w.separate(true)
+	w.sb.WriteString(strings.Repeat("  ", depth) + "- " + text)
+	w.lastList = true
+}
+
+func (w *markdownWriter) paragraph(text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	w.separate(false)
+	w.sb.WriteString(text)
+	w.lastList = false
+}
+
+func (w *markdownWriter) String() string {
+	return w.sb.String()
+}
+
+// readDOCXFile converts a Word document to Markdown
+func readDOCXFile(path string) (string, error) {
+	return readZippedXML(path, "word/document.xml", parseDOCX)
+}
+
+// readODTFile converts an OpenDocument text file to Markdown
+func readODTFile(path string) (string, error) {
+	return readZippedXML(path, "content.xml", parseODT)
+}
+
+// readZippedXML opens an office archive and parses one XML member from it
+func readZippedXML(path, member string, parse func(io.Reader) (string, error)) (string, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open document: %v", err)
+	}
+	defer archive.Close()
+
+	for _, f := range archive.File {
+		if f.Name != member {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %v", member, err)
+		}
+		defer rc.Close()
+
+		text, err := parse(rc)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse document: %v", err)
+		}
+		return text, nil
+	}
+
+	return "", fmt.Errorf("%s not found, file is not a valid document", member)
+}
+
+// parseDOCX walks word/document.xml, mapping heading styles and numbered
+// paragraphs to Markdown headings and list items
+func parseDOCX(r io.Reader) (string, error) {
+	decoder := xml.NewDecoder(r)
+
+	var (
+		out       markdownWriter
+		text      strings.Builder
+		style     string
+		listLevel = -1
+		inRun     bool // tab elements outside runs are tab stop definitions
+	)
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				text.Reset()
+				style = ""
+				listLevel = -1
+			case "pStyle":
+				style = xmlAttr(t, "val")
+			case "numPr":
+				if listLevel < 0 {
+					listLevel = 0
+				}
+			case "ilvl":
+				if n, err := strconv.Atoi(xmlAttr(t, "val")); err == nil {
+					listLevel = n
+				}
+			case "t":
+				var s string
+				if err := decoder.DecodeElement(&s, &t); err != nil {
+					return "", err
+				}
+				text.WriteString(s)
+			case "r":
+				inRun = true
+			case "tab":
+				if inRun {
+					text.WriteString("\t")
+				}
+			case "br", "cr":
+				text.WriteString("\n")
+			}
+		case xml.EndElement:
+			if t.Name.Local == "r" {
+				inRun = false
+			}
+			if t.Name.Local != "p" {
+				continue
+			}
+
+			if level := docxHeadingLevel(style); level > 0 {
+				out.heading(level, text.String())
+			} else if listLevel >= 0 || strings.EqualFold(style, "ListParagraph") {
+				out.listItem(max(listLevel, 0), text.String())
+			} else {
+				out.paragraph(text.String())
+			}
+		}
+	}
+
+	return out.String(), nil
+}
+
+// docxHeadingLevel returns the heading level for a paragraph style such as
+// "Heading2" or "Title", or 0 for body text
+func docxHeadingLevel(style string) int {
+	lower := strings.ToLower(strings.ReplaceAll(style, " ", ""))
+	switch {
+	case lower == "title":
+		return 1
+	case lower == "subtitle":
+		return 2
+	case strings.HasPrefix(lower, "heading"):
+		digits := strings.TrimFunc(lower[len("heading"):], func(r rune) bool { return !unicode.IsDigit(r) })
+		if n, err := strconv.Atoi(digits); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+// parseODT walks content.xml, mapping text:h and nested text:list elements
+// to Markdown headings and list items
+func parseODT(r io.Reader) (string, error) {
+	decoder := xml.NewDecoder(r)
+
+	var (
+		out          markdownWriter
+		text         strings.Builder
+		listDepth    int
+		headingLevel int
+		blockDepth   int
+	)
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "list":
+				listDepth++
+			case "h":
+				blockDepth++
+				if blockDepth == 1 {
+					text.Reset()
+					headingLevel = 1
+					if n, err := strconv.Atoi(xmlAttr(t, "outline-level")); err == nil {
+						headingLevel = n
+					}
+				}
+			case "p":
+				blockDepth++
+				if blockDepth == 1 {
+					text.Reset()
+					headingLevel = 0
+				}
+			case "s":
+				count := 1
+				if n, err := strconv.Atoi(xmlAttr(t, "c")); err == nil {
+					count = n
+				}
+				text.WriteString(strings.Repeat(" ", count))
+			case "tab":
+				text.WriteString("\t")
+			case "line-break":
+				text.WriteString("\n")
+			}
+		case xml.CharData:
+			if blockDepth > 0 {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "list":
+				listDepth--
+			case "h", "p":
+				blockDepth--
+				if blockDepth > 0 {
+					continue
+				}
+				switch {
+				case headingLevel > 0:
+					out.heading(headingLevel, text.String())
+				case listDepth > 0:
+					out.listItem(listDepth-1, text.String())
+				default:
+					out.paragraph(text.String())
+				}
+			}
+		}
+	}
+
+	return out.String(), nil
+}
+
+// xmlAttr returns the value of the attribute with the given local name
+func xmlAttr(el xml.StartElement, local string) string {
+	for _, attr := range el.Attr {
+		if attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultOllamaURL is where Ollama listens unless configured otherwise
+	defaultOllamaURL = "http://localhost:11434"
+	// ollamaVendor is the name Fabric lists Ollama models under
+	ollamaVendor = "Ollama"
+	// ollamaTimeout bounds the quick Ollama calls; pulls run until done
+	ollamaTimeout = 10 * time.Second
+)
+
+// OllamaModel is a model installed in Ollama
+type OllamaModel struct {
+	Name          string `json:"name"`
+	Size          int64  `json:"size"`
+	ModifiedAt    string `json:"modifiedAt"`
+	Family        string `json:"family,omitempty"`
+	ParameterSize string `json:"parameterSize,omitempty"`
+	Quantization  string `json:"quantization,omitempty"`
+	InFabric      bool   `json:"inFabric"` // listed under the Ollama vendor by GetModels
+}
+
+// OllamaPullProgress is emitted as "ollama:pull" while a model downloads
+type OllamaPullProgress struct {
+	Model     string  `json:"model"`
+	Status    string  `json:"status"`
+	Completed int64   `json:"completed"`
+	Total     int64   `json:"total"`
+	Percent   float64 `json:"percent"` // -1 while the current step has no size
+}
+
+// ollamaURL returns the Ollama endpoint from the preferences, then Fabric's
+// own configuration, then the default
+func (a *App) ollamaURL() string {
+	url := ""
+	if prefs, err := a.loadPreferences(); err == nil {
+		url = prefs.OllamaURL
+	}
+	if url == "" {
+		if dir, err := fabricConfigDir(); err == nil {
+			if env, err := readEnvFile(filepath.Join(dir, ".env")); err == nil {
+				url = env["OLLAMA_API_URL"]
+			}
+		}
+	}
+	if url == "" {
+		url = defaultOllamaURL
+	}
+	return strings.TrimRight(url, "/")
+}
+
+// ListOllamaModels returns the models installed in Ollama, marking those
+// Fabric offers for chat
+func (a *App) ListOllamaModels() ([]OllamaModel, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ollamaTimeout)
+	defer cancel()
+
+	var body struct {
+		Models []struct {
+			Name       string `json:"name"`
+			Size       int64  `json:"size"`
+			ModifiedAt string `json:"modified_at"`
+			Details    struct {
+				Family            string `json:"family"`
+				ParameterSize     string `json:"parameter_size"`
+				QuantizationLevel string `json:"quantization_level"`
+			} `json:"details"`
+		} `json:"models"`
+	}
+	if err := a.ollamaRequest(ctx, "GET", "/api/tags", nil, &body); err != nil {
+		return nil, fmt.Errorf("failed to list Ollama models: %v", err)
+	}
+
+	inFabric := a.fabricOllamaModels(ctx)
+	models := make([]OllamaModel, 0, len(body.Models))
+	for _, m := range body.Models {
+		models = append(models, OllamaModel{
+			Name:          m.Name,
+			Size:          m.Size,
+			ModifiedAt:    m.ModifiedAt,
+			Family:        m.Details.Family,
+			ParameterSize: m.Details.ParameterSize,
+			Quantization:  m.Details.QuantizationLevel,
+			InFabric:      inFabric[m.Name] || inFabric[strings.TrimSuffix(m.Name, ":latest")],
+		})
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].Name < models[j].Name })
+	return models, nil
+}
+
+// fabricOllamaModels returns the models the Fabric server lists under its
+// Ollama vendor. The server being offline is not an error here.
+func (a *App) fabricOllamaModels(ctx context.Context) map[string]bool {
+	names := map[string]bool{}
+	resp, err := a.fabricClient().Models(ctx)
+	if err != nil {
+		a.log.Debug("could not match Ollama models to Fabric", "error", err)
+		return names
+	}
+	for vendor, models := range resp.Vendors {
+		if strings.EqualFold(vendor, ollamaVendor) {
+			for _, m := range models {
+				names[m] = true
+			}
+		}
+	}
+	return names
+}
+
+// PullOllamaModel downloads a model into Ollama, emitting "ollama:pull"
+// progress events, and returns once it is installed
+func (a *App) PullOllamaModel(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("no model given")
+	}
+
+	job, ctx := a.startJob("ollama", "Pull "+name)
+	err := a.pullOllamaModel(ctx, job, name)
+	a.finishJob(job, err)
+	if err != nil {
+		return fmt.Errorf("failed to pull %s: %v", name, err)
+	}
+
+	a.log.Info("pulled Ollama model", "model", name)
+	a.emit("ollama:pulled", name)
+	return nil
+}
+
+func (a *App) pullOllamaModel(ctx context.Context, job *job, name string) error {
+	payload, _ := json.Marshal(map[string]any{"model": name, "stream": true})
+	req, err := http.NewRequestWithContext(ctx, "POST", a.ollamaURL()+"/api/pull", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return ollamaError(resp)
+	}
+
+	// Ollama streams one JSON object per line, one step per layer
+	lastPercent := -1
+	lastStatus := ""
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var line struct {
+			Status    string `json:"status"`
+			Total     int64  `json:"total"`
+			Completed int64  `json:"completed"`
+			Error     string `json:"error"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			continue
+		}
+		if line.Error != "" {
+			return fmt.Errorf("%s", line.Error)
+		}
+
+		progress := OllamaPullProgress{Model: name, Status: line.Status, Completed: line.Completed, Total: line.Total, Percent: -1}
+		if line.Total > 0 {
+			progress.Percent = float64(line.Completed) / float64(line.Total) * 100
+		}
+		// Only report status changes and whole-percent steps
+		if line.Status == lastStatus && int(progress.Percent) == lastPercent {
+			continue
+		}
+		lastStatus, lastPercent = line.Status, int(progress.Percent)
+		a.updateJob(job, progress.Percent, line.Status)
+		a.emit("ollama:pull", progress)
+	}
+	return scanner.Err()
+}
+
+// DeleteOllamaModel removes a model from Ollama
+func (a *App) DeleteOllamaModel(name string) error {
+	if name == "" {
+		return fmt.Errorf("no model given")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ollamaTimeout)
+	defer cancel()
+
+	if err := a.ollamaRequest(ctx, "DELETE", "/api/delete", map[string]string{"model": name}, nil); err != nil {
+		return fmt.Errorf("failed to delete %s: %v", name, err)
+	}
+	a.log.Info("deleted Ollama model", "model", name)
+	a.emit("ollama:deleted", name)
+	return nil
+}
+
+// ollamaRequest sends body as JSON to the Ollama API and decodes the reply
+// into out when it is not nil
+func (a *App) ollamaRequest(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, a.ollamaURL()+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("is Ollama running? %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return ollamaError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// ollamaError turns an error response into an error, using Ollama's message
+// when it sent one
+func ollamaError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(data, &body) == nil && body.Error != "" {
+		return fmt.Errorf("Ollama error %d: %s", resp.StatusCode, body.Error)
+	}
+	return fmt.Errorf("Ollama error %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+}
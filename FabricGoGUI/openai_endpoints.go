@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// OpenAIEndpoint is an OpenAI-compatible server, such as LM Studio, vLLM or
+// LiteLLM, offered to Fabric as a vendor of its own
+type OpenAIEndpoint struct {
+	Name    string `json:"name"`
+	BaseURL string `json:"baseUrl"`          // up to and including /v1
+	APIKey  string `json:"apiKey,omitempty"` // kept in Fabric's vendor configuration, never returned
+}
+
+// endpointName limits vendor names to what maps cleanly onto env keys
+var endpointName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9 _-]*$`)
+
+// envPrefix is the prefix of the endpoint's keys in Fabric's .env, the name
+// upper-cased with separators turned into underscores: "LM Studio" becomes
+// LM_STUDIO_API_BASE_URL and LM_STUDIO_API_KEY
+func (e OpenAIEndpoint) envPrefix() string {
+	return strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_").Replace(e.Name))
+}
+
+// validate checks the name, base URL and key, which all end up in Fabric's .env
+func (e OpenAIEndpoint) validate() error {
+	if !endpointName.MatchString(e.Name) {
+		return fmt.Errorf("invalid endpoint name %q: use letters, digits, spaces, - and _", e.Name)
+	}
+	u, err := url.Parse(e.BaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || !validEnvValue(e.BaseURL) {
+		return fmt.Errorf("invalid base URL %q", e.BaseURL)
+	}
+	if !validEnvValue(e.APIKey) {
+		return fmt.Errorf("invalid API key: line breaks, quotes and surrounding spaces are not allowed")
+	}
+	return nil
+}
+
+// GetOpenAIEndpoints returns the registered OpenAI-compatible endpoints,
+// without their keys
+func (a *App) GetOpenAIEndpoints() []OpenAIEndpoint {
+	prefs, err := a.loadPreferences()
+	if err != nil || prefs.OpenAIEndpoints == nil {
+		return []OpenAIEndpoint{}
+	}
+	endpoints := slices.Clone(prefs.OpenAIEndpoints)
+	for i := range endpoints {
+		endpoints[i].APIKey = ""
+	}
+	return endpoints
+}
+
+// SaveOpenAIEndpoint registers an endpoint, or updates the one with the same
+// name, and writes it into Fabric's configuration so its models show up in
+// GetModels. The key goes to secret storage; without one the stored key is
+// kept.
+func (a *App) SaveOpenAIEndpoint(endpoint OpenAIEndpoint) error {
+	endpoint.Name = strings.TrimSpace(endpoint.Name)
+	endpoint.BaseURL = strings.TrimRight(strings.TrimSpace(endpoint.BaseURL), "/")
+	if err := endpoint.validate(); err != nil {
+		return err
+	}
+
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	// Only the name and URL are kept in preferences
+	saved := OpenAIEndpoint{Name: endpoint.Name, BaseURL: endpoint.BaseURL}
+	replaced := false
+	for i, e := range prefs.OpenAIEndpoints {
+		if e.envPrefix() == endpoint.envPrefix() {
+			prefs.OpenAIEndpoints[i] = saved
+			replaced = true
+		}
+	}
+	if !replaced {
+		prefs.OpenAIEndpoints = append(prefs.OpenAIEndpoints, saved)
+		sort.Slice(prefs.OpenAIEndpoints, func(i, j int) bool {
+			return prefs.OpenAIEndpoints[i].Name < prefs.OpenAIEndpoints[j].Name
+		})
+	}
+
+	set := map[string]string{endpoint.envPrefix() + "_API_BASE_URL": endpoint.BaseURL}
+	if endpoint.APIKey != "" {
+		set[endpoint.envPrefix()+"_API_KEY"] = endpoint.APIKey
+	}
+	if err := a.writeVendorConfig(set, nil); err != nil {
+		return err
+	}
+	if err := a.SavePreferences(*prefs); err != nil {
+		return err
+	}
+
+	a.log.Info("saved OpenAI-compatible endpoint", "name", endpoint.Name, "url", endpoint.BaseURL)
+	return a.reloadFabricVendors()
+}
+
+// DeleteOpenAIEndpoint unregisters an endpoint and removes it from Fabric's
+// configuration
+func (a *App) DeleteOpenAIEndpoint(name string) error {
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+
+	target := OpenAIEndpoint{Name: name}
+	kept := prefs.OpenAIEndpoints[:0]
+	for _, e := range prefs.OpenAIEndpoints {
+		if e.envPrefix() != target.envPrefix() {
+			kept = append(kept, e)
+		}
+	}
+	if len(kept) == len(prefs.OpenAIEndpoints) {
+		return fmt.Errorf("endpoint %s not found", name)
+	}
+	prefs.OpenAIEndpoints = kept
+
+	if err := a.writeVendorConfig(nil, []string{target.envPrefix() + "_API_BASE_URL", target.envPrefix() + "_API_KEY"}); err != nil {
+		return err
+	}
+	if err := a.SavePreferences(*prefs); err != nil {
+		return err
+	}
+
+	a.log.Info("deleted OpenAI-compatible endpoint", "name", name)
+	return a.reloadFabricVendors()
+}
+
+// TestOpenAIEndpoint asks an endpoint for its models, to check the URL and
+// key before saving them. Without a key the stored one is used.
+func (a *App) TestOpenAIEndpoint(endpoint OpenAIEndpoint) ([]string, error) {
+	endpoint.BaseURL = strings.TrimRight(strings.TrimSpace(endpoint.BaseURL), "/")
+	if err := endpoint.validate(); err != nil {
+		return nil, err
+	}
+	if endpoint.APIKey == "" && endpoint.Name != "" {
+		endpoint.APIKey = a.vendorEnv()[endpoint.envPrefix()+"_API_KEY"]
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint.BaseURL+"/models", nil)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+endpoint.APIKey)
+	}
+	resp, err := a.externalClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s: %v", endpoint.BaseURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("endpoint error %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var body struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to parse model list: %v", err)
+	}
+	models := make([]string, 0, len(body.Data))
+	for _, m := range body.Data {
+		models = append(models, m.ID)
+	}
+	sort.Strings(models)
+	return models, nil
+}
+
+// reloadFabricVendors restarts a server started by the app, since Fabric
+// only reads its .env at startup, and has the model list refetched
+func (a *App) reloadFabricVendors() error {
+	a.catalog.invalidate()
+	if a.IsServerRunning() {
+		a.StopServer()
+		if err := a.StartServer(); err != nil {
+			return fmt.Errorf("the endpoint was saved but the server did not restart: %v", err)
+		}
+	}
+	return nil
+}
+
+// envKey is what Fabric's .env accepts as a key
+var envKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validEnvValue reports whether a value can be written unquoted to a .env
+// line without ending it early or being read differently by Fabric
+func validEnvValue(value string) bool {
+	return !strings.ContainsAny(value, "\r\n\"'`\x00") && !strings.Contains(value, " #") && strings.TrimSpace(value) == value
+}
+
+// writeFabricEnv sets and removes keys in Fabric's .env, keeping every other
+// line as it is. Keys and values that would break the file are refused.
+func (a *App) writeFabricEnv(set map[string]string, unset []string) error {
+	for k, v := range set {
+		if !envKey.MatchString(k) {
+			return fmt.Errorf("invalid Fabric config key %q", k)
+		}
+		if !validEnvValue(v) {
+			return fmt.Errorf("invalid value for %s: line breaks, quotes, # and surrounding spaces are not allowed", k)
+		}
+	}
+
+	dir, err := fabricConfigDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create Fabric config directory: %v", err)
+	}
+	path := filepath.Join(dir, ".env")
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read Fabric config: %v", err)
+	}
+
+	remove := map[string]bool{}
+	for _, key := range unset {
+		remove[key] = true
+	}
+	pending := map[string]string{}
+	for k, v := range set {
+		pending[k] = v
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		key, _, ok := strings.Cut(strings.TrimSpace(line), "=")
+		key = strings.TrimSpace(strings.TrimPrefix(key, "export "))
+		switch {
+		case ok && remove[key]:
+			continue
+		case ok && pending[key] != "":
+			line = key + "=" + pending[key]
+			delete(pending, key)
+		}
+		if line != "" || len(lines) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	keys := make([]string, 0, len(pending))
+	for k := range pending {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		lines = append(lines, k+"="+pending[k])
+	}
+
+	// The file holds API keys
+	if err := writeFileAtomic(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write Fabric config: %v", err)
+	}
+	return nil
+}
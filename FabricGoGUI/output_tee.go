@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ChooseOutputFile asks where a chat's output should be streamed to, for
+// ChatExtras.OutputFile. Returns "" if the dialog was cancelled.
+func (a *App) ChooseOutputFile() (string, error) {
+	return runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Stream Output to File",
+		DefaultFilename: "fabric_output.md",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Markdown Files", Pattern: "*.md"},
+			{DisplayName: "Text Files", Pattern: "*.txt"},
+		},
+	})
+}
+
+// prepareOutputFile creates or empties the file a chat streams to, so a bad
+// path fails the request before it starts
+func prepareOutputFile(path string) error {
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create output folder: %v", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %v", err)
+	}
+	return f.Close()
+}
+
+// outputTee copies streamed chunks to a file as they arrive. Writes are
+// unbuffered, so whatever was received survives the app closing mid-stream.
+// A nil tee, used when no file was asked for, does nothing.
+type outputTee struct {
+	path string
+	file *os.File
+	log  *slog.Logger
+}
+
+// openOutputTee opens path for appending, so a resumed stream continues the
+// file. Failures are logged and the stream carries on without the file.
+func openOutputTee(path string, log *slog.Logger) *outputTee {
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		log.Warn("failed to open output file", "path", path, "error", err)
+		return nil
+	}
+	return &outputTee{path: path, file: f, log: log}
+}
+
+func (t *outputTee) Write(chunk string) {
+	if t == nil || t.file == nil {
+		return
+	}
+	if _, err := t.file.WriteString(chunk); err != nil {
+		t.log.Warn("failed to write output file, no longer copying the stream", "path", t.path, "error", err)
+		t.file.Close()
+		t.file = nil
+	}
+}
+
+// Close flushes the file to disk and closes it
+func (t *outputTee) Close() {
+	if t == nil || t.file == nil {
+		return
+	}
+	t.file.Sync()
+	t.file.Close()
+	t.file = nil
+}
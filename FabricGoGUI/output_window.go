@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// outputWindowMaxAge is how long rendered output pages are kept
+const outputWindowMaxAge = 7 * 24 * time.Hour
+
+// OpenOutputWindow shows a history entry's output in a window of its own,
+// so it stays in view while new runs use the main window. Wails v2 apps
+// have a single window, so the output is rendered to a page opened in the
+// default browser. An empty entryID uses the latest entry. Returns the
+// page's path.
+func (a *App) OpenOutputWindow(entryID string) (string, error) {
+	entry, ok := a.historyEntryOrLast(entryID)
+	if !ok {
+		return "", fmt.Errorf("history entry not found: %s", entryID)
+	}
+	if strings.TrimSpace(entry.Output) == "" {
+		return "", fmt.Errorf("the entry has no output")
+	}
+
+	theme := "dark"
+	if prefs, err := a.loadPreferences(); err == nil && prefs.Theme == "light" {
+		theme = "light"
+	}
+	data, err := renderHTMLDocument(entry.Output, HTMLOptions{
+		Title:   defaultNoteTitle(entry),
+		Pattern: entry.Pattern,
+		Model:   entry.Model,
+		Time:    entry.Time,
+		Theme:   theme,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// The pages live in the config folder, which other users cannot write to,
+	// unlike a fixed folder in the shared temp directory
+	configDir := a.getConfigDir()
+	if configDir == "" {
+		return "", fmt.Errorf("could not determine config directory")
+	}
+	dir := filepath.Join(configDir, "output_pages")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create output folder: %v", err)
+	}
+	pruneOutputPages(dir)
+	// CreateTemp picks an unused name and opens it with O_EXCL
+	f, err := os.CreateTemp(dir, "output-"+fileNamePart(entry.ID)+"-*.html")
+	if err != nil {
+		return "", fmt.Errorf("failed to save output page: %v", err)
+	}
+	path := f.Name()
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("failed to save output page: %v", err)
+	}
+	if err := openPath(path); err != nil {
+		return "", err
+	}
+	a.log.Info("opened output window", "entry", entry.ID, "path", path)
+	return path, nil
+}
+
+// pruneOutputPages removes pages older than outputWindowMaxAge, which are
+// no longer open anywhere
+func pruneOutputPages(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		info, err := e.Info()
+		if err == nil && !e.IsDir() && time.Since(info.ModTime()) > outputWindowMaxAge {
+			os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// bundleManifestFile describes the patterns in a bundle
+const bundleManifestFile = "manifest.json"
+
+// maxBundleFileSize caps each file read from a pattern bundle
+const maxBundleFileSize = 1024 * 1024
+
+// bundleManifest is written to manifest.json in an exported bundle
+type bundleManifest struct {
+	ExportedAt string        `json:"exportedAt"`
+	AppVersion string        `json:"appVersion"`
+	Patterns   []PatternInfo `json:"patterns"`
+}
+
+// PatternBundleImport reports what ImportPatternBundle installed
+type PatternBundleImport struct {
+	Imported []string `json:"imported"`
+	Skipped  []string `json:"skipped"` // already present as custom patterns
+}
+
+// ExportPatterns writes the named patterns, with all their files, to a zip
+// bundle at bundlePath, asking where to save it when that is empty. Returns
+// the path written, or "" if the save dialog was cancelled.
+func (a *App) ExportPatterns(names []string, bundlePath string) (string, error) {
+	if len(names) == 0 {
+		return "", fmt.Errorf("no patterns selected")
+	}
+	if bundlePath == "" {
+		var err error
+		bundlePath, err = runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+			Title:           "Export Patterns",
+			DefaultFilename: "fabric-patterns-" + time.Now().Format("20060102") + ".zip",
+			Filters:         []runtime.FileFilter{{DisplayName: "Zip Archives", Pattern: "*.zip"}},
+		})
+		if err != nil {
+			return "", err
+		}
+		if bundlePath == "" {
+			return "", nil // User cancelled
+		}
+	}
+
+	fabricDir, _ := fabricPatternsDir()
+	files := map[string][]byte{}
+	manifest := bundleManifest{ExportedAt: time.Now().Format(time.RFC3339), AppVersion: appVersion}
+
+	for _, name := range names {
+		if err := validatePatternName(name); err != nil {
+			return "", err
+		}
+		info, ok := a.findCustomPattern(name)
+		dir := info.Dir
+		if !ok {
+			info = PatternInfo{Name: name, Source: PatternSourceFabric}
+			dir = fabricDir
+		}
+
+		root := filepath.Join(dir, name)
+		if _, err := os.Stat(filepath.Join(root, patternSystemFile)); err != nil {
+			return "", fmt.Errorf("pattern %s not found", name)
+		}
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			rel, _ := filepath.Rel(dir, p)
+			files[filepath.ToSlash(rel)] = data
+			return nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to read pattern %s: %v", name, err)
+		}
+
+		info.Dir = ""
+		manifest.Patterns = append(manifest.Patterns, info)
+	}
+
+	a.describePatterns(manifest.Patterns)
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	files[bundleManifestFile] = data
+
+	if err := writeZip(bundlePath, files); err != nil {
+		return "", fmt.Errorf("failed to write bundle: %v", err)
+	}
+	a.log.Info("exported patterns", "count", len(names), "path", bundlePath)
+	return bundlePath, nil
+}
+
+// ImportPatternBundle installs the patterns in a bundle made by ExportPatterns
+// into the first custom pattern directory, asking for the file when
+// bundlePath is empty. Patterns that already exist as custom patterns are
+// skipped.
+func (a *App) ImportPatternBundle(bundlePath string) (*PatternBundleImport, error) {
+	if bundlePath == "" {
+		var err error
+		bundlePath, err = runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+			Title:   "Import Pattern Bundle",
+			Filters: []runtime.FileFilter{{DisplayName: "Zip Archives", Pattern: "*.zip"}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if bundlePath == "" {
+			return nil, nil // User cancelled
+		}
+	}
+
+	dirs := a.customPatternDirs()
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("add a custom pattern directory in preferences to import patterns")
+	}
+
+	zr, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %v", err)
+	}
+	defer zr.Close()
+
+	// Group the files by pattern, the first path element
+	patterns := map[string][]*zip.File{}
+	for _, f := range zr.File {
+		name := path.Clean(f.Name)
+		if f.FileInfo().IsDir() || name == bundleManifestFile {
+			continue
+		}
+		// Cleaning leaves ".." only at the front, where the name check rejects it
+		pattern, _, ok := strings.Cut(name, "/")
+		if !ok || validatePatternName(pattern) != nil || path.IsAbs(name) || strings.Contains(name, `\`) {
+			continue
+		}
+		patterns[pattern] = append(patterns[pattern], f)
+	}
+
+	result := &PatternBundleImport{Imported: []string{}, Skipped: []string{}}
+	for _, name := range slices.Sorted(maps.Keys(patterns)) {
+		if _, exists := a.findCustomPattern(name); exists {
+			result.Skipped = append(result.Skipped, name)
+			continue
+		}
+		if err := installBundlePattern(dirs[0], name, patterns[name]); err != nil {
+			return result, fmt.Errorf("failed to import pattern %s: %v", name, err)
+		}
+		result.Imported = append(result.Imported, name)
+	}
+	if len(result.Imported) == 0 && len(result.Skipped) == 0 {
+		return nil, fmt.Errorf("the bundle contains no patterns")
+	}
+
+	a.patternIndex.invalidate()
+	a.log.Info("imported pattern bundle", "path", bundlePath, "imported", len(result.Imported), "skipped", len(result.Skipped))
+	return result, nil
+}
+
+// installBundlePattern writes one pattern's files under dir. A pattern
+// without a valid system.md is rejected before anything is written.
+func installBundlePattern(dir, name string, files []*zip.File) error {
+	contents := map[string][]byte{}
+	for _, f := range files {
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(io.LimitReader(rc, maxBundleFileSize+1))
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		if len(data) > maxBundleFileSize {
+			return fmt.Errorf("%s is too large", f.Name)
+		}
+		contents[path.Clean(f.Name)] = data
+	}
+
+	system, ok := contents[name+"/"+patternSystemFile]
+	if !ok {
+		return fmt.Errorf("missing %s", patternSystemFile)
+	}
+	if err := validatePatternContent(system); err != nil {
+		return err
+	}
+
+	for rel, data := range contents {
+		target := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := writeFileAtomic(target, data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
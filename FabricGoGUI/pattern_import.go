@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxPatternSize is the largest system.md ImportPatternFromURL accepts
+const maxPatternSize = 256 * 1024
+
+// ImportPatternFromURL downloads a system.md and installs it as a custom
+// pattern. GitHub file, folder and gist page URLs are turned into their raw
+// equivalents. The name defaults to the pattern's folder or file name.
+func (a *App) ImportPatternFromURL(rawURL, name string) (*PatternInfo, error) {
+	source, err := rawPatternURL(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, err
+	}
+	if name = strings.TrimSpace(name); name == "" {
+		name = patternNameFromURL(source)
+	}
+	if err := validatePatternName(name); err != nil {
+		return nil, err
+	}
+	if _, exists := a.findCustomPattern(name); exists {
+		return nil, fmt.Errorf("a custom pattern named %s already exists", name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	data, err := a.fetch(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download pattern: %v", err)
+	}
+	if err := validatePatternContent(data); err != nil {
+		return nil, err
+	}
+
+	info, err := a.SavePattern(name, string(data))
+	if err != nil {
+		return nil, err
+	}
+	a.log.Info("imported pattern", "pattern", name, "url", source)
+	a.emit("patterns:imported", info)
+	return info, nil
+}
+
+// rawPatternURL maps GitHub page URLs to the URL of the raw system.md
+func rawPatternURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", fmt.Errorf("invalid URL %q", rawURL)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	switch u.Host {
+	case "github.com", "www.github.com":
+		// github.com/user/repo/blob/branch/path or .../tree/branch/folder
+		if len(parts) < 5 || (parts[2] != "blob" && parts[2] != "tree") {
+			return "", fmt.Errorf("link to a system.md file or a pattern folder on GitHub")
+		}
+		file := strings.Join(parts[4:], "/")
+		if parts[2] == "tree" {
+			file += "/" + patternSystemFile
+		}
+		return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/%s", parts[0], parts[1], parts[3], file), nil
+	case "gist.github.com":
+		// gist.github.com/user/id serves its (first) file at /raw
+		if len(parts) == 2 {
+			return fmt.Sprintf("https://gist.githubusercontent.com/%s/%s/raw", parts[0], parts[1]), nil
+		}
+	}
+	return u.String(), nil
+}
+
+// patternNameFromURL guesses a pattern name: the folder holding system.md,
+// or the file name without its extension
+func patternNameFromURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	p := strings.TrimSuffix(u.Path, "/")
+	base := path.Base(p)
+	if strings.EqualFold(base, patternSystemFile) || base == "raw" {
+		base = path.Base(path.Dir(p))
+	}
+	return strings.TrimSuffix(base, path.Ext(base))
+}
+
+// validatePatternContent checks that a download looks like a prompt rather
+// than an error page or a binary file
+func validatePatternContent(data []byte) error {
+	if len(data) > maxPatternSize {
+		return fmt.Errorf("pattern is larger than %d KB", maxPatternSize/1024)
+	}
+	if !utf8.Valid(data) {
+		return fmt.Errorf("pattern is not a text file")
+	}
+	text := strings.TrimSpace(string(data))
+	if text == "" {
+		return fmt.Errorf("pattern is empty")
+	}
+	lower := strings.ToLower(text[:min(len(text), 100)])
+	if strings.HasPrefix(lower, "<!doctype html") || strings.HasPrefix(lower, "<html") {
+		return fmt.Errorf("the URL returned a web page, link to the raw system.md instead")
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// patternDescriptionsFile is the name of Fabric's pattern metadata file
+const patternDescriptionsFile = "pattern_descriptions.json"
+
+// maxPatternDescription caps descriptions taken from a pattern's prompt
+const maxPatternDescription = 200
+
+// patternDescriptions is the format of pattern_descriptions.json
+type patternDescriptions struct {
+	Patterns []struct {
+		PatternName string   `json:"patternName"`
+		Description string   `json:"description"`
+		Tags        []string `json:"tags"`
+	} `json:"patterns"`
+}
+
+// PatternTag is a tag with the number of patterns carrying it
+type PatternTag struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// loadPatternDescriptions reads pattern_descriptions.json from a patterns
+// directory, where Fabric's pattern update may have placed it
+func loadPatternDescriptions(dir string) map[string]patternDescriptions {
+	found := map[string]patternDescriptions{}
+	for _, path := range []string{
+		filepath.Join(dir, patternDescriptionsFile),
+		filepath.Join(dir, "pattern_descriptions", patternDescriptionsFile),
+	} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var file patternDescriptions
+		if err := json.Unmarshal(data, &file); err == nil {
+			found[path] = file
+		}
+	}
+	return found
+}
+
+// describePatterns fills in descriptions, tags and categories. Fabric's
+// metadata file is used where available, otherwise the description is taken
+// from the pattern's prompt.
+func (a *App) describePatterns(patterns []PatternInfo) {
+	type meta struct {
+		description string
+		tags        []string
+	}
+	known := map[string]meta{}
+
+	dirs := a.customPatternDirs()
+	if dir, err := fabricPatternsDir(); err == nil {
+		dirs = append(dirs, dir)
+	}
+	// Earlier directories take precedence, as with the patterns themselves
+	for i := len(dirs) - 1; i >= 0; i-- {
+		for _, file := range loadPatternDescriptions(dirs[i]) {
+			for _, p := range file.Patterns {
+				known[p.PatternName] = meta{p.Description, p.Tags}
+			}
+		}
+	}
+
+	fabricDir, _ := fabricPatternsDir()
+	for i := range patterns {
+		p := &patterns[i]
+		m := known[p.Name]
+		p.Description, p.Tags = m.description, m.tags
+		if p.Description == "" {
+			dir := p.Dir
+			if dir == "" {
+				dir = fabricDir
+			}
+			if data, err := os.ReadFile(filepath.Join(dir, p.Name, patternSystemFile)); err == nil {
+				p.Description = describePrompt(string(data))
+			}
+		}
+		p.Category = patternCategory(p.Name, p.Tags)
+	}
+}
+
+// describePrompt summarises a pattern prompt by the first paragraph of its
+// IDENTITY section, or of the prompt when it has none
+func describePrompt(prompt string) string {
+	lines := strings.Split(prompt, "\n")
+	start := 0
+	for i, line := range lines {
+		if strings.HasPrefix(line, "#") && strings.Contains(strings.ToUpper(line), "IDENTITY") {
+			start = i + 1
+			break
+		}
+	}
+
+	var paragraph []string
+	for _, line := range lines[start:] {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		if line == "" {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
+
+	description := strings.Join(paragraph, " ")
+	if len([]rune(description)) > maxPatternDescription {
+		description = truncateRunes(description, maxPatternDescription-1) + "…"
+	}
+	return description
+}
+
+// patternCategory groups a pattern by its first tag, or by the verb its name
+// starts with (extract_wisdom is in "extract")
+func patternCategory(name string, tags []string) string {
+	if len(tags) > 0 {
+		return strings.ToLower(tags[0])
+	}
+	verb, _, _ := strings.Cut(name, "_")
+	return strings.ToLower(verb)
+}
+
+// GetPatternTags lists every pattern tag, most common first
+func (a *App) GetPatternTags() ([]PatternTag, error) {
+	patterns, err := a.GetPatterns()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, p := range patterns {
+		for _, tag := range p.Tags {
+			counts[tag]++
+		}
+	}
+	tags := make([]PatternTag, 0, len(counts))
+	for name, count := range counts {
+		tags = append(tags, PatternTag{Name: name, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Name < tags[j].Name
+	})
+	return tags, nil
+}
+
+// GetPatternsByTag returns the patterns carrying tag, ignoring case
+func (a *App) GetPatternsByTag(tag string) ([]PatternInfo, error) {
+	patterns, err := a.GetPatterns()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []PatternInfo{}
+	for _, p := range patterns {
+		for _, t := range p.Tags {
+			if strings.EqualFold(t, tag) {
+				filtered = append(filtered, p)
+				break
+			}
+		}
+	}
+	return filtered, nil
+}
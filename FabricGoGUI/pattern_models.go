@@ -0,0 +1,61 @@
+package main
+
+import "fmt"
+
+// PatternModel is the model a pattern runs with by default
+type PatternModel struct {
+	Vendor string `json:"vendor,omitempty"` // looked up from the model list when empty
+	Model  string `json:"model"`
+}
+
+// GetPatternModel returns the model mapped to pattern, or nil if there is none
+func (a *App) GetPatternModel(pattern string) *PatternModel {
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return nil
+	}
+	if m, ok := prefs.PatternModels[pattern]; ok && m.Model != "" {
+		return &m
+	}
+	return nil
+}
+
+// SetPatternModel maps pattern to a model, or removes the mapping when model
+// is empty
+func (a *App) SetPatternModel(pattern, vendor, model string) error {
+	if pattern == "" {
+		return fmt.Errorf("no pattern given")
+	}
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+
+	if model == "" {
+		delete(prefs.PatternModels, pattern)
+	} else {
+		if prefs.PatternModels == nil {
+			prefs.PatternModels = map[string]PatternModel{}
+		}
+		prefs.PatternModels[pattern] = PatternModel{Vendor: vendor, Model: model}
+	}
+	return a.SavePreferences(*prefs)
+}
+
+// patternDefaultModel fills in the vendor and model mapped to pattern when no
+// model was chosen. An explicit model always wins.
+func (a *App) patternDefaultModel(pattern, vendor, model string) (string, string) {
+	if model != "" {
+		return vendor, model
+	}
+	mapped := a.GetPatternModel(pattern)
+	if mapped == nil {
+		return vendor, model
+	}
+	if mapped.Vendor == "" {
+		if v, err := a.vendorForModel(mapped.Model); err == nil {
+			mapped.Vendor = v
+		}
+	}
+	return mapped.Vendor, mapped.Model
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// patternIndexTTL is how long SearchPatterns reuses the pattern list before
+// fetching it again
+const patternIndexTTL = 5 * time.Minute
+
+// patternIndex caches the pattern list with its search fields lowercased
+type patternIndex struct {
+	mu      sync.Mutex
+	entries []indexedPattern
+	built   time.Time
+}
+
+type indexedPattern struct {
+	info        PatternInfo
+	name        string // lowercased, underscores as spaces
+	description string
+	tags        []string
+}
+
+// set replaces the cached patterns
+func (idx *patternIndex) set(patterns []PatternInfo) {
+	entries := make([]indexedPattern, len(patterns))
+	for i, p := range patterns {
+		tags := make([]string, len(p.Tags))
+		for j, tag := range p.Tags {
+			tags[j] = strings.ToLower(tag)
+		}
+		entries[i] = indexedPattern{
+			info:        p,
+			name:        strings.ReplaceAll(strings.ToLower(p.Name), "_", " "),
+			description: strings.ToLower(p.Description),
+			tags:        tags,
+		}
+	}
+
+	idx.mu.Lock()
+	idx.entries = entries
+	idx.built = time.Now()
+	idx.mu.Unlock()
+}
+
+// get returns the cached patterns, or false when they are missing or stale
+func (idx *patternIndex) get() ([]indexedPattern, bool) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.entries == nil || time.Since(idx.built) > patternIndexTTL {
+		return nil, false
+	}
+	return idx.entries, true
+}
+
+// invalidate drops the cache after patterns change
+func (idx *patternIndex) invalidate() {
+	idx.mu.Lock()
+	idx.entries = nil
+	idx.mu.Unlock()
+}
+
+// SearchPatterns ranks patterns against query, best match first. Every word
+// of the query has to match the name, a tag or the description; matches in
+// the name rank highest, and a name can also match fuzzily ("exwis" finds
+// extract_wisdom). An empty query returns every pattern.
+func (a *App) SearchPatterns(query string) ([]PatternInfo, error) {
+	entries, ok := a.patternIndex.get()
+	if !ok {
+		if _, err := a.GetPatterns(); err != nil {
+			return nil, err
+		}
+		entries, _ = a.patternIndex.get()
+	}
+
+	terms := strings.Fields(strings.ToLower(strings.ReplaceAll(query, "_", " ")))
+
+	type match struct {
+		info  PatternInfo
+		score int
+	}
+	var matches []match
+	for _, e := range entries {
+		total := 0
+		for _, term := range terms {
+			score := scorePatternTerm(e, term)
+			if score == 0 {
+				total = 0
+				break
+			}
+			total += score
+		}
+		if total > 0 || len(terms) == 0 {
+			matches = append(matches, match{e.info, total})
+		}
+	}
+
+	// The whole query as one phrase is a strong hint for multi-word queries
+	if phrase := strings.Join(terms, " "); len(terms) > 1 {
+		for i := range matches {
+			if strings.Contains(strings.ReplaceAll(strings.ToLower(matches[i].info.Name), "_", " "), phrase) {
+				matches[i].score += 500
+			}
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].info.Name < matches[j].info.Name
+	})
+
+	results := make([]PatternInfo, len(matches))
+	for i, m := range matches {
+		results[i] = m.info
+	}
+	return results, nil
+}
+
+// scorePatternTerm scores one query word against a pattern, 0 for no match
+func scorePatternTerm(e indexedPattern, term string) int {
+	switch {
+	case e.name == term:
+		return 1000
+	case strings.HasPrefix(e.name, term):
+		return 800
+	case strings.Contains(" "+e.name, " "+term):
+		return 700 // start of a word in the name
+	case strings.Contains(e.name, term):
+		return 600
+	}
+	for _, tag := range e.tags {
+		if tag == term {
+			return 400
+		}
+		if strings.HasPrefix(tag, term) {
+			return 300
+		}
+	}
+	if score := fuzzyScore(e.name, term); score > 0 {
+		return score
+	}
+	if strings.Contains(e.description, term) {
+		return 100
+	}
+	return 0
+}
+
+// fuzzyScore matches term as a subsequence of name, scoring 150-299 with
+// higher scores for characters that are adjacent or start a word
+func fuzzyScore(name, term string) int {
+	ti, bonus, prev := 0, 0, -2
+	for i := 0; i < len(name) && ti < len(term); i++ {
+		if name[i] != term[ti] {
+			continue
+		}
+		if i == prev+1 {
+			bonus += 2
+		}
+		if i == 0 || name[i-1] == ' ' {
+			bonus += 3
+		}
+		prev = i
+		ti++
+	}
+	if ti < len(term) {
+		return 0
+	}
+	return 150 + min(bonus*100/(5*len(term)), 149)
+}
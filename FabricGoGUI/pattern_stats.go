@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PatternStats summarises how a pattern has been used. Averages cover
+// successful runs only.
+type PatternStats struct {
+	Pattern        string `json:"pattern"`
+	Runs           int    `json:"runs"`
+	Failures       int    `json:"failures"`
+	LastUsed       int64  `json:"lastUsed"` // Unix seconds
+	AvgDurationMs  int64  `json:"avgDurationMs"`
+	AvgOutputChars int    `json:"avgOutputChars"`
+
+	TotalDurationMs  int64 `json:"totalDurationMs"`
+	TotalOutputChars int64 `json:"totalOutputChars"`
+}
+
+// patternStatsStore keeps per-pattern usage counters. Unlike history it is
+// never pruned, so the numbers cover every run since it was created.
+type patternStatsStore struct {
+	mu    sync.Mutex
+	stats map[string]*PatternStats
+	path  string
+}
+
+func newPatternStatsStore() *patternStatsStore {
+	return &patternStatsStore{stats: map[string]*PatternStats{}}
+}
+
+// Load reads persisted stats from path and keeps saving there afterwards. The
+// first time, when there is no file yet, the stats are built from history.
+func (s *patternStatsStore) Load(path string, history []HistoryEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.path = path
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		for _, e := range history {
+			s.recordLocked(e.Pattern, e.Time, e.DurationMs, len(e.Output), e.Error != "")
+		}
+		s.saveLocked()
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read pattern stats: %v", err)
+	}
+
+	var stats []*PatternStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return fmt.Errorf("failed to parse pattern stats: %v", err)
+	}
+	for _, st := range stats {
+		s.stats[st.Pattern] = st
+	}
+	return nil
+}
+
+// Record counts one run of pattern
+func (s *patternStatsStore) Record(pattern string, durationMs int64, outputChars int, failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.recordLocked(pattern, time.Now().Unix(), durationMs, outputChars, failed)
+	s.saveLocked()
+}
+
+func (s *patternStatsStore) recordLocked(pattern string, at, durationMs int64, outputChars int, failed bool) {
+	if pattern == "" {
+		return
+	}
+	st, ok := s.stats[pattern]
+	if !ok {
+		st = &PatternStats{Pattern: pattern}
+		s.stats[pattern] = st
+	}
+
+	st.Runs++
+	st.LastUsed = max(st.LastUsed, at)
+	if failed {
+		st.Failures++
+		return
+	}
+	st.TotalDurationMs += durationMs
+	st.TotalOutputChars += int64(outputChars)
+	if succeeded := st.Runs - st.Failures; succeeded > 0 {
+		st.AvgDurationMs = st.TotalDurationMs / int64(succeeded)
+		st.AvgOutputChars = int(st.TotalOutputChars / int64(succeeded))
+	}
+}
+
+// All returns a copy of every pattern's stats
+func (s *patternStatsStore) All() []PatternStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all := make([]PatternStats, 0, len(s.stats))
+	for _, st := range s.stats {
+		all = append(all, *st)
+	}
+	return all
+}
+
+// Reset forgets all stats
+func (s *patternStatsStore) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats = map[string]*PatternStats{}
+	s.saveLocked()
+}
+
+func (s *patternStatsStore) saveLocked() {
+	if s.path == "" {
+		return
+	}
+	stats := make([]*PatternStats, 0, len(s.stats))
+	for _, st := range s.stats {
+		stats = append(stats, st)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Pattern < stats[j].Pattern })
+
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return
+	}
+	writeFileAtomic(s.path, data, 0644)
+}
+
+// GetPatternStats returns usage stats for every pattern that has been run,
+// sorted by "runs" (the default), "recent", "duration", "output" or "name"
+func (a *App) GetPatternStats(sortBy string) []PatternStats {
+	stats := a.patternStats.All()
+
+	less := func(i, j int) bool { return stats[i].Runs > stats[j].Runs }
+	switch sortBy {
+	case "recent":
+		less = func(i, j int) bool { return stats[i].LastUsed > stats[j].LastUsed }
+	case "duration":
+		less = func(i, j int) bool { return stats[i].AvgDurationMs > stats[j].AvgDurationMs }
+	case "output":
+		less = func(i, j int) bool { return stats[i].AvgOutputChars > stats[j].AvgOutputChars }
+	case "name":
+		less = func(i, j int) bool { return stats[i].Pattern < stats[j].Pattern }
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		if less(i, j) != less(j, i) {
+			return less(i, j)
+		}
+		return stats[i].Pattern < stats[j].Pattern
+	})
+	return stats
+}
+
+// ResetPatternStats clears the usage stats
+func (a *App) ResetPatternStats() {
+	a.patternStats.Reset()
+}
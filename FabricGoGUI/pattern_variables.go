@@ -0,0 +1,48 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// patternVariable matches a {{name}} placeholder. Fabric fills {{input}}
+// itself, and names containing a colon are its template plugins.
+var patternVariable = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_-]*)\s*\}\}`)
+
+// GetPatternVariables lists the {{variables}} a pattern expects, in the order
+// they first appear
+func (a *App) GetPatternVariables(name string) ([]string, error) {
+	pattern, err := a.GetPattern(name)
+	if err != nil {
+		return nil, err
+	}
+	return findPatternVariables(pattern.System), nil
+}
+
+// findPatternVariables returns the distinct variable names in a prompt
+func findPatternVariables(prompt string) []string {
+	seen := map[string]bool{"input": true}
+	variables := []string{}
+	for _, match := range patternVariable.FindAllStringSubmatch(prompt, -1) {
+		if name := match[1]; !seen[name] {
+			seen[name] = true
+			variables = append(variables, name)
+		}
+	}
+	return variables
+}
+
+// substituteVariables fills {{name}} placeholders from variables, leaving
+// unknown ones and {{input}} alone
+func substituteVariables(prompt string, variables map[string]string) string {
+	if len(variables) == 0 {
+		return prompt
+	}
+	return patternVariable.ReplaceAllStringFunc(prompt, func(placeholder string) string {
+		name := strings.TrimSpace(strings.Trim(placeholder, "{}"))
+		if value, ok := variables[name]; ok && name != "input" {
+			return value
+		}
+		return placeholder
+	})
+}
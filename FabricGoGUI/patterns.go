@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+)
+
+// PatternUpdate summarises what fabric --updatepatterns changed
+type PatternUpdate struct {
+	Added    []string      `json:"added"`
+	Changed  []string      `json:"changed"`
+	Removed  []string      `json:"removed"`
+	Total    int           `json:"total"`
+	Patterns []PatternInfo `json:"patterns"` // the refreshed list
+}
+
+// fabricPatternsDir returns the directory fabric keeps its patterns in
+func fabricPatternsDir() (string, error) {
+	dir, err := fabricConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "patterns"), nil
+}
+
+// UpdatePatterns runs fabric --updatepatterns, emitting its output as
+// "patterns:output" lines, then refreshes the pattern list and emits it with
+// the added, changed and removed patterns as "patterns:updated"
+func (a *App) UpdatePatterns() (*PatternUpdate, error) {
+	return a.updatePatterns("Updating patterns", func(line string) {
+		a.emit("patterns:output", line)
+	})
+}
+
+// updatePatterns runs the pattern update as a job and works out what changed
+// by comparing the patterns directory before and after
+func (a *App) updatePatterns(title string, onLine func(string)) (*PatternUpdate, error) {
+	path, err := a.fabricPath()
+	if err != nil {
+		return nil, err
+	}
+	dir, err := fabricPatternsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	before, _ := snapshotPatterns(dir) // Missing on first download
+
+	job, ctx := a.startJob("patterns", title)
+	cmd := exec.CommandContext(ctx, path, "--updatepatterns")
+	err = a.runWithOutput(cmd, func(line string) {
+		a.updateJob(job, -1, line)
+		onLine(line)
+	})
+	a.finishJob(job, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update patterns: %v", err)
+	}
+
+	after, err := snapshotPatterns(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read patterns: %v", err)
+	}
+
+	update := diffPatternSnapshots(before, after)
+	// The cached list predates the update, fetch it again so new patterns are
+	// in the result rather than only after the next background refresh
+	a.catalog.invalidate()
+	fetchCtx, cancel := context.WithTimeout(context.Background(), catalogTimeout)
+	if names, err := a.fabricClient().Patterns(fetchCtx); err != nil {
+		a.log.Debug("failed to refresh patterns", "error", err)
+	} else if a.catalog.setPatterns(a.GetBaseURL(), names) {
+		a.patternIndex.invalidate()
+	}
+	cancel()
+	if patterns, err := a.GetPatterns(); err == nil {
+		update.Patterns = patterns
+	}
+	a.log.Info("updated patterns", "added", len(update.Added), "changed", len(update.Changed), "removed", len(update.Removed))
+	a.emit("patterns:updated", update)
+	return update, nil
+}
+
+// snapshotPatterns hashes the files of every pattern in dir
+func snapshotPatterns(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := make(map[string]string, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		hash := sha256.New()
+		root := filepath.Join(dir, e.Name())
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil
+			}
+			rel, _ := filepath.Rel(root, path)
+			hash.Write([]byte(rel))
+			hash.Write(data)
+			return nil
+		})
+		snapshot[e.Name()] = hex.EncodeToString(hash.Sum(nil))
+	}
+	return snapshot, nil
+}
+
+// diffPatternSnapshots compares two snapshots taken by snapshotPatterns
+func diffPatternSnapshots(before, after map[string]string) *PatternUpdate {
+	update := &PatternUpdate{Added: []string{}, Changed: []string{}, Removed: []string{}, Total: len(after)}
+	for name, hash := range after {
+		old, ok := before[name]
+		switch {
+		case !ok:
+			update.Added = append(update.Added, name)
+		case old != hash:
+			update.Changed = append(update.Changed, name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			update.Removed = append(update.Removed, name)
+		}
+	}
+	sort.Strings(update.Added)
+	sort.Strings(update.Changed)
+	sort.Strings(update.Removed)
+	return update
+}
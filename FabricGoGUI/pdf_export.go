@@ -0,0 +1,695 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	xfont "golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// PDFOptions control the layout of ExportToPDF
+type PDFOptions struct {
+	Title    string `json:"title,omitempty"`
+	Pattern  string `json:"pattern,omitempty"`
+	Model    string `json:"model,omitempty"`
+	Time     int64  `json:"time,omitempty"`     // unix seconds shown in the header, now when unset
+	Header   bool   `json:"header"`             // title, pattern, model and time at the top of each page
+	Footer   bool   `json:"footer"`             // page numbers at the bottom of each page
+	PageSize string `json:"pageSize,omitempty"` // "a4" (default) or "letter"
+}
+
+// pdfPageSizes are page dimensions in points
+var pdfPageSizes = map[string][2]float64{
+	"a4":     {595.28, 841.89},
+	"letter": {612, 792},
+}
+
+const (
+	pdfMargin     = 56.0 // left, right and default top and bottom margin
+	pdfBodySize   = 10.5
+	pdfCodeSize   = 9.0
+	pdfTableSize  = 9.0
+	pdfListIndent = 18.0
+)
+
+// ExportToPDF renders markdown output to a paginated PDF, with headings,
+// lists, code blocks and tables laid out. Text the embedded fonts cannot
+// show, such as emoji, is an error. When path is empty a save dialog is
+// shown. Returns the path written, or "" if the dialog was cancelled.
+func (a *App) ExportToPDF(content, path string, options PDFOptions) (string, error) {
+	if strings.TrimSpace(content) == "" {
+		return "", fmt.Errorf("no content to export")
+	}
+	pageSize := strings.ToLower(options.PageSize)
+	if pageSize == "" {
+		pageSize = "a4"
+	}
+	size, ok := pdfPageSizes[pageSize]
+	if !ok {
+		return "", fmt.Errorf("unsupported page size %q", options.PageSize)
+	}
+
+	if path == "" {
+		name := "fabric_output"
+		if options.Pattern != "" {
+			name = options.Pattern
+		}
+		var err error
+		path, err = runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+			Title:           "Export to PDF",
+			DefaultFilename: name + ".pdf",
+			Filters:         []runtime.FileFilter{{DisplayName: "PDF Documents", Pattern: "*.pdf"}},
+		})
+		if err != nil {
+			return "", err
+		}
+		if path == "" {
+			return "", nil // User cancelled
+		}
+	}
+
+	data, err := renderPDF(content, options, size[0], size[1])
+	if err != nil {
+		return "", fmt.Errorf("failed to render PDF: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to save file: %v", err)
+	}
+	a.log.Info("exported PDF", "path", path)
+	return path, nil
+}
+
+// pdfFont is a TrueType font embedded in the PDF. Text is written as glyph
+// IDs, so every character the font has can be shown; the Go fonts cover
+// Latin, Greek and Cyrillic.
+type pdfFont struct {
+	ref   string // resource name used in content streams
+	ttf   []byte
+	mono  bool
+	once  sync.Once
+	name  string            // PostScript name
+	glyph map[rune]pdfGlyph // characters the font has
+	// Metrics in 1/1000 em, for the font descriptor
+	ascent, descent, capHeight int
+	bbox                       [4]int
+}
+
+// pdfGlyph is a character's glyph ID and advance width in 1/1000 em
+type pdfGlyph struct {
+	id    uint16
+	width int
+}
+
+var (
+	pdfRegular = &pdfFont{ref: "F1", ttf: goregular.TTF}
+	pdfBold    = &pdfFont{ref: "F2", ttf: gobold.TTF}
+	pdfMono    = &pdfFont{ref: "F3", ttf: gomono.TTF, mono: true}
+	pdfFonts   = []*pdfFont{pdfRegular, pdfBold, pdfMono}
+)
+
+// load reads the character map and metrics on first use
+func (f *pdfFont) load() *pdfFont {
+	f.once.Do(func() {
+		font, err := sfnt.Parse(f.ttf)
+		if err != nil {
+			panic(fmt.Sprintf("invalid embedded font %s: %v", f.ref, err))
+		}
+		var buf sfnt.Buffer
+		em := fixed.I(1000)
+		f.name, _ = font.Name(&buf, sfnt.NameIDPostScript)
+		if f.name == "" {
+			f.name = "Font" + f.ref
+		}
+
+		f.glyph = map[rune]pdfGlyph{}
+		for r := rune(32); r <= 0xFFFF; r++ {
+			id, err := font.GlyphIndex(&buf, r)
+			if err != nil || id == 0 {
+				continue
+			}
+			advance, err := font.GlyphAdvance(&buf, id, em, xfont.HintingNone)
+			if err != nil {
+				continue
+			}
+			f.glyph[r] = pdfGlyph{id: uint16(id), width: advance.Round()}
+		}
+
+		if m, err := font.Metrics(&buf, em, xfont.HintingNone); err == nil {
+			f.ascent, f.descent, f.capHeight = m.Ascent.Round(), -m.Descent.Round(), m.CapHeight.Round()
+		}
+		// sfnt measures y downwards, PDF upwards
+		if b, err := font.Bounds(&buf, em, xfont.HintingNone); err == nil {
+			f.bbox = [4]int{b.Min.X.Round(), -b.Max.Y.Round(), b.Max.X.Round(), -b.Min.Y.Round()}
+		}
+	})
+	return f
+}
+
+// width returns the width of text in points
+func (f *pdfFont) width(text string, size float64) float64 {
+	glyphs := f.load().glyph
+	total := 0
+	for _, r := range text {
+		total += glyphs[r].width
+	}
+	return float64(total) * size / 1000
+}
+
+// pdfClean turns tabs into spaces and drops other control characters, which
+// have no glyphs
+func pdfClean(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t':
+			return ' '
+		case r != '\n' && unicode.IsControl(r):
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// checkPDFText fails for characters the fonts cannot show, such as emoji
+// or CJK, rather than printing them as blanks
+func checkPDFText(text string) error {
+	for _, r := range pdfClean(text) {
+		if r == '\n' {
+			continue
+		}
+		for _, f := range pdfFonts {
+			if _, ok := f.load().glyph[r]; !ok {
+				return fmt.Errorf("the PDF fonts cannot show %q (U+%04X), export as markdown or HTML instead", r, r)
+			}
+		}
+	}
+	return nil
+}
+
+// pdfTextString encodes text for the document info as UTF-16
+func pdfTextString(text string) string {
+	var sb strings.Builder
+	sb.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(text)) {
+		fmt.Fprintf(&sb, "%04X", u)
+	}
+	sb.WriteByte('>')
+	return sb.String()
+}
+
+// pdfLayout places blocks on pages, top to bottom. y is measured from the
+// top of the page and converted when drawing.
+type pdfLayout struct {
+	width, height float64
+	top, bottom   float64 // margins
+	pages         []*bytes.Buffer
+	page          *bytes.Buffer
+	y             float64
+	used          map[*pdfFont]map[uint16]rune // glyphs drawn, for the width and ToUnicode tables
+}
+
+func (l *pdfLayout) newPage() {
+	l.page = &bytes.Buffer{}
+	l.pages = append(l.pages, l.page)
+	l.y = l.top
+}
+
+// ensure starts a new page unless height fits on the current one
+func (l *pdfLayout) ensure(height float64) {
+	if l.page == nil || (l.y+height > l.height-l.bottom && l.y > l.top) {
+		l.newPage()
+	}
+}
+
+// space adds vertical space, except at the top of a page
+func (l *pdfLayout) space(height float64) {
+	if l.page != nil && l.y > l.top {
+		l.y += height
+	}
+}
+
+// text draws a line of text as glyph IDs
+func (l *pdfLayout) text(font *pdfFont, size, x, baseline, gray float64, text string) {
+	if l.used[font] == nil {
+		l.used[font] = map[uint16]rune{}
+	}
+	var hex strings.Builder
+	for _, r := range text {
+		if g, ok := font.load().glyph[r]; ok {
+			l.used[font][g.id] = r
+			fmt.Fprintf(&hex, "%04X", g.id)
+		}
+	}
+	fmt.Fprintf(l.page, "BT /%s %.1f Tf %.2f g 1 0 0 1 %.2f %.2f Tm <%s> Tj ET\n",
+		font.ref, size, gray, x, l.height-baseline, hex.String())
+}
+
+func (l *pdfLayout) fill(x, y, w, h, gray float64) {
+	fmt.Fprintf(l.page, "%.2f g %.2f %.2f %.2f %.2f re f\n", gray, x, l.height-y-h, w, h)
+}
+
+func (l *pdfLayout) stroke(x, y, w, h, gray float64) {
+	fmt.Fprintf(l.page, "%.2f G 0.5 w %.2f %.2f %.2f %.2f re S\n", gray, x, l.height-y-h, w, h)
+}
+
+func (l *pdfLayout) line(x1, y1, x2, y2, gray, width float64) {
+	fmt.Fprintf(l.page, "%.2f G %.2f w %.2f %.2f m %.2f %.2f l S\n", gray, width, x1, l.height-y1, x2, l.height-y2)
+}
+
+// block lays out one markdown block
+func (l *pdfLayout) block(b mdBlock) {
+	left := pdfMargin + float64(b.Indent)*pdfListIndent
+	if b.Quote {
+		left += 14
+	}
+	right := l.width - pdfMargin
+
+	switch b.Kind {
+	case mdHeading:
+		size := map[int]float64{1: 18, 2: 15, 3: 13}[b.Level]
+		if size == 0 {
+			size = 11.5
+		}
+		l.space(size * 0.8)
+		lines := wrapPDFText(b.Text, pdfBold, size, right-left)
+		l.ensure(float64(len(lines))*size*1.25 + pdfBodySize*3) // keep the heading with what follows
+		for _, text := range lines {
+			l.text(pdfBold, size, left, l.y+size, 0.1, text)
+			l.y += size * 1.25
+		}
+		if b.Level <= 2 {
+			l.line(left, l.y+2, right, l.y+2, 0.8, 0.5)
+			l.y += 4
+		}
+		l.y += 4
+
+	case mdParagraph:
+		lead := pdfBodySize * 1.4
+		if b.Indent == 0 {
+			l.space(pdfBodySize * 0.6)
+		}
+		for i, text := range wrapPDFText(b.Text, pdfRegular, pdfBodySize, right-left) {
+			l.ensure(lead)
+			baseline := l.y + pdfBodySize
+			if i == 0 && b.Marker != "" {
+				l.text(pdfRegular, pdfBodySize, left-5-pdfRegular.width(b.Marker, pdfBodySize), baseline, 0.1, b.Marker)
+			}
+			if b.Quote {
+				l.fill(pdfMargin+2, l.y, 2, lead, 0.75)
+			}
+			l.text(pdfRegular, pdfBodySize, left, baseline, 0.1, text)
+			l.y += lead
+		}
+
+	case mdCode:
+		const pad = 5.0
+		lead := pdfCodeSize * 1.35
+		l.space(pdfBodySize * 0.6)
+		perLine := int((right - left - 2*pad) / (pdfCodeSize * 0.6))
+		lines := wrapPDFCode(b.Text, perLine)
+		l.ensure(lead + 2*pad)
+		l.fill(left, l.y, right-left, pad, 0.95)
+		l.y += pad
+		for _, text := range lines {
+			l.ensure(lead)
+			l.fill(left, l.y, right-left, lead, 0.95)
+			l.text(pdfMono, pdfCodeSize, left+pad, l.y+pdfCodeSize, 0.15, text)
+			l.y += lead
+		}
+		l.fill(left, l.y, right-left, pad, 0.95)
+		l.y += pad
+
+	case mdTable:
+		l.space(pdfBodySize * 0.6)
+		l.table(b.Rows, left, right)
+
+	case mdRule:
+		l.space(pdfBodySize * 0.6)
+		l.ensure(10)
+		l.line(left, l.y+5, right, l.y+5, 0.75, 0.75)
+		l.y += 10
+	}
+}
+
+// table lays out rows with column widths in proportion to their content,
+// wrapping cells that do not fit
+func (l *pdfLayout) table(rows [][]string, left, right float64) {
+	const pad = 4.0
+	cols := 0
+	for _, row := range rows {
+		cols = max(cols, len(row))
+	}
+	if cols == 0 {
+		return
+	}
+
+	natural := make([]float64, cols)
+	total := 0.0
+	for r, row := range rows {
+		font := pdfRegular
+		if r == 0 {
+			font = pdfBold
+		}
+		for i, cell := range row {
+			natural[i] = max(natural[i], font.width(cell, pdfTableSize)+2*pad)
+		}
+	}
+	for _, w := range natural {
+		total += w
+	}
+	widths := natural
+	if avail := right - left; total > avail {
+		for i := range widths {
+			widths[i] = widths[i] * avail / total
+		}
+	}
+
+	lead := pdfTableSize * 1.35
+	for r, row := range rows {
+		font := pdfRegular
+		if r == 0 {
+			font = pdfBold
+		}
+		cells := make([][]string, cols)
+		lines := 0
+		for i := range cells {
+			if i < len(row) {
+				cells[i] = wrapPDFText(row[i], font, pdfTableSize, widths[i]-2*pad)
+			}
+			lines = max(lines, len(cells[i]))
+		}
+
+		// A row fitting on a page is kept whole, a taller one continues on
+		// the following pages
+		l.ensure(float64(lines)*lead + 2*pad)
+		for first := 0; first < lines || first == 0; {
+			fit := int((l.height - l.bottom - l.y - 2*pad) / lead)
+			if fit < 1 {
+				l.newPage()
+				continue
+			}
+			n := min(lines-first, fit)
+			height := float64(n)*lead + 2*pad
+			x := left
+			for i, cell := range cells {
+				if r == 0 {
+					l.fill(x, l.y, widths[i], height, 0.93)
+				}
+				l.stroke(x, l.y, widths[i], height, 0.7)
+				for j := first; j < first+n && j < len(cell); j++ {
+					l.text(font, pdfTableSize, x+pad, l.y+pad+float64(j-first)*lead+pdfTableSize, 0.1, cell[j])
+				}
+				x += widths[i]
+			}
+			l.y += height
+			first += max(n, 1)
+			if first < lines {
+				l.newPage()
+			}
+		}
+	}
+}
+
+// decorate adds the header and footer to every page once the page count is known
+func (l *pdfLayout) decorate(options PDFOptions) {
+	stamp := time.Now()
+	if options.Time > 0 {
+		stamp = time.Unix(options.Time, 0)
+	}
+	parts := []string{}
+	for _, p := range []string{options.Title, options.Pattern, options.Model} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	title := pdfClean(strings.Join(parts, " · "))
+	date := stamp.Format("2006-01-02 15:04")
+
+	for i, page := range l.pages {
+		l.page = page
+		if options.Header {
+			dateX := l.width - pdfMargin - pdfRegular.width(date, 8)
+			l.text(pdfRegular, 8, pdfMargin, 34, 0.45, truncatePDFText(title, pdfRegular, 8, dateX-pdfMargin-12))
+			l.text(pdfRegular, 8, dateX, 34, 0.45, date)
+			l.line(pdfMargin, 40, l.width-pdfMargin, 40, 0.85, 0.5)
+		}
+		if options.Footer {
+			number := fmt.Sprintf("Page %d of %d", i+1, len(l.pages))
+			l.text(pdfRegular, 8, (l.width-pdfRegular.width(number, 8))/2, l.height-28, 0.45, number)
+		}
+	}
+}
+
+// wrapPDFText breaks text into lines no wider than width, splitting words
+// that are longer than a line
+func wrapPDFText(text string, font *pdfFont, size, width float64) []string {
+	lines := []string{}
+	for _, para := range strings.Split(pdfClean(text), "\n") {
+		line, started := "", false
+		for _, word := range strings.Fields(para) {
+			if started {
+				if candidate := line + " " + word; font.width(candidate, size) <= width {
+					line = candidate
+					continue
+				}
+				lines = append(lines, line)
+			}
+			w := []rune(word)
+			for font.width(string(w), size) > width && len(w) > 1 {
+				n := len(w) - 1
+				for n > 1 && font.width(string(w[:n]), size) > width {
+					n--
+				}
+				lines = append(lines, string(w[:n]))
+				w = w[n:]
+			}
+			line, started = string(w), true
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// wrapPDFCode hard-wraps code at perLine characters, expanding tabs
+func wrapPDFCode(code string, perLine int) []string {
+	perLine = max(perLine, 1)
+	lines := []string{}
+	for _, raw := range strings.Split(code, "\n") {
+		line := []rune(pdfClean(strings.ReplaceAll(raw, "\t", "    ")))
+		for len(line) > perLine {
+			lines = append(lines, string(line[:perLine]))
+			line = line[perLine:]
+		}
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+// truncatePDFText shortens text with an ellipsis to fit width
+func truncatePDFText(text string, font *pdfFont, size, width float64) string {
+	if font.width(text, size) <= width {
+		return text
+	}
+	const ellipsis = "…"
+	runes := []rune(text)
+	for n := len(runes) - 1; n > 0; n-- {
+		if font.width(string(runes[:n])+ellipsis, size) <= width {
+			return string(runes[:n]) + ellipsis
+		}
+	}
+	return ellipsis
+}
+
+// checkPDFBlocks checks the text of every block and the header can be shown
+func checkPDFBlocks(blocks []mdBlock, options PDFOptions) error {
+	texts := []string{options.Title, options.Pattern, options.Model}
+	for _, b := range blocks {
+		texts = append(texts, b.Text, b.Marker)
+		for _, row := range b.Rows {
+			texts = append(texts, row...)
+		}
+	}
+	for _, text := range texts {
+		if err := checkPDFText(text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deflate compresses a stream
+func deflate(data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	zw := zlib.NewWriter(&out)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// pdfWidths is the CID font's /W array for the glyphs drawn
+func pdfWidths(font *pdfFont, used map[uint16]rune) string {
+	ids := slices.Sorted(maps.Keys(used))
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for _, id := range ids {
+		fmt.Fprintf(&sb, "%d [%d] ", id, font.glyph[used[id]].width)
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+// pdfToUnicode maps the glyphs drawn back to characters, so text can be
+// searched and copied from the PDF
+func pdfToUnicode(used map[uint16]rune) string {
+	var sb strings.Builder
+	sb.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	ids := slices.Sorted(maps.Keys(used))
+	for len(ids) > 0 {
+		chunk := ids[:min(len(ids), 100)] // at most 100 entries per block
+		ids = ids[len(chunk):]
+		fmt.Fprintf(&sb, "%d beginbfchar\n", len(chunk))
+		for _, id := range chunk {
+			fmt.Fprintf(&sb, "<%04X> <", id)
+			for _, u := range utf16.Encode([]rune{used[id]}) {
+				fmt.Fprintf(&sb, "%04X", u)
+			}
+			sb.WriteString(">\n")
+		}
+		sb.WriteString("endbfchar\n")
+	}
+	sb.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend")
+	return sb.String()
+}
+
+// renderPDF lays out markdown and writes the PDF file structure
+func renderPDF(content string, options PDFOptions, width, height float64) ([]byte, error) {
+	blocks := markdownBlocks(content)
+	if err := checkPDFBlocks(blocks, options); err != nil {
+		return nil, err
+	}
+
+	l := &pdfLayout{width: width, height: height, top: pdfMargin, bottom: pdfMargin, used: map[*pdfFont]map[uint16]rune{}}
+	if options.Header {
+		l.top = pdfMargin + 8
+	}
+	if options.Footer {
+		l.bottom = pdfMargin + 4
+	}
+	l.newPage()
+	for _, b := range blocks {
+		l.block(b)
+	}
+	l.decorate(options)
+
+	// Objects: 1 catalog, 2 page tree, 3 info, then five for each font used,
+	// then a page and its content stream for each page
+	var out bytes.Buffer
+	offsets := []int{}
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	stream := func(dict string, data []byte) error {
+		compressed, err := deflate(data)
+		if err != nil {
+			return err
+		}
+		object(fmt.Sprintf("<< %s/Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", dict, len(compressed), compressed))
+		return nil
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	var used []*pdfFont
+	for _, f := range pdfFonts {
+		if len(l.used[f]) > 0 {
+			used = append(used, f)
+		}
+	}
+	const fontStart, perFont = 4, 5
+	pageStart := fontStart + perFont*len(used)
+	kids := make([]string, len(l.pages))
+	for i := range l.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", pageStart+2*i)
+	}
+	fonts := make([]string, len(used))
+	for i, f := range used {
+		fonts[i] = fmt.Sprintf("/%s %d 0 R", f.ref, fontStart+perFont*i)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(l.pages)))
+	title := options.Title
+	if title == "" {
+		title = options.Pattern
+	}
+	object(fmt.Sprintf("<< /Title %s /Producer (Fabric GUI) /CreationDate (D:%s) >>",
+		pdfTextString(title), time.Now().Format("20060102150405")))
+
+	// Each font is a Type0 font over a CID font, its descriptor, the
+	// TrueType file and the map back to Unicode
+	for i, f := range used {
+		n := fontStart + perFont*i
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+			f.name, n+1, n+4))
+		object(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /CIDToGIDMap /Identity /W %s >>",
+			f.name, n+2, pdfWidths(f, l.used[f])))
+		flags := 32 // nonsymbolic
+		if f.mono {
+			flags |= 1
+		}
+		object(fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags %d /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+			f.name, flags, f.bbox[0], f.bbox[1], f.bbox[2], f.bbox[3], f.ascent, f.descent, f.capHeight, n+3))
+		if err := stream(fmt.Sprintf("/Length1 %d ", len(f.ttf)), f.ttf); err != nil {
+			return nil, err
+		}
+		if err := stream("", []byte(pdfToUnicode(l.used[f]))); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, page := range l.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s >> >> /Contents %d 0 R >>",
+			pdfNumber(width), pdfNumber(height), strings.Join(fonts, " "), pageStart+2*i+1))
+		if err := stream("", page.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes(), nil
+}
+
+// pdfNumber formats a dimension without needless decimals
+func pdfNumber(v float64) string {
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%d", int(v))
+	}
+	return fmt.Sprintf("%.2f", v)
+}
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxPodcastSize bounds how much audio an episode download may be
+const maxPodcastSize = 1 << 30
+
+// audioExtensions are the file types of podcast audio
+var audioExtensions = map[string]bool{
+	".mp3": true, ".m4a": true, ".aac": true, ".ogg": true, ".opus": true, ".wav": true, ".flac": true,
+}
+
+// PodcastRequest is an episode to transcribe and run through a pattern
+type PodcastRequest struct {
+	URL     string `json:"url"`     // a podcast feed, an episode page or the audio itself
+	Episode string `json:"episode"` // guid, link or part of the title of a feed's episode; the latest when empty
+	Preset  string `json:"preset"`  // runs the preset, otherwise the pattern with the model
+	Pattern string `json:"pattern"`
+	Vendor  string `json:"vendor"`
+	Model   string `json:"model"` // defaults to the pattern's model
+}
+
+// PodcastResult is a processed episode
+type PodcastResult struct {
+	Show       string `json:"show,omitempty"`
+	Title      string `json:"title"`
+	AudioURL   string `json:"audioUrl"`
+	Transcript string `json:"transcript"`
+	Output     string `json:"output"`
+	EntryID    string `json:"entryId"`
+}
+
+// PodcastProgress reports the stage an episode is at, emitted as
+// "podcast:progress"
+type PodcastProgress struct {
+	JobID    string  `json:"jobId"`
+	Stage    string  `json:"stage"`    // resolving, downloading, compressing, transcribing, running, complete, error
+	Progress float64 `json:"progress"` // percent of the stage, -1 when unknown
+	Message  string  `json:"message"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// podcastEpisode is an episode's audio and what it is called
+type podcastEpisode struct {
+	show, title, audio string
+}
+
+// isAudioType reports whether a MIME type, or failing that a URL's file
+// extension, is audio
+func isAudioType(mimeType, rawURL string) bool {
+	if mediaType, _, err := mime.ParseMediaType(mimeType); err == nil && mediaType != "" {
+		return strings.HasPrefix(mediaType, "audio/")
+	}
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return false
+	}
+	return audioExtensions[strings.ToLower(path.Ext(u.Path))]
+}
+
+// ProcessPodcast downloads a podcast episode, transcribes it and runs the
+// transcript through a preset or pattern, as one job
+func (a *App) ProcessPodcast(req PodcastRequest) (*PodcastResult, error) {
+	req.URL = strings.TrimSpace(req.URL)
+	if req.URL == "" {
+		return nil, fmt.Errorf("a feed or episode URL is required")
+	}
+	if req.Preset == "" && req.Pattern == "" {
+		return nil, fmt.Errorf("a preset or a pattern is required")
+	}
+	prefs, _ := a.loadPreferences()
+	provider, endpoint, err := whisperEndpoint(prefs)
+	if err != nil {
+		return nil, err
+	}
+
+	j, ctx := a.startJob("podcast", "Podcast: "+req.URL)
+	result, err := a.processPodcast(ctx, j, req, prefs, provider, endpoint)
+	if err == nil {
+		a.setJobResult(j, result.EntryID)
+	}
+	a.finishJob(j, err)
+	if err != nil {
+		a.emit("podcast:progress", PodcastProgress{JobID: j.ID, Stage: "error", Progress: -1, Message: "Failed", Error: toChatError(err).Message})
+		return nil, err
+	}
+	a.emit("podcast:progress", PodcastProgress{JobID: j.ID, Stage: "complete", Progress: 100, Message: result.Title})
+	return result, nil
+}
+
+// processPodcast runs the stages of ProcessPodcast, reporting each
+func (a *App) processPodcast(ctx context.Context, j *job, req PodcastRequest, prefs *Preferences, provider, endpoint string) (*PodcastResult, error) {
+	stage := func(name string, progress float64, message string) {
+		a.updateJob(j, progress, message)
+		a.emit("podcast:progress", PodcastProgress{JobID: j.ID, Stage: name, Progress: progress, Message: message})
+	}
+
+	stage("resolving", -1, "Finding the episode")
+	episode, err := a.resolvePodcastEpisode(ctx, req.URL, req.Episode)
+	if err != nil {
+		return nil, err
+	}
+	result := &PodcastResult{Show: episode.show, Title: episode.title, AudioURL: episode.audio}
+	a.log.Info("processing podcast episode", "title", episode.title, "audio", episode.audio)
+
+	stage("downloading", 0, "Downloading "+episode.title)
+	audioPath, err := a.downloadPodcastAudio(ctx, episode.audio, func(percent float64) {
+		stage("downloading", percent, "Downloading "+episode.title)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(audioPath)
+
+	info, err := os.Stat(audioPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audio file: %v", err)
+	}
+	if provider == "openai" && info.Size() > openAIMaxAudioSize {
+		stage("compressing", -1, "Compressing audio for upload")
+		compressed, err := compressAudio(ctx, audioPath)
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(compressed)
+		if info, err = os.Stat(compressed); err != nil {
+			return nil, fmt.Errorf("failed to read audio file: %v", err)
+		}
+		if info.Size() > openAIMaxAudioSize {
+			return nil, fmt.Errorf("episode is %d MB even compressed, OpenAI accepts at most 25 MB", info.Size()/(1024*1024))
+		}
+		audioPath = compressed
+	}
+
+	stage("transcribing", -1, "Transcribing "+episode.title)
+	result.Transcript, err = a.uploadAudio(ctx, j, endpoint, provider, prefs, audioPath, info.Size())
+	if err != nil {
+		return nil, err
+	}
+	if result.Transcript == "" {
+		return nil, fmt.Errorf("the episode's transcript is empty")
+	}
+
+	stage("running", -1, "Running the transcript")
+	prompt, err := a.automatedPrompt(req.Preset, req.Pattern, req.Vendor, req.Model, result.Transcript)
+	if err != nil {
+		return nil, err
+	}
+	result.EntryID, result.Output, err = a.runAutomated(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// resolvePodcastEpisode finds the audio of an episode. The URL may be the
+// audio itself, a feed, where the episode is picked by its guid, link or
+// title, or an episode page with an audio player.
+func (a *App) resolvePodcastEpisode(ctx context.Context, rawURL, episode string) (*podcastEpisode, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("not a web address: %s", rawURL)
+	}
+	if isAudioType("", rawURL) {
+		return &podcastEpisode{title: path.Base(u.Path), audio: rawURL}, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %v", err)
+	}
+	req.Header.Set("User-Agent", "FabricGUI/"+appVersion)
+	resp, err := a.externalClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %v", u.Host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("%s returned status %d", u.Host, resp.StatusCode)
+	}
+	if isAudioType(resp.Header.Get("Content-Type"), "") {
+		return &podcastEpisode{title: path.Base(u.Path), audio: rawURL}, nil
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", u.Host, err)
+	}
+
+	if feed, err := parseFeed(data); err == nil {
+		return pickEpisode(feed, episode)
+	}
+
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", u.Host, err)
+	}
+	found := &podcastEpisode{}
+	pageAudio(doc, found)
+	if found.audio == "" {
+		return nil, fmt.Errorf("no podcast feed or episode audio found at %s", rawURL)
+	}
+	audioURL, err := resp.Request.URL.Parse(found.audio)
+	if err != nil {
+		return nil, fmt.Errorf("invalid episode audio URL: %v", err)
+	}
+	found.audio = audioURL.String()
+	if found.title == "" {
+		found.title = path.Base(audioURL.Path)
+	}
+	return found, nil
+}
+
+// pickEpisode returns the feed's episode matching the guid, link or part of
+// the title, or its latest when none is asked for
+func pickEpisode(feed *Feed, episode string) (*podcastEpisode, error) {
+	episode = strings.TrimSpace(episode)
+	for _, item := range feed.Items {
+		if item.Audio == "" {
+			continue
+		}
+		if episode == "" || item.ID == episode || item.Link == episode ||
+			strings.Contains(strings.ToLower(item.Title), strings.ToLower(episode)) {
+			return &podcastEpisode{show: feed.Title, title: item.Title, audio: item.Audio}, nil
+		}
+	}
+	if episode != "" {
+		return nil, fmt.Errorf("no episode of %s matches %q", feed.Title, episode)
+	}
+	return nil, fmt.Errorf("%s has no episodes with audio", feed.Title)
+}
+
+// pageAudio finds an episode page's audio, from its og:audio tag or its
+// audio player, and its title
+func pageAudio(n *html.Node, found *podcastEpisode) {
+	if n.Type == html.ElementNode {
+		attr := func(key string) string {
+			for _, a := range n.Attr {
+				if a.Key == key {
+					return strings.TrimSpace(a.Val)
+				}
+			}
+			return ""
+		}
+		switch n.DataAtom {
+		case atom.Meta:
+			switch attr("property") {
+			case "og:audio", "og:audio:url", "og:audio:secure_url":
+				// The page's own tag is preferred over a player's source
+				found.audio = attr("content")
+			case "og:title":
+				found.title = attr("content")
+			}
+		case atom.Audio, atom.Source:
+			if src := attr("src"); src != "" && found.audio == "" {
+				found.audio = src
+			}
+		case atom.Title:
+			if found.title == "" && n.FirstChild != nil {
+				found.title = strings.TrimSpace(n.FirstChild.Data)
+			}
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		pageAudio(c, found)
+	}
+}
+
+// downloadPodcastAudio saves an episode's audio to a temporary file,
+// reporting the percent downloaded when the size is known
+func (a *App) downloadPodcastAudio(ctx context.Context, audioURL string, report func(percent float64)) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", audioURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid audio URL: %v", err)
+	}
+	req.Header.Set("User-Agent", "FabricGUI/"+appVersion)
+	resp, err := a.externalClient().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download episode: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("episode download returned status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > maxPodcastSize {
+		return "", fmt.Errorf("episode is %d MB, larger than the %d MB limit", resp.ContentLength/(1024*1024), maxPodcastSize/(1024*1024))
+	}
+
+	ext := strings.ToLower(path.Ext(resp.Request.URL.Path))
+	if !audioExtensions[ext] {
+		ext = ".mp3"
+		if exts, _ := mime.ExtensionsByType(resp.Header.Get("Content-Type")); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+	f, err := os.CreateTemp("", "fabric_gui_podcast_*"+ext)
+	if err != nil {
+		return "", fmt.Errorf("failed to create audio file: %v", err)
+	}
+	defer f.Close()
+
+	// Only report when the whole percentage changes to avoid flooding the frontend
+	lastPercent := -1
+	body := &progressReader{r: io.LimitReader(resp.Body, maxPodcastSize+1), report: func(read int64) {
+		if resp.ContentLength <= 0 {
+			return
+		}
+		percent := float64(read) / float64(resp.ContentLength) * 100
+		if int(percent) == lastPercent {
+			return
+		}
+		lastPercent = int(percent)
+		report(percent)
+	}}
+	n, err := io.Copy(f, body)
+	if err == nil && n > maxPodcastSize {
+		err = fmt.Errorf("episode is larger than the %d MB limit", maxPodcastSize/(1024*1024))
+	} else if err != nil {
+		err = fmt.Errorf("failed to download episode: %v", err)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// compressAudio re-encodes audio with ffmpeg as low bitrate mono, which
+// is all speech needs, so long episodes fit OpenAI's upload limit
+func compressAudio(ctx context.Context, in string) (string, error) {
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return "", fmt.Errorf("the episode is over 25 MB and ffmpeg, needed to compress it, is not in PATH")
+	}
+	out := strings.TrimSuffix(in, filepath.Ext(in)) + "_compressed.mp3"
+	cmd := exec.CommandContext(ctx, ffmpeg, "-y", "-i", in, "-vn", "-ac", "1", "-ar", "16000", "-b:a", "24k", out)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(out)
+		lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+		return "", fmt.Errorf("failed to compress audio: %v: %s", err, lines[len(lines)-1])
+	}
+	return out, nil
+}
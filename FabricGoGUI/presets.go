@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Preset is a saved combination of pattern, model and parameters
+type Preset struct {
+	Name          string      `json:"name"`
+	Pattern       string      `json:"pattern"`
+	Vendor        string      `json:"vendor"`
+	Model         string      `json:"model"`
+	Params        ChatOptions `json:"params"`
+	InputTemplate string      `json:"inputTemplate"` // {input} and {date} are filled in when run
+}
+
+// presetsPath returns where presets are stored
+func (a *App) presetsPath() (string, error) {
+	dir := a.getConfigDir()
+	if dir == "" {
+		return "", fmt.Errorf("could not determine config directory")
+	}
+	return filepath.Join(dir, "presets.json"), nil
+}
+
+// ListPresets returns the saved presets, sorted by name
+func (a *App) ListPresets() ([]Preset, error) {
+	path, err := a.presetsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return []Preset{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read presets: %v", err)
+	}
+
+	var presets []Preset
+	if err := json.Unmarshal(data, &presets); err != nil {
+		return nil, fmt.Errorf("failed to parse presets: %v", err)
+	}
+	sort.Slice(presets, func(i, k int) bool {
+		return strings.ToLower(presets[i].Name) < strings.ToLower(presets[k].Name)
+	})
+	return presets, nil
+}
+
+// GetPreset returns the preset with the given name
+func (a *App) GetPreset(name string) (*Preset, error) {
+	presets, err := a.ListPresets()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range presets {
+		if p.Name == name {
+			return &p, nil
+		}
+	}
+	return nil, fmt.Errorf("preset %q not found", name)
+}
+
+// SavePreset creates a preset, or replaces the one with the same name
+func (a *App) SavePreset(preset Preset) error {
+	preset.Name = strings.TrimSpace(preset.Name)
+	if preset.Name == "" {
+		return fmt.Errorf("a preset name is required")
+	}
+	if preset.Pattern == "" {
+		return fmt.Errorf("a pattern is required")
+	}
+
+	presets, err := a.ListPresets()
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i := range presets {
+		if presets[i].Name == preset.Name {
+			presets[i] = preset
+			replaced = true
+		}
+	}
+	if !replaced {
+		presets = append(presets, preset)
+	}
+	return a.writePresets(presets)
+}
+
+// DeletePreset removes the preset with the given name
+func (a *App) DeletePreset(name string) error {
+	presets, err := a.ListPresets()
+	if err != nil {
+		return err
+	}
+
+	kept := make([]Preset, 0, len(presets))
+	for _, p := range presets {
+		if p.Name != name {
+			kept = append(kept, p)
+		}
+	}
+	if len(kept) == len(presets) {
+		return fmt.Errorf("preset %q not found", name)
+	}
+	return a.writePresets(kept)
+}
+
+// RunPreset starts a chat with a preset's pattern, model and parameters and
+// returns the stream ID
+func (a *App) RunPreset(name, input string) (string, error) {
+	preset, err := a.GetPreset(name)
+	if err != nil {
+		return "", err
+	}
+
+	vendor := preset.Vendor
+	if vendor == "" {
+		if vendor, err = a.vendorForModel(preset.Model); err != nil {
+			return "", err
+		}
+	}
+
+	prompt := a.newPrompt(preset.Pattern, vendor, preset.Model, expandPresetInput(preset.InputTemplate, input))
+	prompt.Options = preset.Params
+
+	streamID, job, ctx := a.openStream(preset.Name)
+	go a.runStream(streamID, job, ctx, chatRun{Prompt: prompt})
+	return streamID, nil
+}
+
+// expandPresetInput fills the input into a preset's template. Without a
+// template, or a template lacking {input}, the input is used as is or appended.
+func expandPresetInput(template, input string) string {
+	if strings.TrimSpace(template) == "" {
+		return input
+	}
+	if !strings.Contains(template, "{input}") {
+		template += "\n\n{input}"
+	}
+	return strings.NewReplacer(
+		"{input}", input,
+		"{date}", time.Now().Format("2006-01-02"),
+	).Replace(template)
+}
+
+// writePresets saves the presets to disk
+func (a *App) writePresets(presets []Preset) error {
+	path, err := a.presetsPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(presets, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to save presets: %v", err)
+	}
+	return nil
+}
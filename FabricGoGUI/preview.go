@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+)
+
+// PromptPreview is the prompt a chat request would send, assembled without
+// calling a model
+type PromptPreview struct {
+	Pattern          string   `json:"pattern"`
+	Source           string   `json:"source"` // fabric or custom
+	System           string   `json:"system"`
+	User             string   `json:"user"`
+	MissingVariables []string `json:"missingVariables,omitempty"` // placeholders left unfilled
+	EstimatedTokens  int      `json:"estimatedTokens"`
+}
+
+// PreviewPrompt assembles the system and user messages for a pattern, input
+// and variables the way they will be sent, so they can be checked before
+// spending tokens. Fabric's own patterns become the system message; custom
+// patterns are expanded into the user message as streamChat does.
+func (a *App) PreviewPrompt(pattern, input string, variables map[string]string) (*PromptPreview, error) {
+	preview := &PromptPreview{Pattern: pattern, Source: PatternSourceFabric, User: input}
+
+	if pattern != "" {
+		if _, ok := a.findCustomPattern(pattern); ok {
+			resolved := a.resolveCustomPattern(PromptRequest{PatternName: pattern, UserInput: input, Variables: variables})
+			preview.Source = PatternSourceCustom
+			preview.User = resolved.UserInput
+		} else {
+			p, err := a.GetPattern(pattern)
+			if err != nil {
+				return nil, err
+			}
+			system := substituteVariables(p.System, variables)
+			// Fabric puts the input into the pattern when it asks for it
+			if strings.Contains(system, "{{input}}") {
+				system = strings.ReplaceAll(system, "{{input}}", input)
+				preview.User = ""
+			}
+			preview.System = system
+		}
+	}
+
+	preview.MissingVariables = findPatternVariables(preview.System + "\n" + preview.User)
+	preview.EstimatedTokens = estimateTokens(preview.System) + estimateTokens(preview.User)
+	return preview, nil
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultProfileName is used for the implicit profile when none are saved
+const defaultProfileName = "Local"
+
+// ConnectionProfile holds everything needed to reach one Fabric server
+type ConnectionProfile struct {
+	Name     string         `json:"name"`
+	BaseURL  string         `json:"baseUrl"`
+	APIKey   string         `json:"apiKey,omitempty"` // sent as X-API-Key, for servers started with --api-key
+	TLS      TLSOptions     `json:"tls"`
+	Timeouts TimeoutOptions `json:"timeouts"`
+}
+
+// profileStore is the on-disk format of profiles.json
+type profileStore struct {
+	Active   string              `json:"active"`
+	Profiles []ConnectionProfile `json:"profiles"`
+}
+
+// ProfileSwitch is returned and emitted as "profile:switched" after changing profiles
+type ProfileSwitch struct {
+	Profile  ConnectionProfile `json:"profile"`
+	Online   bool              `json:"online"`
+	Patterns []PatternInfo     `json:"patterns"`
+	Models   *ModelsResponse   `json:"models"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// profilesPath returns where connection profiles are stored
+func (a *App) profilesPath() (string, error) {
+	dir := a.getConfigDir()
+	if dir == "" {
+		return "", fmt.Errorf("could not determine config directory")
+	}
+	return filepath.Join(dir, "profiles.json"), nil
+}
+
+// loadProfiles reads the saved profiles. Without any, a single profile for
+// the current base URL is returned so there is always one to switch back to.
+func (a *App) loadProfiles() (*profileStore, error) {
+	path, err := a.profilesPath()
+	if err != nil {
+		return nil, err
+	}
+
+	store := &profileStore{}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read profiles: %v", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, store); err != nil {
+			return nil, fmt.Errorf("failed to parse profiles: %v", err)
+		}
+	}
+
+	if len(store.Profiles) == 0 {
+		store.Profiles = []ConnectionProfile{{Name: defaultProfileName, BaseURL: a.GetBaseURL()}}
+		store.Active = defaultProfileName
+	}
+	return store, nil
+}
+
+// saveProfiles writes the profiles to disk. The file holds API keys, so it is
+// only readable by the user.
+func (a *App) saveProfiles(store *profileStore) error {
+	path, err := a.profilesPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to save profiles: %v", err)
+	}
+	a.refreshRedactions()
+	return nil
+}
+
+// find returns the index of the profile with the given name, or -1
+func (s *profileStore) find(name string) int {
+	for i, p := range s.Profiles {
+		if p.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// ListProfiles returns the saved connection profiles
+func (a *App) ListProfiles() ([]ConnectionProfile, error) {
+	store, err := a.loadProfiles()
+	if err != nil {
+		return nil, err
+	}
+	return store.Profiles, nil
+}
+
+// GetActiveProfile returns the name of the profile in use
+func (a *App) GetActiveProfile() (string, error) {
+	store, err := a.loadProfiles()
+	if err != nil {
+		return "", err
+	}
+	return store.Active, nil
+}
+
+// SaveProfile creates a profile, or replaces the one with the same name. When
+// the active profile is changed its settings take effect immediately.
+func (a *App) SaveProfile(profile ConnectionProfile) error {
+	profile.Name = strings.TrimSpace(profile.Name)
+	profile.BaseURL = strings.TrimSuffix(strings.TrimSpace(profile.BaseURL), "/")
+	if profile.Name == "" {
+		return fmt.Errorf("a profile name is required")
+	}
+	if profile.BaseURL == "" {
+		return fmt.Errorf("a server URL is required")
+	}
+	if _, err := profile.TLS.config(); err != nil {
+		return err
+	}
+
+	store, err := a.loadProfiles()
+	if err != nil {
+		return err
+	}
+	if i := store.find(profile.Name); i >= 0 {
+		store.Profiles[i] = profile
+	} else {
+		store.Profiles = append(store.Profiles, profile)
+	}
+	if err := a.saveProfiles(store); err != nil {
+		return err
+	}
+
+	if profile.Name == store.Active {
+		return a.applyProfile(profile)
+	}
+	return nil
+}
+
+// DeleteProfile removes a profile. The active profile cannot be deleted.
+func (a *App) DeleteProfile(name string) error {
+	store, err := a.loadProfiles()
+	if err != nil {
+		return err
+	}
+
+	i := store.find(name)
+	if i < 0 {
+		return fmt.Errorf("profile %q not found", name)
+	}
+	if name == store.Active {
+		return fmt.Errorf("cannot delete the active profile, switch to another one first")
+	}
+
+	store.Profiles = append(store.Profiles[:i], store.Profiles[i+1:]...)
+	return a.saveProfiles(store)
+}
+
+// SwitchProfile makes a profile active, rebuilds the HTTP client for it and
+// reloads patterns and models from the new server
+func (a *App) SwitchProfile(name string) (*ProfileSwitch, error) {
+	store, err := a.loadProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	i := store.find(name)
+	if i < 0 {
+		return nil, fmt.Errorf("profile %q not found", name)
+	}
+	profile := store.Profiles[i]
+	prefs, _ := a.loadPreferences()
+	if _, err := newHTTPClient(profile, prefs); err != nil {
+		return nil, err
+	}
+
+	store.Active = name
+	if err := a.saveProfiles(store); err != nil {
+		return nil, err
+	}
+	if err := a.applyProfile(profile); err != nil {
+		return nil, err
+	}
+
+	result := &ProfileSwitch{Profile: profile, Online: a.CheckHealth()}
+	if result.Online {
+		if result.Patterns, err = a.GetPatterns(); err != nil {
+			result.Error = err.Error()
+		} else if result.Models, err = a.GetModels(); err != nil {
+			result.Error = err.Error()
+		}
+	}
+
+	a.emit("profile:switched", result)
+	return result, nil
+}
+
+// applyProfile points the app at a profile's server. The base URL is also
+// stored in preferences, which is where it is restored from on startup.
+func (a *App) applyProfile(profile ConnectionProfile) error {
+	prefs, _ := a.loadPreferences()
+	if err := a.useProfile(profile, prefs); err != nil {
+		return err
+	}
+	a.warnInsecure(profile)
+	a.patternIndex.invalidate()
+
+	if prefs.BaseURL == profile.BaseURL {
+		a.SetBaseURL(profile.BaseURL)
+		return nil
+	}
+	prefs.BaseURL = profile.BaseURL
+	return a.SavePreferences(*prefs)
+}
+
+// restoreProfile sets up the HTTP client for the active profile on startup
+func (a *App) restoreProfile(prefs *Preferences) {
+	store, err := a.loadProfiles()
+	if err != nil {
+		a.log.Warn("failed to load connection profiles", "error", err)
+		a.emit("debug:log", err.Error())
+		return
+	}
+	i := store.find(store.Active)
+	if i < 0 {
+		return
+	}
+
+	if err := a.useProfile(store.Profiles[i], prefs); err != nil {
+		a.log.Warn("failed to apply connection profile", "profile", store.Active, "error", err)
+		a.emit("debug:log", err.Error())
+		return
+	}
+	a.warnInsecure(store.Profiles[i])
+}
+
+// useProfile builds the HTTP clients and stream settings for a profile
+func (a *App) useProfile(profile ConnectionProfile, prefs *Preferences) error {
+	client, err := newHTTPClient(profile, prefs)
+	if err != nil {
+		return err
+	}
+	external, err := newExternalClient(prefs)
+	if err != nil {
+		return err
+	}
+	a.connMutex.Lock()
+	a.client = client
+	a.external = external
+	a.streamIdleTimeout = profile.Timeouts.streamIdle()
+	a.connMutex.Unlock()
+	return nil
+}
+
+// warnInsecure tells the frontend when certificate checks are switched off
+func (a *App) warnInsecure(profile ConnectionProfile) {
+	if profile.TLS.InsecureSkipVerify {
+		a.log.Warn("certificate verification disabled", "profile", profile.Name)
+		a.emit("profile:insecure", profile.Name)
+	}
+}
+
+// syncActiveProfile applies saved preferences to the connection: the active
+// profile follows edits to the base URL in the settings, and the HTTP client
+// is rebuilt so proxy changes take effect
+func (a *App) syncActiveProfile(prefs *Preferences) error {
+	store, err := a.loadProfiles()
+	if err != nil {
+		return err
+	}
+	i := store.find(store.Active)
+	if i < 0 {
+		return nil
+	}
+
+	if prefs.BaseURL != "" && store.Profiles[i].BaseURL != prefs.BaseURL {
+		store.Profiles[i].BaseURL = prefs.BaseURL
+		if err := a.saveProfiles(store); err != nil {
+			return err
+		}
+	}
+
+	return a.useProfile(store.Profiles[i], prefs)
+}
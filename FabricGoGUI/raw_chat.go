@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rawChatTitle names streams and jobs of chats sent without a pattern
+const rawChatTitle = "chat"
+
+// How an ad-hoc system prompt combines with the selected pattern
+const (
+	SystemPromptReplace = "replace" // use it instead of the pattern
+	SystemPromptPrepend = "prepend" // put it before the pattern
+)
+
+// chatTitle is the stream title for a chat request with pattern
+func chatTitle(pattern string) string {
+	if pattern == "" {
+		return rawChatTitle
+	}
+	return pattern
+}
+
+// resolveSystemPrompt expands an ad-hoc system prompt into the prompt itself.
+// Without a pattern it is the whole system prompt; with one it overrides the
+// pattern's for this run only, replacing it or going before it. With no
+// system prompt the request is left to the pattern, or sent as it is.
+func (a *App) resolveSystemPrompt(prompt PromptRequest) (PromptRequest, error) {
+	if prompt.SystemPrompt == "" {
+		return prompt, nil
+	}
+
+	system := prompt.SystemPrompt
+	if prompt.PatternName != "" {
+		switch prompt.SystemMode {
+		case "", SystemPromptReplace:
+		case SystemPromptPrepend:
+			p, err := a.GetPattern(prompt.PatternName)
+			if err != nil {
+				return prompt, err
+			}
+			system = strings.TrimRight(system, "\n") + "\n\n" + p.System
+		default:
+			return prompt, fmt.Errorf("unknown system prompt mode %q", prompt.SystemMode)
+		}
+	}
+
+	prompt = inlineSystemPrompt(prompt, system)
+	prompt.SystemPrompt = ""
+	prompt.SystemMode = ""
+	return prompt, nil
+}
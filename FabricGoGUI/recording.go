@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"time"
+)
+
+// recording tracks an in-progress microphone capture
+type recording struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	path    string
+	started time.Time
+}
+
+// StartRecording starts capturing microphone audio with ffmpeg
+func (a *App) StartRecording() error {
+	a.recordingMutex.Lock()
+	defer a.recordingMutex.Unlock()
+
+	if a.recording != nil {
+		return fmt.Errorf("already recording")
+	}
+
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return fmt.Errorf("ffmpeg not found in PATH: %v", err)
+	}
+
+	prefs, _ := a.loadPreferences()
+	input, err := recordingInputArgs(prefs.RecordingDevice)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(os.TempDir(), fmt.Sprintf("fabric_gui_recording_%d.wav", time.Now().UnixNano()))
+
+	// 16 kHz mono is what Whisper works with internally, so keep uploads small
+	args := append(input, "-ac", "1", "-ar", "16000", "-y", path)
+	cmd := exec.Command(ffmpeg, args...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to start recording: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start recording: %v", err)
+	}
+
+	a.recording = &recording{cmd: cmd, stdin: stdin, path: path, started: time.Now()}
+
+	a.emit("recording:started", "")
+	return nil
+}
+
+// StopRecording stops the microphone capture, transcribes it and places the text in the input
+func (a *App) StopRecording() (string, error) {
+	a.recordingMutex.Lock()
+	rec := a.recording
+	a.recording = nil
+	a.recordingMutex.Unlock()
+
+	if rec == nil {
+		return "", fmt.Errorf("not recording")
+	}
+	defer os.Remove(rec.path)
+
+	// ffmpeg finalizes the WAV header when asked to quit via stdin
+	io.WriteString(rec.stdin, "q")
+	rec.stdin.Close()
+
+	done := make(chan error, 1)
+	go func() { done <- rec.cmd.Wait() }()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		rec.cmd.Process.Kill()
+		<-done
+	}
+
+	a.emit("recording:stopped", time.Since(rec.started).Milliseconds())
+
+	if info, err := os.Stat(rec.path); err != nil || info.Size() == 0 {
+		return "", fmt.Errorf("no audio was captured")
+	}
+
+	text, err := a.TranscribeAudio(rec.path)
+	if err != nil {
+		return "", err
+	}
+
+	a.emit("input:loaded", InputLoaded{
+		Filename: "Voice note",
+		Content:  text,
+	})
+	return text, nil
+}
+
+// IsRecording reports whether the microphone is being captured
+func (a *App) IsRecording() bool {
+	a.recordingMutex.Lock()
+	defer a.recordingMutex.Unlock()
+	return a.recording != nil
+}
+
+// discardRecording kills an in-progress capture without transcribing it
+func (a *App) discardRecording() {
+	a.recordingMutex.Lock()
+	rec := a.recording
+	a.recording = nil
+	a.recordingMutex.Unlock()
+
+	if rec == nil {
+		return
+	}
+	rec.cmd.Process.Kill()
+	rec.cmd.Wait()
+	os.Remove(rec.path)
+}
+
+// recordingInputArgs returns the ffmpeg input arguments for the platform's audio system
+func recordingInputArgs(device string) ([]string, error) {
+	switch goruntime.GOOS {
+	case "linux":
+		if device == "" {
+			device = "default"
+		}
+		return []string{"-f", "pulse", "-i", device}, nil
+	case "darwin":
+		if device == "" {
+			device = ":0"
+		}
+		return []string{"-f", "avfoundation", "-i", device}, nil
+	case "windows":
+		if device == "" {
+			return nil, fmt.Errorf("set a recording device in preferences (see: ffmpeg -list_devices true -f dshow -i dummy)")
+		}
+		return []string{"-f", "dshow", "-i", "audio=" + device}, nil
+	default:
+		return nil, fmt.Errorf("recording is not supported on %s", goruntime.GOOS)
+	}
+}
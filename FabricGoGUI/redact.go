@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// minRedactedValue is the shortest stored secret that is scrubbed by value;
+// shorter ones would mask ordinary words
+const minRedactedValue = 8
+
+// secretPatterns match credentials that may end up in logs or error messages
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(authorization["']?\s*[:=]\s*["']?(?:(?:basic|bearer)\s+)?)[^\s"'&,\[]+`),
+	regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]+`),
+	regexp.MustCompile(`(?i)((?:api[_-]?key|token|secret|password)["']?\s*[:=]\s*["']?)[^\s"'&,]+`),
+	regexp.MustCompile(`(?i)((?:x-api-key|x-goog-api-key)["']?\s*[:=]\s*["']?)[^\s"'&,]+`),
+	regexp.MustCompile(`(?i)([?&]key=)[^\s"'&]+`),
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{16,}`),
+	regexp.MustCompile(`\bAIza[A-Za-z0-9_-]{30,}`),
+	regexp.MustCompile(`\b(?:ghp|gho|ghs|ghu|github_pat)_[A-Za-z0-9_]{20,}`),
+	regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`),
+	regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`\bgsk_[A-Za-z0-9]{20,}`),
+	regexp.MustCompile(`(?i)(\b[a-z][a-z0-9+.-]*://)[^/\s@]+(@)`), // user and password in a URL
+}
+
+// redactor scrubs credentials from text: the values of the configured
+// secrets, and anything matching secretPatterns
+type redactor struct {
+	mu     sync.RWMutex
+	values []string // longest first, so a secret containing another is masked whole
+}
+
+func newRedactor() *redactor {
+	return &redactor{}
+}
+
+// SetValues replaces the known secret values
+func (r *redactor) SetValues(values []string) {
+	kept := []string{}
+	for _, v := range values {
+		if len(v) >= minRedactedValue {
+			kept = append(kept, v)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool { return len(kept[i]) > len(kept[j]) })
+
+	r.mu.Lock()
+	r.values = kept
+	r.mu.Unlock()
+}
+
+// String masks anything in text that is, or looks like, a credential
+func (r *redactor) String(text string) string {
+	if r != nil {
+		r.mu.RLock()
+		for _, v := range r.values {
+			text = strings.ReplaceAll(text, v, "[redacted]")
+		}
+		r.mu.RUnlock()
+	}
+	for _, re := range secretPatterns {
+		switch re.NumSubexp() {
+		case 2: // the second group follows the secret
+			text = re.ReplaceAllString(text, "${1}[redacted]${2}")
+		case 1:
+			text = re.ReplaceAllString(text, "${1}[redacted]")
+		default:
+			text = re.ReplaceAllString(text, "[redacted]")
+		}
+	}
+	return text
+}
+
+// refreshRedactions loads the current secret values into the redactor: the
+// vendor keys, the credentials in preferences and the profiles' API keys. It
+// is called at startup and whenever any of them change.
+func (a *App) refreshRedactions() {
+	values := []string{}
+	for key, value := range a.vendorEnv() {
+		if isSecretEnvKey(key) {
+			values = append(values, value)
+		}
+	}
+	if prefs, err := a.loadPreferences(); err == nil {
+		values = append(values, prefs.secretValues()...)
+	}
+	if store, err := a.loadProfiles(); err == nil {
+		for _, p := range store.Profiles {
+			values = append(values, p.APIKey)
+		}
+	}
+	a.redact.SetValues(values)
+}
+
+// secretValues returns the credentials kept in preferences. Webhook URLs are
+// included since Slack and Discord put the secret in the path.
+func (p *Preferences) secretValues() []string {
+	values := []string{p.WhisperAPIKey, p.GitHubToken, p.Notion.Token, p.SMTP.Password, p.TTS.APIKey, p.API.Token,
+		p.SlackWebhookURL, p.DiscordWebhookURL}
+	for _, w := range p.Webhooks {
+		values = append(values, w.URL, w.Secret)
+	}
+	for _, e := range p.OpenAIEndpoints {
+		values = append(values, e.APIKey)
+	}
+	if u, err := url.Parse(p.ProxyURL); err == nil && u.User != nil {
+		password, _ := u.User.Password()
+		values = append(values, u.User.Username(), password)
+	}
+	return values
+}
+
+// redactingWriter scrubs secrets from everything written through it. The
+// slog handler writes one record per call, so a secret is never split.
+type redactingWriter struct {
+	w io.Writer
+	r *redactor
+}
+
+func (rw *redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(rw.w, rw.r.String(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// maskedJSON renders v as JSON with the values of secret-looking fields masked
+func maskedJSON(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(maskSecrets(generic), "", "  ")
+}
+
+// maskSecrets walks decoded JSON, masking non-empty fields named like secrets,
+// webhook URLs and the user and password in any URL, such as the proxyUrl
+func maskSecrets(v any) any {
+	return maskSecretsIn("", v)
+}
+
+// maskSecretsIn is maskSecrets for a value found under the field parent
+func maskSecretsIn(parent string, v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		for key, field := range value {
+			lower := strings.ToLower(key)
+			secret := (strings.Contains(lower, "key") || strings.Contains(lower, "token") ||
+				strings.Contains(lower, "secret") || strings.Contains(lower, "password")) &&
+				!strings.HasSuffix(lower, "file") // paths such as a TLS keyFile are not secret
+			secret = secret || strings.HasSuffix(lower, "webhookurl") || (parent == "webhooks" && lower == "url")
+			if s, ok := field.(string); ok && s != "" {
+				if secret {
+					value[key] = "****"
+				} else {
+					value[key] = maskURLUser(s)
+				}
+			} else {
+				value[key] = maskSecretsIn(lower, field)
+			}
+		}
+	case []any:
+		for i := range value {
+			value[i] = maskSecretsIn(parent, value[i])
+		}
+	}
+	return v
+}
+
+// maskURLUser masks the user and password of a URL, returning anything
+// else as it is
+func maskURLUser(s string) string {
+	if !strings.Contains(s, "://") {
+		return s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	u.User = nil
+	scheme, rest, _ := strings.Cut(u.String(), "://")
+	return scheme + "://****@" + rest
+}
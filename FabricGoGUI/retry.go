@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultChatRetries is how many times a transient failure is retried
+	defaultChatRetries = 3
+	// defaultRetryDelay is the wait before the first retry, doubled for each attempt
+	defaultRetryDelay = time.Second
+	// maxRetryDelay caps the exponential backoff
+	maxRetryDelay = 30 * time.Second
+)
+
+// ChatRetry is emitted as "chat:retry" before a failed request is re-sent
+type ChatRetry struct {
+	StreamID    string `json:"streamId"`
+	Attempt     int    `json:"attempt"`
+	MaxAttempts int    `json:"maxAttempts"`
+	DelayMs     int64  `json:"delayMs"`
+	Error       string `json:"error"`
+	Code        string `json:"code"`
+}
+
+// retryPolicy controls how transient chat failures are retried
+type retryPolicy struct {
+	retries   int
+	baseDelay time.Duration
+}
+
+// chatRetryPolicy reads the retry settings from preferences
+func (a *App) chatRetryPolicy() retryPolicy {
+	prefs, _ := a.loadPreferences()
+
+	policy := retryPolicy{retries: defaultChatRetries, baseDelay: defaultRetryDelay}
+	if prefs.DisableChatRetry {
+		policy.retries = 0
+	} else if prefs.ChatMaxRetries > 0 {
+		policy.retries = prefs.ChatMaxRetries
+	}
+	if prefs.ChatRetryDelayMs > 0 {
+		policy.baseDelay = time.Duration(prefs.ChatRetryDelayMs) * time.Millisecond
+	}
+	return policy
+}
+
+// delay returns the backoff before the given retry (1-based), preferring the
+// server's Retry-After hint when there is one
+func (p retryPolicy) delay(attempt int, err *chatError) time.Duration {
+	if err.RetryAfter > 0 {
+		return min(err.RetryAfter, maxRetryDelay)
+	}
+	d := time.Duration(float64(p.baseDelay) * math.Pow(2, float64(attempt-1)))
+	return min(d, maxRetryDelay)
+}
+
+// streamChatWithRetry calls streamChat, retrying connection resets, 429s and
+// 5xx responses with exponential backoff. A request is only retried if no
+// output has been streamed yet, so chunks are never delivered twice; partial
+// output is returned with the error instead.
+func (a *App) streamChatWithRetry(ctx context.Context, prompt PromptRequest, onChunk func(string), onUsage func(streamUsage), onRetry func(ChatRetry)) (string, error) {
+	policy := a.chatRetryPolicy()
+
+	for attempt := 0; ; attempt++ {
+		received := false
+		output, err := a.streamChat(ctx, prompt, func(chunk string) {
+			received = true
+			onChunk(chunk)
+		}, onUsage)
+		if err == nil {
+			return output, nil
+		}
+
+		ce := toChatError(err)
+		if !ce.Retryable || received || attempt >= policy.retries || ctx.Err() != nil {
+			return output, err
+		}
+
+		delay := policy.delay(attempt+1, ce)
+		onRetry(ChatRetry{
+			Attempt:     attempt + 1,
+			MaxAttempts: policy.retries,
+			DelayMs:     delay.Milliseconds(),
+			Error:       ce.Message,
+			Code:        ce.Code,
+		})
+
+		select {
+		case <-ctx.Done():
+			return "", newNetworkChatError(ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
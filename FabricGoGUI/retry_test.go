@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// retryTestApp returns an app using mock, with retries that do not wait long
+func retryTestApp(t *testing.T, mock *mockFabricClient, retries int) *App {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	a := NewApp()
+	a.headless = true
+	a.fabric = mock
+	if err := a.SavePreferences(Preferences{ChatMaxRetries: retries, ChatRetryDelayMs: 1}); err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestStreamChatRetriesTransientErrors(t *testing.T) {
+	mock := &mockFabricClient{
+		Chunks:    []string{"done"},
+		FailFirst: []error{newHTTPChatError(503, "unavailable"), newNetworkChatError(errors.New("connection reset"))},
+	}
+	a := retryTestApp(t, mock, 3)
+
+	var retries []ChatRetry
+	output, err := a.streamChatWithRetry(context.Background(), PromptRequest{}, func(string) {}, func(streamUsage) {},
+		func(r ChatRetry) { retries = append(retries, r) })
+	if err != nil || output != "done" {
+		t.Fatalf("got %q, %v", output, err)
+	}
+	if len(retries) != 2 || retries[0].Attempt != 1 || retries[1].Attempt != 2 || retries[1].MaxAttempts != 3 {
+		t.Errorf("retries = %+v", retries)
+	}
+	if len(mock.Prompts()) != 3 {
+		t.Errorf("sent %d requests, want 3", len(mock.Prompts()))
+	}
+}
+
+func TestStreamChatGivesUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		mock     *mockFabricClient
+		requests int
+	}{
+		{"not retryable", &mockFabricClient{FailFirst: []error{newHTTPChatError(401, "unauthorized")}}, 1},
+		{"out of retries", &mockFabricClient{ChatErr: newHTTPChatError(429, "slow down")}, 3},
+		{"output already streamed", &mockFabricClient{Chunks: []string{"partial"}, ChatErr: &chatError{Code: ChatErrInterrupted, Retryable: true}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := retryTestApp(t, tt.mock, 2)
+			_, err := a.streamChatWithRetry(context.Background(), PromptRequest{}, func(string) {}, func(streamUsage) {}, func(ChatRetry) {})
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := len(tt.mock.Prompts()); got != tt.requests {
+				t.Errorf("sent %d requests, want %d", got, tt.requests)
+			}
+		})
+	}
+}
+
+func TestStreamChatStopsWhenCancelled(t *testing.T) {
+	mock := &mockFabricClient{ChatErr: newHTTPChatError(503, "unavailable")}
+	a := retryTestApp(t, mock, 5)
+	if err := a.SavePreferences(Preferences{ChatMaxRetries: 5, ChatRetryDelayMs: 10000}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	start := time.Now()
+	_, err := a.streamChatWithRetry(ctx, PromptRequest{}, func(string) {}, func(streamUsage) {}, func(ChatRetry) { cancel() })
+	if ce := toChatError(err); ce.Code != ChatErrCancelled {
+		t.Errorf("got %+v, want cancelled", ce)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("waited out the retry delay after cancelling")
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := retryPolicy{retries: 5, baseDelay: time.Second}
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 10: maxRetryDelay} {
+		if got := p.delay(attempt, &chatError{}); got != want {
+			t.Errorf("delay(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+	if got := p.delay(1, &chatError{RetryAfter: 5 * time.Second}); got != 5*time.Second {
+		t.Errorf("Retry-After delay = %s", got)
+	}
+	if got := p.delay(1, &chatError{RetryAfter: time.Hour}); got != maxRetryDelay {
+		t.Errorf("long Retry-After delay = %s, want it capped", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	header := http.Header{}
+	if got := parseRetryAfter(header); got != 0 {
+		t.Errorf("no header = %s", got)
+	}
+	header.Set("Retry-After", "12")
+	if got := parseRetryAfter(header); got != 12*time.Second {
+		t.Errorf("seconds = %s", got)
+	}
+	header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	if got := parseRetryAfter(header); got < 58*time.Second || got > time.Minute {
+		t.Errorf("date = %s", got)
+	}
+	header.Set("Retry-After", "soon")
+	if got := parseRetryAfter(header); got != 0 {
+		t.Errorf("invalid = %s", got)
+	}
+}
+
+func TestToChatError(t *testing.T) {
+	tests := []struct {
+		err       error
+		code      string
+		retryable bool
+	}{
+		{&chatError{Code: ChatErrParse}, ChatErrParse, false},
+		{&net.OpError{Op: "dial", Err: errors.New("refused")}, ChatErrNetwork, true},
+		{context.Canceled, ChatErrCancelled, false},
+		{errors.New("Incorrect API key provided"), ChatErrAuth, false},
+		{errors.New("model is overloaded"), ChatErrRateLimit, true},
+		{errors.New("unexpected EOF"), ChatErrNetwork, true},
+		{errors.New("something else"), ChatErrServer, false},
+	}
+	for _, tt := range tests {
+		ce := toChatError(tt.err)
+		if ce.Code != tt.code || ce.Retryable != tt.retryable {
+			t.Errorf("toChatError(%v) = %q retryable %v, want %q %v", tt.err, ce.Code, ce.Retryable, tt.code, tt.retryable)
+		}
+	}
+}
+
+func TestNewHTTPChatErrorKeepsVendorCode(t *testing.T) {
+	// A vendor rate limit wrapped in a 500 stays a retryable rate limit
+	ce := newHTTPChatError(500, "upstream: 429 Too Many Requests")
+	if ce.Code != ChatErrRateLimit || !ce.Retryable || ce.Status != 500 {
+		t.Errorf("got %+v", ce)
+	}
+}
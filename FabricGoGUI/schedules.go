@@ -0,0 +1,630 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Schedule is a pattern run started on a cron schedule while the app is
+// open, including when it is closed to the tray. Every run is saved to
+// history, and to OutputDir when set.
+type Schedule struct {
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	Enabled        bool           `json:"enabled"`
+	Cron           string         `json:"cron"` // "minute hour day month weekday", or a macro such as @daily
+	Source         ScheduleSource `json:"source"`
+	Preset         string         `json:"preset"` // runs the preset, otherwise the pattern with the model; digests use the feeds' own
+	Pattern        string         `json:"pattern"`
+	Vendor         string         `json:"vendor"`
+	Model          string         `json:"model"`          // defaults to the pattern's model
+	OutputDir      string         `json:"outputDir"`      // outputs are also written here when set
+	OutputTemplate string         `json:"outputTemplate"` // file names, as the batch template; defaults to defaultScheduleTemplate
+	Notify         bool           `json:"notify"`         // announce finished runs as the notification settings allow
+}
+
+// ScheduleSource is where a scheduled run's input comes from. Feeds and
+// folders only run what is new since the last run, one run per item.
+type ScheduleSource struct {
+	Kind string `json:"kind"` // text, url, feed, folder, or digest for the subscribed feeds' digest
+	Text string `json:"text"` // text, or for the other kinds instructions put before the input
+	URL  string `json:"url"`  // page or feed
+	Dir  string `json:"dir"`  // folder
+	Glob string `json:"glob"` // files of the folder to run, defaults to every file
+}
+
+// ScheduleStatus is a schedule with when it runs next and how it ran last
+type ScheduleStatus struct {
+	Schedule
+	NextRun int64        `json:"nextRun,omitempty"` // Unix time, unset when disabled
+	LastRun *ScheduleRun `json:"lastRun,omitempty"`
+	Running bool         `json:"running"`
+	Error   string       `json:"error,omitempty"` // why the schedule cannot run
+}
+
+// ScheduleRun is the log of one run of a schedule, emitted as
+// "schedule:finished"
+type ScheduleRun struct {
+	ScheduleID string   `json:"scheduleId"`
+	Name       string   `json:"name"`
+	Manual     bool     `json:"manual"` // started with RunScheduleNow
+	StartedAt  int64    `json:"startedAt"`
+	FinishedAt int64    `json:"finishedAt"`
+	Status     string   `json:"status"` // complete, failed, partial or idle (nothing new to run)
+	Items      int      `json:"items"`
+	Succeeded  int      `json:"succeeded"`
+	Errors     []string `json:"errors,omitempty"`
+	EntryIDs   []string `json:"entryIds,omitempty"` // history entries the run saved
+	Outputs    []string `json:"outputs,omitempty"`  // files the run wrote
+}
+
+// Schedule run statuses
+const (
+	ScheduleRunComplete = "complete"
+	ScheduleRunFailed   = "failed"
+	ScheduleRunPartial  = "partial"
+	ScheduleRunIdle     = "idle"
+)
+
+const (
+	// defaultScheduleTemplate names output files after the schedule, item
+	// and time
+	defaultScheduleTemplate = "{date}_{time}_{name}_{pattern}.md"
+	// maxScheduleRuns is how many run logs are kept, across schedules
+	maxScheduleRuns = 200
+	// maxScheduleSeen is how many feed items and files are remembered per
+	// schedule as already run
+	maxScheduleSeen = 1000
+	// maxScheduleItems bounds the runs one scheduled run starts, so a new
+	// feed or a full folder does not run hundreds of times at once
+	maxScheduleItems = 20
+)
+
+// scheduleState is what the scheduler remembers between runs, stored in
+// schedules.json beside the preferences
+type scheduleState struct {
+	Runs []ScheduleRun       `json:"runs"` // oldest first
+	Seen map[string][]string `json:"seen"` // feed items and files each schedule has run
+}
+
+// scheduler runs the schedules that are due every minute
+type scheduler struct {
+	stop    chan struct{}
+	mu      sync.Mutex
+	running map[string]bool // IDs of schedules running now
+	state   scheduleState
+	path    string
+}
+
+// scheduleItem is one input a scheduled run processes
+type scheduleItem struct {
+	key   string // remembered as seen once run, empty for text and pages
+	name  string // for output file names
+	input string
+}
+
+// GetSchedules returns the schedules with their next and last runs
+func (a *App) GetSchedules() []ScheduleStatus {
+	s := a.currentScheduler()
+	statuses := []ScheduleStatus{}
+	for _, sched := range a.schedules() {
+		status := ScheduleStatus{Schedule: sched}
+		if cron, err := parseCron(sched.Cron); err != nil {
+			status.Error = err.Error()
+		} else if sched.Enabled {
+			if next := cron.Next(time.Now()); !next.IsZero() {
+				status.NextRun = next.Unix()
+			}
+		}
+		if s != nil {
+			s.mu.Lock()
+			status.Running = s.running[sched.ID]
+			for i := len(s.state.Runs) - 1; i >= 0; i-- {
+				if s.state.Runs[i].ScheduleID == sched.ID {
+					run := s.state.Runs[i]
+					status.LastRun = &run
+					break
+				}
+			}
+			s.mu.Unlock()
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// SaveSchedule creates a schedule, or replaces the one with the same ID,
+// and returns it as saved
+func (a *App) SaveSchedule(sched Schedule) (Schedule, error) {
+	sched.Name = strings.TrimSpace(sched.Name)
+	sched.Cron = strings.TrimSpace(sched.Cron)
+	if sched.Name == "" {
+		return sched, fmt.Errorf("a schedule name is required")
+	}
+	if _, err := parseCron(sched.Cron); err != nil {
+		return sched, err
+	}
+	if sched.Preset == "" && sched.Pattern == "" && sched.Source.Kind != "digest" {
+		return sched, fmt.Errorf("a preset or a pattern is required")
+	}
+	switch sched.Source.Kind {
+	case "digest":
+	case "text":
+		if strings.TrimSpace(sched.Source.Text) == "" {
+			return sched, fmt.Errorf("the text to run is required")
+		}
+	case "url", "feed":
+		if !strings.HasPrefix(sched.Source.URL, "http://") && !strings.HasPrefix(sched.Source.URL, "https://") {
+			return sched, fmt.Errorf("invalid URL %q", sched.Source.URL)
+		}
+	case "folder":
+		if info, err := os.Stat(sched.Source.Dir); err != nil || !info.IsDir() {
+			return sched, fmt.Errorf("folder not found: %s", sched.Source.Dir)
+		}
+		if _, err := filepath.Match(sched.Source.Glob, ""); err != nil {
+			return sched, fmt.Errorf("invalid file pattern: %v", err)
+		}
+	default:
+		return sched, fmt.Errorf("unknown input source %q, use text, url, feed, folder or digest", sched.Source.Kind)
+	}
+	if sched.ID == "" {
+		sched.ID = newHistoryID()
+	}
+
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return sched, err
+	}
+	i := slices.IndexFunc(prefs.Schedules, func(s Schedule) bool { return s.ID == sched.ID })
+	if i >= 0 {
+		prefs.Schedules[i] = sched
+	} else {
+		prefs.Schedules = append(prefs.Schedules, sched)
+	}
+	return sched, a.SavePreferences(*prefs)
+}
+
+// DeleteSchedule removes a schedule. Its run logs are kept.
+func (a *App) DeleteSchedule(id string) error {
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(prefs.Schedules, func(s Schedule) bool { return s.ID == id })
+	if i < 0 {
+		return fmt.Errorf("schedule not found: %s", id)
+	}
+	prefs.Schedules = slices.Delete(prefs.Schedules, i, i+1)
+	if err := a.SavePreferences(*prefs); err != nil {
+		return err
+	}
+	if s := a.currentScheduler(); s != nil {
+		s.mu.Lock()
+		delete(s.state.Seen, id)
+		s.mu.Unlock()
+		return s.save()
+	}
+	return nil
+}
+
+// RunScheduleNow starts a schedule at once, whether or not it is enabled
+func (a *App) RunScheduleNow(id string) error {
+	s := a.currentScheduler()
+	if s == nil {
+		return fmt.Errorf("the scheduler is not running")
+	}
+	sched, ok := a.findSchedule(id)
+	if !ok {
+		return fmt.Errorf("schedule not found: %s", id)
+	}
+	if !s.begin(sched.ID) {
+		return fmt.Errorf("%s is already running", sched.Name)
+	}
+	go a.runSchedule(s, sched, true)
+	return nil
+}
+
+// GetScheduleRuns returns the run logs of a schedule, or of every schedule
+// when id is empty, newest first
+func (a *App) GetScheduleRuns(id string) []ScheduleRun {
+	runs := []ScheduleRun{}
+	s := a.currentScheduler()
+	if s == nil {
+		return runs
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.state.Runs) - 1; i >= 0; i-- {
+		if id == "" || s.state.Runs[i].ScheduleID == id {
+			runs = append(runs, s.state.Runs[i])
+		}
+	}
+	return runs
+}
+
+// schedules returns the configured schedules
+func (a *App) schedules() []Schedule {
+	prefs, err := a.loadPreferences()
+	if err != nil || prefs.Schedules == nil {
+		return []Schedule{}
+	}
+	return prefs.Schedules
+}
+
+func (a *App) findSchedule(id string) (Schedule, bool) {
+	for _, sched := range a.schedules() {
+		if sched.ID == id {
+			return sched, true
+		}
+	}
+	return Schedule{}, false
+}
+
+func (a *App) currentScheduler() *scheduler {
+	a.schedulerMutex.Lock()
+	defer a.schedulerMutex.Unlock()
+	return a.scheduler
+}
+
+// startScheduler loads the run logs and starts running schedules
+func (a *App) startScheduler() {
+	dir := a.getConfigDir()
+	if dir == "" {
+		return
+	}
+	s := &scheduler{
+		stop:    make(chan struct{}),
+		running: map[string]bool{},
+		path:    filepath.Join(dir, "schedules.json"),
+	}
+	if data, err := os.ReadFile(s.path); err == nil {
+		if err := json.Unmarshal(data, &s.state); err != nil {
+			a.log.Error("failed to load schedule logs", "error", err)
+		}
+	}
+	if s.state.Seen == nil {
+		s.state.Seen = map[string][]string{}
+	}
+
+	a.schedulerMutex.Lock()
+	a.scheduler = s
+	a.schedulerMutex.Unlock()
+	go a.runScheduler(s)
+}
+
+// stopScheduler stops starting schedules. Runs in progress are cancelled
+// with the other jobs.
+func (a *App) stopScheduler() {
+	a.schedulerMutex.Lock()
+	defer a.schedulerMutex.Unlock()
+	if a.scheduler != nil {
+		close(a.scheduler.stop)
+		a.scheduler = nil
+	}
+}
+
+// runScheduler wakes at the start of every minute and starts the
+// schedules due then. Minutes the computer slept through are not caught up.
+func (a *App) runScheduler(s *scheduler) {
+	for {
+		minute := time.Now().Truncate(time.Minute).Add(time.Minute)
+		timer := time.NewTimer(time.Until(minute))
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		for _, sched := range a.schedules() {
+			if !sched.Enabled {
+				continue
+			}
+			cron, err := parseCron(sched.Cron)
+			if err != nil || !cron.Next(minute.Add(-time.Minute)).Equal(minute) {
+				continue
+			}
+			if !s.begin(sched.ID) {
+				a.log.Warn("skipped schedule, the last run is still going", "schedule", sched.Name)
+				continue
+			}
+			go a.runSchedule(s, sched, false)
+		}
+	}
+}
+
+// begin marks a schedule as running, reporting false if it already is
+func (s *scheduler) begin(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running[id] {
+		return false
+	}
+	s.running[id] = true
+	return true
+}
+
+// runSchedule runs a schedule as a job, logs the run and reports it as
+// "schedule:started" and "schedule:finished"
+func (a *App) runSchedule(s *scheduler, sched Schedule, manual bool) {
+	run := ScheduleRun{ScheduleID: sched.ID, Name: sched.Name, Manual: manual, StartedAt: time.Now().Unix()}
+	a.log.Info("running schedule", "schedule", sched.Name, "manual", manual)
+	a.emit("schedule:started", sched.ID)
+	j, ctx := a.startJob("schedule", "Scheduled: "+sched.Name)
+
+	err := a.runScheduleItems(ctx, j, s, sched, &run)
+	if err != nil {
+		run.Errors = append(run.Errors, err.Error())
+	}
+	switch {
+	case run.Items == 0 && err == nil:
+		run.Status = ScheduleRunIdle
+	case run.Succeeded == 0:
+		run.Status = ScheduleRunFailed
+	case run.Succeeded < run.Items:
+		run.Status = ScheduleRunPartial
+	default:
+		run.Status = ScheduleRunComplete
+	}
+	if run.Status == ScheduleRunFailed && err == nil {
+		err = fmt.Errorf("%s", run.Errors[0])
+	}
+	a.finishJob(j, err)
+	run.FinishedAt = time.Now().Unix()
+
+	s.mu.Lock()
+	s.state.Runs = append(s.state.Runs, run)
+	if len(s.state.Runs) > maxScheduleRuns {
+		s.state.Runs = s.state.Runs[len(s.state.Runs)-maxScheduleRuns:]
+	}
+	delete(s.running, sched.ID)
+	s.mu.Unlock()
+	if err := s.save(); err != nil {
+		a.log.Error("failed to save schedule logs", "error", err)
+	}
+
+	a.log.Info("schedule finished", "schedule", sched.Name, "status", run.Status, "items", run.Items, "succeeded", run.Succeeded)
+	a.emit("schedule:finished", run)
+	if sched.Notify && run.Status != ScheduleRunIdle {
+		a.notifySchedule(run)
+	}
+}
+
+// runScheduleItems collects what is new at the schedule's source and runs
+// each item, recording the results in run
+func (a *App) runScheduleItems(ctx context.Context, j *job, s *scheduler, sched Schedule, run *ScheduleRun) error {
+	if sched.Source.Kind == "digest" {
+		return a.runScheduledDigest(ctx, j, sched, run)
+	}
+	items, err := a.scheduleItems(ctx, s, sched)
+	if err != nil {
+		return err
+	}
+	run.Items = len(items)
+
+	for i, item := range items {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		a.updateJob(j, float64(i)/float64(len(items))*100, fmt.Sprintf("%d of %d: %s", i+1, len(items), item.name))
+
+		entryID, output, err := a.runScheduleItem(ctx, sched, item, i)
+		if entryID != "" {
+			run.EntryIDs = append(run.EntryIDs, entryID)
+		}
+		if err != nil {
+			run.Errors = append(run.Errors, fmt.Sprintf("%s: %s", item.name, toChatError(err).Message))
+			continue
+		}
+		run.Succeeded++
+		if output != "" {
+			run.Outputs = append(run.Outputs, output)
+		}
+		if item.key != "" {
+			s.markSeen(sched.ID, item.key)
+		}
+	}
+	return nil
+}
+
+// runScheduleItem runs one input through the schedule's pattern, saves it
+// to history and writes the output file. Returns the history entry and the
+// file written. index is the item's position in the run, for {index}.
+func (a *App) runScheduleItem(ctx context.Context, sched Schedule, item scheduleItem, index int) (string, string, error) {
+	input := item.input
+	if sched.Source.Kind != "text" && strings.TrimSpace(sched.Source.Text) != "" {
+		input = sched.Source.Text + "\n\n" + input
+	}
+
+	prompt, err := a.automatedPrompt(sched.Preset, sched.Pattern, sched.Vendor, sched.Model, input)
+	if err != nil {
+		return "", "", err
+	}
+	entryID, output, err := a.runAutomated(ctx, prompt)
+	if err != nil || sched.OutputDir == "" {
+		return entryID, "", err
+	}
+
+	template := sched.OutputTemplate
+	if template == "" {
+		template = defaultScheduleTemplate
+	}
+	var name string
+	if sched.Source.Kind == "folder" {
+		name = expandBatchTemplate(template, item.name, prompt.PatternName, prompt.Model, index)
+	} else {
+		// Feed titles and schedule names may hold dots and characters
+		// Windows does not allow in file names
+		name = expandOutputTemplate(template, fileNamePart(item.name), "", prompt.PatternName, prompt.Model, index)
+	}
+	if err := os.MkdirAll(sched.OutputDir, 0755); err != nil {
+		return entryID, "", fmt.Errorf("failed to create output folder: %v", err)
+	}
+	// Items of the same title run in the same second keep their own files
+	ext := filepath.Ext(name)
+	path, err := writeNewFile(sched.OutputDir, strings.TrimSuffix(name, ext), ext, []byte(output))
+	if err != nil {
+		return entryID, "", fmt.Errorf("failed to save output: %v", err)
+	}
+	return entryID, path, nil
+}
+
+// automatedPrompt builds the prompt of a run nobody watches: a preset, or a
+// pattern with the model given or the pattern's own
+func (a *App) automatedPrompt(preset, pattern, vendor, model, input string) (PromptRequest, error) {
+	if preset != "" {
+		return a.presetPrompt(preset, input)
+	}
+	prompt := PromptRequest{UserInput: input, PatternName: pattern}
+	prompt.Vendor, prompt.Model = a.patternDefaultModel(pattern, vendor, model)
+	if prompt.Model == "" {
+		return prompt, fmt.Errorf("no model is set, and pattern %s has none", pattern)
+	}
+	if prompt.Vendor == "" {
+		var err error
+		if prompt.Vendor, err = a.vendorForModel(prompt.Model); err != nil {
+			return prompt, err
+		}
+	}
+	return prompt, nil
+}
+
+// runAutomated runs a prompt in the background and saves it to history,
+// returning the entry and the output
+func (a *App) runAutomated(ctx context.Context, prompt PromptRequest) (string, string, error) {
+	start := time.Now()
+	var stats runStats
+	output, err := a.streamChatWithRetry(ctx, prompt, func(string) {}, stats.addUsage, func(ChatRetry) {})
+	stats.duration = time.Since(start)
+	stats.err = err
+	var entryID string
+	if err == nil || output != "" {
+		entryID = a.recordHistory(chatRun{Prompt: prompt}, output, stats)
+	}
+	return entryID, output, err
+}
+
+// scheduleItems returns the inputs a run of the schedule processes: the
+// text or page, or the feed items and files not run before
+func (a *App) scheduleItems(ctx context.Context, s *scheduler, sched Schedule) ([]scheduleItem, error) {
+	source := sched.Source
+	switch source.Kind {
+	case "text":
+		return []scheduleItem{{name: sched.Name, input: source.Text}}, nil
+
+	case "url":
+		fetchCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		text, err := a.fetchPageText(fetchCtx, source.URL)
+		if err != nil {
+			return nil, err
+		}
+		return []scheduleItem{{name: sched.Name, input: text}}, nil
+
+	case "feed":
+		fetchCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		feed, err := a.fetchFeed(fetchCtx, source.URL)
+		if err != nil {
+			return nil, err
+		}
+		var items []scheduleItem
+		for _, fi := range feed.Items {
+			if fi.ID == "" || s.seen(sched.ID, fi.ID) {
+				continue
+			}
+			input := fi.Content
+			if fi.Title != "" || fi.Link != "" {
+				input = strings.TrimSpace(fmt.Sprintf("# %s\n%s", fi.Title, fi.Link)) + "\n\n" + input
+			}
+			name := fi.Title
+			if name == "" {
+				name = feed.Title
+			}
+			items = append(items, scheduleItem{key: fi.ID, name: name, input: input})
+		}
+		return limitScheduleItems(items), nil
+
+	case "folder":
+		glob := source.Glob
+		if glob == "" {
+			glob = "*"
+		}
+		matches, err := filepath.Glob(filepath.Join(source.Dir, glob))
+		if err != nil {
+			return nil, fmt.Errorf("invalid file pattern: %v", err)
+		}
+		var items []scheduleItem
+		var errs []string
+		for _, path := range matches {
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			// A file changed since it was run is new again
+			key := fmt.Sprintf("%s@%d", path, info.ModTime().Unix())
+			if s.seen(sched.ID, key) {
+				continue
+			}
+			if !importableExtensions[strings.ToLower(filepath.Ext(path))] {
+				continue
+			}
+			text, err := readImportFile(path)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", filepath.Base(path), err))
+				continue
+			}
+			items = append(items, scheduleItem{key: key, name: filepath.Base(path), input: text})
+		}
+		if len(items) == 0 && len(errs) > 0 {
+			return nil, fmt.Errorf("%s", strings.Join(errs, "; "))
+		}
+		return limitScheduleItems(items), nil
+	}
+	return nil, fmt.Errorf("unknown input source %q", source.Kind)
+}
+
+// limitScheduleItems keeps the first maxScheduleItems items, leaving the
+// rest for the next runs
+func limitScheduleItems(items []scheduleItem) []scheduleItem {
+	if len(items) > maxScheduleItems {
+		return items[:maxScheduleItems]
+	}
+	return items
+}
+
+// seen reports whether a schedule has run a feed item or file
+func (s *scheduler) seen(id, key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Contains(s.state.Seen[id], key)
+}
+
+// markSeen remembers that a schedule ran a feed item or file
+func (s *scheduler) markSeen(id, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := append(s.state.Seen[id], key)
+	if len(seen) > maxScheduleSeen {
+		seen = seen[len(seen)-maxScheduleSeen:]
+	}
+	s.state.Seen[id] = seen
+}
+
+// save writes the run logs and seen items
+func (s *scheduler) save() error {
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s.state, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, data, 0644)
+}
@@ -0,0 +1,513 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// errSecretNotFound is returned by a secret backend for a missing entry
+var errSecretNotFound = errors.New("secret not found")
+
+// secretBackend stores named secrets, in the OS keychain or a file
+type secretBackend interface {
+	Name() string
+	Get(name string) (string, error)
+	Set(name, value string) error
+	Delete(name string) error
+}
+
+// secretStore keeps vendor API keys out of Fabric's plaintext .env. The
+// backend holds the values; the names are kept in secrets_index.json, since
+// keychains cannot be listed by application. Values are cached, since the
+// keychain backends run a command for every read.
+type secretStore struct {
+	mu        sync.Mutex
+	backend   secretBackend
+	indexPath string
+	names     []string
+	cache     map[string]string
+}
+
+// openSecretStore picks the OS keychain when it works, and an encrypted file
+// in dir otherwise
+func openSecretStore(dir string) *secretStore {
+	var backend secretBackend
+	if keychain, err := newKeychainBackend(dir); err == nil {
+		backend = keychain
+	} else {
+		backend = &fileSecretBackend{path: filepath.Join(dir, "secrets.enc"), keyPath: filepath.Join(dir, "secrets.key")}
+	}
+
+	s := &secretStore{backend: backend, indexPath: filepath.Join(dir, "secrets_index.json"), cache: map[string]string{}}
+	if data, err := os.ReadFile(s.indexPath); err == nil {
+		json.Unmarshal(data, &s.names)
+	}
+	return s
+}
+
+// Names returns the names of the stored secrets
+func (s *secretStore) Names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.names...)
+}
+
+// Get returns a secret, or "" if there is none
+func (s *secretStore) Get(name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if value, ok := s.cache[name]; ok {
+		return value, nil
+	}
+	value, err := s.backend.Get(name)
+	if errors.Is(err, errSecretNotFound) {
+		value, err = "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	s.cache[name] = value
+	return value, nil
+}
+
+// Set stores a secret, replacing any earlier value
+func (s *secretStore) Set(name, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cached, ok := s.cache[name]; ok && cached == value && containsFold(s.names, name) {
+		return nil
+	}
+	if err := s.backend.Set(name, value); err != nil {
+		return fmt.Errorf("failed to store %s in the %s: %v", name, s.backend.Name(), err)
+	}
+	s.cache[name] = value
+	if !containsFold(s.names, name) {
+		s.names = append(s.names, name)
+		sort.Strings(s.names)
+	}
+	return s.saveIndexLocked()
+}
+
+// Delete removes a secret
+func (s *secretStore) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if value, ok := s.cache[name]; ok && value == "" && !containsFold(s.names, name) {
+		return nil
+	}
+	if err := s.backend.Delete(name); err != nil && !errors.Is(err, errSecretNotFound) {
+		return fmt.Errorf("failed to remove %s from the %s: %v", name, s.backend.Name(), err)
+	}
+	s.cache[name] = ""
+	s.names = removeFold(s.names, name)
+	return s.saveIndexLocked()
+}
+
+func (s *secretStore) saveIndexLocked() error {
+	data, err := json.Marshal(s.names)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.indexPath, data, 0600)
+}
+
+// isSecretEnvKey reports whether a Fabric .env key holds a secret rather than
+// a setting such as a URL or default model
+func isSecretEnvKey(key string) bool {
+	return strings.HasSuffix(key, "_API_KEY") || strings.HasSuffix(key, "_TOKEN") || strings.HasSuffix(key, "_SECRET")
+}
+
+// SecretsStatus describes where vendor keys are kept
+type SecretsStatus struct {
+	Backend   string   `json:"backend"`   // keychain, libsecret, dpapi or file
+	Stored    []string `json:"stored"`    // keys held by the backend
+	Plaintext []string `json:"plaintext"` // keys still in Fabric's .env, see MigrateSecrets
+}
+
+// GetSecretsStatus reports which vendor keys are stored securely and which
+// are still plaintext
+func (a *App) GetSecretsStatus() (*SecretsStatus, error) {
+	if a.secrets == nil {
+		return nil, fmt.Errorf("secret storage is not available")
+	}
+	status := &SecretsStatus{Backend: a.secrets.backend.Name(), Stored: a.secrets.Names(), Plaintext: []string{}}
+	if dir, err := fabricConfigDir(); err == nil {
+		env, _ := readEnvFile(filepath.Join(dir, ".env"))
+		for key, value := range env {
+			if isSecretEnvKey(key) && value != "" {
+				status.Plaintext = append(status.Plaintext, key)
+			}
+		}
+	}
+	sort.Strings(status.Plaintext)
+	return status, nil
+}
+
+// MigrateSecrets moves the API keys in Fabric's .env into secret storage and
+// removes them from the file. A server started by the app is restarted, as
+// it now gets the keys from its environment.
+func (a *App) MigrateSecrets() (*SecretsStatus, error) {
+	if a.secrets == nil {
+		return nil, fmt.Errorf("secret storage is not available")
+	}
+	dir, err := fabricConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	env, err := readEnvFile(filepath.Join(dir, ".env"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Fabric config: %v", err)
+	}
+
+	var moved []string
+	for key, value := range env {
+		if !isSecretEnvKey(key) || value == "" {
+			continue
+		}
+		if err := a.secrets.Set(key, value); err != nil {
+			return nil, err
+		}
+		moved = append(moved, key)
+	}
+	// Only drop the keys from the file once every one is stored
+	if err := a.writeFabricEnv(nil, moved); err != nil {
+		return nil, err
+	}
+	a.log.Info("moved API keys to secret storage", "backend", a.secrets.backend.Name(), "count", len(moved))
+
+	if err := a.reloadFabricVendors(); err != nil {
+		return nil, err
+	}
+	return a.GetSecretsStatus()
+}
+
+// vendorEnv returns Fabric's .env settings with the stored secrets added
+func (a *App) vendorEnv() map[string]string {
+	env := map[string]string{}
+	if dir, err := fabricConfigDir(); err == nil {
+		if e, err := readEnvFile(filepath.Join(dir, ".env")); err == nil {
+			env = e
+		}
+	}
+	for key, value := range a.secretEnv() {
+		env[key] = value
+	}
+	return env
+}
+
+// secretEnv returns the stored secrets as environment variables
+func (a *App) secretEnv() map[string]string {
+	env := map[string]string{}
+	if a.secrets == nil {
+		return env
+	}
+	for _, name := range a.secrets.Names() {
+		if !isSecretEnvKey(name) {
+			continue // the app's own secrets, such as the history key
+		}
+		value, err := a.secrets.Get(name)
+		if err != nil {
+			a.log.Warn("failed to read secret", "name", name, "error", err)
+			continue
+		}
+		if value != "" {
+			env[name] = value
+		}
+	}
+	return env
+}
+
+// writeVendorConfig sets and removes Fabric settings, putting secrets in
+// secret storage and everything else in .env
+func (a *App) writeVendorConfig(set map[string]string, unset []string) error {
+	plain := map[string]string{}
+	for key, value := range set {
+		if isSecretEnvKey(key) && a.secrets != nil {
+			if err := a.secrets.Set(key, value); err != nil {
+				return err
+			}
+			continue
+		}
+		plain[key] = value
+	}
+	for _, key := range unset {
+		if isSecretEnvKey(key) && a.secrets != nil {
+			if err := a.secrets.Delete(key); err != nil {
+				return err
+			}
+		}
+	}
+	// A key moved into secret storage must not linger in the file
+	for key := range set {
+		if _, ok := plain[key]; !ok {
+			unset = append(unset, key)
+		}
+	}
+	if err := a.writeFabricEnv(plain, unset); err != nil {
+		return err
+	}
+	a.refreshRedactions()
+	return nil
+}
+
+// Names of the credentials from preferences in secret storage. None ends in
+// _KEY, _TOKEN or _SECRET, so secretEnv keeps them from Fabric.
+const (
+	githubTokenSecret   = "fabric-gui-github-token"
+	whisperKeySecret    = "fabric-gui-whisper-api-key"
+	ttsKeySecret        = "fabric-gui-tts-api-key"
+	apiTokenSecret      = "fabric-gui-api-token"
+	notionTokenSecret   = "fabric-gui-notion-token"
+	smtpPasswordSecret  = "fabric-gui-smtp-password"
+	webhookSecretPrefix = "fabric-gui-webhook-secret-" // followed by the webhook's name
+)
+
+// secretFields returns the credentials in preferences that are kept in
+// secret storage rather than preferences.json, by their name there
+func (p *Preferences) secretFields() map[string]*string {
+	fields := map[string]*string{
+		githubTokenSecret:  &p.GitHubToken,
+		whisperKeySecret:   &p.WhisperAPIKey,
+		ttsKeySecret:       &p.TTS.APIKey,
+		apiTokenSecret:     &p.API.Token,
+		notionTokenSecret:  &p.Notion.Token,
+		smtpPasswordSecret: &p.SMTP.Password,
+	}
+	for i := range p.Webhooks {
+		fields[webhookSecretPrefix+strings.ToLower(p.Webhooks[i].Name)] = &p.Webhooks[i].Secret
+	}
+	return fields
+}
+
+// loadPreferenceSecrets fills in the credentials kept in secret storage. A
+// value still in preferences.json, from an older version or edited in by
+// hand, is kept and moved to secret storage on the next save.
+func (a *App) loadPreferenceSecrets(prefs *Preferences) {
+	if a.secrets == nil {
+		return
+	}
+	for name, field := range prefs.secretFields() {
+		if *field != "" {
+			continue
+		}
+		value, err := a.secrets.Get(name)
+		if err != nil {
+			a.log.Warn("failed to read secret", "name", name, "error", err)
+			continue
+		}
+		*field = value
+	}
+}
+
+// storePreferenceSecrets moves the credentials in prefs to secret storage,
+// removes the ones that were cleared and blanks them for preferences.json.
+// Endpoint keys are blanked in any case: writeVendorConfig keeps them.
+func (a *App) storePreferenceSecrets(prefs *Preferences) error {
+	// Cloned since the caller's slices share the entries
+	prefs.Webhooks = slices.Clone(prefs.Webhooks)
+	prefs.OpenAIEndpoints = slices.Clone(prefs.OpenAIEndpoints)
+	for i := range prefs.OpenAIEndpoints {
+		prefs.OpenAIEndpoints[i].APIKey = ""
+	}
+	if a.secrets == nil {
+		return nil
+	}
+	fields := prefs.secretFields()
+	for name, field := range fields {
+		var err error
+		if value := strings.TrimSpace(*field); value != "" {
+			err = a.secrets.Set(name, value)
+		} else {
+			err = a.secrets.Delete(name)
+		}
+		if err != nil {
+			return err
+		}
+		*field = ""
+	}
+	// The secrets of webhooks that were removed or renamed
+	for _, name := range a.secrets.Names() {
+		if _, ok := fields[name]; !ok && strings.HasPrefix(name, webhookSecretPrefix) {
+			if err := a.secrets.Delete(name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// moveSecretsFromPreferences moves credentials found in preferences.json to
+// secret storage, for preferences written before they were kept there. prefs
+// is what was read from the file and gets the stored credentials filled in.
+func (a *App) moveSecretsFromPreferences(prefs *Preferences) {
+	plaintext := false
+	for _, field := range prefs.secretFields() {
+		plaintext = plaintext || *field != ""
+	}
+	for _, e := range prefs.OpenAIEndpoints {
+		plaintext = plaintext || e.APIKey != ""
+	}
+	a.loadPreferenceSecrets(prefs)
+	if !plaintext {
+		return
+	}
+	if err := a.SavePreferences(*prefs); err != nil {
+		a.log.Error("failed to move credentials out of preferences", "error", err)
+		return
+	}
+	a.log.Info("moved credentials from preferences to secret storage")
+}
+
+// fileSecretBackend is the fallback when no keychain is usable: secrets are
+// sealed with AES-GCM under a random key kept beside them. That keeps them out
+// of plaintext files and backups of .env, but not from someone who can read
+// the config directory.
+type fileSecretBackend struct {
+	mu      sync.Mutex
+	path    string
+	keyPath string
+}
+
+func (f *fileSecretBackend) Name() string { return "file" }
+
+func (f *fileSecretBackend) Get(name string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	secrets, err := f.load()
+	if err != nil {
+		return "", err
+	}
+	value, ok := secrets[name]
+	if !ok {
+		return "", errSecretNotFound
+	}
+	return value, nil
+}
+
+func (f *fileSecretBackend) Set(name, value string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	secrets, err := f.load()
+	if err != nil {
+		return err
+	}
+	secrets[name] = value
+	return f.save(secrets)
+}
+
+func (f *fileSecretBackend) Delete(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	secrets, err := f.load()
+	if err != nil {
+		return err
+	}
+	delete(secrets, name)
+	return f.save(secrets)
+}
+
+func (f *fileSecretBackend) load() (map[string]string, error) {
+	secrets := map[string]string{}
+	data, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	key, err := f.key()
+	if err != nil {
+		return nil, err
+	}
+	plain, err := openSealed(key, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secrets: %v", err)
+	}
+	return secrets, json.Unmarshal(plain, &secrets)
+}
+
+func (f *fileSecretBackend) save(secrets map[string]string) error {
+	key, err := f.key()
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	sealed, err := seal(key, plain)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(f.path, sealed, 0600)
+}
+
+// key reads the file key, creating it on first use. A new key is only made
+// while there are no secrets yet, since replacing a lost or damaged key
+// would make the existing ones unreadable and the next save drop them.
+func (f *fileSecretBackend) key() ([]byte, error) {
+	key, err := os.ReadFile(f.keyPath)
+	if err == nil {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("secret key %s is damaged", f.keyPath)
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read secret key: %v", err)
+	}
+	if _, err := os.Stat(f.path); err == nil {
+		return nil, fmt.Errorf("secret key %s is missing, the secrets in %s cannot be decrypted", f.keyPath, f.path)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, writeFileAtomic(f.keyPath, key, 0600)
+}
+
+// seal encrypts data with AES-256-GCM, prefixing the random nonce
+func seal(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+// openSealed decrypts data written by seal
+func openSealed(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("data is too short")
+	}
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// sessionSaveInterval is how often unsaved session changes are written
+	sessionSaveInterval = 2 * time.Second
+	// sessionFileName holds the running session, removed on a clean exit
+	sessionFileName = "session.json"
+	// recoveredSessionFileName is a session left behind by a crash, kept
+	// until it is restored or discarded
+	recoveredSessionFileName = "session.recovered"
+)
+
+// Draft is the unsent input and the selection it was meant for
+type Draft struct {
+	Input   string `json:"input"`
+	Pattern string `json:"pattern,omitempty"`
+	Vendor  string `json:"vendor,omitempty"`
+	Model   string `json:"model,omitempty"`
+}
+
+// StreamDraft is the output received so far by a stream in progress
+type StreamDraft struct {
+	StreamID  string `json:"streamId"`
+	Pattern   string `json:"pattern,omitempty"`
+	Vendor    string `json:"vendor,omitempty"`
+	Model     string `json:"model,omitempty"`
+	Input     string `json:"input"`
+	Output    string `json:"output"`
+	StartedAt int64  `json:"startedAt"`
+}
+
+// SavedSession is the state persisted so a crash or power loss loses little
+type SavedSession struct {
+	SavedAt int64         `json:"savedAt"`
+	Draft   Draft         `json:"draft"`
+	Streams []StreamDraft `json:"streams"`
+}
+
+// sessionSaver keeps the current session and writes it to disk every
+// sessionSaveInterval while it changes. Until start is called it only
+// tracks state.
+type sessionSaver struct {
+	mu      sync.Mutex
+	path    string
+	seal    func([]byte) ([]byte, error)
+	draft   Draft
+	streams map[string]*StreamDraft
+	outputs map[string]*strings.Builder
+	dirty   bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func newSessionSaver() *sessionSaver {
+	return &sessionSaver{
+		streams: make(map[string]*StreamDraft),
+		outputs: make(map[string]*strings.Builder),
+	}
+}
+
+// start begins saving to path, sealing the data with seal
+func (s *sessionSaver) start(path string, seal func([]byte) ([]byte, error)) {
+	s.mu.Lock()
+	s.path = path
+	s.seal = seal
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	s.mu.Unlock()
+
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(sessionSaveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.save()
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// close stops saving and removes the file, as nothing needs recovering
+// after a clean exit
+func (s *sessionSaver) close() {
+	s.mu.Lock()
+	stop, done, path := s.stop, s.done, s.path
+	s.path = ""
+	s.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+	if path != "" {
+		os.Remove(path)
+	}
+}
+
+// SetDraft records the unsent input
+func (s *sessionSaver) SetDraft(draft Draft) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.draft = draft
+	s.dirty = true
+}
+
+// StreamStarted records a stream, continuing from partial for a resumed one
+func (s *sessionSaver) StreamStarted(id string, prompt PromptRequest, partial string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.streams[id] = &StreamDraft{
+		StreamID:  id,
+		Pattern:   prompt.PatternName,
+		Vendor:    prompt.Vendor,
+		Model:     prompt.Model,
+		Input:     prompt.UserInput,
+		StartedAt: time.Now().Unix(),
+	}
+	output := &strings.Builder{}
+	output.WriteString(partial)
+	s.outputs[id] = output
+	s.dirty = true
+}
+
+// StreamChunk adds received output to a stream
+func (s *sessionSaver) StreamChunk(id, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if output, ok := s.outputs[id]; ok {
+		output.WriteString(content)
+		s.dirty = true
+	}
+}
+
+// StreamEnded forgets a stream, its output now being in history
+func (s *sessionSaver) StreamEnded(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.streams, id)
+	delete(s.outputs, id)
+	s.dirty = true
+}
+
+// save writes the session if it changed, or removes the file when there is
+// nothing worth recovering
+func (s *sessionSaver) save() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dirty || s.path == "" {
+		return
+	}
+
+	session := SavedSession{SavedAt: time.Now().Unix(), Draft: s.draft, Streams: []StreamDraft{}}
+	for id, stream := range s.streams {
+		saved := *stream
+		saved.Output = s.outputs[id].String()
+		session.Streams = append(session.Streams, saved)
+	}
+	sort.Slice(session.Streams, func(i, j int) bool { return session.Streams[i].StartedAt < session.Streams[j].StartedAt })
+
+	if strings.TrimSpace(session.Draft.Input) == "" && len(session.Streams) == 0 {
+		os.Remove(s.path)
+		s.dirty = false
+		return
+	}
+
+	data, err := json.Marshal(session)
+	if err == nil {
+		// Fails while encrypted history is locked, which keeps the draft
+		// off disk until the key is known
+		data, err = s.seal(data)
+	}
+	if err != nil {
+		return
+	}
+	if writeFileAtomic(s.path, data, 0600) == nil {
+		s.dirty = false
+	}
+}
+
+// startSessionRecovery keeps a session left by a crash for GetSavedSession
+// and starts saving the current one
+func (a *App) startSessionRecovery(dir string) {
+	path := filepath.Join(dir, sessionFileName)
+	if _, err := os.Stat(path); err == nil {
+		if err := os.Rename(path, filepath.Join(dir, recoveredSessionFileName)); err != nil {
+			a.log.Error("failed to keep previous session", "error", err)
+		} else {
+			a.log.Warn("previous session did not exit cleanly, it can be restored")
+		}
+	}
+	a.session.start(path, a.history.Seal)
+}
+
+// SaveDraft records the unsent input so it survives a crash
+func (a *App) SaveDraft(draft Draft) {
+	a.session.SetDraft(draft)
+}
+
+// GetSavedSession returns the session left behind when the app last exited
+// without shutting down cleanly, or nil if there is none
+func (a *App) GetSavedSession() (*SavedSession, error) {
+	dir := a.getConfigDir()
+	if dir == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filepath.Join(dir, recoveredSessionFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read saved session: %v", err)
+	}
+	if data, err = a.history.Open(data); err != nil {
+		return nil, fmt.Errorf("failed to open saved session: %v", err)
+	}
+	var session SavedSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("invalid saved session: %v", err)
+	}
+	return &session, nil
+}
+
+// RestoreSession returns the saved session for the frontend to reload and
+// removes it. Partial outputs of interrupted streams are kept in history.
+func (a *App) RestoreSession() (*SavedSession, error) {
+	session, err := a.GetSavedSession()
+	if err != nil || session == nil {
+		return session, err
+	}
+	for _, stream := range session.Streams {
+		if stream.Output == "" {
+			continue
+		}
+		a.history.Add(HistoryEntry{
+			Pattern: stream.Pattern,
+			Vendor:  stream.Vendor,
+			Model:   stream.Model,
+			Input:   stream.Input,
+			Output:  stream.Output,
+			Time:    stream.StartedAt,
+			Error:   "interrupted: the app closed before the output finished",
+		})
+	}
+	if err := a.DiscardSession(); err != nil {
+		return nil, err
+	}
+	a.log.Info("restored previous session", "streams", len(session.Streams))
+	return session, nil
+}
+
+// DiscardSession deletes the saved session
+func (a *App) DiscardSession() error {
+	dir := a.getConfigDir()
+	if dir == "" {
+		return nil
+	}
+	err := os.Remove(filepath.Join(dir, recoveredSessionFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to discard saved session: %v", err)
+	}
+	return nil
+}
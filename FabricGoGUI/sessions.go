@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// FabricSession is a conversation the server remembers between requests
+type FabricSession struct {
+	Name     string           `json:"name"`
+	Messages []SessionMessage `json:"messages"`
+}
+
+// SessionMessage is one message stored in a session
+type SessionMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ListSessions returns the names of the server's sessions
+func (a *App) ListSessions() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+	return a.fabricClient().StorageNames(ctx, storageSessions)
+}
+
+// GetSession returns a session with its messages
+func (a *App) GetSession(name string) (*FabricSession, error) {
+	if err := validatePatternName(name); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+
+	data, err := a.fabricClient().StorageGet(ctx, storageSessions, name)
+	if err != nil {
+		return nil, err
+	}
+	session := FabricSession{Messages: []SessionMessage{}}
+	if err := json.Unmarshal(data, &session); err != nil {
+		// Some servers store the message list on its own
+		if err := json.Unmarshal(data, &session.Messages); err != nil {
+			return nil, fmt.Errorf("invalid session: %v", err)
+		}
+	}
+	session.Name = name
+	return &session, nil
+}
+
+// CreateSession starts an empty session. Chat requests naming it then build
+// up its history on the server.
+func (a *App) CreateSession(name string) error {
+	if err := validatePatternName(name); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+
+	if err := a.fabricClient().StorageSave(ctx, storageSessions, name, []byte("[]")); err != nil {
+		return fmt.Errorf("failed to create session: %v", err)
+	}
+	a.log.Info("created session", "session", name)
+	return nil
+}
+
+// DeleteSession removes a session and its history
+func (a *App) DeleteSession(name string) error {
+	if err := validatePatternName(name); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+
+	if err := a.fabricClient().StorageDelete(ctx, storageSessions, name); err != nil {
+		return fmt.Errorf("failed to delete session: %v", err)
+	}
+	a.log.Info("deleted session", "session", name)
+	return nil
+}
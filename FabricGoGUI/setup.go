@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// fabricInstallURL documents how to install Fabric by hand
+const fabricInstallURL = "https://github.com/danielmiessler/fabric#installation"
+
+// Setup step statuses
+const (
+	SetupChecking = "checking"
+	SetupOK       = "ok"
+	SetupMissing  = "missing"
+)
+
+// SetupStep is the result of one setup check, emitted as "setup:step". Remedy
+// names the call that fixes it: install (InstallFabric, or OpenFabricInstallPage
+// for a manual install), setup (RunFabricSetup) or patterns (DownloadPatterns).
+type SetupStep struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Remedy string `json:"remedy,omitempty"`
+}
+
+// fabricPath returns the fabric binary to run, preferring the copy installed
+// by InstallFabric over one in PATH
+func (a *App) fabricPath() (string, error) {
+	if managed := a.managedFabricPath(); managed != "" {
+		if info, err := os.Stat(managed); err == nil && !info.IsDir() {
+			return managed, nil
+		}
+	}
+
+	path, err := exec.LookPath("fabric")
+	if err != nil {
+		return "", fmt.Errorf("fabric not found in PATH: %v", err)
+	}
+	return path, nil
+}
+
+// fabricConfigDir returns Fabric's own configuration directory
+func fabricConfigDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "fabric"), nil
+}
+
+// setupSteps lists the setup checks in the order they are run
+var setupSteps = []SetupStep{
+	{ID: "fabric", Title: "Fabric installed"},
+	{ID: "vendor", Title: "AI vendor configured"},
+	{ID: "patterns", Title: "Patterns downloaded"},
+}
+
+// newSetupStep returns the step with the given ID, marked as missing
+func newSetupStep(id, remedy string) SetupStep {
+	for _, step := range setupSteps {
+		if step.ID == id {
+			step.Status = SetupMissing
+			step.Remedy = remedy
+			return step
+		}
+	}
+	return SetupStep{ID: id, Status: SetupMissing, Remedy: remedy}
+}
+
+// RunSetupChecks runs every setup check in order, emitting each step as it
+// starts and finishes, and returns the results
+func (a *App) RunSetupChecks() []SetupStep {
+	checks := map[string]func() SetupStep{
+		"fabric":   a.CheckFabricInstalled,
+		"vendor":   a.CheckVendorConfigured,
+		"patterns": a.CheckPatternsInstalled,
+	}
+
+	results := make([]SetupStep, 0, len(setupSteps))
+	for _, pending := range setupSteps {
+		pending.Status = SetupChecking
+		a.emit("setup:step", pending)
+
+		step := checks[pending.ID]()
+		a.emit("setup:step", step)
+		results = append(results, step)
+	}
+	return results
+}
+
+// CheckFabricInstalled looks for the fabric binary
+func (a *App) CheckFabricInstalled() SetupStep {
+	step := newSetupStep("fabric", "install")
+
+	path, err := a.fabricPath()
+	if err != nil {
+		step.Detail = "The fabric command was not found"
+		return step
+	}
+
+	step.Status = SetupOK
+	step.Remedy = ""
+	step.Detail = fmt.Sprintf("%s (%s)", path, fabricBinaryVersion(path))
+	return step
+}
+
+// CheckVendorConfigured looks for at least one AI vendor in Fabric's .env file
+// and the app's secret storage
+func (a *App) CheckVendorConfigured() SetupStep {
+	step := newSetupStep("vendor", "setup")
+
+	env := a.vendorEnv()
+	if len(env) == 0 {
+		step.Detail = "Fabric has not been set up yet"
+		return step
+	}
+
+	var vendors []string
+	for key, value := range env {
+		if value == "" {
+			continue
+		}
+		// Cloud vendors store an API key, local ones such as Ollama a URL
+		if strings.HasSuffix(key, "_API_KEY") || strings.HasSuffix(key, "_API_URL") || strings.HasSuffix(key, "_API_BASE_URL") {
+			vendors = append(vendors, strings.SplitN(key, "_API_", 2)[0])
+		}
+	}
+	if len(vendors) == 0 {
+		step.Detail = "No API keys or vendor URLs are configured"
+		return step
+	}
+
+	step.Status = SetupOK
+	step.Remedy = ""
+	step.Detail = strings.Join(vendors, ", ")
+	if model := env["DEFAULT_MODEL"]; model != "" {
+		step.Detail += "; default model " + model
+	}
+	return step
+}
+
+// CheckPatternsInstalled counts the patterns in Fabric's patterns directory
+func (a *App) CheckPatternsInstalled() SetupStep {
+	step := newSetupStep("patterns", "patterns")
+
+	dir, err := fabricPatternsDir()
+	if err != nil {
+		step.Detail = err.Error()
+		return step
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		step.Detail = "No patterns have been downloaded"
+		return step
+	}
+
+	count := 0
+	for _, e := range entries {
+		if e.IsDir() {
+			count++
+		}
+	}
+	if count == 0 {
+		step.Detail = "The patterns directory is empty"
+		return step
+	}
+
+	step.Status = SetupOK
+	step.Remedy = ""
+	step.Detail = fmt.Sprintf("%d patterns", count)
+	return step
+}
+
+// OpenFabricInstallPage opens the Fabric installation instructions
+func (a *App) OpenFabricInstallPage() {
+	runtime.BrowserOpenURL(a.ctx, fabricInstallURL)
+}
+
+// RunFabricSetup opens a terminal running fabric --setup. The setup is
+// interactive, so it cannot run inside the app.
+func (a *App) RunFabricSetup() error {
+	path, err := a.fabricPath()
+	if err != nil {
+		return err
+	}
+
+	var cmd *exec.Cmd
+	switch goruntime.GOOS {
+	case "windows":
+		cmd = exec.Command("cmd", "/c", "start", "cmd", "/k", path, "--setup")
+	case "darwin":
+		script := fmt.Sprintf(`tell application "Terminal" to do script "%s --setup"`, strings.ReplaceAll(path, `"`, `\"`))
+		cmd = exec.Command("osascript", "-e", script, "-e", `tell application "Terminal" to activate`)
+	default:
+		terminal, err := findTerminal()
+		if err != nil {
+			return err
+		}
+		cmd = exec.Command(terminal, "-e", path, "--setup")
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open a terminal: %v", err)
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// findTerminal picks a terminal emulator on Linux and the BSDs
+func findTerminal() (string, error) {
+	for _, name := range []string{"x-terminal-emulator", "gnome-terminal", "konsole", "xfce4-terminal", "alacritty", "kitty", "xterm"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no terminal emulator found, run fabric --setup in a terminal")
+}
+
+// DownloadPatterns runs fabric --updatepatterns, emitting its output as
+// "setup:output" lines, and reports the patterns check afterwards
+func (a *App) DownloadPatterns() (SetupStep, error) {
+	_, err := a.updatePatterns("Downloading patterns", func(line string) {
+		a.emit("setup:output", line)
+	})
+	if err != nil {
+		return SetupStep{}, err
+	}
+
+	step := a.CheckPatternsInstalled()
+	a.emit("setup:step", step)
+	return step, nil
+}
+
+// runWithOutput runs cmd, passing each line it prints to onLine and the log
+func (a *App) runWithOutput(cmd *exec.Cmd, onLine func(string)) error {
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	cmd.Stderr = cmd.Stdout
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(stdout)
+	for {
+		line, err := reader.ReadString('\n')
+		if line = strings.TrimSpace(line); line != "" {
+			a.log.Info(filepath.Base(cmd.Path), "output", line)
+			onLine(line)
+		}
+		if err != nil {
+			break
+		}
+	}
+	return cmd.Wait()
+}
+
+// readEnvFile parses a KEY=value file such as Fabric's .env
+func readEnvFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	env := map[string]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		env[strings.TrimSpace(strings.TrimPrefix(key, "export "))] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	return env, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Strategy is a Fabric prompting strategy, such as chain-of-thought, which
+// is added to the system prompt of a chat request
+type Strategy struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Prompt      string `json:"prompt"`
+}
+
+// ListStrategies returns the prompting strategies with their descriptions.
+// Servers without the strategies endpoint fall back to the strategy files in
+// Fabric's config directory.
+func (a *App) ListStrategies() ([]Strategy, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), storageTimeout)
+	defer cancel()
+
+	strategies, err := a.fabricClient().Strategies(ctx)
+	if err == nil {
+		return strategies, nil
+	}
+
+	local, localErr := localStrategies()
+	if localErr != nil || len(local) == 0 {
+		return nil, err
+	}
+	a.log.Info("using local strategies", "reason", err)
+	return local, nil
+}
+
+// localStrategies reads ~/.config/fabric/strategies/*.json
+func localStrategies() ([]Strategy, error) {
+	dir, err := fabricConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "strategies", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	strategies := []Strategy{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var s Strategy
+		if err := json.Unmarshal(data, &s); err != nil {
+			continue
+		}
+		s.Name = strings.TrimSuffix(filepath.Base(file), ".json")
+		strategies = append(strategies, s)
+	}
+	sort.Slice(strategies, func(i, j int) bool { return strategies[i].Name < strategies[j].Name })
+	return strategies, nil
+}
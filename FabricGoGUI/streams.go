@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ChatChunk is emitted as "chat:chunk" for every piece of streamed output
+type ChatChunk struct {
+	StreamID string `json:"streamId"`
+	Content  string `json:"content"`
+}
+
+// ChatComplete is emitted as "chat:complete" when a stream finishes
+type ChatComplete struct {
+	StreamID  string `json:"streamId"`
+	Output    string `json:"output"`
+	HistoryID string `json:"historyId"`
+}
+
+// ChatError is emitted as "chat:error" when a stream fails
+type ChatError struct {
+	StreamID  string `json:"streamId"`
+	Error     string `json:"error"`
+	Code      string `json:"code"` // network, auth, rate-limit, server, parse, cancelled, interrupted
+	Status    int    `json:"status,omitempty"`
+	Retryable bool   `json:"retryable"`
+	Partial   string `json:"partial,omitempty"`
+	Resumable bool   `json:"resumable"` // ResumeChat can continue from Partial
+}
+
+// chatRun is a chat request together with how its result is recorded in history
+type chatRun struct {
+	Prompt   PromptRequest
+	RerunOf  string // ID of the history entry this run replays
+	ThreadID string // ID of the conversation this run is a reply in
+	ChunkOf  string // stream ID of the chunked run this belongs to
+	Part     int    // chunk this run processed, 0 for the combining pass
+
+	OutputFile string // file the output is copied to as it streams in
+}
+
+// interruptedStream keeps what is needed to resume a stream that broke off
+type interruptedStream struct {
+	run     chatRun
+	partial string
+	stats   runStats
+}
+
+// ListStreams returns the IDs of chat streams currently in progress
+func (a *App) ListStreams() []string {
+	a.streamsMutex.Lock()
+	defer a.streamsMutex.Unlock()
+
+	ids := make([]string, 0, len(a.streams))
+	for id := range a.streams {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// CancelStream aborts an in-progress chat stream
+func (a *App) CancelStream(id string) error {
+	a.streamsMutex.Lock()
+	j, ok := a.streams[id]
+	a.streamsMutex.Unlock()
+
+	if !ok {
+		return fmt.Errorf("stream %s not found", id)
+	}
+	return a.CancelJob(j.ID)
+}
+
+// openStream registers a new chat stream backed by a job. The job ID doubles as the stream ID.
+func (a *App) openStream(title string) (string, *job, context.Context) {
+	j, ctx := a.startJob("chat", title)
+
+	a.streamsMutex.Lock()
+	if a.streams == nil {
+		a.streams = make(map[string]*job)
+	}
+	a.streams[j.ID] = j
+	a.streamsMutex.Unlock()
+
+	return j.ID, j, ctx
+}
+
+// runStream performs a chat request, emitting events keyed by the stream ID
+func (a *App) runStream(id string, j *job, ctx context.Context, run chatRun) error {
+	return a.continueStream(id, j, ctx, run, "", runStats{})
+}
+
+// ResumeChat re-issues an interrupted stream, asking the model to continue
+// from the partial output. New chunks are emitted under the same stream ID.
+func (a *App) ResumeChat(id string) error {
+	a.streamsMutex.Lock()
+	interrupted, ok := a.interrupted[id]
+	if ok {
+		delete(a.interrupted, id)
+	}
+	a.streamsMutex.Unlock()
+
+	if !ok {
+		return fmt.Errorf("stream %s cannot be resumed", id)
+	}
+
+	j, ctx := a.startJob("chat", interrupted.run.Prompt.PatternName+" (resumed)")
+	a.streamsMutex.Lock()
+	a.streams[id] = j
+	a.streamsMutex.Unlock()
+
+	go a.continueStream(id, j, ctx, interrupted.run, interrupted.partial, interrupted.stats)
+	return nil
+}
+
+// continueStream streams a prompt, or the continuation of partial output when
+// partial is set. An interrupted stream is resumed automatically once before
+// the partial output is surfaced to the frontend. stats carries the usage and
+// time of earlier attempts at the same run.
+func (a *App) continueStream(id string, j *job, ctx context.Context, run chatRun, partial string, stats runStats) error {
+	prompt := a.threadPrompt(run)
+	start := time.Now()
+
+	defer func() {
+		a.streamsMutex.Lock()
+		delete(a.streams, id)
+		a.streamsMutex.Unlock()
+	}()
+
+	// Keep the output on disk as it arrives, for recovery after a crash
+	a.session.StreamStarted(id, prompt, partial)
+	defer a.session.StreamEnded(id)
+
+	coalescer := newChunkCoalescer(chunkCoalesceInterval, func(content string) {
+		a.emit("chat:chunk", ChatChunk{StreamID: id, Content: content})
+	})
+	tee := openOutputTee(run.OutputFile, a.log)
+	defer tee.Close()
+	onChunk := func(content string) {
+		tee.Write(content)
+		a.session.StreamChunk(id, content)
+		coalescer.Add(content)
+	}
+	onRetry := func(retry ChatRetry) {
+		retry.StreamID = id
+		a.updateJob(j, -1, fmt.Sprintf("Retrying (%d/%d)", retry.Attempt, retry.MaxAttempts))
+		a.emit("chat:retry", retry)
+	}
+
+	request := prompt
+	if partial != "" {
+		request = continuationPrompt(prompt, partial)
+	}
+	more, err := a.streamChatWithRetry(ctx, request, onChunk, stats.addUsage, onRetry)
+	output := partial + more
+
+	if isResumable(err, output) && partial == "" {
+		a.updateJob(j, -1, "Resuming interrupted stream")
+		a.emit("chat:resuming", id)
+		more, err = a.streamChatWithRetry(ctx, continuationPrompt(prompt, output), onChunk, stats.addUsage, onRetry)
+		output += more
+	}
+
+	// Deliver buffered chunks before the completion or error event
+	coalescer.Flush()
+	stats.duration += time.Since(start)
+	stats.err = err
+
+	a.finishJob(j, err)
+	if err != nil {
+		ce := toChatError(err)
+		event := ChatError{
+			StreamID:  id,
+			Error:     ce.Message,
+			Code:      ce.Code,
+			Status:    ce.Status,
+			Retryable: ce.Retryable,
+		}
+
+		// Keep the partial text so it is not lost and can be resumed later
+		historyID := ""
+		if isResumable(err, output) {
+			a.streamsMutex.Lock()
+			if a.interrupted == nil {
+				a.interrupted = make(map[string]*interruptedStream)
+			}
+			a.interrupted[id] = &interruptedStream{run: run, partial: output, stats: stats}
+			a.streamsMutex.Unlock()
+
+			event.Partial = output
+			event.Resumable = true
+		} else if ce.Code != ChatErrCancelled {
+			// Failed runs are logged too, resumable ones once they finish
+			historyID = a.recordHistory(run, output, stats)
+		}
+
+		a.emit("chat:error", event)
+		go a.notifyRun(run, historyID, output, stats.duration, err)
+		return err
+	}
+
+	historyID := a.recordHistory(run, output, stats)
+	a.emit("chat:complete", ChatComplete{StreamID: id, Output: output, HistoryID: historyID})
+	go a.notifyRun(run, historyID, output, stats.duration, nil)
+	return nil
+}
+
+// isResumable reports whether a failed stream produced output worth continuing
+func isResumable(err error, output string) bool {
+	if err == nil || output == "" {
+		return false
+	}
+	code := toChatError(err).Code
+	return code == ChatErrInterrupted || code == ChatErrNetwork
+}
+
+// continuationPrompt asks the model to carry on from where a response stopped
+func continuationPrompt(prompt PromptRequest, partial string) PromptRequest {
+	prompt.UserInput = prompt.UserInput +
+		"\n\n---\nYour previous response to this input was cut off. It is reproduced below. " +
+		"Continue exactly where it stops, without repeating any of it.\n\n" + partial
+	return prompt
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// defaultSuggestions is how many patterns SuggestPatterns returns by default
+const defaultSuggestions = 5
+
+// PatternSuggestion is a pattern recommended for an input
+type PatternSuggestion struct {
+	Pattern string `json:"pattern"`
+	Reason  string `json:"reason"`
+}
+
+// inputKind is a type of input and the patterns that suit it, best first.
+// The name completes "the input looks like ...".
+type inputKind struct {
+	name     string
+	detect   func(input string) bool
+	patterns []string
+}
+
+var (
+	youtubeURL      = regexp.MustCompile(`^https?://(www\.|m\.)?(youtube\.com|youtu\.be)/`)
+	githubPullURL   = regexp.MustCompile(`^https?://(www\.)?github\.com/[^/\s]+/[^/\s]+/(pull|compare)/\S+$`)
+	githubIssueURL  = regexp.MustCompile(`^https?://(www\.)?github\.com/[^/\s]+/[^/\s]+/issues/\d+\S*$`)
+	anyURL          = regexp.MustCompile(`^https?://\S+$`)
+	diffHeader      = regexp.MustCompile(`(?m)^(diff --git |@@ -\d+(,\d+)? \+\d+(,\d+)? @@)`)
+	codeLine        = regexp.MustCompile(`(?m)^\s*(func |def |class |import |package |#include|public |private |const |let |var |return\b|if \(|for \()|[{};]\s*$`)
+	logLine         = regexp.MustCompile(`(?m)^\S*\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}.*\b(ERROR|WARN|INFO|DEBUG|FATAL)\b|^\s+at \S+\(.*\)$|Traceback \(most recent call last\)|panic: `)
+	transcriptCue   = regexp.MustCompile(`(?m)^\s*(\[?\d{1,2}:\d{2}(:\d{2})?\]?|[A-Z][A-Za-z .]{1,30}:)\s`)
+	sentenceEndings = regexp.MustCompile(`[.!?]["')\]]?(\s|$)`)
+)
+
+// inputKinds are checked in order, the first match decides
+var inputKinds = []inputKind{
+	{"a YouTube video", func(s string) bool { return youtubeURL.MatchString(s) },
+		[]string{"youtube_summary", "extract_wisdom", "summarize", "extract_insights", "extract_recommendations"}},
+	{"a GitHub pull request", func(s string) bool { return githubPullURL.MatchString(s) },
+		[]string{"summarize_pull-request", "summarize_git_diff", "review_code", "explain_code"}},
+	{"a GitHub issue", func(s string) bool { return githubIssueURL.MatchString(s) },
+		[]string{"summarize", "extract_main_idea", "extract_recommendations", "analyze_claims"}},
+	{"a web page", func(s string) bool { return anyURL.MatchString(s) },
+		[]string{"summarize", "extract_article_wisdom", "analyze_claims", "extract_main_idea", "rate_content"}},
+	{"a diff", func(s string) bool { return diffHeader.MatchString(s) },
+		[]string{"summarize_git_diff", "create_git_diff_commit", "write_pull-request", "review_code"}},
+	{"log output", func(s string) bool { return len(logLine.FindAllString(s, 3)) >= 2 },
+		[]string{"analyze_logs", "analyze_incident", "explain_code", "summarize"}},
+	{"code", func(s string) bool { return matchRatio(codeLine, s) >= 0.3 },
+		[]string{"explain_code", "review_code", "improve_code", "create_coding_project", "summarize"}},
+	{"a transcript", func(s string) bool { return matchRatio(transcriptCue, s) >= 0.3 },
+		[]string{"extract_wisdom", "summarize_meeting", "summarize", "extract_insights", "extract_recommendations"}},
+	{"an article or essay", func(s string) bool {
+		return len(strings.Fields(s)) >= 200 && len(sentenceEndings.FindAllString(s, -1)) >= 8
+	}, []string{"summarize", "extract_wisdom", "analyze_claims", "extract_main_idea", "improve_writing", "rate_content"}},
+	{"a short prompt", func(s string) bool { return len(strings.Fields(s)) < 60 },
+		[]string{"ai", "improve_prompt", "improve_writing", "create_summary"}},
+}
+
+// matchRatio is the share of non-empty lines matching re
+func matchRatio(re *regexp.Regexp, s string) float64 {
+	lines, matched := 0, 0
+	for _, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines++
+		if re.MatchString(line) {
+			matched++
+		}
+	}
+	if lines == 0 {
+		return 0
+	}
+	return float64(matched) / float64(lines)
+}
+
+// detectInputKind returns the first input kind matching input
+func detectInputKind(input string) (inputKind, bool) {
+	for _, kind := range inputKinds {
+		if kind.detect(input) {
+			return kind, true
+		}
+	}
+	return inputKind{}, false
+}
+
+// SuggestPatterns recommends up to limit (default 5) installed patterns for
+// an input, judging by what the input looks like (a URL, code, a transcript,
+// an essay, ...) and topping up with the most used patterns
+func (a *App) SuggestPatterns(input string, limit int) ([]PatternSuggestion, error) {
+	if limit <= 0 {
+		limit = defaultSuggestions
+	}
+
+	entries, ok := a.patternIndex.get()
+	if !ok {
+		if _, err := a.GetPatterns(); err != nil {
+			return nil, err
+		}
+		entries, _ = a.patternIndex.get()
+	}
+	available := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		available[e.info.Name] = true
+	}
+
+	suggestions := []PatternSuggestion{}
+	added := map[string]bool{}
+	add := func(pattern, reason string) {
+		if len(suggestions) < limit && available[pattern] && !added[pattern] {
+			added[pattern] = true
+			suggestions = append(suggestions, PatternSuggestion{Pattern: pattern, Reason: reason})
+		}
+	}
+
+	if kind, ok := detectInputKind(strings.TrimSpace(input)); ok {
+		for _, pattern := range kind.patterns {
+			add(pattern, "the input looks like "+kind.name)
+		}
+	}
+	for _, st := range a.GetPatternStats("runs") {
+		add(st.Pattern, "one of your most used patterns")
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+const (
+	// slackMessageLimit stays under the 4000 characters Slack shows before
+	// truncating a message's text
+	slackMessageLimit = 3500
+	// discordMessageLimit is the most a Discord message can hold
+	discordMessageLimit = 2000
+	// teamChatPostDelay keeps a long output's messages within the incoming
+	// webhook rate limit of about one per second
+	teamChatPostDelay = time.Second
+)
+
+// SendToSlack posts content to the Slack incoming webhook, converted to
+// Slack's mrkdwn and split across messages when it is too long
+func (a *App) SendToSlack(content string) error {
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	if prefs.SlackWebhookURL == "" {
+		return fmt.Errorf("no Slack webhook URL configured")
+	}
+	messages := splitMessage(markdownToMrkdwn(content), slackMessageLimit)
+	if len(messages) == 0 {
+		return fmt.Errorf("no content to send")
+	}
+	return a.postTeamChat("Slack", prefs.SlackWebhookURL, messages, func(text string) any {
+		return map[string]any{"text": text, "mrkdwn": true}
+	})
+}
+
+// SendToDiscord posts content to the Discord webhook, split across messages
+// when it is too long. Discord renders markdown itself.
+func (a *App) SendToDiscord(content string) error {
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	if prefs.DiscordWebhookURL == "" {
+		return fmt.Errorf("no Discord webhook URL configured")
+	}
+	messages := splitMessage(strings.TrimSpace(content), discordMessageLimit)
+	if len(messages) == 0 {
+		return fmt.Errorf("no content to send")
+	}
+	return a.postTeamChat("Discord", prefs.DiscordWebhookURL, messages, func(text string) any {
+		// Mentions in model output must not ping the channel
+		return map[string]any{"content": text, "allowed_mentions": map[string]any{"parse": []string{}}}
+	})
+}
+
+// postTeamChat posts each message in order, waiting out rate limits
+func (a *App) postTeamChat(service, hookURL string, messages []string, payload func(string) any) error {
+	if u, err := url.Parse(hookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid %s webhook URL", service)
+	}
+	for i, message := range messages {
+		if i > 0 {
+			time.Sleep(teamChatPostDelay)
+		}
+		body, _ := json.Marshal(payload(message))
+		if err := a.postTeamChatMessage(hookURL, body); err != nil {
+			if len(messages) > 1 {
+				return fmt.Errorf("failed to post to %s (message %d of %d): %v", service, i+1, len(messages), err)
+			}
+			return fmt.Errorf("failed to post to %s: %v", service, err)
+		}
+	}
+	a.log.Info("posted output", "service", service, "messages", len(messages))
+	return nil
+}
+
+// postTeamChatMessage sends one message, retrying when rate limited
+func (a *App) postTeamChatMessage(hookURL string, body []byte) error {
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+		req, err := http.NewRequestWithContext(ctx, "POST", hookURL, bytes.NewReader(body))
+		if err != nil {
+			cancel()
+			return fmt.Errorf("failed to create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := a.externalClient().Do(req)
+		if err != nil {
+			cancel()
+			return err
+		}
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		cancel()
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= webhookRetries {
+			return fmt.Errorf("%d: %s", resp.StatusCode, strings.TrimSpace(string(text)))
+		}
+		delay := parseRetryAfter(resp.Header)
+		if delay <= 0 {
+			delay = defaultRetryDelay << attempt
+		}
+		time.Sleep(min(delay, maxRetryDelay))
+	}
+}
+
+// markdownToMrkdwn converts markdown to Slack's mrkdwn: *bold*, _italic_,
+// ~strike~ and <url|text> links. Headings become bold lines and tables
+// code blocks, as Slack has neither.
+func markdownToMrkdwn(source string) string {
+	var sb strings.Builder
+	blocks := markdownBlocksWith(source, mrkdwnInline)
+	for i, b := range blocks {
+		if i > 0 {
+			if b.Indent > 0 && blocks[i-1].Indent > 0 {
+				sb.WriteString("\n")
+			} else {
+				sb.WriteString("\n\n")
+			}
+		}
+
+		prefix := ""
+		if b.Indent > 0 {
+			prefix = strings.Repeat("    ", b.Indent-1)
+			if b.Marker != "" {
+				prefix += b.Marker + " "
+			} else {
+				prefix += "  "
+			}
+		}
+
+		var block string
+		switch b.Kind {
+		case mdHeading:
+			block = "*" + b.Text + "*"
+		case mdRule:
+			block = strings.Repeat("─", 20)
+		case mdCode:
+			block = "```\n" + slackEscape(b.Text) + "\n```"
+		case mdTable:
+			block = "```\n" + slackEscape(textTable(b.Rows)) + "\n```"
+		default:
+			block = indentLines(b.Text, prefix)
+		}
+		if b.Quote {
+			block = "> " + strings.ReplaceAll(block, "\n", "\n> ")
+		}
+		sb.WriteString(block)
+	}
+	return sb.String()
+}
+
+// mrkdwnInline renders inline markdown as mrkdwn
+func mrkdwnInline(n ast.Node, src []byte) string {
+	var sb strings.Builder
+	var walk func(ast.Node)
+	walk = func(n ast.Node) {
+		for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+			switch node := c.(type) {
+			case *ast.Text:
+				sb.WriteString(slackEscape(string(node.Segment.Value(src))))
+				if node.HardLineBreak() {
+					sb.WriteString("\n")
+				} else if node.SoftLineBreak() {
+					sb.WriteString(" ")
+				}
+			case *ast.String:
+				sb.WriteString(slackEscape(string(node.Value)))
+			case *ast.CodeSpan:
+				sb.WriteString("`" + slackEscape(inlineText(node, src)) + "`")
+			case *ast.Emphasis:
+				mark := "_"
+				if node.Level == 2 {
+					mark = "*"
+				}
+				sb.WriteString(mark)
+				walk(node)
+				sb.WriteString(mark)
+			case *east.Strikethrough:
+				sb.WriteString("~")
+				walk(node)
+				sb.WriteString("~")
+			case *ast.Link:
+				fmt.Fprintf(&sb, "<%s|%s>", slackEscape(string(node.Destination)), slackEscape(inlineText(node, src)))
+			case *ast.Image:
+				fmt.Fprintf(&sb, "<%s|%s>", slackEscape(string(node.Destination)), slackEscape(inlineText(node, src)))
+			case *ast.AutoLink:
+				fmt.Fprintf(&sb, "<%s>", slackEscape(string(node.URL(src))))
+			case *ast.RawHTML:
+				// Tag-like text in the output is shown as written
+				for i := 0; i < node.Segments.Len(); i++ {
+					segment := node.Segments.At(i)
+					sb.WriteString(slackEscape(string(segment.Value(src))))
+				}
+			case *east.TaskCheckBox:
+				if node.IsChecked {
+					sb.WriteString("☑ ")
+				} else {
+					sb.WriteString("☐ ")
+				}
+			default:
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return strings.TrimSpace(sb.String())
+}
+
+// slackEscape escapes the characters Slack reads as control sequences
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// splitMessage cuts text into messages of at most limit characters,
+// breaking at blank lines where possible. A break inside a code block
+// closes it and reopens it in the next message.
+func splitMessage(text string, limit int) []string {
+	var messages []string
+	var lines []string
+	lastBreak := -1 // index in lines of the last blank line outside a code block
+	fence := ""     // opening line of the code block lines end inside
+
+	emit := func(ls []string) {
+		if message := strings.Trim(strings.Join(ls, "\n"), "\n"); strings.TrimSpace(message) != "" {
+			messages = append(messages, message)
+		}
+	}
+	size := func(ls []string) int {
+		n := 0
+		for _, l := range ls {
+			n += utf8.RuneCountInString(l) + 1
+		}
+		return n
+	}
+
+	push := func(line string) {
+		reserve := 0
+		if fence != "" {
+			reserve = len("\n```")
+		}
+		for len(lines) > 0 && size(lines)+utf8.RuneCountInString(line)+reserve > limit {
+			if lastBreak > 0 {
+				emit(lines[:lastBreak])
+				lines = append([]string{}, lines[lastBreak+1:]...)
+				lastBreak = -1
+				continue
+			}
+			if fence != "" {
+				emit(append(lines, "```"))
+				lines = []string{fence}
+			} else {
+				emit(lines)
+				lines = nil
+			}
+			break
+		}
+		lines = append(lines, line)
+
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			if fence == "" {
+				fence = line
+			} else {
+				fence = ""
+			}
+		case fence == "" && trimmed == "":
+			lastBreak = len(lines) - 1
+		}
+	}
+
+	// Lines longer than half a message are cut, so one always fits after a
+	// reopened code block
+	maxLine := max(limit/2, 1)
+	for _, line := range strings.Split(text, "\n") {
+		for utf8.RuneCountInString(line) > maxLine {
+			runes := []rune(line)
+			push(string(runes[:maxLine]))
+			line = string(runes[maxLine:])
+		}
+		push(line)
+	}
+	emit(lines)
+	return messages
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// threadID returns the conversation an entry belongs to. The first turn of a
+// conversation is its own thread.
+func (e HistoryEntry) threadID() string {
+	if e.ThreadID != "" {
+		return e.ThreadID
+	}
+	return e.ID
+}
+
+// threadEntries returns the turns of a thread, oldest first
+func (a *App) threadEntries(threadID string) []HistoryEntry {
+	turns := []HistoryEntry{}
+	for _, e := range a.history.All() {
+		if e.threadID() == threadID {
+			turns = append(turns, e)
+		}
+	}
+	return turns
+}
+
+// GetThread returns the ordered exchange of the conversation containing the
+// given history entry
+func (a *App) GetThread(id string) ([]HistoryEntry, error) {
+	entry, ok := a.history.Find(id)
+	if !ok {
+		return nil, fmt.Errorf("history entry %s not found", id)
+	}
+	return a.threadEntries(entry.threadID()), nil
+}
+
+// ContinueThread sends a follow-up in the conversation containing the given
+// history entry, using the pattern and model of its latest turn, and returns
+// the stream ID
+func (a *App) ContinueThread(id, input string) (string, error) {
+	thread, err := a.GetThread(id)
+	if err != nil {
+		return "", err
+	}
+	last := thread[len(thread)-1]
+
+	vendor := last.Vendor
+	if vendor == "" {
+		if vendor, err = a.vendorForModel(last.Model); err != nil {
+			return "", err
+		}
+	}
+
+	prompt := a.newPrompt(last.Pattern, vendor, last.Model, input)
+	last.extras().apply(&prompt)
+
+	streamID, job, ctx := a.openStream(chatTitle(last.Pattern) + " (reply)")
+	go a.runStream(streamID, job, ctx, chatRun{
+		Prompt:   prompt,
+		ThreadID: last.threadID(),
+	})
+	return streamID, nil
+}
+
+// threadPrompt prefixes a reply with the earlier turns of its thread so the
+// model sees the whole conversation. A server-side session already holds them.
+func (a *App) threadPrompt(run chatRun) PromptRequest {
+	prompt := run.Prompt
+	if run.ThreadID == "" || prompt.SessionName != "" {
+		return prompt
+	}
+
+	turns := a.threadEntries(run.ThreadID)
+	if len(turns) == 0 {
+		return prompt
+	}
+
+	var sb strings.Builder
+	sb.WriteString("This is a follow-up in an ongoing conversation. The earlier exchange is reproduced below.\n\n")
+	for _, turn := range turns {
+		fmt.Fprintf(&sb, "User:\n%s\n\nAssistant:\n%s\n\n", turn.Input, turn.Output)
+	}
+	sb.WriteString("---\nFollow-up:\n\n")
+	sb.WriteString(prompt.UserInput)
+
+	prompt.UserInput = sb.String()
+	return prompt
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// History title modes
+const (
+	titlesHeuristic = "heuristic"
+	titlesModel     = "model"
+	titlesOff       = "off"
+)
+
+const (
+	// maxTitleLength is the longest title kept, in characters
+	maxTitleLength = 60
+	// titleTimeout bounds the background title request
+	titleTimeout = 30 * time.Second
+	// titleInputLimit is how much of the input and output is sent to the title model
+	titleInputLimit = 2000
+)
+
+// HistoryTitle is emitted as "history:titled" when a model-generated title is stored
+type HistoryTitle struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// historyTitleMode reads how history titles are produced from preferences
+func (a *App) historyTitleMode() string {
+	prefs, _ := a.loadPreferences()
+	switch prefs.HistoryTitles {
+	case titlesModel, titlesOff:
+		return prefs.HistoryTitles
+	default:
+		return titlesHeuristic
+	}
+}
+
+// heuristicTitle uses the first Markdown heading of the output, or failing
+// that the first line of the input
+func heuristicTitle(input, output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			if title := strings.TrimSpace(strings.TrimLeft(line, "#")); title != "" {
+				return shortenTitle(title)
+			}
+		}
+	}
+	for _, line := range strings.Split(input, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return shortenTitle(line)
+		}
+	}
+	return ""
+}
+
+// shortenTitle strips Markdown emphasis and cuts a title at a word boundary
+func shortenTitle(title string) string {
+	title = strings.Trim(strings.TrimSpace(title), "*_`\"'")
+	if utf8.RuneCountInString(title) <= maxTitleLength {
+		return title
+	}
+
+	runes := []rune(title)[:maxTitleLength]
+	cut := string(runes)
+	if i := strings.LastIndex(cut, " "); i > maxTitleLength/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:-") + "…"
+}
+
+// generateTitle asks the configured title model for a short title and stores
+// it on the entry, keeping the heuristic title if the request fails
+func (a *App) generateTitle(id string, entry HistoryEntry) {
+	prefs, _ := a.loadPreferences()
+	vendor, model := prefs.TitleVendor, prefs.TitleModel
+	if model == "" {
+		vendor, model = entry.Vendor, entry.Model
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), titleTimeout)
+	defer cancel()
+
+	output, err := a.streamChat(ctx, PromptRequest{
+		UserInput: "Write a title of at most eight words for the exchange below. " +
+			"Reply with the title only, without quotes or punctuation at the end.\n\n" +
+			"Input:\n" + truncateRunes(entry.Input, titleInputLimit) + "\n\n" +
+			"Output:\n" + truncateRunes(entry.Output, titleInputLimit),
+		Vendor: vendor,
+		Model:  model,
+	}, func(string) {}, func(streamUsage) {})
+	if err != nil {
+		return
+	}
+
+	title := shortenTitle(strings.SplitN(strings.TrimSpace(output), "\n", 2)[0])
+	if title == "" {
+		return
+	}
+	if a.history.Update(id, func(e *HistoryEntry) { e.Title = title }) {
+		a.emit("history:titled", HistoryTitle{ID: id, Title: title})
+	}
+}
+
+// truncateRunes cuts s to at most n characters
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
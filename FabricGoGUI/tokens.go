@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// TokenCount is an estimate of a text's size against a model's limits
+type TokenCount struct {
+	Tokens        int     `json:"tokens"`
+	ContextWindow int     `json:"contextWindow,omitempty"` // 0 when the model is unknown
+	Remaining     int     `json:"remaining,omitempty"`
+	Exceeds       bool    `json:"exceeds"`
+	InputCost     float64 `json:"inputCost,omitempty"` // US dollars
+}
+
+// CountTokens estimates the tokens text takes for model, and how that
+// compares with the model's context window when it is known
+func (a *App) CountTokens(model, text string) TokenCount {
+	return a.countTokens(model, estimateTokens(text))
+}
+
+func (a *App) countTokens(model string, tokens int) TokenCount {
+	count := TokenCount{Tokens: tokens}
+	if info := a.GetModelInfo(model); info != nil {
+		count.InputCost = float64(tokens) / 1000 * info.InputPer1K
+		if info.ContextWindow > 0 {
+			count.ContextWindow = info.ContextWindow
+			count.Remaining = max(info.ContextWindow-tokens, 0)
+			count.Exceeds = tokens > info.ContextWindow
+		}
+	}
+	return count
+}
+
+// checkContextWindow compares a chat's pattern, system prompt and input with
+// the model's context window. Oversized chats raise "chat:contextWarning",
+// or are refused when the preferences say so.
+func (a *App) checkContextWindow(prompt PromptRequest) error {
+	count := a.countTokens(prompt.Model, a.promptOverhead(prompt)+estimateTokens(prompt.UserInput))
+	if !count.Exceeds {
+		return nil
+	}
+	if prefs, err := a.loadPreferences(); err == nil && prefs.RefuseOversized {
+		return fmt.Errorf("the request is about %d tokens, more than the %d %s accepts", count.Tokens, count.ContextWindow, prompt.Model)
+	}
+	a.log.Warn("chat exceeds the context window", "model", prompt.Model, "tokens", count.Tokens, "window", count.ContextWindow)
+	a.emit("chat:contextWarning", count)
+	return nil
+}
+
+// promptOverhead estimates the tokens a chat adds around its input: the
+// pattern and any ad-hoc system prompt
+func (a *App) promptOverhead(prompt PromptRequest) int {
+	tokens := estimateTokens(prompt.SystemPrompt)
+	if prompt.PatternName != "" && (prompt.SystemPrompt == "" || prompt.SystemMode == SystemPromptPrepend) {
+		if p, err := a.GetPattern(prompt.PatternName); err == nil {
+			tokens += estimateTokens(p.System)
+		}
+	}
+	return tokens
+}
+
+// estimateTokens approximates how a BPE tokenizer splits text: common words
+// are a token each and long ones a token per five letters, numbers a token
+// per three digits, punctuation and line breaks a token each, and CJK text a
+// token per character.
+func estimateTokens(text string) int {
+	tokens := 0
+	letters, digits := 0, 0
+	flush := func() {
+		tokens += (letters+4)/5 + (digits+2)/3
+		letters, digits = 0, 0
+	}
+
+	for _, r := range text {
+		switch {
+		case r >= 0x2E80 && unicode.IsLetter(r):
+			flush()
+			tokens++
+		case unicode.IsLetter(r):
+			if digits > 0 {
+				flush()
+			}
+			letters++
+		case unicode.IsDigit(r):
+			if letters > 0 {
+				flush()
+			}
+			digits++
+		case r == ' ':
+			// A single space is part of the next word's token
+			flush()
+		case unicode.IsSpace(r):
+			flush()
+			if r == '\n' {
+				tokens++
+			}
+		default:
+			flush()
+			tokens++
+		}
+	}
+	flush()
+	return tokens
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// openAIWhisperURL is the hosted OpenAI transcription endpoint
+	openAIWhisperURL = "https://api.openai.com/v1/audio/transcriptions"
+	// whisperCppURL is the default address of a local whisper.cpp server
+	whisperCppURL = "http://localhost:8081/inference"
+	// openAIMaxAudioSize is the upload limit of the OpenAI transcription API
+	openAIMaxAudioSize = 25 * 1024 * 1024
+)
+
+// TranscribeProgress is emitted while an audio file is being uploaded
+type TranscribeProgress struct {
+	Path    string  `json:"path"`
+	Sent    int64   `json:"sent"`
+	Total   int64   `json:"total"`
+	Percent float64 `json:"percent"`
+}
+
+// progressReader reports how many bytes have been read from the wrapped reader
+type progressReader struct {
+	r      io.Reader
+	read   int64
+	report func(read int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	p.report(p.read)
+	return n, err
+}
+
+// TranscribeAudio sends an audio file to the configured Whisper endpoint and returns the transcript
+func (a *App) TranscribeAudio(path string) (string, error) {
+	prefs, _ := a.loadPreferences()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read audio file: %v", err)
+	}
+
+	provider, endpoint, err := whisperEndpoint(prefs)
+	if err != nil {
+		return "", err
+	}
+	if provider == "openai" && info.Size() > openAIMaxAudioSize {
+		return "", fmt.Errorf("audio file is %d MB, OpenAI accepts at most 25 MB", info.Size()/(1024*1024))
+	}
+
+	a.emit("transcribe:started", path)
+
+	job, ctx := a.startJob("transcribe", filepath.Base(path))
+	text, err := a.uploadAudio(ctx, job, endpoint, provider, prefs, path, info.Size())
+	a.finishJob(job, err)
+	if err != nil {
+		a.emit("transcribe:error", err.Error())
+		return "", err
+	}
+
+	a.emit("transcribe:complete", path)
+	return text, nil
+}
+
+// whisperEndpoint returns the configured transcription provider and the
+// URL to send audio to
+func whisperEndpoint(prefs *Preferences) (string, string, error) {
+	provider := prefs.WhisperProvider
+	if provider == "" {
+		provider = "openai"
+	}
+
+	endpoint := prefs.WhisperURL
+	switch provider {
+	case "openai":
+		if endpoint == "" {
+			endpoint = openAIWhisperURL
+		}
+		if prefs.WhisperAPIKey == "" {
+			return "", "", fmt.Errorf("an API key is required for OpenAI transcription")
+		}
+	case "whispercpp":
+		if endpoint == "" {
+			endpoint = whisperCppURL
+		}
+	default:
+		return "", "", fmt.Errorf("unknown transcription provider %q", provider)
+	}
+	return provider, endpoint, nil
+}
+
+// uploadAudio streams the audio file as multipart form data and decodes the transcript
+func (a *App) uploadAudio(ctx context.Context, job *job, endpoint, provider string, prefs *Preferences, path string, size int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open audio file: %v", err)
+	}
+	defer f.Close()
+
+	// Stream the multipart body so large recordings are not held in memory
+	pr, pw := io.Pipe()
+	form := multipart.NewWriter(pw)
+
+	// Built first, since nothing would read the pipe if it failed
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, pr)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if prefs.WhisperAPIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+prefs.WhisperAPIKey)
+	}
+
+	go func() {
+		part, err := form.CreateFormFile("file", filepath.Base(path))
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+
+		// Only emit when the whole percentage changes to avoid flooding the frontend
+		lastPercent := -1
+		body := &progressReader{r: f, report: func(read int64) {
+			percent := float64(read) / float64(max(size, 1)) * 100
+			if int(percent) == lastPercent {
+				return
+			}
+			lastPercent = int(percent)
+			a.updateJob(job, percent, "Uploading")
+			a.emit("transcribe:progress", TranscribeProgress{
+				Path:    path,
+				Sent:    read,
+				Total:   size,
+				Percent: percent,
+			})
+		}}
+		if _, err := io.Copy(part, body); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+
+		model := prefs.WhisperModel
+		if model == "" && provider == "openai" {
+			model = "whisper-1"
+		}
+		if model != "" {
+			form.WriteField("model", model)
+		}
+		if prefs.WhisperLanguage != "" {
+			form.WriteField("language", prefs.WhisperLanguage)
+		}
+		form.WriteField("response_format", "json")
+
+		pw.CloseWithError(form.Close())
+
+		// Upload finished, the server is now transcribing
+		a.updateJob(job, -1, "Transcribing")
+		a.emit("transcribe:processing", path)
+	}()
+
+	resp, err := a.externalClient().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send audio: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("transcription error %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to parse transcript: %v", err)
+	}
+
+	return strings.TrimSpace(result.Text), nil
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// TLSOptions configures how a profile's HTTPS connections are verified
+type TLSOptions struct {
+	CAFile             string `json:"caFile,omitempty"`   // PEM bundle trusted in addition to the system roots
+	CertFile           string `json:"certFile,omitempty"` // client certificate for mutual TLS
+	KeyFile            string `json:"keyFile,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"` // accept any certificate, never on untrusted networks
+}
+
+// Default timeouts, used for any TimeoutOptions field left at zero
+const (
+	defaultDialTimeout           = 10 * time.Second
+	defaultTLSHandshakeTimeout   = 10 * time.Second
+	defaultResponseHeaderTimeout = 5 * time.Minute // transcription and model loading can take a while
+	defaultStreamIdleTimeout     = 2 * time.Minute
+	defaultKeepAlive             = 30 * time.Second
+	defaultIdleConnTimeout       = 90 * time.Second
+	defaultMaxIdleConns          = 10
+)
+
+// TimeoutOptions tunes a profile's connections. Zero values use the defaults.
+type TimeoutOptions struct {
+	DialSeconds           int `json:"dialSeconds,omitempty"`
+	TLSHandshakeSeconds   int `json:"tlsHandshakeSeconds,omitempty"`
+	ResponseHeaderSeconds int `json:"responseHeaderSeconds,omitempty"` // wait for the server to start answering
+	StreamIdleSeconds     int `json:"streamIdleSeconds,omitempty"`     // abort a stream that sends nothing for this long
+	KeepAliveSeconds      int `json:"keepAliveSeconds,omitempty"`
+	IdleConnSeconds       int `json:"idleConnSeconds,omitempty"` // close pooled connections unused for this long
+	MaxIdleConns          int `json:"maxIdleConns,omitempty"`
+	MaxConnsPerHost       int `json:"maxConnsPerHost,omitempty"` // 0 means unlimited
+}
+
+// seconds converts a setting to a duration, falling back to def when unset
+func seconds(value int, def time.Duration) time.Duration {
+	if value <= 0 {
+		return def
+	}
+	return time.Duration(value) * time.Second
+}
+
+// streamIdle returns how long a stream may go without data
+func (o TimeoutOptions) streamIdle() time.Duration {
+	return seconds(o.StreamIdleSeconds, defaultStreamIdleTimeout)
+}
+
+// Proxy modes
+const (
+	proxySystem = "system"
+	proxyManual = "manual"
+	proxyNone   = "none"
+)
+
+// newHTTPClient builds the client used to talk to a profile's Fabric server.
+// The proxy settings come from preferences and apply to every request.
+func newHTTPClient(profile ConnectionProfile, prefs *Preferences) (*http.Client, error) {
+	t := profile.Timeouts
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.DialContext = (&net.Dialer{
+		Timeout:   seconds(t.DialSeconds, defaultDialTimeout),
+		KeepAlive: seconds(t.KeepAliveSeconds, defaultKeepAlive),
+	}).DialContext
+	base.TLSHandshakeTimeout = seconds(t.TLSHandshakeSeconds, defaultTLSHandshakeTimeout)
+	base.ResponseHeaderTimeout = seconds(t.ResponseHeaderSeconds, defaultResponseHeaderTimeout)
+	base.IdleConnTimeout = seconds(t.IdleConnSeconds, defaultIdleConnTimeout)
+	base.MaxIdleConns = defaultMaxIdleConns
+	if t.MaxIdleConns > 0 {
+		base.MaxIdleConns = t.MaxIdleConns
+	}
+	base.MaxIdleConnsPerHost = base.MaxIdleConns
+	base.MaxConnsPerHost = t.MaxConnsPerHost
+
+	proxy, err := proxyFunc(prefs)
+	if err != nil {
+		return nil, err
+	}
+	base.Proxy = proxy
+
+	tlsConfig, err := profile.TLS.config()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		base.TLSClientConfig = tlsConfig
+	}
+
+	var transport http.RoundTripper = base
+	if profile.APIKey != "" {
+		if u, err := url.Parse(profile.BaseURL); err == nil {
+			transport = &apiKeyTransport{base: transport, host: u.Host, apiKey: profile.APIKey}
+		}
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   0, // Streams can run for minutes, stalls are caught by the stream idle timeout
+	}, nil
+}
+
+// newExternalClient builds the client for everything that is not the Fabric
+// server: installs and updates, vendor APIs, webhooks, feeds and web pages.
+// It shares the proxy settings but never a profile's certificates, API key
+// or disabled verification, which are only meant for that one server.
+func newExternalClient(prefs *Preferences) (*http.Client, error) {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	proxy, err := proxyFunc(prefs)
+	if err != nil {
+		return nil, err
+	}
+	base.Proxy = proxy
+	base.ResponseHeaderTimeout = defaultResponseHeaderTimeout
+	return &http.Client{Transport: base}, nil
+}
+
+// publicClient returns a copy of client that only connects to public
+// addresses. The address is checked as it is dialled, after resolving, so a
+// host cannot pass the check and then resolve to a local address. A proxy
+// resolves the host itself, so through one the host is resolved and checked
+// beforehand instead.
+func publicClient(client *http.Client) *http.Client {
+	base, ok := client.Transport.(*http.Transport)
+	if !ok {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	transport := base.Clone()
+
+	var proxies sync.Map // proxy addresses, which are dialled unchecked
+	if proxy := transport.Proxy; proxy != nil {
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			u, err := proxy(req)
+			if err != nil || u == nil {
+				return u, err
+			}
+			if err := checkPublicHost(req.Context(), req.URL.Hostname()); err != nil {
+				return nil, err
+			}
+			proxies.Store(proxyAddr(u), true)
+			return u, nil
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: defaultDialTimeout, KeepAlive: defaultKeepAlive}
+	public := &net.Dialer{Timeout: defaultDialTimeout, KeepAlive: defaultKeepAlive,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip, err := netip.ParseAddr(host)
+			if err != nil {
+				return err
+			}
+			if !isPublicIP(ip) {
+				return fmt.Errorf("%s is a local address, links can only fetch public pages", host)
+			}
+			return nil
+		},
+	}
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if _, ok := proxies.Load(addr); ok {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		return public.DialContext(ctx, network, addr)
+	}
+
+	c := *client
+	c.Transport = transport
+	return &c
+}
+
+// proxyAddr returns the host and port a proxy is dialled at
+func proxyAddr(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	switch u.Scheme {
+	case "https":
+		return net.JoinHostPort(u.Hostname(), "443")
+	case "socks5", "socks5h":
+		return net.JoinHostPort(u.Hostname(), "1080")
+	}
+	return net.JoinHostPort(u.Hostname(), "80")
+}
+
+// checkPublicHost refuses a host that is, or resolves to, an address
+// isPublicIP refuses
+func checkPublicHost(ctx context.Context, host string) error {
+	var ips []netip.Addr
+	if ip, err := netip.ParseAddr(host); err == nil {
+		ips = []netip.Addr{ip}
+	} else {
+		addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return fmt.Errorf("failed to look up %s: %v", host, err)
+		}
+		ips = addrs
+	}
+	for _, ip := range ips {
+		if !isPublicIP(ip) {
+			return fmt.Errorf("%s is a local address, links can only fetch public pages", host)
+		}
+	}
+	return nil
+}
+
+// localPrefixes are ranges beyond loopback, private, link-local and
+// multicast ones that reach local networks, or that embed an IPv4 address
+// which may be one
+var localPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // this network
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved, and broadcast
+	netip.MustParsePrefix("fec0::/10"),      // site-local, deprecated
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+	netip.MustParsePrefix("2001::/32"),      // Teredo
+	netip.MustParsePrefix("2002::/16"),      // 6to4
+}
+
+// isPublicIP reports whether ip is an address on the internet rather than
+// this machine or a local network. IPv6 unique local addresses (fc00::/7)
+// count as private.
+func isPublicIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsMulticast() {
+		return false
+	}
+	for _, prefix := range localPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// proxyFunc returns how requests pick a proxy. The system mode follows the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+func proxyFunc(prefs *Preferences) (func(*http.Request) (*url.URL, error), error) {
+	switch prefs.ProxyMode {
+	case proxyNone:
+		return nil, nil
+	case proxyManual:
+		u, err := url.Parse(prefs.ProxyURL)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", prefs.ProxyURL)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("unsupported proxy scheme %q, use http, https or socks5", u.Scheme)
+		}
+
+		config := httpproxy.Config{HTTPProxy: prefs.ProxyURL, HTTPSProxy: prefs.ProxyURL, NoProxy: prefs.NoProxy}
+		proxy := config.ProxyFunc()
+		return func(req *http.Request) (*url.URL, error) {
+			return proxy(req.URL)
+		}, nil
+	case proxySystem, "":
+		return http.ProxyFromEnvironment, nil
+	default:
+		return nil, fmt.Errorf("unknown proxy mode %q", prefs.ProxyMode)
+	}
+}
+
+// config builds a tls.Config from the options, or returns nil when the
+// defaults apply
+func (o TLSOptions) config() (*tls.Config, error) {
+	if o.CAFile == "" && o.CertFile == "" && o.KeyFile == "" && !o.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	config := &tls.Config{InsecureSkipVerify: o.InsecureSkipVerify}
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", o.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if o.CertFile != "" || o.KeyFile != "" {
+		if o.CertFile == "" || o.KeyFile == "" {
+			return nil, fmt.Errorf("a client certificate needs both a certificate and a key file")
+		}
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// apiKeyTransport adds the Fabric API key to requests for the profile's
+// server. Other hosts, such as transcription and OCR endpoints, never see it.
+type apiKeyTransport struct {
+	base   http.RoundTripper
+	host   string
+	apiKey string
+}
+
+func (t *apiKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.host {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("X-API-Key", t.apiKey)
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	goruntime "runtime"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// TraySettings configure the system tray icon
+type TraySettings struct {
+	Enabled     bool   `json:"enabled"`     // show the tray icon
+	CloseToTray bool   `json:"closeToTray"` // closing the window hides it while the icon is shown
+	Preset      string `json:"preset"`      // preset the tray runs on the clipboard
+}
+
+// TrayStatus describes the tray icon
+type TrayStatus struct {
+	Supported bool   `json:"supported"`
+	Running   bool   `json:"running"`
+	Error     string `json:"error,omitempty"` // why the icon could not be shown
+}
+
+//go:embed build/appicon.png
+var trayIconPNG []byte
+
+// GetTraySettings returns the tray settings
+func (a *App) GetTraySettings() TraySettings {
+	if prefs, err := a.loadPreferences(); err == nil {
+		return prefs.Tray
+	}
+	return TraySettings{}
+}
+
+// SaveTraySettings stores the tray settings and shows or removes the icon
+// to match
+func (a *App) SaveTraySettings(settings TraySettings) error {
+	if settings.Preset != "" {
+		if _, err := a.GetPreset(settings.Preset); err != nil {
+			return err
+		}
+	}
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	prefs.Tray = settings
+	if err := a.SavePreferences(*prefs); err != nil {
+		return err
+	}
+
+	if !settings.Enabled {
+		a.stopTray()
+		return nil
+	}
+	if err := a.startTray(); err != nil {
+		return err
+	}
+	a.refreshTray()
+	return nil
+}
+
+// GetTrayStatus reports whether the tray icon is shown
+func (a *App) GetTrayStatus() TrayStatus {
+	a.trayMutex.Lock()
+	defer a.trayMutex.Unlock()
+	return TrayStatus{Supported: goruntime.GOOS == "linux", Running: a.tray != nil, Error: a.trayError}
+}
+
+// startTray shows the tray icon if it is not shown yet. Only Linux desktops
+// that implement StatusNotifierItem (KDE, and GNOME with the AppIndicator
+// extension) are supported so far.
+func (a *App) startTray() error {
+	a.trayMutex.Lock()
+	defer a.trayMutex.Unlock()
+	if a.tray != nil {
+		return nil
+	}
+	if goruntime.GOOS != "linux" {
+		a.trayError = fmt.Sprintf("the tray icon is not supported on %s yet", goruntime.GOOS)
+		return fmt.Errorf("%s", a.trayError)
+	}
+
+	tray, err := newStatusNotifier(a, trayIconPNG)
+	if err != nil {
+		a.trayError = err.Error()
+		a.log.Warn("failed to show tray icon", "error", err)
+		return err
+	}
+	a.tray = tray
+	a.trayError = ""
+	a.log.Info("showing tray icon")
+	return nil
+}
+
+// stopTray removes the tray icon
+func (a *App) stopTray() {
+	a.trayMutex.Lock()
+	tray := a.tray
+	a.tray = nil
+	a.trayMutex.Unlock()
+	if tray != nil {
+		tray.close()
+	}
+}
+
+// refreshTray updates the tray menu after the server or settings changed
+func (a *App) refreshTray() {
+	a.trayMutex.Lock()
+	defer a.trayMutex.Unlock()
+	if a.tray != nil {
+		a.tray.refresh()
+	}
+}
+
+// trayRunning reports whether the tray icon is shown
+func (a *App) trayRunning() bool {
+	a.trayMutex.Lock()
+	defer a.trayMutex.Unlock()
+	return a.tray != nil
+}
+
+// beforeClose hides the window instead of quitting when it closes to the
+// tray, so the app keeps running in the background. Quitting from the tray
+// menu or for an update goes through quit and is let through.
+func (a *App) beforeClose(ctx context.Context) bool {
+	if a.quitting.Load() || !a.trayRunning() || !a.GetTraySettings().CloseToTray {
+		return false
+	}
+	runtime.WindowHide(ctx)
+	return true
+}
+
+// quit closes the app, even when closing the window only hides it
+func (a *App) quit() {
+	a.quitting.Store(true)
+	runtime.Quit(a.ctx)
+}
+
+// showWindow brings the main window back from the tray
+func (a *App) showWindow() {
+	runtime.WindowShow(a.ctx)
+	runtime.WindowUnminimise(a.ctx)
+}
+
+// toggleServer starts the Fabric server, or stops it when running
+func (a *App) toggleServer() {
+	var err error
+	if a.IsServerRunning() {
+		err = a.StopServer()
+	} else {
+		err = a.StartServer()
+	}
+	if err != nil {
+		a.log.Warn("tray server action failed", "error", err)
+		a.emit("server:error", err.Error())
+	}
+}
+
+// runPresetOnClipboard runs a preset on the clipboard text in the
+// background and copies the result back to the clipboard. Failures are also
+// reported as quick:error events.
+func (a *App) runPresetOnClipboard(name string) error {
+	prompt, err := a.clipboardPresetPrompt(name)
+	if err != nil {
+		a.emit("quick:error", err.Error())
+		return err
+	}
+	output, err := a.runQuick(prompt, name, nil)
+	if err != nil {
+		return err
+	}
+	return a.WriteClipboard(output)
+}
+
+// clipboardPresetPrompt builds the prompt for running a preset on the
+// clipboard text
+func (a *App) clipboardPresetPrompt(name string) (PromptRequest, error) {
+	text, err := a.ReadClipboard()
+	if err != nil {
+		return PromptRequest{}, err
+	}
+	if strings.TrimSpace(text) == "" {
+		return PromptRequest{}, fmt.Errorf("the clipboard is empty")
+	}
+	return a.presetPrompt(name, text)
+}
+
+// presetPrompt builds the prompt for running a preset on text
+func (a *App) presetPrompt(name, text string) (PromptRequest, error) {
+	preset, err := a.GetPreset(name)
+	if err != nil {
+		return PromptRequest{}, err
+	}
+	vendor := preset.Vendor
+	if vendor == "" {
+		if vendor, err = a.vendorForModel(preset.Model); err != nil {
+			return PromptRequest{}, err
+		}
+	}
+	prompt := a.newPrompt(preset.Pattern, vendor, preset.Model, expandPresetInput(preset.InputTemplate, text))
+	prompt.Options = preset.Params
+	return prompt, nil
+}
+
+// runTrayPreset is the tray's "Run preset on clipboard" action
+func (a *App) runTrayPreset() {
+	name := a.GetTraySettings().Preset
+	if name == "" {
+		return
+	}
+	if err := a.runPresetOnClipboard(name); err != nil {
+		a.log.Warn("tray preset failed", "preset", name, "error", err)
+	}
+}
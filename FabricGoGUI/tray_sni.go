@@ -0,0 +1,468 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+)
+
+// The tray icon is a StatusNotifierItem with a com.canonical.dbusmenu menu,
+// the D-Bus protocols KDE and the GNOME AppIndicator extension implement
+const (
+	sniPath        = dbus.ObjectPath("/StatusNotifierItem")
+	sniInterface   = "org.kde.StatusNotifierItem"
+	sniWatcherName = "org.kde.StatusNotifierWatcher"
+	sniWatcherPath = dbus.ObjectPath("/StatusNotifierWatcher")
+	trayMenuPath   = dbus.ObjectPath("/MenuBar")
+	trayMenuIface  = "com.canonical.dbusmenu"
+)
+
+// Tray menu item IDs, 0 is the root
+const (
+	trayItemOpen int32 = iota + 1
+	trayItemMini
+	trayItemPreset
+	trayItemServerSeparator
+	trayItemServer
+	trayItemQuitSeparator
+	trayItemQuit
+)
+
+// trayIconSizes are the pixmap sizes offered to the tray host
+var trayIconSizes = []int{32, 64}
+
+// statusNotifier is the tray icon exported on the session bus
+type statusNotifier struct {
+	app      *App
+	conn     *dbus.Conn
+	name     string
+	mutex    sync.Mutex
+	revision uint32
+}
+
+// sniPixmap is the a(iiay) icon format: ARGB32 in network byte order
+type sniPixmap struct {
+	Width  int32
+	Height int32
+	Data   []byte
+}
+
+// sniToolTip is the (sa(iiay)ss) tooltip format
+type sniToolTip struct {
+	IconName    string
+	IconPixmap  []sniPixmap
+	Title       string
+	Description string
+}
+
+// trayMenuLayout is the (ia{sv}av) dbusmenu layout format
+type trayMenuLayout struct {
+	ID         int32
+	Properties map[string]dbus.Variant
+	Children   []dbus.Variant
+}
+
+// trayMenuItem is the (ia{sv}) dbusmenu item properties format
+type trayMenuItem struct {
+	ID         int32
+	Properties map[string]dbus.Variant
+}
+
+// trayMenuEvent is the (isvu) dbusmenu event format
+type trayMenuEvent struct {
+	ID        int32
+	EventID   string
+	Data      dbus.Variant
+	Timestamp uint32
+}
+
+// newStatusNotifier exports the tray icon and menu and registers them with
+// the desktop's tray host
+func newStatusNotifier(a *App, iconPNG []byte) (*statusNotifier, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the session bus: %v", err)
+	}
+	s := &statusNotifier{
+		app:      a,
+		conn:     conn,
+		name:     fmt.Sprintf("org.kde.StatusNotifierItem-%d-1", os.Getpid()),
+		revision: 1,
+	}
+	if err := s.export(iconPNG); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := s.register(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	go s.watch()
+	return s, nil
+}
+
+// export publishes the item and its menu on the bus
+func (s *statusNotifier) export(iconPNG []byte) error {
+	item := &sniItem{app: s.app}
+	if err := s.conn.Export(item, sniPath, sniInterface); err != nil {
+		return fmt.Errorf("failed to export tray icon: %v", err)
+	}
+	pixmaps := trayPixmaps(iconPNG)
+	itemProps, err := prop.Export(s.conn, sniPath, prop.Map{sniInterface: {
+		"Category":            {Value: "ApplicationStatus"},
+		"Id":                  {Value: "fabric-gui"},
+		"Title":               {Value: "Fabric GUI"},
+		"Status":              {Value: "Active"},
+		"WindowId":            {Value: int32(0)},
+		"IconName":            {Value: ""},
+		"IconThemePath":       {Value: ""},
+		"IconPixmap":          {Value: pixmaps},
+		"OverlayIconName":     {Value: ""},
+		"OverlayIconPixmap":   {Value: []sniPixmap{}},
+		"AttentionIconName":   {Value: ""},
+		"AttentionIconPixmap": {Value: []sniPixmap{}},
+		"AttentionMovieName":  {Value: ""},
+		"ToolTip":             {Value: sniToolTip{IconPixmap: []sniPixmap{}, Title: "Fabric GUI"}},
+		"ItemIsMenu":          {Value: false},
+		"Menu":                {Value: trayMenuPath},
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to export tray icon: %v", err)
+	}
+	itemNode := &introspect.Node{
+		Name: string(sniPath),
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			prop.IntrospectData,
+			{Name: sniInterface, Methods: introspect.Methods(item), Properties: itemProps.Introspection(sniInterface)},
+		},
+	}
+	if err := s.conn.Export(introspect.NewIntrospectable(itemNode), sniPath, "org.freedesktop.DBus.Introspectable"); err != nil {
+		return fmt.Errorf("failed to export tray icon: %v", err)
+	}
+
+	menu := &trayMenu{s: s}
+	if err := s.conn.Export(menu, trayMenuPath, trayMenuIface); err != nil {
+		return fmt.Errorf("failed to export tray menu: %v", err)
+	}
+	menuProps, err := prop.Export(s.conn, trayMenuPath, prop.Map{trayMenuIface: {
+		"Version":       {Value: uint32(3)},
+		"TextDirection": {Value: "ltr"},
+		"Status":        {Value: "normal"},
+		"IconThemePath": {Value: []string{}},
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to export tray menu: %v", err)
+	}
+	menuNode := &introspect.Node{
+		Name: string(trayMenuPath),
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			prop.IntrospectData,
+			{
+				Name:       trayMenuIface,
+				Methods:    introspect.Methods(menu),
+				Properties: menuProps.Introspection(trayMenuIface),
+				Signals: []introspect.Signal{{
+					Name: "LayoutUpdated",
+					Args: []introspect.Arg{{Name: "revision", Type: "u"}, {Name: "parent", Type: "i"}},
+				}},
+			},
+		},
+	}
+	if err := s.conn.Export(introspect.NewIntrospectable(menuNode), trayMenuPath, "org.freedesktop.DBus.Introspectable"); err != nil {
+		return fmt.Errorf("failed to export tray menu: %v", err)
+	}
+
+	reply, err := s.conn.RequestName(s.name, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		return fmt.Errorf("failed to claim %s: %v", s.name, err)
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		return fmt.Errorf("failed to claim %s: already taken", s.name)
+	}
+	return nil
+}
+
+// register announces the item to the tray host
+func (s *statusNotifier) register() error {
+	watcher := s.conn.Object(sniWatcherName, sniWatcherPath)
+	if call := watcher.Call(sniWatcherName+".RegisterStatusNotifierItem", 0, s.name); call.Err != nil {
+		return fmt.Errorf("no system tray found, the desktop needs StatusNotifierItem support: %v", call.Err)
+	}
+	return nil
+}
+
+// watch registers the item again whenever the tray host restarts, e.g.
+// when the panel crashes or the user logs into a new shell session
+func (s *statusNotifier) watch() {
+	err := s.conn.AddMatchSignal(
+		dbus.WithMatchInterface("org.freedesktop.DBus"),
+		dbus.WithMatchMember("NameOwnerChanged"),
+		dbus.WithMatchArg(0, sniWatcherName),
+	)
+	if err != nil {
+		s.app.log.Warn("failed to watch tray host", "error", err)
+		return
+	}
+	signals := make(chan *dbus.Signal, 4)
+	s.conn.Signal(signals)
+	// The channel is closed when the connection is
+	for signal := range signals {
+		if signal.Name != "org.freedesktop.DBus.NameOwnerChanged" || len(signal.Body) != 3 {
+			continue
+		}
+		if owner, _ := signal.Body[2].(string); owner != "" {
+			if err := s.register(); err != nil {
+				s.app.log.Warn("failed to register tray icon again", "error", err)
+			}
+		}
+	}
+}
+
+// refresh tells the tray host to fetch the menu again
+func (s *statusNotifier) refresh() {
+	s.mutex.Lock()
+	s.revision++
+	revision := s.revision
+	s.mutex.Unlock()
+	s.conn.Emit(trayMenuPath, trayMenuIface+".LayoutUpdated", revision, int32(0))
+}
+
+// close removes the icon; the tray host drops it once the bus name is gone
+func (s *statusNotifier) close() {
+	s.conn.Close()
+}
+
+// sniItem implements the org.kde.StatusNotifierItem methods
+type sniItem struct {
+	app *App
+}
+
+// Activate is a click on the icon
+func (i *sniItem) Activate(x, y int32) *dbus.Error {
+	go i.app.showWindow()
+	return nil
+}
+
+// SecondaryActivate is a middle click on the icon
+func (i *sniItem) SecondaryActivate(x, y int32) *dbus.Error {
+	go i.app.runTrayPreset()
+	return nil
+}
+
+// ContextMenu is only called by hosts that cannot show Menu themselves
+func (i *sniItem) ContextMenu(x, y int32) *dbus.Error {
+	go i.app.showWindow()
+	return nil
+}
+
+// Scroll is a mouse wheel turn over the icon
+func (i *sniItem) Scroll(delta int32, orientation string) *dbus.Error {
+	return nil
+}
+
+// trayMenu implements the com.canonical.dbusmenu methods. The menu is flat
+// and rebuilt on every request, so it always shows the current state.
+type trayMenu struct {
+	s *statusNotifier
+}
+
+// items builds the menu entries
+func (m *trayMenu) items() []trayMenuItem {
+	preset := m.s.app.GetTraySettings().Preset
+	presetLabel := "Run preset on clipboard"
+	if preset != "" {
+		presetLabel = fmt.Sprintf("Run %s on clipboard", preset)
+	}
+	miniLabel := "Quick capture"
+	if m.s.app.IsMiniWindow() {
+		miniLabel = "Full window"
+	}
+	serverLabel := "Start server"
+	if m.s.app.IsServerRunning() {
+		serverLabel = "Stop server"
+	}
+	return []trayMenuItem{
+		{trayItemOpen, trayMenuLabel("Open Fabric GUI", true)},
+		{trayItemMini, trayMenuLabel(miniLabel, true)},
+		{trayItemPreset, trayMenuLabel(presetLabel, preset != "")},
+		{trayItemServerSeparator, map[string]dbus.Variant{"type": dbus.MakeVariant("separator")}},
+		{trayItemServer, trayMenuLabel(serverLabel, true)},
+		{trayItemQuitSeparator, map[string]dbus.Variant{"type": dbus.MakeVariant("separator")}},
+		{trayItemQuit, trayMenuLabel("Quit", true)},
+	}
+}
+
+// trayMenuLabel returns the properties of a plain menu entry
+func trayMenuLabel(label string, enabled bool) map[string]dbus.Variant {
+	// dbusmenu reads _ as a mnemonic marker
+	return map[string]dbus.Variant{
+		"label":   dbus.MakeVariant(strings.ReplaceAll(label, "_", "__")),
+		"enabled": dbus.MakeVariant(enabled),
+	}
+}
+
+// GetLayout returns the menu tree below parentID
+func (m *trayMenu) GetLayout(parentID, recursionDepth int32, propertyNames []string) (uint32, trayMenuLayout, *dbus.Error) {
+	m.s.mutex.Lock()
+	revision := m.s.revision
+	m.s.mutex.Unlock()
+
+	items := m.items()
+	if parentID != 0 {
+		for _, item := range items {
+			if item.ID == parentID {
+				return revision, trayMenuLayout{ID: item.ID, Properties: item.Properties, Children: []dbus.Variant{}}, nil
+			}
+		}
+		return 0, trayMenuLayout{}, dbus.MakeFailedError(fmt.Errorf("unknown menu item %d", parentID))
+	}
+
+	root := trayMenuLayout{
+		Properties: map[string]dbus.Variant{"children-display": dbus.MakeVariant("submenu")},
+		Children:   []dbus.Variant{},
+	}
+	if recursionDepth != 0 {
+		for _, item := range items {
+			root.Children = append(root.Children, dbus.MakeVariant(trayMenuLayout{
+				ID:         item.ID,
+				Properties: item.Properties,
+				Children:   []dbus.Variant{},
+			}))
+		}
+	}
+	return revision, root, nil
+}
+
+// GetGroupProperties returns the properties of the given items, or of all
+// items when ids is empty
+func (m *trayMenu) GetGroupProperties(ids []int32, propertyNames []string) ([]trayMenuItem, *dbus.Error) {
+	result := []trayMenuItem{}
+	for _, item := range m.items() {
+		for _, id := range ids {
+			if id == item.ID {
+				result = append(result, item)
+				break
+			}
+		}
+		if len(ids) == 0 {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// GetProperty returns one property of an item
+func (m *trayMenu) GetProperty(id int32, name string) (dbus.Variant, *dbus.Error) {
+	for _, item := range m.items() {
+		if item.ID != id {
+			continue
+		}
+		if value, ok := item.Properties[name]; ok {
+			return value, nil
+		}
+		return dbus.Variant{}, dbus.MakeFailedError(fmt.Errorf("menu item %d has no property %s", id, name))
+	}
+	return dbus.Variant{}, dbus.MakeFailedError(fmt.Errorf("unknown menu item %d", id))
+}
+
+// Event handles a click on an item
+func (m *trayMenu) Event(id int32, eventID string, data dbus.Variant, timestamp uint32) *dbus.Error {
+	if eventID == "clicked" {
+		go m.activate(id)
+	}
+	return nil
+}
+
+// EventGroup handles several events at once
+func (m *trayMenu) EventGroup(events []trayMenuEvent) ([]int32, *dbus.Error) {
+	for _, event := range events {
+		m.Event(event.ID, event.EventID, event.Data, event.Timestamp)
+	}
+	return []int32{}, nil
+}
+
+// AboutToShow is called before the menu opens. The layout is refreshed when
+// the state changes, so it never needs an update here.
+func (m *trayMenu) AboutToShow(id int32) (bool, *dbus.Error) {
+	return false, nil
+}
+
+// AboutToShowGroup is AboutToShow for several items
+func (m *trayMenu) AboutToShowGroup(ids []int32) ([]int32, []int32, *dbus.Error) {
+	return []int32{}, []int32{}, nil
+}
+
+// activate runs a menu item's action
+func (m *trayMenu) activate(id int32) {
+	a := m.s.app
+	switch id {
+	case trayItemOpen:
+		a.showWindow()
+	case trayItemMini:
+		a.ToggleMiniWindow()
+	case trayItemPreset:
+		a.runTrayPreset()
+	case trayItemServer:
+		a.toggleServer()
+	case trayItemQuit:
+		a.quit()
+	}
+}
+
+// trayPixmaps scales the app icon down to the sizes tray hosts draw
+func trayPixmaps(iconPNG []byte) []sniPixmap {
+	img, err := png.Decode(bytes.NewReader(iconPNG))
+	if err != nil {
+		return []sniPixmap{}
+	}
+	pixmaps := make([]sniPixmap, 0, len(trayIconSizes))
+	for _, size := range trayIconSizes {
+		pixmaps = append(pixmaps, scalePixmap(img, size))
+	}
+	return pixmaps
+}
+
+// scalePixmap box-filters img to size x size ARGB32 pixels
+func scalePixmap(img image.Image, size int) sniPixmap {
+	bounds := img.Bounds()
+	data := make([]byte, 0, size*size*4)
+	for y := 0; y < size; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/size
+		y1 := max(bounds.Min.Y+(y+1)*bounds.Dy()/size, y0+1)
+		for x := 0; x < size; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/size
+			x1 := max(bounds.Min.X+(x+1)*bounds.Dx()/size, x0+1)
+
+			var r, g, b, alpha, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, b, alpha = r+uint64(pr), g+uint64(pg), b+uint64(pb), alpha+uint64(pa)
+					n++
+				}
+			}
+			if alpha == 0 {
+				data = append(data, 0, 0, 0, 0)
+				continue
+			}
+			// RGBA is premultiplied, the tray expects straight alpha
+			data = append(data,
+				byte(alpha/n>>8),
+				byte(r*0xff/alpha),
+				byte(g*0xff/alpha),
+				byte(b*0xff/alpha),
+			)
+		}
+	}
+	return sniPixmap{Width: int32(size), Height: int32(size), Data: data}
+}
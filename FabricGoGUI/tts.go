@@ -0,0 +1,395 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+)
+
+// TTSSettings configure SpeakOutput
+type TTSSettings struct {
+	Engine   string `json:"engine"`   // system (default) or api
+	Voice    string `json:"voice"`    // system voice name, or the API voice
+	Rate     int    `json:"rate"`     // words per minute for the system engine, 0 for its default
+	APIURL   string `json:"apiUrl"`   // OpenAI-compatible speech endpoint
+	APIKey   string `json:"apiKey"`   // sent as a bearer token
+	APIModel string `json:"apiModel"` // speech model for the API engine
+}
+
+const (
+	// openAISpeechURL is the hosted OpenAI text-to-speech endpoint
+	openAISpeechURL = "https://api.openai.com/v1/audio/speech"
+	// ttsChunkTokens keeps each API request under OpenAI's 4096 character input limit
+	ttsChunkTokens = 800
+)
+
+// speech tracks the text being read aloud
+type speech struct {
+	cancel context.CancelFunc
+	cmd    *exec.Cmd // process playing the current part, nil between parts
+	paused bool
+}
+
+// GetTTSSettings returns the text-to-speech settings, with defaults filled in
+func (a *App) GetTTSSettings() TTSSettings {
+	settings := TTSSettings{}
+	if prefs, err := a.loadPreferences(); err == nil {
+		settings = prefs.TTS
+	}
+	if settings.Engine == "" {
+		settings.Engine = "system"
+	}
+	if settings.Engine == "api" {
+		if settings.APIURL == "" {
+			settings.APIURL = openAISpeechURL
+		}
+		if settings.APIModel == "" {
+			settings.APIModel = "tts-1"
+		}
+		if settings.Voice == "" {
+			settings.Voice = "alloy"
+		}
+	}
+	return settings
+}
+
+// SaveTTSSettings stores the text-to-speech settings
+func (a *App) SaveTTSSettings(settings TTSSettings) error {
+	if settings.Engine != "" && settings.Engine != "system" && settings.Engine != "api" {
+		return fmt.Errorf("unknown speech engine %q", settings.Engine)
+	}
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	prefs.TTS = settings
+	return a.SavePreferences(*prefs)
+}
+
+// SpeakOutput reads text aloud, stopping anything already being read.
+// Markdown is read as plain text. Emits tts:started, then tts:stopped when
+// reading finishes or is stopped, or tts:error if it fails.
+func (a *App) SpeakOutput(text string) error {
+	text = strings.TrimSpace(markdownToText(text))
+	if text == "" {
+		return fmt.Errorf("no text to speak")
+	}
+	settings := a.GetTTSSettings()
+
+	var parts []string
+	switch settings.Engine {
+	case "system":
+		if _, err := systemSpeechCommand(settings); err != nil {
+			return err
+		}
+		parts = []string{text}
+	case "api":
+		if settings.APIKey == "" && settings.APIURL == openAISpeechURL {
+			return fmt.Errorf("an API key is required for OpenAI text-to-speech")
+		}
+		parts = splitText(text, ttsChunkTokens, "\n\n", "\n", ". ", " ")
+	default:
+		return fmt.Errorf("unknown speech engine %q", settings.Engine)
+	}
+
+	a.StopSpeech()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &speech{cancel: cancel}
+	a.speechMutex.Lock()
+	a.speech = s
+	a.speechMutex.Unlock()
+
+	a.emit("tts:started", "")
+	go func() {
+		err := a.speakParts(ctx, s, settings, parts)
+		cancel()
+
+		a.speechMutex.Lock()
+		if a.speech == s {
+			a.speech = nil
+		}
+		a.speechMutex.Unlock()
+
+		if err != nil && ctx.Err() == nil {
+			a.log.Warn("text-to-speech failed", "engine", settings.Engine, "error", err)
+			a.emit("tts:error", err.Error())
+		}
+		a.emit("tts:stopped", "")
+	}()
+	return nil
+}
+
+// speakParts plays each part in turn. For the API engine the next part is
+// fetched while the current one plays.
+func (a *App) speakParts(ctx context.Context, s *speech, settings TTSSettings, parts []string) error {
+	if settings.Engine == "system" {
+		cmd, _ := systemSpeechCommand(settings)
+		cmd.Stdin = strings.NewReader(parts[0])
+		return a.playSpeech(ctx, s, cmd)
+	}
+
+	type fetched struct {
+		path string
+		err  error
+	}
+	fetch := func(text string) <-chan fetched {
+		ch := make(chan fetched, 1)
+		go func() {
+			path, err := a.fetchSpeech(ctx, settings, text)
+			ch <- fetched{path, err}
+		}()
+		return ch
+	}
+
+	next := fetch(parts[0])
+	for i := range parts {
+		audio := <-next
+		if audio.err != nil {
+			return audio.err
+		}
+		if i+1 < len(parts) {
+			next = fetch(parts[i+1])
+		}
+		cmd, err := audioPlayerCommand(audio.path)
+		if err == nil {
+			err = a.playSpeech(ctx, s, cmd)
+		}
+		os.Remove(audio.path)
+		if err != nil {
+			if i+1 < len(parts) {
+				// Let the prefetch finish so its file can be removed
+				if audio := <-next; audio.err == nil {
+					os.Remove(audio.path)
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// playSpeech runs cmd until it exits or ctx is cancelled
+func (a *App) playSpeech(ctx context.Context, s *speech, cmd *exec.Cmd) error {
+	a.speechMutex.Lock()
+	if ctx.Err() != nil {
+		a.speechMutex.Unlock()
+		return ctx.Err()
+	}
+	if err := cmd.Start(); err != nil {
+		a.speechMutex.Unlock()
+		return fmt.Errorf("failed to start %s: %v", filepath.Base(cmd.Path), err)
+	}
+	s.cmd = cmd
+	if s.paused {
+		// Paused between parts, the next one waits too
+		signalProcess(cmd, "STOP")
+	}
+	a.speechMutex.Unlock()
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// StopSpeech has killed the process
+		<-done
+		err = ctx.Err()
+	}
+
+	a.speechMutex.Lock()
+	s.cmd = nil
+	a.speechMutex.Unlock()
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("%s failed: %v", filepath.Base(cmd.Path), err)
+	}
+	return err
+}
+
+// fetchSpeech asks the speech API to read text and saves the audio to a
+// temporary WAV file
+func (a *App) fetchSpeech(ctx context.Context, settings TTSSettings, text string) (string, error) {
+	body, _ := json.Marshal(map[string]string{
+		"model":           settings.APIModel,
+		"voice":           settings.Voice,
+		"input":           text,
+		"response_format": "wav",
+	})
+	req, err := http.NewRequestWithContext(ctx, "POST", settings.APIURL, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if settings.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+settings.APIKey)
+	}
+
+	resp, err := a.externalClient().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request speech: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("speech error %d: %s", resp.StatusCode, string(body))
+	}
+
+	f, err := os.CreateTemp("", "fabric_gui_speech_*.wav")
+	if err != nil {
+		return "", fmt.Errorf("failed to save speech: %v", err)
+	}
+	_, err = io.Copy(f, resp.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to save speech: %v", err)
+	}
+	return f.Name(), nil
+}
+
+// PauseSpeech pauses reading, emitting tts:paused
+func (a *App) PauseSpeech() error {
+	return a.setSpeechPaused(true, "STOP", "tts:paused")
+}
+
+// ResumeSpeech continues reading after PauseSpeech, emitting tts:resumed
+func (a *App) ResumeSpeech() error {
+	return a.setSpeechPaused(false, "CONT", "tts:resumed")
+}
+
+func (a *App) setSpeechPaused(paused bool, signal, event string) error {
+	if goruntime.GOOS == "windows" {
+		return fmt.Errorf("pausing speech is not supported on Windows")
+	}
+	a.speechMutex.Lock()
+	defer a.speechMutex.Unlock()
+	s := a.speech
+	if s == nil {
+		return fmt.Errorf("nothing is being read")
+	}
+	if s.paused == paused {
+		return nil
+	}
+	if s.cmd != nil {
+		if err := signalProcess(s.cmd, signal); err != nil {
+			return err
+		}
+	}
+	s.paused = paused
+	a.emit(event, "")
+	return nil
+}
+
+// StopSpeech stops reading, emitting tts:stopped
+func (a *App) StopSpeech() {
+	a.speechMutex.Lock()
+	defer a.speechMutex.Unlock()
+	s := a.speech
+	a.speech = nil
+	if s == nil {
+		return
+	}
+	s.cancel()
+	if s.cmd != nil {
+		// A stopped process must be continued before it can exit
+		if s.paused {
+			signalProcess(s.cmd, "CONT")
+		}
+		s.cmd.Process.Kill()
+	}
+}
+
+// IsSpeaking reports whether text is being read, paused or not
+func (a *App) IsSpeaking() bool {
+	a.speechMutex.Lock()
+	defer a.speechMutex.Unlock()
+	return a.speech != nil
+}
+
+// signalProcess sends a job control signal with kill, which unlike
+// syscall builds on every platform
+func signalProcess(cmd *exec.Cmd, signal string) error {
+	if err := exec.Command("kill", "-"+signal, strconv.Itoa(cmd.Process.Pid)).Run(); err != nil {
+		return fmt.Errorf("failed to signal speech process: %v", err)
+	}
+	return nil
+}
+
+// systemSpeechCommand returns the command that reads stdin aloud with the
+// platform's speech engine: say on macOS, espeak-ng or espeak on Linux and
+// System.Speech through PowerShell on Windows
+func systemSpeechCommand(settings TTSSettings) (*exec.Cmd, error) {
+	switch goruntime.GOOS {
+	case "darwin":
+		args := []string{"-f", "-"}
+		if settings.Voice != "" {
+			args = append(args, "-v", settings.Voice)
+		}
+		if settings.Rate > 0 {
+			args = append(args, "-r", strconv.Itoa(settings.Rate))
+		}
+		return exec.Command("say", args...), nil
+	case "windows":
+		script := "Add-Type -AssemblyName System.Speech; $s = New-Object System.Speech.Synthesis.SpeechSynthesizer; "
+		if settings.Voice != "" {
+			script += "$s.SelectVoice('" + strings.ReplaceAll(settings.Voice, "'", "''") + "'); "
+		}
+		if settings.Rate > 0 {
+			// SAPI rates run from -10 to 10, 0 being about 180 words per minute
+			script += fmt.Sprintf("$s.Rate = %d; ", max(-10, min(10, (settings.Rate-180)/20)))
+		}
+		script += "$s.Speak([Console]::In.ReadToEnd())"
+		return exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script), nil
+	default:
+		for _, name := range []string{"espeak-ng", "espeak"} {
+			path, err := exec.LookPath(name)
+			if err != nil {
+				continue
+			}
+			args := []string{"--stdin"}
+			if settings.Voice != "" {
+				args = append(args, "-v", settings.Voice)
+			}
+			if settings.Rate > 0 {
+				args = append(args, "-s", strconv.Itoa(settings.Rate))
+			}
+			return exec.Command(path, args...), nil
+		}
+		return nil, fmt.Errorf("espeak-ng not found in PATH, install it or use a speech API")
+	}
+}
+
+// audioPlayerCommand returns the command that plays a WAV file
+func audioPlayerCommand(path string) (*exec.Cmd, error) {
+	switch goruntime.GOOS {
+	case "darwin":
+		return exec.Command("afplay", path), nil
+	case "windows":
+		script := "(New-Object Media.SoundPlayer '" + strings.ReplaceAll(path, "'", "''") + "').PlaySync()"
+		return exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script), nil
+	default:
+		players := [][]string{
+			{"paplay", path},
+			{"aplay", "-q", path},
+			{"ffplay", "-nodisp", "-autoexit", "-loglevel", "quiet", path},
+		}
+		for _, player := range players {
+			if bin, err := exec.LookPath(player[0]); err == nil {
+				return exec.Command(bin, player[1:]...), nil
+			}
+		}
+		return nil, fmt.Errorf("no audio player found, install pulseaudio-utils, alsa-utils or ffmpeg")
+	}
+}
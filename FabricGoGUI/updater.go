@@ -0,0 +1,424 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// guiReleaseURL is the GitHub API endpoint for the latest GUI release
+const guiReleaseURL = "https://api.github.com/repos/Digitalgods2/FabricGui/releases/latest"
+
+// guiAppName is the name the GUI is built and released under
+const guiAppName = "FabricGoGUI"
+
+// updatePublicKey is the base64 Ed25519 public key release assets are signed
+// with, set at build time with -ldflags "-X main.updatePublicKey=...". Each
+// asset has a detached signature published next to it as <asset>.sig.
+// Builds without a key cannot install updates.
+var updatePublicKey = ""
+
+// UpdateInfo describes the newest GUI release and whether it is installed
+type UpdateInfo struct {
+	Current   string `json:"current"`
+	Latest    string `json:"latest"`
+	Available bool   `json:"available"`
+	Notes     string `json:"notes,omitempty"` // release notes in Markdown
+	URL       string `json:"url,omitempty"`   // release page, for updating by hand
+	Asset     string `json:"asset,omitempty"` // the download for this platform, empty when there is none
+	Applied   bool   `json:"applied"`         // installed by ApplyUpdate, runs from the next start
+}
+
+// CheckForUpdates asks GitHub for the newest GUI release. An available
+// update is also emitted as "update:available". Development builds have no
+// version to compare and never report one.
+func (a *App) CheckForUpdates() (*UpdateInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	info, _, err := a.checkForUpdates(ctx)
+	if err != nil {
+		a.log.Warn("failed to check for updates", "error", err)
+		return nil, err
+	}
+	if info.Available {
+		a.log.Info("update available", "current", info.Current, "latest", info.Latest)
+		a.emit("update:available", info)
+	}
+	return info, nil
+}
+
+// ApplyUpdate downloads the newest release, verifies its checksum and
+// installs it over the running app, which keeps running the old version
+// until it restarts. With restart set the app restarts into the update
+// straight away. Progress is emitted as "update:download".
+func (a *App) ApplyUpdate(restart bool) (*UpdateInfo, error) {
+	target, err := updateTarget()
+	if err != nil {
+		return nil, err
+	}
+
+	job, ctx := a.startJob("update", "Updating Fabric GUI")
+	info, err := a.downloadUpdate(ctx, job, target)
+	a.finishJob(job, err)
+	if err != nil {
+		a.log.Error("failed to update", "error", err)
+		return nil, err
+	}
+	if !info.Applied {
+		return info, nil
+	}
+
+	a.log.Info("installed update", "version", info.Latest, "path", target)
+	a.emit("update:applied", info)
+	if restart {
+		if err := a.restartInto(target); err != nil {
+			return info, fmt.Errorf("the update was installed but the app did not restart: %v", err)
+		}
+	}
+	return info, nil
+}
+
+// checkForUpdates compares the running version with the latest release
+func (a *App) checkForUpdates(ctx context.Context) (*UpdateInfo, *githubRelease, error) {
+	release, err := a.latestRelease(ctx, guiReleaseURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	info := &UpdateInfo{Current: appVersion, Latest: release.TagName, Notes: release.Body, URL: release.HTMLURL}
+	if _, ok := parseVersion(appVersion); ok {
+		info.Available = compareVersions(appVersion, release.TagName) < 0
+	}
+	if asset, err := guiAssetFor(release, goruntime.GOOS, goruntime.GOARCH, isAppImage()); err == nil {
+		info.Asset = asset.Name
+	}
+	return info, release, nil
+}
+
+func (a *App) downloadUpdate(ctx context.Context, j *job, target string) (*UpdateInfo, error) {
+	a.updateJob(j, -1, "Looking up the latest release")
+	info, release, err := a.checkForUpdates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Available {
+		return info, nil
+	}
+
+	asset, err := guiAssetFor(release, goruntime.GOOS, goruntime.GOARCH, isAppImage())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := updateKey(); err != nil {
+		return nil, fmt.Errorf("%v, download the update from %s instead", err, info.URL)
+	}
+	sum, err := a.assetChecksum(ctx, release, asset)
+	if err != nil {
+		return nil, err
+	}
+	data, err := a.downloadAsset(ctx, j, asset, "update:download")
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyChecksum(asset, data, sum); err != nil {
+		return nil, err
+	}
+	// The checksum comes from the same release as the download, only the
+	// signature shows the release is ours
+	if err := a.verifyUpdateSignature(ctx, release, asset, data); err != nil {
+		return nil, fmt.Errorf("%v, download the update from %s instead", err, info.URL)
+	}
+
+	a.updateJob(j, 100, "Installing")
+	if err := installUpdate(target, asset.Name, data); err != nil {
+		if os.IsPermission(err) {
+			return nil, fmt.Errorf("cannot write to %s, download the update from %s instead", target, info.URL)
+		}
+		return nil, fmt.Errorf("failed to install the update: %v", err)
+	}
+	info.Applied = true
+	return info, nil
+}
+
+// verifyUpdateSignature checks an update against its detached Ed25519
+// signature, raw or base64, with the key built into the app
+func (a *App) verifyUpdateSignature(ctx context.Context, release *githubRelease, asset githubAsset, data []byte) error {
+	key, err := updateKey()
+	if err != nil {
+		return err
+	}
+
+	var sig []byte
+	for _, candidate := range release.Assets {
+		if candidate.Name != asset.Name+".sig" {
+			continue
+		}
+		if sig, err = a.fetch(ctx, candidate.DownloadURL); err != nil {
+			return fmt.Errorf("failed to download the signature: %v", err)
+		}
+	}
+	if sig == nil {
+		return fmt.Errorf("release %s publishes no signature for %s", release.TagName, asset.Name)
+	}
+	if len(sig) != ed25519.SignatureSize {
+		if sig, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig))); err != nil {
+			return fmt.Errorf("invalid signature for %s", asset.Name)
+		}
+	}
+	if !ed25519.Verify(key, data, sig) {
+		return fmt.Errorf("the signature of %s does not match, the download may have been tampered with", asset.Name)
+	}
+	return nil
+}
+
+// updateKey decodes updatePublicKey
+func updateKey() (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(updatePublicKey)
+	if updatePublicKey == "" || err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("this build has no key to verify updates with")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// guiAssetFor picks the release asset for a platform. AppImage installs
+// update from the AppImage, others from a bare executable or an archive.
+// macOS builds come as a zipped app bundle, universal or per architecture.
+func guiAssetFor(r *githubRelease, goos, goarch string, appImage bool) (githubAsset, error) {
+	var candidates []string
+	switch {
+	case appImage:
+		arch := map[string]string{"amd64": "x86_64", "arm64": "aarch64"}[goarch]
+		candidates = append(candidates, fmt.Sprintf("%s-%s.AppImage", guiAppName, arch))
+	case goos == "darwin":
+		candidates = append(candidates,
+			fmt.Sprintf("%s-darwin-universal.zip", guiAppName),
+			fmt.Sprintf("%s-darwin-%s.zip", guiAppName, goarch),
+		)
+	case goos == "windows":
+		candidates = append(candidates,
+			fmt.Sprintf("%s-windows-%s.exe", guiAppName, goarch),
+			fmt.Sprintf("%s-windows-%s.zip", guiAppName, goarch),
+		)
+	default:
+		candidates = append(candidates,
+			fmt.Sprintf("%s-%s-%s", guiAppName, goos, goarch),
+			fmt.Sprintf("%s-%s-%s.tar.gz", guiAppName, goos, goarch),
+		)
+	}
+
+	for _, name := range candidates {
+		for _, asset := range r.Assets {
+			if strings.EqualFold(asset.Name, name) {
+				return asset, nil
+			}
+		}
+	}
+	return githubAsset{}, fmt.Errorf("release %s has no build for %s/%s", r.TagName, goos, goarch)
+}
+
+// isAppImage reports whether the app runs from an AppImage
+func isAppImage() bool {
+	return goruntime.GOOS == "linux" && os.Getenv("APPIMAGE") != ""
+}
+
+// updateTarget is what an update replaces: the AppImage, the app bundle on
+// macOS or the executable
+func updateTarget() (string, error) {
+	if isAppImage() {
+		return os.Getenv("APPIMAGE"), nil
+	}
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the running app: %v", err)
+	}
+	if goruntime.GOOS == "darwin" {
+		// FabricGoGUI.app/Contents/MacOS/FabricGoGUI
+		if bundle := filepath.Dir(filepath.Dir(filepath.Dir(exe))); strings.HasSuffix(bundle, ".app") {
+			return bundle, nil
+		}
+	}
+	return exe, nil
+}
+
+// installUpdate replaces target with the downloaded asset. Unix systems keep
+// a running program's file open, so it can be replaced in place. Windows
+// cannot overwrite a running executable but can rename it, so the old one
+// is moved aside and removed on the next start.
+func installUpdate(target, asset string, data []byte) error {
+	if strings.HasSuffix(target, ".app") {
+		return installAppBundle(target, data)
+	}
+
+	binary := data
+	if !strings.HasSuffix(asset, ".AppImage") {
+		var err error
+		if binary, err = extractExecutable(asset, data, guiAppName, guiAppName+".exe"); err != nil {
+			return err
+		}
+	}
+
+	if goruntime.GOOS != "windows" {
+		return writeFileAtomic(target, binary, 0755)
+	}
+	old := target + ".old"
+	os.Remove(old)
+	if err := os.Rename(target, old); err != nil {
+		return err
+	}
+	if err := os.WriteFile(target, binary, 0755); err != nil {
+		os.Remove(target)
+		os.Rename(old, target)
+		return err
+	}
+	return nil
+}
+
+// installAppBundle swaps a macOS app bundle for the one in a zipped release
+func installAppBundle(bundle string, data []byte) error {
+	staging := bundle + ".update"
+	os.RemoveAll(staging)
+	defer os.RemoveAll(staging)
+	if err := unzipTo(data, staging); err != nil {
+		return err
+	}
+	matches, _ := filepath.Glob(filepath.Join(staging, "*.app"))
+	if len(matches) == 0 {
+		return fmt.Errorf("the release contains no app bundle")
+	}
+
+	old := bundle + ".old"
+	os.RemoveAll(old)
+	if err := os.Rename(bundle, old); err != nil {
+		return err
+	}
+	if err := os.Rename(matches[0], bundle); err != nil {
+		os.Rename(old, bundle)
+		return err
+	}
+	os.RemoveAll(old)
+	return nil
+}
+
+// unzipTo unpacks a zip into dir, keeping file modes and the relative
+// symlinks app bundles use
+func unzipTo(data []byte, dir string) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %v", err)
+	}
+	for _, f := range zr.File {
+		if !filepath.IsLocal(f.Name) {
+			return fmt.Errorf("the archive contains an unsafe path: %s", f.Name)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(f.Name))
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %v", err)
+		}
+
+		if f.Mode()&os.ModeSymlink != 0 {
+			link := string(content)
+			if filepath.IsAbs(link) || !filepath.IsLocal(filepath.Join(filepath.Dir(f.Name), link)) {
+				return fmt.Errorf("the archive contains an unsafe link: %s", f.Name)
+			}
+			if err := os.Symlink(link, target); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.WriteFile(target, content, f.Mode().Perm()|0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restartEnv passes the ID of the process an update restarted from to the
+// new one, which waits for it to exit: until then the old process holds
+// the single instance lock and the new one would only hand it its arguments
+const restartEnv = "FABRIC_GUI_RESTARTED_FROM"
+
+// restartInto starts the updated app and quits this one
+func (a *App) restartInto(target string) error {
+	exe := target
+	if strings.HasSuffix(target, ".app") {
+		// Started directly rather than with open, which drops the environment
+		exe = filepath.Join(target, "Contents", "MacOS", guiAppName)
+	}
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("%s=%d", restartEnv, os.Getpid()))
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	cmd.Process.Release()
+	a.quit()
+	return nil
+}
+
+// waitForRestart waits for the process the app was restarted from by an
+// update to exit, for at most 30 seconds
+func waitForRestart() {
+	pid, err := strconv.Atoi(os.Getenv(restartEnv))
+	os.Unsetenv(restartEnv)
+	if err != nil {
+		return
+	}
+	for deadline := time.Now().Add(30 * time.Second); time.Now().Before(deadline) && processRunning(pid); {
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// processRunning reports whether the process with the given ID still runs
+func processRunning(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	defer p.Release()
+	if goruntime.GOOS == "windows" {
+		// Finding a process on Windows opens it, which fails once it exited
+		return true
+	}
+	return p.Signal(syscall.Signal(0)) == nil
+}
+
+// checkForUpdatesOnStartup removes what the last update left behind and
+// looks for a newer release in release builds
+func (a *App) checkForUpdatesOnStartup() {
+	if target, err := updateTarget(); err == nil {
+		os.Remove(target + ".old")
+	}
+	if _, ok := parseVersion(appVersion); ok {
+		a.CheckForUpdates()
+	}
+}
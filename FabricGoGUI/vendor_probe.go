@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// probeTimeout bounds each vendor check
+const probeTimeout = 15 * time.Second
+
+// Probe methods, how a vendor was checked
+const (
+	ProbeKey  = "key"  // the vendor's API accepted the key
+	ProbeChat = "chat" // a one-line chat through Fabric succeeded
+)
+
+// VendorStatus reports whether a vendor can actually be used
+type VendorStatus struct {
+	Vendor    string `json:"vendor"`
+	Usable    bool   `json:"usable"`
+	Method    string `json:"method"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latencyMs"`
+}
+
+// vendorKeyCheck is a cheap authenticated request that validates a vendor's
+// key without running a model
+type vendorKeyCheck struct {
+	url  string // default endpoint, replaced by <VENDOR>_API_BASE_URL + "/models"
+	auth func(req *http.Request, key string)
+}
+
+func bearerAuth(req *http.Request, key string) {
+	req.Header.Set("Authorization", "Bearer "+key)
+}
+
+// vendorKeyChecks are keyed by the vendor's prefix in Fabric's .env
+var vendorKeyChecks = map[string]vendorKeyCheck{
+	"OPENAI": {url: "https://api.openai.com/v1/models", auth: bearerAuth},
+	"ANTHROPIC": {url: "https://api.anthropic.com/v1/models", auth: func(req *http.Request, key string) {
+		req.Header.Set("x-api-key", key)
+		req.Header.Set("anthropic-version", "2023-06-01")
+	}},
+	"GEMINI": {url: "https://generativelanguage.googleapis.com/v1beta/models", auth: func(req *http.Request, key string) {
+		req.Header.Set("x-goog-api-key", key)
+	}},
+	"GROQ":       {url: "https://api.groq.com/openai/v1/models", auth: bearerAuth},
+	"MISTRAL":    {url: "https://api.mistral.ai/v1/models", auth: bearerAuth},
+	"DEEPSEEK":   {url: "https://api.deepseek.com/models", auth: bearerAuth},
+	"OPENROUTER": {url: "https://openrouter.ai/api/v1/auth/key", auth: bearerAuth},
+}
+
+// ProbeVendors checks every vendor GetModels lists, in parallel. Vendors
+// whose key can be validated directly are checked that way, Ollama by
+// listing its models, and the rest with a one-line chat through Fabric.
+func (a *App) ProbeVendors() ([]VendorStatus, error) {
+	models, err := a.GetModels()
+	if err != nil {
+		return nil, err
+	}
+	env := a.vendorEnv()
+
+	statuses := make([]VendorStatus, 0, len(models.Vendors))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for vendor, names := range models.Vendors {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status := a.probeVendor(vendor, names, env)
+			mu.Lock()
+			statuses = append(statuses, status)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Vendor < statuses[j].Vendor })
+	return statuses, nil
+}
+
+// probeVendor checks a single vendor
+func (a *App) probeVendor(vendor string, models []string, env map[string]string) VendorStatus {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	status := VendorStatus{Vendor: vendor, Method: ProbeKey}
+	start := time.Now()
+	prefix := OpenAIEndpoint{Name: vendor}.envPrefix()
+
+	var err error
+	check, known := vendorKeyChecks[prefix]
+	baseURL := env[prefix+"_API_BASE_URL"]
+	switch {
+	case strings.EqualFold(vendor, ollamaVendor):
+		err = a.ollamaRequest(ctx, "GET", "/api/tags", nil, nil)
+	case known || baseURL != "":
+		key := env[prefix+"_API_KEY"]
+		if known && key == "" {
+			err = fmt.Errorf("no API key configured")
+			break
+		}
+		url := check.url
+		if baseURL != "" {
+			url = strings.TrimRight(baseURL, "/") + "/models"
+		}
+		if check.auth == nil {
+			check.auth = bearerAuth
+		}
+		err = a.probeKey(ctx, url, key, check.auth)
+	case len(models) > 0:
+		status.Method = ProbeChat
+		_, err = a.streamChat(ctx, PromptRequest{UserInput: "Reply with OK.", Vendor: vendor, Model: models[0]}, func(string) {}, func(streamUsage) {})
+	default:
+		err = fmt.Errorf("no models")
+	}
+
+	status.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		status.Error = err.Error()
+		a.log.Info("vendor unavailable", "vendor", vendor, "method", status.Method, "error", err)
+	} else {
+		status.Usable = true
+	}
+	return status
+}
+
+// probeKey sends an authenticated GET and treats any 2xx reply as valid
+func (a *App) probeKey(ctx context.Context, url, key string, auth func(*http.Request, string)) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	if key != "" {
+		auth(req, key)
+	}
+	resp, err := a.externalClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == 401 || resp.StatusCode == 403:
+		return fmt.Errorf("the API key was rejected (status %d)", resp.StatusCode)
+	default:
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+}
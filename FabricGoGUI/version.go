@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minFabricVersion is the oldest Fabric release the GUI is known to work with
+const minFabricVersion = "1.4.0"
+
+// requiredEndpoints are the GET endpoints the GUI cannot work without
+var requiredEndpoints = []string{"/patterns/names", "/models/names"}
+
+// FabricVersionInfo describes the installed fabric binary and the server the
+// GUI is connected to. Warnings explain anything that will not work.
+type FabricVersionInfo struct {
+	BinaryPath       string   `json:"binaryPath,omitempty"`
+	Binary           string   `json:"binary,omitempty"`
+	Server           string   `json:"server,omitempty"` // empty when the server does not report one
+	Minimum          string   `json:"minimum"`
+	MissingEndpoints []string `json:"missingEndpoints,omitempty"`
+	Compatible       bool     `json:"compatible"`
+	Warnings         []string `json:"warnings,omitempty"`
+}
+
+// GetFabricVersion reports the fabric binary and server versions and checks
+// that the server provides what the GUI needs. Problems are also emitted as
+// "fabric:incompatible".
+func (a *App) GetFabricVersion() FabricVersionInfo {
+	info := FabricVersionInfo{Minimum: minFabricVersion, Compatible: true}
+
+	if path, err := a.fabricPath(); err == nil {
+		info.BinaryPath = path
+		info.Binary = fabricBinaryVersion(path)
+		if compareVersions(info.Binary, minFabricVersion) < 0 {
+			info.Warnings = append(info.Warnings, fmt.Sprintf("fabric %s is older than %s, update it with UpdateFabric", info.Binary, minFabricVersion))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client := a.fabricClient()
+	if version, err := client.Version(ctx); err == nil {
+		info.Server = version
+		if compareVersions(version, minFabricVersion) < 0 {
+			info.Compatible = false
+			info.Warnings = append(info.Warnings, fmt.Sprintf("the server runs Fabric %s, the GUI needs %s or newer", version, minFabricVersion))
+		}
+	}
+
+	for _, path := range requiredEndpoints {
+		ok, err := client.HasEndpoint(ctx, path)
+		if err != nil {
+			// An offline server is reported by the health check, not here
+			a.log.Debug("could not probe server", "path", path, "error", err)
+			break
+		}
+		if !ok {
+			info.MissingEndpoints = append(info.MissingEndpoints, path)
+		}
+	}
+	if len(info.MissingEndpoints) > 0 {
+		info.Compatible = false
+		info.Warnings = append(info.Warnings, fmt.Sprintf("the server does not provide %s, it is probably older than Fabric %s",
+			strings.Join(info.MissingEndpoints, ", "), minFabricVersion))
+	}
+
+	if len(info.Warnings) > 0 {
+		a.log.Warn("fabric compatibility problems", "warnings", info.Warnings)
+		a.emit("fabric:incompatible", info)
+	}
+	return info
+}
+
+// versionPattern finds a dotted version number in output such as
+// "fabric version v1.4.250"
+var versionPattern = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// parseVersion extracts major, minor and patch numbers from a version string
+func parseVersion(version string) ([3]int, bool) {
+	var parts [3]int
+	match := versionPattern.FindStringSubmatch(version)
+	if match == nil {
+		return parts, false
+	}
+	for i := range parts {
+		parts[i], _ = strconv.Atoi(match[i+1])
+	}
+	return parts, true
+}
+
+// compareVersions returns -1, 0 or 1 as a is older than, the same as or newer
+// than b. Unparseable versions compare as equal so they never cause warnings.
+func compareVersions(a, b string) int {
+	va, okA := parseVersion(a)
+	vb, okB := parseVersion(b)
+	if !okA || !okB {
+		return 0
+	}
+	for i := range va {
+		switch {
+		case va[i] < vb[i]:
+			return -1
+		case va[i] > vb[i]:
+			return 1
+		}
+	}
+	return 0
+}
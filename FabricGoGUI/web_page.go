@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// maxPageSize bounds how much of a web page is downloaded
+const maxPageSize = 8 << 20
+
+// fetchPageText downloads a web page and returns its readable text, with
+// headings and list items marked as in Markdown. Plain text and Markdown
+// are returned as they are. GitHub issues, pull requests and comparisons
+// are read through the GitHub API instead, see FetchGitHub.
+func (a *App) fetchPageText(ctx context.Context, rawURL string) (string, error) {
+	return a.fetchPageTextWith(ctx, a.externalClient(), rawURL)
+}
+
+// fetchPublicPageText is fetchPageText for addresses from outside the app,
+// such as links. Loopback, private and other local addresses are refused,
+// also when a redirect leads there, so a link cannot read from the local
+// network; see publicClient.
+func (a *App) fetchPublicPageText(ctx context.Context, rawURL string) (string, error) {
+	return a.fetchPageTextWith(ctx, publicClient(a.externalClient()), rawURL)
+}
+
+func (a *App) fetchPageTextWith(ctx context.Context, client *http.Client, rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("not a web address: %s", rawURL)
+	}
+	if isGitHubLink(rawURL) {
+		content, err := a.fetchGitHub(ctx, rawURL)
+		if err != nil {
+			return "", err
+		}
+		return content.Text, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "FabricGUI/"+appVersion)
+	req.Header.Set("Accept", "text/html,text/plain;q=0.9,*/*;q=0.5")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %v", u.Host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("%s returned status %d", u.Host, resp.StatusCode)
+	}
+
+	body := io.LimitReader(resp.Body, maxPageSize)
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "" && mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		if !strings.HasPrefix(mediaType, "text/") {
+			return "", fmt.Errorf("%s is %s, not a web page", rawURL, mediaType)
+		}
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %v", u.Host, err)
+		}
+		return string(data), nil
+	}
+
+	doc, err := html.Parse(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %v", u.Host, err)
+	}
+	text := htmlToText(doc)
+	if text == "" {
+		return "", fmt.Errorf("%s has no readable text", rawURL)
+	}
+	return text, nil
+}
+
+// htmlToText extracts the readable text of a page. The main or article
+// element is preferred, and scripts, navigation and page furniture are
+// left out.
+func htmlToText(doc *html.Node) string {
+	root := doc
+	if main := findElement(doc, atom.Main); main != nil {
+		root = main
+	} else if article := findElement(doc, atom.Article); article != nil {
+		root = article
+	}
+
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			return
+		}
+		if n.Type != html.ElementNode && n.Type != html.DocumentNode {
+			return
+		}
+		switch n.DataAtom {
+		case atom.Script, atom.Style, atom.Noscript, atom.Nav, atom.Header, atom.Footer, atom.Aside, atom.Form, atom.Svg, atom.Template:
+			return
+		case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+			b.WriteString("\n\n" + strings.Repeat("#", int(n.Data[1]-'0')) + " ")
+		case atom.Li:
+			b.WriteString("\n- ")
+		case atom.Br:
+			b.WriteString("\n")
+		case atom.P, atom.Div, atom.Section, atom.Blockquote, atom.Pre, atom.Tr, atom.Ul, atom.Ol, atom.Table:
+			b.WriteString("\n\n")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	return tidyText(b.String())
+}
+
+// findElement returns the first element of a kind under n
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// tidyText collapses the whitespace of extracted text, keeping paragraph
+// breaks but no more than one blank line in a row
+func tidyText(s string) string {
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" || line == "-" {
+			blank = len(lines) > 0
+			continue
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
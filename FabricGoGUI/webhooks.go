@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Webhook is a URL run results are POSTed to
+type Webhook struct {
+	Name     string   `json:"name"`
+	URL      string   `json:"url"`
+	Secret   string   `json:"secret,omitempty"`   // signs payloads with HMAC-SHA256 when set
+	Enabled  bool     `json:"enabled"`            // deliver every completed run automatically
+	Patterns []string `json:"patterns,omitempty"` // limits automatic delivery to these patterns
+}
+
+// WebhookPayload is the JSON body sent to a webhook
+type WebhookPayload struct {
+	Event    string          `json:"event"` // run.completed, or run.sent when sent on demand
+	Pattern  string          `json:"pattern"`
+	Model    string          `json:"model"`
+	Vendor   string          `json:"vendor"`
+	Input    string          `json:"input"`
+	Output   string          `json:"output"`
+	Metadata WebhookMetadata `json:"metadata"`
+}
+
+// WebhookMetadata describes the run a payload came from
+type WebhookMetadata struct {
+	ID               string            `json:"id"`
+	Title            string            `json:"title,omitempty"`
+	Time             string            `json:"time"` // RFC 3339
+	Tags             []string          `json:"tags,omitempty"`
+	Variables        map[string]string `json:"variables,omitempty"`
+	Context          string            `json:"context,omitempty"`
+	Strategy         string            `json:"strategy,omitempty"`
+	Session          string            `json:"session,omitempty"`
+	Source           string            `json:"source,omitempty"` // input URL, when the input was one
+	DurationMs       int64             `json:"durationMs,omitempty"`
+	PromptTokens     int               `json:"promptTokens,omitempty"`
+	CompletionTokens int               `json:"completionTokens,omitempty"`
+}
+
+// WebhookResult is emitted as "webhook:delivered" or "webhook:failed"
+type WebhookResult struct {
+	Name     string `json:"name"`
+	EntryID  string `json:"entryId"`
+	Status   int    `json:"status,omitempty"`
+	Attempts int    `json:"attempts"`
+	Error    string `json:"error,omitempty"`
+}
+
+const (
+	// webhookRetries is how many times a failed delivery is retried
+	webhookRetries = 3
+	// webhookTimeout bounds each delivery attempt
+	webhookTimeout = 30 * time.Second
+	// webhookSignatureHeader carries "sha256=" and the hex HMAC of
+	// timestamp + "." + body, keyed with the webhook's secret
+	webhookSignatureHeader = "X-Fabric-Signature"
+	// webhookTimestampHeader carries the Unix time the payload was signed at,
+	// so receivers can reject replays
+	webhookTimestampHeader = "X-Fabric-Timestamp"
+)
+
+// GetWebhooks returns the configured webhooks
+func (a *App) GetWebhooks() []Webhook {
+	prefs, err := a.loadPreferences()
+	if err != nil || prefs.Webhooks == nil {
+		return []Webhook{}
+	}
+	return prefs.Webhooks
+}
+
+// SaveWebhooks replaces the configured webhooks. Names must be unique and
+// URLs http or https.
+func (a *App) SaveWebhooks(webhooks []Webhook) error {
+	names := []string{}
+	for i := range webhooks {
+		hook := &webhooks[i]
+		hook.Name = strings.TrimSpace(hook.Name)
+		hook.URL = strings.TrimSpace(hook.URL)
+		if hook.Name == "" {
+			return fmt.Errorf("webhook %d has no name", i+1)
+		}
+		if containsFold(names, hook.Name) {
+			return fmt.Errorf("duplicate webhook name: %s", hook.Name)
+		}
+		names = append(names, hook.Name)
+		if u, err := url.Parse(hook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook %s: invalid URL %q", hook.Name, hook.URL)
+		}
+	}
+	prefs, err := a.loadPreferences()
+	if err != nil {
+		return err
+	}
+	prefs.Webhooks = webhooks
+	return a.SavePreferences(*prefs)
+}
+
+// SendToWebhook delivers a history entry to the named webhook, retrying
+// failures. An empty entryID sends the latest entry.
+func (a *App) SendToWebhook(name, entryID string) error {
+	hook, ok := a.findWebhook(name)
+	if !ok {
+		return fmt.Errorf("webhook not found: %s", name)
+	}
+	entry, ok := a.historyEntryOrLast(entryID)
+	if !ok {
+		return fmt.Errorf("history entry not found: %s", entryID)
+	}
+	_, _, err := a.deliverWebhook(context.Background(), hook, "run.sent", entry)
+	return err
+}
+
+// historyEntryOrLast finds an entry by ID, or the latest one when id is empty
+func (a *App) historyEntryOrLast(id string) (HistoryEntry, bool) {
+	if id == "" {
+		return a.history.Last()
+	}
+	return a.history.Find(id)
+}
+
+func (a *App) findWebhook(name string) (Webhook, bool) {
+	for _, hook := range a.GetWebhooks() {
+		if strings.EqualFold(hook.Name, name) {
+			return hook, true
+		}
+	}
+	return Webhook{}, false
+}
+
+// deliverRunToWebhooks sends a finished run to every enabled webhook whose
+// patterns match, reporting each result as an event
+func (a *App) deliverRunToWebhooks(entry HistoryEntry) {
+	for _, hook := range a.GetWebhooks() {
+		if !hook.Enabled || (len(hook.Patterns) > 0 && !containsFold(hook.Patterns, entry.Pattern)) {
+			continue
+		}
+		go func(hook Webhook) {
+			status, attempts, err := a.deliverWebhook(context.Background(), hook, "run.completed", entry)
+			result := WebhookResult{Name: hook.Name, EntryID: entry.ID, Status: status, Attempts: attempts}
+			if err != nil {
+				result.Error = err.Error()
+				a.emit("webhook:failed", result)
+				return
+			}
+			a.emit("webhook:delivered", result)
+		}(hook)
+	}
+}
+
+// deliverWebhook POSTs the entry, retrying network errors, 429s and 5xx
+// responses with exponential backoff. Every attempt carries the same
+// X-Fabric-Delivery ID so receivers can drop duplicates. Returns the last
+// HTTP status and the number of attempts made.
+func (a *App) deliverWebhook(ctx context.Context, hook Webhook, event string, entry HistoryEntry) (int, int, error) {
+	body, err := json.Marshal(webhookPayload(event, entry))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to encode payload: %v", err)
+	}
+	delivery := newHistoryID()
+
+	status := 0
+	for attempt := 1; ; attempt++ {
+		var retryAfter time.Duration
+		status, retryAfter, err = a.postWebhook(ctx, hook, delivery, body)
+		if err == nil {
+			a.log.Info("delivered webhook", "webhook", hook.Name, "status", status, "attempts", attempt)
+			return status, attempt, nil
+		}
+		retryable := status == 0 || status == http.StatusTooManyRequests || status >= 500
+		if !retryable || attempt > webhookRetries || ctx.Err() != nil {
+			a.log.Warn("failed to deliver webhook", "webhook", hook.Name, "attempts", attempt, "error", err)
+			return status, attempt, err
+		}
+
+		delay := defaultRetryDelay << (attempt - 1)
+		if retryAfter > 0 {
+			delay = retryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return status, attempt, ctx.Err()
+		case <-time.After(min(delay, maxRetryDelay)):
+		}
+	}
+}
+
+// postWebhook makes one delivery attempt, returning the response status
+// (0 when there was no response) and any Retry-After hint
+func (a *App) postWebhook(ctx context.Context, hook Webhook, delivery string, body []byte) (int, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "FabricGUI/"+appVersion)
+	req.Header.Set("X-Fabric-Delivery", delivery)
+	if hook.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(webhookTimestampHeader, timestamp)
+		req.Header.Set(webhookSignatureHeader, "sha256="+signWebhook(hook.Secret, timestamp, body))
+	}
+
+	resp, err := a.externalClient().Do(req)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to send webhook: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp.StatusCode, parseRetryAfter(resp.Header), fmt.Errorf("webhook returned %d: %s", resp.StatusCode, strings.TrimSpace(string(text)))
+	}
+	return resp.StatusCode, 0, nil
+}
+
+// signWebhook returns the hex HMAC-SHA256 of timestamp + "." + body
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookPayload(event string, entry HistoryEntry) WebhookPayload {
+	return WebhookPayload{
+		Event:   event,
+		Pattern: entry.Pattern,
+		Model:   entry.Model,
+		Vendor:  entry.Vendor,
+		Input:   entry.Input,
+		Output:  entry.Output,
+		Metadata: WebhookMetadata{
+			ID:               entry.ID,
+			Title:            entry.Title,
+			Time:             time.Unix(entry.Time, 0).Format(time.RFC3339),
+			Tags:             entry.Tags,
+			Variables:        entry.Variables,
+			Context:          entry.Context,
+			Strategy:         entry.Strategy,
+			Session:          entry.Session,
+			Source:           inputSourceURL(entry.Input),
+			DurationMs:       entry.DurationMs,
+			PromptTokens:     entry.PromptTokens,
+			CompletionTokens: entry.CompletionTokens,
+		},
+	}
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// youtubeVideoID matches the 11 character ID of a video
+var youtubeVideoID = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+
+// YouTubeTranscript is what the server's /youtube/transcript endpoint returns
+type YouTubeTranscript struct {
+	VideoID     string `json:"videoId"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Transcript  string `json:"transcript"`
+}
+
+// YouTubeVideo is a video of a playlist or channel
+type YouTubeVideo struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// YouTubeListing is the videos a YouTube link points to
+type YouTubeListing struct {
+	Title  string         `json:"title"` // the playlist or channel, empty for a single video
+	Videos []YouTubeVideo `json:"videos"`
+}
+
+// YouTubeBatchRequest runs the videos of a video, playlist or channel link
+// through a preset or pattern
+type YouTubeBatchRequest struct {
+	URL       string `json:"url"`
+	Limit     int    `json:"limit"`  // most videos to run, newest first for channels; 0 runs all
+	Preset    string `json:"preset"` // runs the preset, otherwise the pattern with the model
+	Pattern   string `json:"pattern"`
+	Vendor    string `json:"vendor"`
+	Model     string `json:"model"`     // defaults to the pattern's model
+	OutputDir string `json:"outputDir"` // one file per video, named by the batch template; history only when empty
+	Digest    bool   `json:"digest"`    // also combine the outputs into one digest
+}
+
+// YouTubeVideoEvent reports the state of one video in a batch run, emitted
+// as "youtube:video"
+type YouTubeVideoEvent struct {
+	YouTubeVideo
+	Index   int    `json:"index"`
+	Total   int    `json:"total"`
+	Status  string `json:"status"` // started, complete, error
+	Output  string `json:"output,omitempty"`
+	EntryID string `json:"entryId,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// YouTubeBatchSummary is returned and emitted as "youtube:complete" when a
+// batch run finishes
+type YouTubeBatchSummary struct {
+	Title      string              `json:"title"`
+	Videos     []YouTubeVideoEvent `json:"videos"`
+	Succeeded  int                 `json:"succeeded"`
+	Failed     int                 `json:"failed"`
+	Digest     string              `json:"digest,omitempty"` // Markdown of the outputs, when asked for
+	DigestPath string              `json:"digestPath,omitempty"`
+	DurationMs int64               `json:"durationMs"`
+}
+
+// ListYouTubeVideos returns the videos of a video, playlist or channel link,
+// up to limit when it is above 0. Playlists and channels are listed with
+// yt-dlp.
+func (a *App) ListYouTubeVideos(link string, limit int) (*YouTubeListing, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	return listYouTubeVideos(ctx, link, limit)
+}
+
+// RunYouTubeBatch fetches the transcript of every video of a link and runs
+// it through a preset or pattern, a few videos at a time
+func (a *App) RunYouTubeBatch(req YouTubeBatchRequest) (*YouTubeBatchSummary, error) {
+	if req.Preset == "" && req.Pattern == "" {
+		return nil, fmt.Errorf("a preset or a pattern is required")
+	}
+
+	j, ctx := a.startJob("youtube", "YouTube: "+req.URL)
+	a.updateJob(j, -1, "Listing videos")
+	listing, err := listYouTubeVideos(ctx, req.URL, req.Limit)
+	if err == nil && len(listing.Videos) == 0 {
+		err = fmt.Errorf("no videos found at %s", req.URL)
+	}
+	if err != nil {
+		a.finishJob(j, err)
+		return nil, err
+	}
+	if req.OutputDir != "" {
+		if err := os.MkdirAll(req.OutputDir, 0755); err != nil {
+			err = fmt.Errorf("failed to create output folder: %v", err)
+			a.finishJob(j, err)
+			return nil, err
+		}
+	}
+
+	prefs, _ := a.loadPreferences()
+	workers := prefs.BatchWorkers
+	if workers <= 0 {
+		workers = defaultBatchWorkers
+	}
+	template := prefs.BatchTemplate
+	if template == "" {
+		template = defaultBatchTemplate
+	}
+
+	start := time.Now()
+	total := len(listing.Videos)
+	summary := &YouTubeBatchSummary{Title: listing.Title, Videos: make([]YouTubeVideoEvent, total)}
+	outputs := make([]string, total)
+	names := map[string]bool{} // output files taken, so videos of the same title are all kept
+	var summaryMutex sync.Mutex
+
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, total); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				event := YouTubeVideoEvent{YouTubeVideo: listing.Videos[i], Index: i, Total: total, Status: "started"}
+				a.emit("youtube:video", event)
+
+				prompt, entryID, output, err := a.runYouTubeVideo(ctx, req, &event.YouTubeVideo)
+				event.EntryID = entryID
+				if err == nil && req.OutputDir != "" {
+					summaryMutex.Lock()
+					name := fileNamePart(event.Title)
+					if name == "" {
+						name = event.ID
+					}
+					file := expandOutputTemplate(template, name, "", prompt.PatternName, prompt.Model, i)
+					if names[file] {
+						// Videos of the same title, or a template without {name}
+						ext := filepath.Ext(file)
+						file = strings.TrimSuffix(file, ext) + "-" + event.ID + ext
+					}
+					names[file] = true
+					summaryMutex.Unlock()
+
+					event.Output = filepath.Join(req.OutputDir, file)
+					if err = writeFileAtomic(event.Output, []byte(output), 0644); err != nil {
+						err = fmt.Errorf("failed to save output: %v", err)
+						event.Output = ""
+					}
+				}
+				if err != nil {
+					event.Status = "error"
+					event.Error = toChatError(err).Message
+				} else {
+					event.Status = "complete"
+				}
+
+				summaryMutex.Lock()
+				if err != nil {
+					summary.Failed++
+				} else {
+					summary.Succeeded++
+				}
+				summary.Videos[i] = event
+				outputs[i] = output
+				done := summary.Succeeded + summary.Failed
+				summaryMutex.Unlock()
+
+				a.updateJob(j, float64(done)/float64(total)*100, fmt.Sprintf("%d of %d videos", done, total))
+				a.emit("youtube:video", event)
+			}
+		}()
+	}
+
+	// Stop handing out videos once the job is cancelled
+	for i := range listing.Videos {
+		if ctx.Err() != nil {
+			break
+		}
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	if req.Digest && summary.Succeeded > 0 {
+		summary.Digest = youtubeDigestMarkdown(summary, outputs)
+		if req.OutputDir != "" {
+			name := fileNamePart(summary.Title)
+			if name == "" {
+				name = "youtube"
+			}
+			path := filepath.Join(req.OutputDir, expandOutputTemplate("{date}_{name}_digest.md", name, "", "", "", 0))
+			if err := writeFileAtomic(path, []byte(summary.Digest), 0644); err != nil {
+				a.log.Warn("failed to save YouTube digest", "path", path, "error", err)
+			} else {
+				summary.DigestPath = path
+			}
+		}
+	}
+
+	a.finishJob(j, ctx.Err())
+	summary.DurationMs = time.Since(start).Milliseconds()
+	a.emit("youtube:complete", summary)
+	return summary, nil
+}
+
+// runYouTubeVideo fetches a video's transcript through the server and runs
+// it, filling in the video's title when the listing had none. It returns the
+// prompt that ran, the history entry and the output.
+func (a *App) runYouTubeVideo(ctx context.Context, req YouTubeBatchRequest, video *YouTubeVideo) (PromptRequest, string, string, error) {
+	transcript, err := a.fabricClient().YouTubeTranscript(ctx, video.URL)
+	if err != nil {
+		return PromptRequest{}, "", "", err
+	}
+	if video.Title == "" {
+		video.Title = transcript.Title
+	}
+	if strings.TrimSpace(transcript.Transcript) == "" {
+		return PromptRequest{}, "", "", fmt.Errorf("the video has no transcript")
+	}
+	input := strings.TrimSpace(fmt.Sprintf("# %s\n%s", video.Title, video.URL)) + "\n\n" + transcript.Transcript
+
+	prompt, err := a.automatedPrompt(req.Preset, req.Pattern, req.Vendor, req.Model, input)
+	if err != nil {
+		return prompt, "", "", err
+	}
+	entryID, output, err := a.runAutomated(ctx, prompt)
+	return prompt, entryID, output, err
+}
+
+// youtubeDigestMarkdown lays out the outputs of a batch run by video
+func youtubeDigestMarkdown(summary *YouTubeBatchSummary, outputs []string) string {
+	var b strings.Builder
+	title := summary.Title
+	if title == "" {
+		title = "YouTube digest"
+	}
+	fmt.Fprintf(&b, "# %s, %s\n", title, time.Now().Format("2006-01-02 15:04"))
+	for i, video := range summary.Videos {
+		if video.Status == "" {
+			continue // not run, the job was cancelled
+		}
+		fmt.Fprintf(&b, "\n## [%s](%s)\n\n", video.Title, video.URL)
+		if video.Error != "" {
+			fmt.Fprintf(&b, "*Failed: %s*\n", video.Error)
+		} else {
+			b.WriteString(strings.TrimSpace(outputs[i]) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// listYouTubeVideos returns the videos a link points to. A single video is
+// returned as is, without a title; playlists and channels are listed with
+// yt-dlp.
+func listYouTubeVideos(ctx context.Context, link string, limit int) (*YouTubeListing, error) {
+	kind, target, err := parseYouTubeURL(link)
+	if err != nil {
+		return nil, err
+	}
+	if kind == "video" {
+		return &YouTubeListing{Videos: []YouTubeVideo{{ID: target, URL: youtubeWatchURL(target)}}}, nil
+	}
+
+	ytdlp, err := exec.LookPath("yt-dlp")
+	if err != nil {
+		return nil, fmt.Errorf("yt-dlp, needed to list a %s's videos, is not in PATH", kind)
+	}
+	args := []string{"--flat-playlist", "--dump-single-json", "--no-warnings"}
+	if limit > 0 {
+		args = append(args, "--playlist-end", fmt.Sprint(limit))
+	}
+	out, err := exec.CommandContext(ctx, ytdlp, append(args, target)...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			lines := strings.Split(strings.TrimSpace(string(exitErr.Stderr)), "\n")
+			return nil, fmt.Errorf("failed to list videos: %s", lines[len(lines)-1])
+		}
+		return nil, fmt.Errorf("failed to list videos: %v", err)
+	}
+
+	var playlist struct {
+		Title   string `json:"title"`
+		Entries []struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+		} `json:"entries"`
+	}
+	if err := json.Unmarshal(out, &playlist); err != nil {
+		return nil, fmt.Errorf("failed to read the video list: %v", err)
+	}
+	listing := &YouTubeListing{Title: playlist.Title, Videos: []YouTubeVideo{}}
+	for _, entry := range playlist.Entries {
+		// Channel tabs list other tabs, and playlists deleted videos, which
+		// have no ID to watch
+		if !youtubeVideoID.MatchString(entry.ID) {
+			continue
+		}
+		listing.Videos = append(listing.Videos, YouTubeVideo{ID: entry.ID, Title: entry.Title, URL: youtubeWatchURL(entry.ID)})
+	}
+	return listing, nil
+}
+
+// parseYouTubeURL returns what a YouTube link points to: a "video" and its
+// ID, or a "playlist" or "channel" and the URL that lists its videos
+func parseYouTubeURL(link string) (string, string, error) {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", "", fmt.Errorf("not a YouTube link: %s", link)
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	query := u.Query()
+
+	// A video opened from a playlist runs the playlist, except for the
+	// endless mixes YouTube makes up, whose IDs start with RD
+	if list := query.Get("list"); list != "" && !strings.HasPrefix(list, "RD") {
+		return "playlist", "https://www.youtube.com/playlist?list=" + url.QueryEscape(list), nil
+	}
+
+	switch host {
+	case "youtu.be":
+		if youtubeVideoID.MatchString(segments[0]) {
+			return "video", segments[0], nil
+		}
+	case "youtube.com", "m.youtube.com", "music.youtube.com":
+		switch {
+		case segments[0] == "watch" && youtubeVideoID.MatchString(query.Get("v")):
+			return "video", query.Get("v"), nil
+		case (segments[0] == "shorts" || segments[0] == "live" || segments[0] == "embed") && len(segments) > 1 && youtubeVideoID.MatchString(segments[1]):
+			return "video", segments[1], nil
+		case strings.HasPrefix(segments[0], "@"):
+			return "channel", "https://www.youtube.com/" + segments[0] + "/videos", nil
+		case (segments[0] == "channel" || segments[0] == "c" || segments[0] == "user") && len(segments) > 1:
+			return "channel", "https://www.youtube.com/" + segments[0] + "/" + segments[1] + "/videos", nil
+		}
+	}
+	return "", "", fmt.Errorf("not a YouTube video, playlist or channel link: %s", link)
+}
+
+// youtubeWatchURL returns the link to watch a video
+func youtubeWatchURL(id string) string {
+	return "https://www.youtube.com/watch?v=" + id
+}